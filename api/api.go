@@ -17,70 +17,196 @@ func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI) {
 
 	handler := handler.NewHandler(cfg, strg)
 
+	r.POST("/login", handler.Login)
+
+	// every other api needs a token from /login and a permission in config.Permissions
+	auth := r.Group("/", handler.Auth(), handler.Permission())
+
+	// user ...
+	auth.POST("/user", handler.CreateUser)
+	auth.GET("/user/:id", handler.GetByIDUser)
+	auth.GET("/user", handler.GetListUser)
+	auth.PUT("/user/:id", handler.UpdateUser)
+	auth.DELETE("/user/:id", handler.DeleteUser)
+
 	// registration api
-	r.GET("/branch_doc", handler.BranchDoc)
+	auth.GET("/branch_doc", handler.BranchDoc)
 
 	// registration api
-	r.GET("/registration", handler.Registration)
+	auth.GET("/registration", handler.Registration)
+
+	// debt aging api
+	auth.GET("/debt_aging", handler.DebtAging)
 
 	// pay api
-	r.PUT("/make_pay", handler.MakePay)
+	auth.PUT("/make_pay", handler.MakePay)
+
+	// payment ...
+	auth.POST("/payment", handler.CreatePayment)
+	auth.GET("/payment/:id", handler.GetByIDPayment)
+	auth.GET("/payment", handler.GetListPayment)
 
 	// picking_list ...
-	r.POST("/picking_list", handler.CreatePickingList)
-	r.GET("/picking_list/:id", handler.GetByIDPickingList)
-	r.GET("/picking_list", handler.GetListPickingList)
-	r.PUT("/picking_list/:id", handler.UpdatePickingList)
-	r.DELETE("/picking_list/:id", handler.DeletePickingList)
+	auth.POST("/picking_list", handler.CreatePickingList)
+	auth.GET("/picking_list/:id", handler.GetByIDPickingList)
+	auth.GET("/picking_list", handler.GetListPickingList)
+	auth.PUT("/picking_list/:id", handler.UpdatePickingList)
+	auth.DELETE("/picking_list/:id", handler.DeletePickingList)
+
+	// checkout ...
+	auth.POST("/checkout", handler.Checkout)
 
 	// sale_product ...
-	r.POST("/saleproduct", handler.CreateSaleProduct)
-	r.GET("/saleproduct/:id", handler.GetByIDSaleProduct)
-	r.GET("/saleproduct", handler.GetListSaleProduct)
-	r.PUT("/saleproduct/:id", handler.UpdateSaleProduct)
-	r.DELETE("/saleproduct/:id", handler.DeleteSaleProduct)
+	auth.POST("/saleproduct", handler.CreateSaleProduct)
+	auth.GET("/saleproduct/:id", handler.GetByIDSaleProduct)
+	auth.GET("/saleproduct", handler.GetListSaleProduct)
+	auth.PUT("/saleproduct/:id", handler.UpdateSaleProduct)
+	auth.DELETE("/saleproduct/:id", handler.DeleteSaleProduct)
 
 	// sale ...
-	r.POST("/sale", handler.CreateSale)
-	r.GET("/sale/:id", handler.GetByIDSale)
-	r.GET("/sale", handler.GetListSale)
-	r.PUT("/sale/:id", handler.UpdateSale)
-	r.DELETE("/sale/:id", handler.DeleteSale)
+	auth.POST("/sale", handler.CreateSale)
+	auth.GET("/sale/:id", handler.GetByIDSale)
+	auth.GET("/sale", handler.GetListSale)
+	auth.PUT("/sale/:id", handler.UpdateSale)
+	auth.DELETE("/sale/:id", handler.DeleteSale)
+	auth.GET("/sale/:id/lines", handler.GetSaleLines)
+
+	// sale_return ...
+	auth.POST("/sale_return", handler.CreateSaleReturn)
+	auth.GET("/sale_return/:id", handler.GetByIDSaleReturn)
+	auth.GET("/sale_return", handler.GetListSaleReturn)
 
 	// product ...
-	r.POST("/product", handler.CreateProduct)
-	r.GET("/product/:id", handler.GetByIDProduct)
-	r.GET("/product", handler.GetListProduct)
-	r.PUT("/product/:id", handler.UpdateProduct)
-	r.DELETE("/product/:id", handler.DeleteProduct)
+	auth.POST("/product", handler.CreateProduct)
+	auth.GET("/product/:id", handler.GetByIDProduct)
+	auth.GET("/product", handler.GetListProduct)
+	auth.PUT("/product/:id", handler.UpdateProduct)
+	auth.DELETE("/product/:id", handler.DeleteProduct)
+	auth.GET("/product/:id/movements", handler.GetProductMovements)
+	auth.GET("/product/by-barcode/:code", handler.GetProductByBarcode)
+
+	// category
+	auth.POST("/category", handler.CreateCategory)
+	auth.GET("/category/:id", handler.GetByIDCategory)
+	auth.GET("/category", handler.GetListCategory)
+	auth.GET("/category/tree", handler.GetCategoryTree)
+	auth.GET("/category/sales", handler.CategorySales)
+	auth.GET("/category/stock", handler.CategoryStock)
+	auth.PUT("/category/:id", handler.UpdateCategory)
+	auth.DELETE("/category/:id", handler.DeleteCategory)
 
 	// remainder ...
-	r.POST("/remainder", handler.CreateRemainder)
-	r.GET("/remainder/:id", handler.GetByIDRemainder)
-	r.GET("/remainder", handler.GetListRemainder)
-	r.PUT("/remainder/:id", handler.UpdateRemainder)
-	r.DELETE("/remainder/:id", handler.DeleteRemainder)
+	auth.POST("/remainder", handler.CreateRemainder)
+	auth.GET("/remainder/:id", handler.GetByIDRemainder)
+	auth.GET("/remainder/expiring", handler.GetExpiringRemainder)
+	auth.GET("/remainder/low", handler.GetLowStock)
+	auth.GET("/remainder", handler.GetListRemainder)
+	auth.PUT("/remainder/:id", handler.UpdateRemainder)
+	auth.DELETE("/remainder/:id", handler.DeleteRemainder)
+	auth.POST("/remainder/rebuild", handler.RebuildRemainder)
+
+	// stock_movement ...
+	auth.POST("/stock_movement", handler.CreateStockMovement)
+	auth.GET("/stock_movement/:id", handler.GetByIDStockMovement)
+	auth.GET("/stock_movement", handler.GetListStockMovement)
+
+	// transfer ...
+	auth.POST("/transfer", handler.CreateTransfer)
+	auth.GET("/transfer/in_transit", handler.GetInTransit)
+	auth.GET("/transfer/:id", handler.GetByIDTransfer)
+	auth.GET("/transfer", handler.GetListTransfer)
+	auth.PUT("/transfer/:id", handler.UpdateTransfer)
+	auth.DELETE("/transfer/:id", handler.DeleteTransfer)
+	auth.POST("/transfer/:id/send", handler.SendTransfer)
+	auth.POST("/transfer/:id/receive", handler.ReceiveTransfer)
+
+	// stock_take ...
+	auth.POST("/stock_take", handler.CreateStockTake)
+	auth.GET("/stock_take/:id", handler.GetByIDStockTake)
+	auth.GET("/stock_take", handler.GetListStockTake)
+	auth.DELETE("/stock_take/:id", handler.DeleteStockTake)
+	auth.PUT("/stock_take/:id/counts", handler.SetStockTakeCounts)
+	auth.POST("/stock_take/:id/import", handler.ImportStockTakeCounts)
+	auth.POST("/stock_take/:id/approve", handler.ApproveStockTake)
 
 	// client ...
-	r.POST("/client", handler.CreateClient)
-	r.GET("/client/:id", handler.GetByIDClient)
-	r.GET("/client", handler.GetListClient)
-	r.PUT("/client/:id", handler.UpdateClient)
-	r.DELETE("/client/:id", handler.DeleteClient)
+	auth.POST("/client", handler.CreateClient)
+	auth.GET("/client/:id", handler.GetByIDClient)
+	auth.GET("/client", handler.GetListClient)
+	auth.GET("/client/:id/statement", handler.ClientStatement)
+	auth.PUT("/client/:id", handler.UpdateClient)
+	auth.DELETE("/client/:id", handler.DeleteClient)
 
 	// branch ...
-	r.POST("/branch", handler.CreateBranch)
-	r.GET("/branch/:id", handler.GetByIDBranch)
-	r.GET("/branch", handler.GetListBranch)
-	r.PUT("/branch/:id", handler.UpdateBranch)
-	r.DELETE("/branch/:id", handler.DeleteBranch)
+	auth.POST("/branch", handler.CreateBranch)
+	auth.GET("/branch/:id", handler.GetByIDBranch)
+	auth.GET("/branch", handler.GetListBranch)
+	auth.PUT("/branch/:id", handler.UpdateBranch)
+	auth.DELETE("/branch/:id", handler.DeleteBranch)
+	auth.POST("/branch/:id/catalog", handler.CopyBranchCatalog)
+
+	// branch_product
+	auth.PUT("/branch_product", handler.SetBranchProduct)
+	auth.GET("/branch_product", handler.GetListBranchProduct)
+	auth.DELETE("/branch_product", handler.DeleteBranchProduct)
+
+	// price_change
+	auth.POST("/price_change", handler.CreatePriceChange)
+	auth.GET("/price_change/:id", handler.GetByIDPriceChange)
+	auth.GET("/price_change", handler.GetListPriceChange)
+	auth.GET("/price_change/as_of", handler.GetPriceAsOf)
+	auth.DELETE("/price_change/:id", handler.CancelPriceChange)
+
+	// repricing
+	auth.POST("/repricing", handler.CreateRepricing)
+	auth.GET("/repricing/:id", handler.GetByIDRepricing)
+	auth.GET("/repricing", handler.GetListRepricing)
+
+	// promotion
+	auth.POST("/promotion", handler.CreatePromotion)
+	auth.GET("/promotion/:id", handler.GetByIDPromotion)
+	auth.GET("/promotion", handler.GetListPromotion)
+	auth.PUT("/promotion/:id", handler.UpdatePromotion)
+	auth.DELETE("/promotion/:id", handler.DeletePromotion)
+	auth.GET("/promotion/report", handler.PromotionReport)
 
 	// coming
-	r.POST("/coming", handler.CreateComing)
-	r.GET("/coming/:id", handler.GetByIDComing)
-	r.GET("/coming", handler.GetListComing)
-	r.PUT("/coming/:id", handler.UpdateComing)
-	r.DELETE("/coming/:id", handler.DeleteComing)
+	auth.POST("/coming", handler.CreateComing)
+	auth.GET("/coming/:id", handler.GetByIDComing)
+	auth.GET("/coming", handler.GetListComing)
+	auth.PUT("/coming/:id", handler.UpdateComing)
+	auth.DELETE("/coming/:id", handler.DeleteComing)
+	auth.POST("/coming/:id/finish", handler.FinishComing)
+	auth.POST("/coming/:id/reverse", handler.ReverseComing)
+	auth.GET("/coming/:id/events", handler.GetComingEvents)
+
+	// supplier
+	auth.POST("/supplier", handler.CreateSupplier)
+	auth.GET("/supplier/:id", handler.GetByIDSupplier)
+	auth.GET("/supplier", handler.GetListSupplier)
+	auth.PUT("/supplier/:id", handler.UpdateSupplier)
+	auth.DELETE("/supplier/:id", handler.DeleteSupplier)
+	auth.GET("/supplier/:id/statement", handler.SupplierStatement)
+	auth.GET("/supplier_payable", handler.GetPayables)
+	auth.POST("/supplier_payment", handler.CreateSupplierPayment)
+	auth.GET("/supplier_payment/:id", handler.GetByIDSupplierPayment)
+	auth.GET("/supplier_payment", handler.GetListSupplierPayment)
+
+	// purchase_order
+	auth.POST("/purchase_order", handler.CreatePurchaseOrder)
+	auth.GET("/purchase_order/:id", handler.GetByIDPurchaseOrder)
+	auth.GET("/purchase_order", handler.GetListPurchaseOrder)
+	auth.PUT("/purchase_order/:id", handler.UpdatePurchaseOrder)
+	auth.DELETE("/purchase_order/:id", handler.DeletePurchaseOrder)
+	auth.POST("/purchase_order/:id/coming", handler.CreateComingFromPurchaseOrder)
+
+	// stock_level
+	auth.PUT("/stock_level", handler.SetStockLevel)
+	auth.GET("/stock_level", handler.GetListStockLevel)
+	auth.DELETE("/stock_level", handler.DeleteStockLevel)
+	auth.GET("/reorder", handler.GetReorder)
+	auth.POST("/reorder/purchase_order", handler.CreateReorderPurchaseOrder)
+	auth.POST("/reorder/coming", handler.CreateReorderComing)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 }
@@ -46,6 +46,18 @@ const docTemplate = `{
                         "description": "search",
                         "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -281,9 +293,62 @@ const docTemplate = `{
                 }
             }
         },
+        "/branch/{id}/catalog": {
+            "post": {
+                "description": "Copy the assortment and price overrides of another branch to this one, typically a newly opened branch. Products the branch already has take the price of the other branch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BranchProduct"
+                ],
+                "summary": "Copy a Branch catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch to copy to",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "branch to copy from",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CopyCatalog"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Copied",
+                        "schema": {
+                            "$ref": "#/definitions/models.CopyCatalogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/branch_doc": {
             "get": {
-                "description": "Get Branch sales.",
+                "description": "Get Branch sales, gross and net of returns.",
                 "consumes": [
                     "application/json"
                 ],
@@ -331,9 +396,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/client": {
+        "/branch_product": {
             "get": {
-                "description": "Get List Client details by its ok.",
+                "description": "Get the assortment of branches with the price each sells at, by product name.",
                 "consumes": [
                     "application/json"
                 ],
@@ -341,9 +406,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "BranchProduct"
                 ],
-                "summary": "Get List Client",
+                "summary": "Get List Branch Product",
                 "parameters": [
                     {
                         "type": "integer",
@@ -359,16 +424,28 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "search",
+                        "description": "product name",
                         "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Client details",
+                        "description": "Branch Products",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.GetListBranchProductResponse"
                         }
                     },
                     "400": {
@@ -377,12 +454,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -391,8 +462,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create Client",
+            "put": {
+                "description": "Put a product of the catalog in the assortment of a branch, or change its price there. Without a price the branch sells it at the catalog price.",
                 "consumes": [
                     "application/json"
                 ],
@@ -400,25 +471,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "BranchProduct"
                 ],
-                "summary": "create a Client",
+                "summary": "Set Branch Product",
                 "parameters": [
                     {
-                        "description": "Client ID",
+                        "description": "Branch Product",
                         "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateClient"
+                            "$ref": "#/definitions/models.SetBranchProduct"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Client details",
+                        "description": "Branch Product",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.BranchProduct"
                         }
                     },
                     "400": {
@@ -427,8 +498,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -440,11 +511,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/client/{id}": {
-            "get": {
-                "description": "Get Client details by its ID.",
+            },
+            "delete": {
+                "description": "Take a product out of the assortment of a branch, the catalog keeps it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -452,23 +521,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "BranchProduct"
                 ],
-                "summary": "Get a Client by ID",
+                "summary": "Delete Branch Product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Client ID",
-                        "name": "id",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "product_id",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Client details",
+                        "description": "deleted",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/handler.Response"
                         }
                     },
                     "400": {
@@ -477,8 +553,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -490,9 +566,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Get List Client details by its ok.",
+            }
+        },
+        "/category": {
+            "get": {
+                "description": "Get categories, newest first. Filter by parent_id for the children of a category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -500,32 +578,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "Category"
                 ],
-                "summary": "Update Client",
+                "summary": "Get List Category",
                 "parameters": [
                     {
-                        "description": "models.UpdateClient",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateClient"
-                        }
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "parent_id, comma separated for several",
+                        "name": "parent_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Client details",
+                        "description": "Categories",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.GetListCategoryResponse"
                         }
                     },
                     "400": {
@@ -534,12 +632,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -548,8 +640,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Get List Client details by its ok",
+            "post": {
+                "description": "Create a Category under parent_id, a root category when it is empty.",
                 "consumes": [
                     "application/json"
                 ],
@@ -557,23 +649,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Client"
+                    "Category"
                 ],
-                "summary": "Get List Client",
+                "summary": "Create a Category",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Category",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCategory"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Client details",
+                    "201": {
+                        "description": "Created Category",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
                     "400": {
@@ -582,12 +676,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -597,9 +685,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/coming": {
+        "/category/sales": {
             "get": {
-                "description": "Get a list of Comings with optional filtering.",
+                "description": "Sold quantity and amount for every child of parent_id, the root categories when it is empty, each with all of its descendants. A last row with the parent's id holds what was sold directly in the parent, or without a category at the root level. Each row is gross, with what came back of those sales and the net of returns.",
                 "consumes": [
                     "application/json"
                 ],
@@ -607,37 +695,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Coming"
+                    "Category"
                 ],
-                "summary": "Get a list of Comings",
+                "summary": "Sales by category",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Number of items to return (default 10)",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "parent category, the roots when empty",
+                        "name": "parent_id",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of items to skip (default 0)",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "branch_id, all branches when empty",
+                        "name": "branch_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search term",
-                        "name": "search",
+                        "description": "sales from, date or RFC3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sales to, date or RFC3339",
+                        "name": "to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of Comings",
+                        "description": "Sales by category",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Coming"
-                            }
+                            "$ref": "#/definitions/models.CategorySalesResponse"
                         }
                     },
                     "400": {
@@ -646,8 +737,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -659,9 +750,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new Coming in the market system.",
+            }
+        },
+        "/category/stock": {
+            "get": {
+                "description": "Stock quantity, cost and sale value for every child of parent_id, the root categories when it is empty, each with all of its descendants. A last row with the parent's id holds the stock directly in the parent, or without a category at the root level.",
                 "consumes": [
                     "application/json"
                 ],
@@ -669,25 +762,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Coming"
+                    "Category"
                 ],
-                "summary": "Create a new Coming",
+                "summary": "Stock by category",
                 "parameters": [
                     {
-                        "description": "Coming information",
-                        "name": "Coming",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateComing"
-                        }
+                        "type": "string",
+                        "description": "parent category, the roots when empty",
+                        "name": "parent_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, all branches when empty",
+                        "name": "branch_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created Coming",
+                    "200": {
+                        "description": "Stock by category",
                         "schema": {
-                            "$ref": "#/definitions/models.Coming"
+                            "$ref": "#/definitions/models.CategoryStockResponse"
                         }
                     },
                     "400": {
@@ -696,8 +792,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -711,9 +807,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/coming/{id}": {
+        "/category/tree": {
             "get": {
-                "description": "Get Coming details by its ID.",
+                "description": "Every category nested under its parent, siblings by name.",
                 "consumes": [
                     "application/json"
                 ],
@@ -721,39 +817,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Coming"
-                ],
-                "summary": "Get an Coming by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Coming ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Category"
                 ],
+                "summary": "Category tree",
                 "responses": {
                     "200": {
-                        "description": "Coming details",
+                        "description": "Root categories",
                         "schema": {
-                            "$ref": "#/definitions/models.Coming"
-                        }
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CategoryNode"
+                            }
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/category/{id}": {
+            "get": {
+                "description": "Get a Category by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Category"
+                ],
+                "summary": "Get a Category by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Category details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Coming not found",
+                        "description": "Category not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -767,7 +889,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update an existing Coming.",
+                "description": "Rename a Category or move it with its descendants under another parent, not under itself or one of its descendants.",
                 "consumes": [
                     "application/json"
                 ],
@@ -775,32 +897,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Coming"
+                    "Category"
                 ],
-                "summary": "Update an Coming",
+                "summary": "Update Category",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Coming ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated Coming information",
-                        "name": "Coming",
+                        "description": "models.UpdateCategory",
+                        "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateComing"
+                            "$ref": "#/definitions/models.UpdateCategory"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Updated Coming",
+                        "description": "Category details",
                         "schema": {
-                            "$ref": "#/definitions/models.Coming"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
                     "400": {
@@ -809,18 +931,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Coming not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -830,7 +940,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete an existing Coming.",
+                "description": "Delete a Category without children and products.",
                 "consumes": [
                     "application/json"
                 ],
@@ -838,21 +948,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Coming"
+                    "Category"
                 ],
-                "summary": "Delete an Coming",
+                "summary": "Delete Category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Coming ID",
+                        "description": "id",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -860,8 +973,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Category is in use",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -875,9 +988,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/make_pay": {
-            "put": {
-                "description": "Pay client's Sale.",
+        "/checkout": {
+            "post": {
+                "description": "Create a Sale with its products and decrement branch stock in one transaction, from the lots expiring first. Expired lots are not sold. A quantity may be in any unit of the product, the stock is taken in the base unit and a pack with a price of its own sells for it. Every line gets the running promotion taking the most off it, its total_price is net of the discount. Only products in the assortment of the branch are sold.",
                 "consumes": [
                     "application/json"
                 ],
@@ -885,29 +998,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Pay"
+                    "Sale"
                 ],
-                "summary": "MakePay",
+                "summary": "Checkout",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "sale_id",
-                        "name": "sale_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "pay_money",
-                        "name": "money",
-                        "in": "query",
-                        "required": true
+                        "description": "Checkout",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCheckout"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Payed",
+                    "201": {
+                        "description": "Checkout details",
                         "schema": {
-                            "$ref": "#/definitions/models.Coming"
+                            "$ref": "#/definitions/models.Checkout"
                         }
                     },
                     "400": {
@@ -916,12 +1025,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Sale not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -931,9 +1034,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/picking_list": {
+        "/client": {
             "get": {
-                "description": "Get List PickingList details by its ok.",
+                "description": "Get List Client details by its ok.",
                 "consumes": [
                     "application/json"
                 ],
@@ -941,9 +1044,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PickingList"
+                    "Client"
                 ],
-                "summary": "Get List PickingList",
+                "summary": "Get List Client",
                 "parameters": [
                     {
                         "type": "integer",
@@ -958,17 +1061,47 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "search",
                         "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "gender, comma separated for several",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "active, comma separated for several",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "PickingList details",
+                        "description": "Client details",
                         "schema": {
-                            "$ref": "#/definitions/models.PickingList"
+                            "$ref": "#/definitions/models.Client"
                         }
                     },
                     "400": {
@@ -978,7 +1111,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "PickingList not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -992,7 +1125,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create PickingList",
+                "description": "Create Client",
                 "consumes": [
                     "application/json"
                 ],
@@ -1000,25 +1133,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PickingList"
+                    "Client"
                 ],
-                "summary": "create a PickingList",
+                "summary": "create a Client",
                 "parameters": [
                     {
-                        "description": "PickingList ID",
+                        "description": "Client ID",
                         "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePickingList"
+                            "$ref": "#/definitions/models.CreateClient"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "PickingList details",
+                        "description": "Client details",
                         "schema": {
-                            "$ref": "#/definitions/models.PickingList"
+                            "$ref": "#/definitions/models.Client"
                         }
                     },
                     "400": {
@@ -1028,7 +1161,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "PickingList not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1042,9 +1175,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/picking_list/{id}": {
+        "/client/{id}": {
             "get": {
-                "description": "Get PickingList details by its ID.",
+                "description": "Get Client details by its ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1052,13 +1185,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PickingList"
+                    "Client"
                 ],
-                "summary": "Get a PickingList by ID",
+                "summary": "Get a Client by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "PickingList ID",
+                        "description": "Client ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -1066,9 +1199,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "PickingList details",
+                        "description": "Client details",
                         "schema": {
-                            "$ref": "#/definitions/models.PickingList"
+                            "$ref": "#/definitions/models.Client"
                         }
                     },
                     "400": {
@@ -1078,7 +1211,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "PickingList not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1092,7 +1225,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Get List PickingList details by its ok.",
+                "description": "Get List Client details by its ok.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1100,17 +1233,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PickingList"
+                    "Client"
                 ],
-                "summary": "Update PickingList",
+                "summary": "Update Client",
                 "parameters": [
                     {
-                        "description": "models.UpdatePickingList",
+                        "description": "models.UpdateClient",
                         "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdatePickingList"
+                            "$ref": "#/definitions/models.UpdateClient"
                         }
                     },
                     {
@@ -1123,9 +1256,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "PickingList details",
+                        "description": "Client details",
                         "schema": {
-                            "$ref": "#/definitions/models.PickingList"
+                            "$ref": "#/definitions/models.Client"
                         }
                     },
                     "400": {
@@ -1135,7 +1268,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "PickingList not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1149,7 +1282,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Get List PickingList details by its ok",
+                "description": "Get List Client details by its ok",
                 "consumes": [
                     "application/json"
                 ],
@@ -1157,9 +1290,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PickingList"
+                    "Client"
                 ],
-                "summary": "Get List PickingList",
+                "summary": "Get List Client",
                 "parameters": [
                     {
                         "type": "string",
@@ -1171,9 +1304,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "PickingList details",
+                        "description": "Client details",
                         "schema": {
-                            "$ref": "#/definitions/models.PickingList"
+                            "$ref": "#/definitions/models.Client"
                         }
                     },
                     "400": {
@@ -1183,7 +1316,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "PickingList not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1197,9 +1330,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/product": {
+        "/client/{id}/statement": {
             "get": {
-                "description": "Get List Product details by its ok.",
+                "description": "Sales and payments of a Client with a running balance.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1207,34 +1340,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Client"
                 ],
-                "summary": "Get List Product",
+                "summary": "Client statement",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "from, date or RFC3339",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
+                        "description": "to, date or RFC3339",
+                        "name": "to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product details",
+                        "description": "Client statement",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.ClientStatement"
                         }
                     },
                     "400": {
@@ -1244,7 +1378,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Client not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1256,9 +1390,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create Product",
+            }
+        },
+        "/coming": {
+            "get": {
+                "description": "Get a list of Comings with optional filtering.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1266,25 +1402,73 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Coming"
                 ],
-                "summary": "create a Product",
+                "summary": "Get a list of Comings",
                 "parameters": [
                     {
-                        "description": "Product ID",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateProduct"
-                        }
-                    }
-                ],
+                        "type": "integer",
+                        "description": "Number of items to return (default 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "supplier_id, comma separated for several",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "purchase_order_id, comma separated for several",
+                        "name": "purchase_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Product details",
+                        "description": "List of Comings",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Coming"
+                            }
                         }
                     },
                     "400": {
@@ -1293,8 +1477,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Product not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1306,11 +1490,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/product/{id}": {
-            "get": {
-                "description": "Get Product details by its ID.",
+            },
+            "post": {
+                "description": "Create a new Coming in the market system.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1318,23 +1500,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Coming"
                 ],
-                "summary": "Get a Product by ID",
+                "summary": "Create a new Coming",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Coming information",
+                        "name": "Coming",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateComing"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Product details",
+                    "201": {
+                        "description": "Created Coming",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Coming"
                         }
                     },
                     "400": {
@@ -1343,8 +1527,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Product not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1356,9 +1540,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Get List Product details by its ok.",
+            }
+        },
+        "/coming/{id}": {
+            "get": {
+                "description": "Get Coming details by its ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1366,22 +1552,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Coming"
                 ],
-                "summary": "Update Product",
+                "summary": "Get an Coming by ID",
                 "parameters": [
-                    {
-                        "description": "models.UpdateProduct",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateProduct"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "Coming ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -1389,9 +1566,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Product details",
+                        "description": "Coming details",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Coming"
                         }
                     },
                     "400": {
@@ -1400,8 +1577,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Coming not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1414,8 +1597,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Get List Product details by its ok",
+            "put": {
+                "description": "Update an existing Coming.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1423,23 +1606,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Coming"
                 ],
-                "summary": "Get List Product",
+                "summary": "Update an Coming",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "Coming ID",
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "Updated Coming information",
+                        "name": "Coming",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateComing"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Product details",
+                    "202": {
+                        "description": "Updated Coming",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Coming"
                         }
                     },
                     "400": {
@@ -1448,8 +1640,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Coming not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1461,11 +1659,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/registration": {
-            "get": {
-                "description": "Get clients.",
+            },
+            "delete": {
+                "description": "Delete an existing Coming.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1473,31 +1669,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Registration"
+                    "Coming"
                 ],
-                "summary": "Registration",
+                "summary": "Delete an Coming",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "From day",
-                        "name": "from",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "To day",
-                        "name": "to",
+                        "description": "Coming ID",
+                        "name": "id",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Client details",
-                        "schema": {
-                            "$ref": "#/definitions/models.GetListClientResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1505,8 +1691,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Client not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1520,9 +1706,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/remainder": {
+        "/coming/{id}/events": {
             "get": {
-                "description": "Get List Remainder details by its ok.",
+                "description": "Who finished and reversed a Coming, and why.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1530,34 +1716,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Remainder"
+                    "Coming"
                 ],
-                "summary": "Get List Remainder",
+                "summary": "Coming events",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Coming ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Remainder details",
+                        "description": "Coming events",
                         "schema": {
-                            "$ref": "#/definitions/models.Remainder"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ComingEvent"
+                            }
                         }
                     },
                     "400": {
@@ -1566,12 +1744,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Remainder not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1579,9 +1751,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/coming/{id}/finish": {
             "post": {
-                "description": "Create Remainder",
+                "description": "Post every picking list of a draft Coming to the remainder of its branch and lock it against edits. Every product must be in the assortment of the branch.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1589,25 +1763,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Remainder"
+                    "Coming"
                 ],
-                "summary": "create a Remainder",
+                "summary": "Finish a Coming",
                 "parameters": [
                     {
-                        "description": "Remainder ID",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateRemainder"
-                        }
+                        "type": "string",
+                        "description": "Coming ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Remainder details",
+                        "description": "Finished Coming",
                         "schema": {
-                            "$ref": "#/definitions/models.Remainder"
+                            "$ref": "#/definitions/models.Coming"
                         }
                     },
                     "400": {
@@ -1617,7 +1789,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Remainder not found",
+                        "description": "Coming not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Coming is not a draft",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1631,9 +1809,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/remainder/{id}": {
-            "get": {
-                "description": "Get Remainder details by its ID.",
+        "/coming/{id}/reverse": {
+            "post": {
+                "description": "Take the picking lists of a finished Coming back out of the remainder of its branch.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1641,23 +1819,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Remainder"
+                    "Coming"
                 ],
-                "summary": "Get a Remainder by ID",
+                "summary": "Reverse a Coming",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Remainder ID",
+                        "description": "Coming ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReverseComing"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Remainder details",
+                        "description": "Reversed Coming",
                         "schema": {
-                            "$ref": "#/definitions/models.Remainder"
+                            "$ref": "#/definitions/models.Coming"
                         }
                     },
                     "400": {
@@ -1667,7 +1854,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Remainder not found",
+                        "description": "Coming not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Coming is not finished or its stock is already sold",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1679,9 +1872,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Get List Remainder details by its ok.",
+            }
+        },
+        "/debt_aging": {
+            "get": {
+                "description": "Outstanding debt of clients by days since the sale: 0-30, 31-60, 61-90 and 90+.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1689,32 +1884,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Remainder"
+                    "Client"
                 ],
-                "summary": "Update Remainder",
+                "summary": "Debt aging",
                 "parameters": [
                     {
-                        "description": "models.UpdateRemainder",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateRemainder"
-                        }
+                        "type": "string",
+                        "description": "Branch Id",
+                        "name": "branch_id",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "count the age to this day, now by default",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Remainder details",
+                        "description": "Debt aging",
                         "schema": {
-                            "$ref": "#/definitions/models.Remainder"
+                            "$ref": "#/definitions/models.DebtAgingResponse"
                         }
                     },
                     "400": {
@@ -1723,12 +1914,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Remainder not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1736,9 +1921,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Get List Remainder details by its ok",
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Exchange login and password for an access token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1746,23 +1933,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Remainder"
+                    "Auth"
                 ],
-                "summary": "Get List Remainder",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Login",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Remainder details",
+                        "description": "Access token",
                         "schema": {
-                            "$ref": "#/definitions/models.Remainder"
+                            "$ref": "#/definitions/models.LoginResponse"
                         }
                     },
                     "400": {
@@ -1771,8 +1960,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Remainder not found",
+                    "401": {
+                        "description": "Wrong login or password",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1786,9 +1975,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/sale": {
-            "get": {
-                "description": "Get List Sale details by its ok.",
+        "/make_pay": {
+            "put": {
+                "description": "Pay client's Sale by its increment id. Payments add up, a negative money is a refund.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1796,34 +1985,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sale"
+                    "Pay"
                 ],
-                "summary": "Get List Sale",
+                "summary": "MakePay",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
+                        "type": "string",
+                        "description": "sale increment_id",
+                        "name": "sale_id",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
+                        "type": "number",
+                        "description": "pay_money",
+                        "name": "money",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "cash, card or transfer, cash by default",
+                        "name": "method",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
+                        "description": "cashier",
+                        "name": "cashier",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Sale details",
+                    "201": {
+                        "description": "Payed",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Payment"
                         }
                     },
                     "400": {
@@ -1845,9 +2042,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create Sale",
+            }
+        },
+        "/payment": {
+            "get": {
+                "description": "Get payment history, newest first. Filter by sale_id for the history of one sale.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1855,25 +2054,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sale"
+                    "Payment"
                 ],
-                "summary": "create a Sale",
+                "summary": "Get List Payment",
                 "parameters": [
                     {
-                        "description": "Sale ID",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateSale"
-                        }
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sale_id, comma separated for several",
+                        "name": "sale_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "method, comma separated for several",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cashier, comma separated for several",
+                        "name": "cashier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id of the sale, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min amount",
+                        "name": "amount_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max amount",
+                        "name": "amount_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Sale details",
+                        "description": "Payment details",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.GetListPaymentResponse"
                         }
                     },
                     "400": {
@@ -1882,12 +2138,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Sale not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1895,11 +2145,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/sale/{id}": {
-            "get": {
-                "description": "Get Sale details by its ID.",
+            },
+            "post": {
+                "description": "Pay a Sale. A negative amount is a refund.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1907,23 +2155,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sale"
+                    "Payment"
                 ],
-                "summary": "Get a Sale by ID",
+                "summary": "create a Payment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Sale ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Payment",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePayment"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Sale details",
+                    "201": {
+                        "description": "Payment details",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Payment"
                         }
                     },
                     "400": {
@@ -1932,12 +2182,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Sale not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1945,9 +2189,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Get List Sale details by its ok.",
+            }
+        },
+        "/payment/{id}": {
+            "get": {
+                "description": "Get Payment details by its ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1955,32 +2201,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sale"
+                    "Payment"
                 ],
-                "summary": "Update Sale",
+                "summary": "Get a Payment by ID",
                 "parameters": [
-                    {
-                        "description": "models.UpdateSale",
-                        "name": "object",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateSale"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "Payment ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Sale details",
+                        "description": "Payment details",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Payment"
                         }
                     },
                     "400": {
@@ -1990,7 +2227,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Sale not found",
+                        "description": "Payment not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2002,9 +2239,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Get List Sale details by its ok",
+            }
+        },
+        "/picking_list": {
+            "get": {
+                "description": "Get List PickingList details by its ok.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2012,59 +2251,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sale"
+                    "PickingList"
                 ],
-                "summary": "Get List Sale",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Sale details",
-                        "schema": {
-                            "$ref": "#/definitions/models.Sale"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Sale not found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/saleproduct": {
-            "get": {
-                "description": "Get List SaleProduct details by its ok.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "SaleProduct"
-                ],
-                "summary": "Get List SaleProduct",
+                "summary": "Get List PickingList",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2079,17 +2268,95 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "search",
                         "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "coming_id, comma separated for several",
+                        "name": "coming_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "coming_increment_id, comma separated for several",
+                        "name": "coming_increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lot_number, comma separated for several",
+                        "name": "lot_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id of the coming, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min quantity",
+                        "name": "quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max quantity",
+                        "name": "quantity_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min total_price",
+                        "name": "total_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max total_price",
+                        "name": "total_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "SaleProduct details",
+                        "description": "PickingList details",
                         "schema": {
-                            "$ref": "#/definitions/models.SaleProduct"
+                            "$ref": "#/definitions/models.PickingList"
                         }
                     },
                     "400": {
@@ -2099,7 +2366,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "SaleProduct not found",
+                        "description": "PickingList not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2113,7 +2380,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create SaleProduct",
+                "description": "Create a PickingList line of a draft coming. Quantity and price are in unit, a pack of the product or its base unit when empty, and are kept in the base unit.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2121,25 +2388,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SaleProduct"
+                    "PickingList"
                 ],
-                "summary": "create a SaleProduct",
+                "summary": "create a PickingList",
                 "parameters": [
                     {
-                        "description": "SaleProduct ID",
+                        "description": "PickingList ID",
                         "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateSaleProduct"
+                            "$ref": "#/definitions/models.CreatePickingList"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "SaleProduct details",
+                        "description": "PickingList details",
                         "schema": {
-                            "$ref": "#/definitions/models.SaleProduct"
+                            "$ref": "#/definitions/models.PickingList"
                         }
                     },
                     "400": {
@@ -2149,7 +2416,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "SaleProduct not found",
+                        "description": "PickingList not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2163,9 +2430,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/saleproduct/{id}": {
+        "/picking_list/{id}": {
             "get": {
-                "description": "Get SaleProduct details by its ID.",
+                "description": "Get PickingList details by its ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2173,13 +2440,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SaleProduct"
+                    "PickingList"
                 ],
-                "summary": "Get a SaleProduct by ID",
+                "summary": "Get a PickingList by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "SaleProduct ID",
+                        "description": "PickingList ID",
                         "name": "id",
                         "in": "query",
                         "required": true
@@ -2187,9 +2454,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "SaleProduct details",
+                        "description": "PickingList details",
                         "schema": {
-                            "$ref": "#/definitions/models.SaleProduct"
+                            "$ref": "#/definitions/models.PickingList"
                         }
                     },
                     "400": {
@@ -2199,7 +2466,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "SaleProduct not found",
+                        "description": "PickingList not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2213,7 +2480,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Get List SaleProduct details by its ok.",
+                "description": "Get List PickingList details by its ok.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2221,17 +2488,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SaleProduct"
+                    "PickingList"
                 ],
-                "summary": "Update SaleProduct",
+                "summary": "Update PickingList",
                 "parameters": [
                     {
-                        "description": "models.UpdateSaleProduct",
+                        "description": "models.UpdatePickingList",
                         "name": "object",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateSaleProduct"
+                            "$ref": "#/definitions/models.UpdatePickingList"
                         }
                     },
                     {
@@ -2244,9 +2511,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "SaleProduct details",
+                        "description": "PickingList details",
                         "schema": {
-                            "$ref": "#/definitions/models.SaleProduct"
+                            "$ref": "#/definitions/models.PickingList"
                         }
                     },
                     "400": {
@@ -2256,7 +2523,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "SaleProduct not found",
+                        "description": "PickingList not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2270,7 +2537,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Get List SaleProduct details by its ok",
+                "description": "Get List PickingList details by its ok",
                 "consumes": [
                     "application/json"
                 ],
@@ -2278,9 +2545,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SaleProduct"
+                    "PickingList"
                 ],
-                "summary": "Get List SaleProduct",
+                "summary": "Get List PickingList",
                 "parameters": [
                     {
                         "type": "string",
@@ -2292,9 +2559,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "SaleProduct details",
+                        "description": "PickingList details",
                         "schema": {
-                            "$ref": "#/definitions/models.SaleProduct"
+                            "$ref": "#/definitions/models.PickingList"
                         }
                     },
                     "400": {
@@ -2304,7 +2571,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "SaleProduct not found",
+                        "description": "PickingList not found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -2317,159 +2584,7689 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Branch": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Client": {
-            "type": "object",
-            "properties": {
-                "active": {
-                    "type": "string"
-                },
-                "birthday": {
-                    "type": "string"
-                },
-                "branch_id": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "father_name": {
-                    "type": "string"
-                },
-                "first_name": {
-                    "type": "string"
-                },
-                "gender": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
         },
-        "models.Coming": {
-            "type": "object",
-            "properties": {
-                "branch_id": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "increment_id": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
+        "/price_change": {
+            "get": {
+                "description": "Get the price changes of products, latest effective_from first. Catalog changes have an empty branch_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PriceChange"
+                ],
+                "summary": "Get the Price history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "repricing id, comma separated for several",
+                        "name": "document_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only the changes not applied yet",
+                        "name": "pending",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListPriceChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Change the catalog price of a product, or its price in branch_id. Without effective_from, or with a past one, the change is applied right away, otherwise when it becomes due. A null price puts the branch back on the catalog price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PriceChange"
+                ],
+                "summary": "Schedule a Price change",
+                "parameters": [
+                    {
+                        "description": "Price change",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePriceChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Price change",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price_change/as_of": {
+            "get": {
+                "description": "Get the price of a product in a branch at a time, from its price history, the catalog price when the branch had no override then. Without branch_id the catalog price, without at the price now. Scheduled changes count from their effective_from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PriceChange"
+                ],
+                "summary": "Get a Price as of a time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "product_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date or RFC3339, now when empty",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceAsOf"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No price at that time",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price_change/{id}": {
+            "get": {
+                "description": "Get a change of the price history, applied_at is empty while it waits for effective_from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PriceChange"
+                ],
+                "summary": "Get a Price change by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price change ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price change",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Price change not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a scheduled price change before it is applied, an applied one stays in the history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PriceChange"
+                ],
+                "summary": "Cancel a Price change",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price change ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already applied",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/product": {
+            "get": {
+                "description": "Get List Product details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get List Product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "products the branch carries, comma separated for several branches",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "category_id with its descendants, comma separated for several",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a Product of the catalog with its barcodes, its base unit (pcs when empty) and the packs it is received and sold in. A product created for a branch_id is put in the assortment of that branch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "create a Product",
+                "parameters": [
+                    {
+                        "description": "Product ID",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProduct"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/by-barcode/{code}": {
+            "get": {
+                "description": "Find the product of a barcode for the POS, with the sale price of the lot a sale takes first and the sellable stock of the branch. The sale price is the price of the branch when nothing is in stock. EAN codes with a wrong check digit are rejected as misreads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Scan a Product barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, the user's branch for branch users, all branches when empty",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product with price and stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductByBarcode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Barcode not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}": {
+            "get": {
+                "description": "Get Product details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get a Product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Get List Product details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Update Product",
+                "parameters": [
+                    {
+                        "description": "models.UpdateProduct",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProduct"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Get List Product details by its ok",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get List Product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Product details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/movements": {
+            "get": {
+                "description": "Every receipt, sale, return, transfer, write off and adjustment of a product, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockMovement"
+                ],
+                "summary": "Product movement history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "type, comma separated for several",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock movements",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListStockMovementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotion": {
+            "get": {
+                "description": "Get the promotion rules, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Get List Promotion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "type, comma separated for several",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "category_id, comma separated for several",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client_group, comma separated for several",
+                        "name": "client_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min value",
+                        "name": "value_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max value",
+                        "name": "value_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promotions",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListPromotionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a discount given at checkout from starts_at, now when empty, until ends_at, for good when empty. percent takes value percent off a line, fixed takes value off each unit sold and buy_x_get_y gives free_quantity units of every buy_quantity + free_quantity sold. branch_id, product_id, category_id with its subcategories and client_group limit where it applies when set. A line gets the promotion taking the most off it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Create a Promotion",
+                "parameters": [
+                    {
+                        "description": "Promotion",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePromotion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotion/report": {
+            "get": {
+                "description": "The discount every promotion gave on the sales of branch_id, all branches when empty, created in [from, to), with the sales, lines and base units it was given on. Returned units are left out with their share of the discount, deleted promotions are kept. The costliest promotion comes first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Promotion costs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id, all branches when empty",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sales from, date or RFC3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sales to, date or RFC3339",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promotion costs",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromotionReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotion/{id}": {
+            "get": {
+                "description": "Get a promotion rule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Get a Promotion by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promotion not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change a promotion rule, the sales it already discounted keep their discount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Update Promotion",
+                "parameters": [
+                    {
+                        "description": "models.UpdatePromotion",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePromotion"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a promotion rule. It ends now, or never starts when it has not, and stays on the sales it discounted and in the report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotion"
+                ],
+                "summary": "Delete Promotion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase_order": {
+            "get": {
+                "description": "Get purchase orders without their lines, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Get List Purchase Order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "supplier_id, comma separated for several",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "open or closed, comma separated for several",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Purchase Orders",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListPurchaseOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Order products from a supplier for a branch at agreed prices.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Create a Purchase Order",
+                "parameters": [
+                    {
+                        "description": "Purchase Order",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePurchaseOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Purchase Order",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase_order/{id}": {
+            "get": {
+                "description": "Get a Purchase Order with its lines: ordered and received quantities, the average received price and the deviations (under, over, price).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Get a Purchase Order by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Purchase Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Purchase Order details",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Purchase Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change the supplier and the lines of a Purchase Order nothing was received against yet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Update Purchase Order",
+                "parameters": [
+                    {
+                        "description": "models.UpdatePurchaseOrder",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePurchaseOrder"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Purchase Order details",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Purchase Order has comings",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a Purchase Order nothing was received against yet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Delete Purchase Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Purchase Order has comings",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase_order/{id}/coming": {
+            "post": {
+                "description": "Draft a Coming of the supplier and branch of an open Purchase Order with a picking list for every outstanding line at the agreed price. Edit the picking lists to what arrived, then finish the Coming.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PurchaseOrder"
+                ],
+                "summary": "Create a Coming from a Purchase Order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Purchase Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Coming",
+                        "schema": {
+                            "$ref": "#/definitions/models.Coming"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Purchase Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Purchase Order is closed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/registration": {
+            "get": {
+                "description": "Get clients.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Registration"
+                ],
+                "summary": "Registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From day",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Client details",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/remainder": {
+            "get": {
+                "description": "Get List Remainder details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Get List Remainder",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lot_number, comma separated for several",
+                        "name": "lot_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min quantity",
+                        "name": "quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max quantity",
+                        "name": "quantity_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min coming_price",
+                        "name": "coming_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max coming_price",
+                        "name": "coming_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min sale_price",
+                        "name": "sale_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max sale_price",
+                        "name": "sale_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remainder details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Remainder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Remainder not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create the opening stock of a product in a branch. The sale price defaults to the price of the product in the branch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "create a Remainder",
+                "parameters": [
+                    {
+                        "description": "Remainder ID",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRemainder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remainder details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Remainder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Remainder not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/remainder/expiring": {
+            "get": {
+                "description": "Lots in stock that expire within the given days, already expired ones included, the earliest expiry first, with their cost at coming_price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Expiring stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id, all branches when empty",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "days from today, 30 by default",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Expiring lots",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExpiringResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/remainder/low": {
+            "get": {
+                "description": "Products whose lots together are below the min_quantity of their stock level, the largest shortage first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Low stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id, all branches when empty",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Low stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.LowStockResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/remainder/rebuild": {
+            "post": {
+                "description": "Compare remainder with the stock journal and, unless dry_run, set it to the journal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Rebuild remainder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only this branch",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only report the discrepancies",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Discrepancies",
+                        "schema": {
+                            "$ref": "#/definitions/models.RebuildRemainderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/remainder/{id}": {
+            "get": {
+                "description": "Get Remainder details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Get a Remainder by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Remainder ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remainder details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Remainder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Remainder not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Get List Remainder details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Update Remainder",
+                "parameters": [
+                    {
+                        "description": "models.UpdateRemainder",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRemainder"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remainder details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Remainder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Remainder not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Get List Remainder details by its ok",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Remainder"
+                ],
+                "summary": "Get List Remainder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remainder details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Remainder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Remainder not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reorder": {
+            "get": {
+                "description": "Order quantities for the products of a branch whose stock, less the average daily sales over window_days, net of returns, times lead_days, falls below min_quantity. Each is proposed back to max_quantity at the coming price of its newest lot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Reorder suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id, the user's branch for branch users",
+                        "name": "branch_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "days of sales to average, REORDER_WINDOW_DAYS by default",
+                        "name": "window_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "days an order takes to arrive, REORDER_LEAD_DAYS by default",
+                        "name": "lead_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reorder suggestions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReorderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reorder/coming": {
+            "post": {
+                "description": "Draft a Coming of a supplier for a branch with a picking list for every suggested quantity at the coming price of its newest lot. Zero window_days and lead_days take the configured defaults.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Coming from reorder suggestions",
+                "parameters": [
+                    {
+                        "description": "Reorder",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReorder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Coming",
+                        "schema": {
+                            "$ref": "#/definitions/models.Coming"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing to reorder",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reorder/purchase_order": {
+            "post": {
+                "description": "Order the suggested quantities of a branch from a supplier at the coming price of their newest lot. Zero window_days and lead_days take the configured defaults.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Purchase Order from reorder suggestions",
+                "parameters": [
+                    {
+                        "description": "Reorder",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReorder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Purchase Order",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing to reorder",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/repricing": {
+            "get": {
+                "description": "Get the repricings, newest first, without their lines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repricing"
+                ],
+                "summary": "Get List Repricing",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id or comment",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "category_id, comma separated for several",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Repricings",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListRepricingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Change the prices of the products of category_id and its subcategories, or of product_ids, in branch_id or the catalog when it is empty. The new price is price, or the current one changed by percent and rounded to cents. A branch only reprices the products it carries. The changes are applied at effective_from, right away when it is empty or past.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repricing"
+                ],
+                "summary": "Create a Repricing",
+                "parameters": [
+                    {
+                        "description": "Repricing",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRepricing"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Repricing",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repricing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/repricing/{id}": {
+            "get": {
+                "description": "Get a repricing with the price changes it made.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repricing"
+                ],
+                "summary": "Get a Repricing by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Repricing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Repricing",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repricing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Repricing not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale": {
+            "get": {
+                "description": "Get List Sale details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "Get List Sale",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client_id, comma separated for several",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min total_price",
+                        "name": "total_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max total_price",
+                        "name": "total_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min paid",
+                        "name": "paid_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max paid",
+                        "name": "paid_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min debt",
+                        "name": "debt_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max debt",
+                        "name": "debt_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create Sale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "create a Sale",
+                "parameters": [
+                    {
+                        "description": "Sale ID",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSale"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}": {
+            "get": {
+                "description": "Get Sale details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "Get a Sale by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sale ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Get List Sale details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "Update Sale",
+                "parameters": [
+                    {
+                        "description": "models.UpdateSale",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSale"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Get List Sale details by its ok",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "Get List Sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Sale has lines, payments or returns",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/lines": {
+            "get": {
+                "description": "Get a sale with its lines and the price its branch listed each product at when the sale was made, from the price history, to render an old sale as it was.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sale"
+                ],
+                "summary": "Get the lines of a Sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sale ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale lines",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleLines"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale_return": {
+            "get": {
+                "description": "Get sale returns without their lines, newest first. Filter by sale_id for the returns of one sale.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleReturn"
+                ],
+                "summary": "Get List Sale return",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sale_id, comma separated for several",
+                        "name": "sale_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client_id, comma separated for several",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min total_price",
+                        "name": "total_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max total_price",
+                        "name": "total_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min refund",
+                        "name": "refund_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max refund",
+                        "name": "refund_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale returns",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListSaleReturnResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Take back goods of sale lines. They go back to the remainder of the branch, their value reduces the debt of the sale and what is left over is refunded by method.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleReturn"
+                ],
+                "summary": "Create a Sale return",
+                "parameters": [
+                    {
+                        "description": "Sale return",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSaleReturn"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Sale return",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleReturn"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale_return/{id}": {
+            "get": {
+                "description": "Get a sale return with its lines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleReturn"
+                ],
+                "summary": "Get a Sale return by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sale return ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sale return details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleReturn"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sale return not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/saleproduct": {
+            "get": {
+                "description": "Get List SaleProduct details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleProduct"
+                ],
+                "summary": "Get List SaleProduct",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sale_id, comma separated for several",
+                        "name": "sale_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sale_increment_id, comma separated for several",
+                        "name": "sale_increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id of the sale, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min quantity",
+                        "name": "quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max quantity",
+                        "name": "quantity_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min total_price",
+                        "name": "total_price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max total_price",
+                        "name": "total_price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SaleProduct details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleProduct"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "SaleProduct not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create SaleProduct",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleProduct"
+                ],
+                "summary": "create a SaleProduct",
+                "parameters": [
+                    {
+                        "description": "SaleProduct ID",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSaleProduct"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SaleProduct details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleProduct"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "SaleProduct not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/saleproduct/{id}": {
+            "get": {
+                "description": "Get SaleProduct details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleProduct"
+                ],
+                "summary": "Get a SaleProduct by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SaleProduct ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SaleProduct details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleProduct"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "SaleProduct not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Get List SaleProduct details by its ok.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleProduct"
+                ],
+                "summary": "Update SaleProduct",
+                "parameters": [
+                    {
+                        "description": "models.UpdateSaleProduct",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSaleProduct"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SaleProduct details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleProduct"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "SaleProduct not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Goods of the line were returned",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Get List SaleProduct details by its ok",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SaleProduct"
+                ],
+                "summary": "Get List SaleProduct",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SaleProduct details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleProduct"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "SaleProduct not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Goods of the line were returned",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_level": {
+            "get": {
+                "description": "Get the min and max stock of products per branch, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Get List Stock Level",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min min_quantity",
+                        "name": "min_quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max min_quantity",
+                        "name": "min_quantity_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min max_quantity",
+                        "name": "max_quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max max_quantity",
+                        "name": "max_quantity_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock Levels",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListStockLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the min and max stock of a product in a branch, counted over all of its lots.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Set Stock Level",
+                "parameters": [
+                    {
+                        "description": "Stock Level",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetStockLevel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock Level",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockLevel"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop watching the stock of a product in a branch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockLevel"
+                ],
+                "summary": "Delete Stock Level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "product_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_movement": {
+            "get": {
+                "description": "Get the stock journal, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockMovement"
+                ],
+                "summary": "Get List StockMovement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id, comma separated for several",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "type, comma separated for several",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "document_id, comma separated for several",
+                        "name": "document_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lot_number, comma separated for several",
+                        "name": "lot_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min quantity",
+                        "name": "quantity_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max quantity",
+                        "name": "quantity_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock movements",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListStockMovementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Record a write_off (negative quantity) or an adjustment of the remainder of a product, of one lot when lot_number is set and from the lots expiring first otherwise. Stock is only adjusted in for products in the assortment of the branch. Receipts, sales, returns and transfers are recorded by their documents.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockMovement"
+                ],
+                "summary": "Write off or adjust stock",
+                "parameters": [
+                    {
+                        "description": "Stock movement",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateStockMovement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Stock movement",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockMovement"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_movement/{id}": {
+            "get": {
+                "description": "Get StockMovement details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockMovement"
+                ],
+                "summary": "Get a StockMovement by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StockMovement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "StockMovement details",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockMovement"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "StockMovement not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_take": {
+            "get": {
+                "description": "Get stock-takes without their lines, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Get List Stock-take",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "open or approved, comma separated for several",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "increment_id, comma separated for several",
+                        "name": "increment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock-takes",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListStockTakeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Open a count of a branch with the remainder of its products, or of product_ids only, as the expected quantities.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Create a Stock-take",
+                "parameters": [
+                    {
+                        "description": "Stock-take",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateStockTake"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Stock-take",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_take/{id}": {
+            "get": {
+                "description": "Get a stock-take with its lines, their variance and the cost impact at coming_price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Get a Stock-take by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock-take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock-take details",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock-take not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an open stock-take.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Delete Stock-take",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock-take is approved",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_take/{id}/approve": {
+            "post": {
+                "description": "Post the variance of every counted line against the stock on hand at approval as an adjustment, so the remainder matches the count. Lines not counted are left as they are.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Approve a Stock-take",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock-take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Approved Stock-take",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock-take not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock-take is approved",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_take/{id}/counts": {
+            "put": {
+                "description": "Set the counted quantity of products of an open stock-take. Products left out keep their count.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Set Stock-take counts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock-take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Counts",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetStockTakeCounts"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock-take details",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock-take not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock-take is approved",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock_take/{id}/import": {
+            "post": {
+                "description": "Set counts from the CSV of a scanner, as a multipart \"file\" or as the body. Each row is product_id,quantity with \",\" or \";\" between, a header row is skipped and the rows of a product are added up.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "StockTake"
+                ],
+                "summary": "Import Stock-take counts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock-take ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stock-take details",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockTake"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Stock-take not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock-take is approved",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier": {
+            "get": {
+                "description": "Get List Supplier, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Get List Supplier",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search by name, tin, phone or address",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "tin, comma separated for several",
+                        "name": "tin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier details",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListSupplierResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create Supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "create a Supplier",
+                "parameters": [
+                    {
+                        "description": "Supplier",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSupplier"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Supplier details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "TIN is taken",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier/{id}": {
+            "get": {
+                "description": "Get Supplier details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Get a Supplier by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Supplier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Supplier not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing Supplier.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Update Supplier",
+                "parameters": [
+                    {
+                        "description": "models.UpdateSupplier",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSupplier"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Supplier details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "TIN is taken",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a Supplier without comings and payments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Delete Supplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier/{id}/statement": {
+            "get": {
+                "description": "Finished comings, their reversals and payments of a Supplier with the running balance we owe.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Supplier statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Supplier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch Id, all branches by default",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from, date or RFC3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to, date or RFC3339",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier statement",
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierStatement"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Supplier not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier_payable": {
+            "get": {
+                "description": "What is owed to each supplier: finished comings less payments, the largest balance first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Supplier payables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch Id, all branches by default",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payables",
+                        "schema": {
+                            "$ref": "#/definitions/models.PayablesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier_payment": {
+            "get": {
+                "description": "Get supplier payments, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Get List Supplier Payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search by comment",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "supplier_id, comma separated for several",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "method, comma separated for several",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id, comma separated for several",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min amount",
+                        "name": "amount_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max amount",
+                        "name": "amount_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier Payment details",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListSupplierPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Pay a Supplier from a branch, at most what the branch owes it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "create a Supplier Payment",
+                "parameters": [
+                    {
+                        "description": "Supplier Payment",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSupplierPayment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Supplier Payment details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierPayment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier_payment/{id}": {
+            "get": {
+                "description": "Get Supplier Payment details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "Get a Supplier Payment by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Supplier Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier Payment details",
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierPayment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Supplier Payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer": {
+            "get": {
+                "description": "Get transfers, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Get List Transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sent from or to this branch",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from_branch_id, comma separated for several",
+                        "name": "from_branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to_branch_id, comma separated for several",
+                        "name": "to_branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "draft, sent or received, comma separated for several",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transfers",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Draft a transfer of goods from one branch to another. The receiving branch must carry every product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Create a Transfer",
+                "parameters": [
+                    {
+                        "description": "Transfer",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTransfer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer/in_transit": {
+            "get": {
+                "description": "Lines of sent transfers that are not received yet, the oldest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Goods in transit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sent from or to this branch",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Goods in transit",
+                        "schema": {
+                            "$ref": "#/definitions/models.InTransitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer/{id}": {
+            "get": {
+                "description": "Get Transfer details with its lines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Get a Transfer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transfer details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change the destination and the lines of a draft transfer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Update Transfer",
+                "parameters": [
+                    {
+                        "description": "models.UpdateTransfer",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTransfer"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Transfer details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transfer is not a draft",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a draft transfer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Delete Transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transfer is not a draft",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer/{id}/receive": {
+            "post": {
+                "description": "Add what arrived of a sent transfer to the remainder of the receiving branch at the carried coming_price. Lines left out arrived in full, the rest is recorded as shortage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Receive a Transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Received quantities",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReceiveTransfer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Received Transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transfer is not sent",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer/{id}/send": {
+            "post": {
+                "description": "Take the lines of a draft transfer out of the remainder of the sending branch. They stay in transit until received.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "Send a Transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sent Transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transfer is not a draft",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "description": "Get List User details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get List User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client_type, comma separated for several",
+                        "name": "client_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id, comma separated for several",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from, date or RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to, date or RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetListUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create User, the password is stored as a bcrypt hash.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "create a User",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "description": "Get User details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get a User by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update User, an empty password keeps the current one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update User",
+                "parameters": [
+                    {
+                        "description": "models.UpdateUser",
+                        "name": "object",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUser"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete User",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperror.Code": {
+            "type": "string",
+            "enum": [
+                "not_found",
+                "conflict",
+                "validation",
+                "foreign_key_violation",
+                "unauthorized",
+                "forbidden",
+                "internal"
+            ],
+            "x-enum-varnames": [
+                "NotFound",
+                "Conflict",
+                "Validation",
+                "ForeignKeyViolation",
+                "Unauthorized",
+                "Forbidden",
+                "Internal"
+            ]
+        },
+        "apperror.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/apperror.Code"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/apperror.Error"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.Response": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "description": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Barcode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Branch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BranchProduct": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "catalog_price": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "sale_price": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategoryNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategoryNode"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategorySales": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "net_amount": {
+                    "type": "number"
+                },
+                "net_quantity": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "returned_amount": {
+                    "type": "number"
+                },
+                "returned_quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategorySalesResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategorySales"
+                    }
+                },
+                "net_amount": {
+                    "type": "number"
+                },
+                "net_quantity": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "returned_amount": {
+                    "type": "number"
+                },
+                "returned_quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryStock": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CategoryStockResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategoryStock"
+                    }
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Checkout": {
+            "type": "object",
+            "properties": {
+                "sale": {
+                    "$ref": "#/definitions/models.Sale"
+                },
+                "sale_products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SaleProduct"
+                    }
+                }
+            }
+        },
+        "models.CheckoutProduct": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Client": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "father_name": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClientStatement": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "closing_balance": {
+                    "type": "number"
+                },
+                "credit": {
+                    "type": "number"
+                },
+                "debit": {
+                    "type": "number"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClientStatementEntry"
+                    }
+                },
+                "opening_balance": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ClientStatementEntry": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit": {
+                    "type": "number"
+                },
+                "debit": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Coming": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "purchase_order_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ComingEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "coming_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CopyCatalog": {
+            "type": "object",
+            "properties": {
+                "from_branch_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CopyCatalogResponse": {
+            "type": "object",
+            "properties": {
+                "copied": {
+                    "type": "integer"
+                },
+                "from_branch_id": {
+                    "type": "string"
+                },
+                "to_branch_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateBranch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateCategory": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateCheckout": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckoutProduct"
+                    }
+                }
+            }
+        },
+        "models.CreateClient": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "father_name": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateComing": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "cashier": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePickingList": {
+            "type": "object",
+            "properties": {
+                "coming_increment_id": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePriceChange": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateProduct": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Barcode"
+                    }
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                }
+            }
+        },
+        "models.CreatePromotion": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "buy_quantity": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "client_group": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "free_quantity": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreatePurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreatePurchaseOrderLine"
+                    }
+                },
+                "supplier_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePurchaseOrderLine": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateRemainder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "coming_price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateReorder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "lead_days": {
+                    "type": "integer"
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "supplier_id": {
+                    "type": "string"
+                },
+                "window_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateRepricing": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateSale": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateSaleProduct": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "sale_increment_id": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateSaleReturn": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateSaleReturnLine"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSaleReturnLine": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateStockMovement": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "document_number": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateStockTake": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateSupplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "tin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSupplierPayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTransfer": {
+            "type": "object",
+            "properties": {
+                "from_branch_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransferLine"
+                    }
+                },
+                "to_branch_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTransferLine": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateUser": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_type": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DebtAging": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "days_0_30": {
+                    "type": "number"
+                },
+                "days_31_60": {
+                    "type": "number"
+                },
+                "days_61_90": {
+                    "type": "number"
+                },
+                "days_90_plus": {
+                    "type": "number"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.DebtAgingResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DebtAging"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/models.DebtAging"
+                }
+            }
+        },
+        "models.Doc": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "branch_name": {
+                    "type": "string"
+                },
+                "net_sale_price": {
+                    "type": "number"
+                },
+                "net_sale_quantity": {
+                    "type": "integer"
+                },
+                "total_return_price": {
+                    "type": "number"
+                },
+                "total_return_quantity": {
+                    "type": "integer"
+                },
+                "total_sale_price": {
+                    "type": "number"
+                },
+                "total_sale_quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ExpiringLot": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "coming_price": {
+                    "type": "number"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "days_left": {
+                    "type": "integer"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "remainder_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExpiringResponse": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "expired": {
+                    "type": "number"
+                },
+                "lots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExpiringLot"
+                    }
+                }
+            }
+        },
+        "models.GetListBranchProductResponse": {
+            "type": "object",
+            "properties": {
+                "branch_products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BranchProduct"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetListCategoryResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetListClientResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Client"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetListPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payment"
+                    }
+                }
+            }
+        },
+        "models.GetListPriceChangeResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "price_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceChange"
+                    }
+                }
+            }
+        },
+        "models.GetListPromotionResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "promotions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Promotion"
+                    }
+                }
+            }
+        },
+        "models.GetListPurchaseOrderResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "purchase_orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrder"
+                    }
+                }
+            }
+        },
+        "models.GetListRepricingResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "repricings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Repricing"
+                    }
+                }
+            }
+        },
+        "models.GetListSaleReturnResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "sale_returns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SaleReturn"
+                    }
+                }
+            }
+        },
+        "models.GetListStockLevelResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "stock_levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockLevel"
+                    }
+                }
+            }
+        },
+        "models.GetListStockMovementResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "stock_movements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockMovement"
+                    }
+                }
+            }
+        },
+        "models.GetListStockTakeResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "stock_takes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTake"
+                    }
+                }
+            }
+        },
+        "models.GetListSupplierPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "supplier_payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SupplierPayment"
+                    }
+                }
+            }
+        },
+        "models.GetListSupplierResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "suppliers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Supplier"
+                    }
+                }
+            }
+        },
+        "models.GetListTransferResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transfer"
+                    }
+                }
+            }
+        },
+        "models.GetListUserResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                }
+            }
+        },
+        "models.InTransit": {
+            "type": "object",
+            "properties": {
+                "coming_price": {
+                    "type": "number"
+                },
+                "from_branch_id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "to_branch_id": {
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InTransitResponse": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InTransit"
+                    }
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "models.LowStock": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "max_quantity": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "shortage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LowStockResponse": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LowStock"
+                    }
+                }
+            }
+        },
+        "models.Payable": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "paid": {
+                    "type": "number"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "received": {
+                    "type": "number"
+                },
+                "supplier_id": {
+                    "type": "string"
+                },
+                "tin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayablesResponse": {
+            "type": "object",
+            "properties": {
+                "suppliers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payable"
+                    }
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "cashier": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PickingList": {
+            "type": "object",
+            "properties": {
+                "coming_id": {
+                    "type": "string"
+                },
+                "coming_increment_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_quantity": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceAsOf": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "change_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceChange": {
+            "type": "object",
+            "properties": {
+                "applied_at": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Barcode"
+                    }
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductByBarcode": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "$ref": "#/definitions/models.Barcode"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "product": {
+                    "$ref": "#/definitions/models.Product"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ProductUnit": {
+            "type": "object",
+            "properties": {
+                "factor": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Promotion": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "buy_quantity": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "client_group": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "free_quantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PromotionCost": {
+            "type": "object",
+            "properties": {
+                "discount": {
+                    "type": "number"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "promotion_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sales": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PromotionReportResponse": {
+            "type": "object",
+            "properties": {
+                "discount": {
+                    "type": "number"
+                },
+                "promotions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PromotionCost"
+                    }
+                }
+            }
+        },
+        "models.PurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrderLine"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PurchaseOrderLine": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "outstanding": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "purchase_order_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "received": {
+                    "type": "integer"
+                },
+                "received_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.RebuildRemainderResponse": {
+            "type": "object",
+            "properties": {
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockDiscrepancy"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ReceiveTransfer": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReceiveTransferLine"
+                    }
+                }
+            }
+        },
+        "models.ReceiveTransferLine": {
+            "type": "object",
+            "properties": {
+                "line_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Remainder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "coming_price": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_price": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReorderLine": {
+            "type": "object",
+            "properties": {
+                "daily_sales": {
+                    "type": "number"
+                },
+                "max_quantity": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "projected": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sold": {
+                    "type": "integer"
+                },
+                "suggested": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReorderResponse": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "lead_days": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReorderLine"
+                    }
+                },
+                "window_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Repricing": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceChange"
+                    }
+                },
+                "percent": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReverseComing": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Sale": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "debd": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "paid": {
+                    "type": "number"
+                },
+                "returned": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
         },
-        "models.CreateBranch": {
+        "models.SaleLine": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string"
+                "list_price": {
+                    "type": "number"
                 },
-                "name": {
-                    "type": "string"
+                "sale_product": {
+                    "$ref": "#/definitions/models.SaleProduct"
+                }
+            }
+        },
+        "models.SaleLines": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SaleLine"
+                    }
                 },
-                "phone": {
-                    "type": "string"
+                "sale": {
+                    "$ref": "#/definitions/models.Sale"
                 }
             }
         },
-        "models.CreateClient": {
+        "models.SaleProduct": {
             "type": "object",
             "properties": {
-                "active": {
+                "created_at": {
                     "type": "string"
                 },
-                "birthday": {
+                "discount": {
+                    "type": "number"
+                },
+                "id": {
                     "type": "string"
                 },
-                "branch_id": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
                     "type": "string"
                 },
-                "father_name": {
+                "promotion_id": {
                     "type": "string"
                 },
-                "first_name": {
+                "quantity": {
+                    "type": "integer"
+                },
+                "sale_id": {
                     "type": "string"
                 },
-                "gender": {
+                "sale_increment_id": {
                     "type": "string"
                 },
-                "last_name": {
+                "total_price": {
+                    "type": "number"
+                },
+                "unit": {
                     "type": "string"
                 },
-                "phone": {
+                "unit_price": {
+                    "type": "number"
+                },
+                "unit_quantity": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateComing": {
+        "models.SaleReturn": {
             "type": "object",
             "properties": {
                 "branch_id": {
                     "type": "string"
                 },
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "debt_reduced": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "increment_id": {
                     "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SaleReturnLine"
+                    }
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "refund": {
+                    "type": "number"
+                },
+                "refund_payment_id": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreatePickingList": {
+        "models.SaleReturnLine": {
             "type": "object",
             "properties": {
-                "coming_increment_id": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
                 },
                 "price": {
@@ -2480,65 +10277,132 @@ const docTemplate = `{
                 },
                 "quantity": {
                     "type": "integer"
+                },
+                "sale_product_id": {
+                    "type": "string"
+                },
+                "sale_return_id": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateProduct": {
+        "models.SetBranchProduct": {
             "type": "object",
             "properties": {
                 "branch_id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
-                },
                 "price": {
                     "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreateRemainder": {
+        "models.SetStockLevel": {
             "type": "object",
             "properties": {
                 "branch_id": {
                     "type": "string"
                 },
-                "coming_price": {
-                    "type": "number"
+                "max_quantity": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
                 },
                 "product_id": {
                     "type": "string"
+                }
+            }
+        },
+        "models.SetStockTakeCounts": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTakeCount"
+                    }
+                }
+            }
+        },
+        "models.StockDiscrepancy": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
                 },
-                "quantity": {
+                "difference": {
                     "type": "integer"
                 },
-                "sale_price": {
-                    "type": "number"
+                "journal": {
+                    "type": "integer"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "remainder": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CreateSale": {
+        "models.StockLevel": {
             "type": "object",
             "properties": {
                 "branch_id": {
                     "type": "string"
                 },
-                "client_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "increment_id": {
+                "max_quantity": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
+                },
+                "product_id": {
                     "type": "string"
                 },
-                "total_price": {
-                    "type": "number"
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreateSaleProduct": {
+        "models.StockMovement": {
             "type": "object",
             "properties": {
-                "price": {
-                    "type": "number"
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "document_number": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
                 },
                 "product_id": {
                     "type": "string"
@@ -2546,195 +10410,286 @@ const docTemplate = `{
                 "quantity": {
                     "type": "integer"
                 },
-                "sale_id": {
+                "type": {
                     "type": "string"
                 },
-                "sale_increment_id": {
+                "user_id": {
                     "type": "string"
-                },
-                "total_price": {
-                    "type": "number"
                 }
             }
         },
-        "models.Doc": {
+        "models.StockTake": {
             "type": "object",
             "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
                 "branch_id": {
                     "type": "string"
                 },
-                "branch_name": {
+                "cost_impact": {
+                    "type": "number"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "total_sale_price": {
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockTakeLine"
+                    }
+                },
+                "shortage": {
                     "type": "number"
                 },
-                "total_sale_quantity": {
+                "status": {
+                    "type": "string"
+                },
+                "surplus": {
+                    "type": "number"
+                },
+                "uncounted": {
                     "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.GetListClientResponse": {
+        "models.StockTakeCount": {
             "type": "object",
             "properties": {
-                "clients": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Client"
-                    }
-                },
-                "count": {
+                "counted": {
                     "type": "integer"
+                },
+                "product_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.PickingList": {
+        "models.StockTakeLine": {
             "type": "object",
             "properties": {
-                "coming_id": {
-                    "type": "string"
+                "coming_price": {
+                    "type": "number"
                 },
-                "coming_increment_id": {
-                    "type": "string"
+                "cost_impact": {
+                    "type": "number"
+                },
+                "counted": {
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "expected": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
-                },
                 "product_id": {
                     "type": "string"
                 },
-                "quantity": {
+                "stock_take_id": {
+                    "type": "string"
+                },
+                "variance": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.Supplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
                 },
-                "total_price": {
-                    "type": "number"
+                "tin": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.Product": {
+        "models.SupplierPayment": {
             "type": "object",
             "properties": {
+                "amount": {
+                    "type": "number"
+                },
                 "branch_id": {
                     "type": "string"
                 },
+                "comment": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "method": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "supplier_id": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "models.Remainder": {
+        "models.SupplierStatement": {
             "type": "object",
             "properties": {
                 "branch_id": {
                     "type": "string"
                 },
-                "coming_price": {
+                "closing_balance": {
                     "type": "number"
                 },
-                "created_at": {
+                "credit": {
+                    "type": "number"
+                },
+                "debit": {
+                    "type": "number"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SupplierStatementEntry"
+                    }
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "supplier_id": {
                     "type": "string"
+                }
+            }
+        },
+        "models.SupplierStatementEntry": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
                 },
-                "id": {
+                "branch_id": {
                     "type": "string"
                 },
-                "name": {
+                "coming_id": {
                     "type": "string"
                 },
-                "product_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer"
+                "credit": {
+                    "type": "number"
                 },
-                "sale_price": {
+                "debit": {
                     "type": "number"
                 },
-                "updated_at": {
+                "id": {
+                    "type": "string"
+                },
+                "increment_id": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "models.Sale": {
+        "models.Transfer": {
             "type": "object",
             "properties": {
-                "branch_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "client_id": {
+                "from_branch_id": {
                     "type": "string"
                 },
-                "created_at": {
+                "id": {
                     "type": "string"
                 },
-                "debd": {
-                    "type": "number"
+                "increment_id": {
+                    "type": "string"
                 },
-                "id": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransferLine"
+                    }
+                },
+                "received_at": {
                     "type": "string"
                 },
-                "increment_id": {
+                "sent_at": {
                     "type": "string"
                 },
-                "paid": {
-                    "type": "number"
+                "status": {
+                    "type": "string"
                 },
-                "total_price": {
-                    "type": "number"
+                "to_branch_id": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.SaleProduct": {
+        "models.TransferLine": {
             "type": "object",
             "properties": {
+                "coming_price": {
+                    "type": "number"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
-                },
                 "product_id": {
                     "type": "string"
                 },
                 "quantity": {
                     "type": "integer"
                 },
-                "sale_id": {
-                    "type": "string"
-                },
-                "sale_increment_id": {
-                    "type": "string"
+                "received_quantity": {
+                    "type": "integer"
                 },
-                "total_price": {
-                    "type": "number"
+                "shortage": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "transfer_id": {
                     "type": "string"
                 }
             }
@@ -2756,6 +10711,20 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateCategory": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UpdateClient": {
             "type": "object",
             "properties": {
@@ -2777,6 +10746,9 @@ const docTemplate = `{
                 "gender": {
                     "type": "string"
                 },
+                "group": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -2799,6 +10771,9 @@ const docTemplate = `{
                 },
                 "status": {
                     "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
                 }
             }
         },
@@ -2811,6 +10786,12 @@ const docTemplate = `{
                 "coming_increment_id": {
                     "type": "string"
                 },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "lot_number": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "number"
                 },
@@ -2819,15 +10800,27 @@ const docTemplate = `{
                 },
                 "quantity": {
                     "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
                 }
             }
         },
         "models.UpdateProduct": {
             "type": "object",
             "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Barcode"
+                    }
+                },
                 "branch_id": {
                     "type": "string"
                 },
+                "category_id": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -2836,6 +10829,73 @@ const docTemplate = `{
                 },
                 "price": {
                     "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                }
+            }
+        },
+        "models.UpdatePromotion": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "buy_quantity": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "client_group": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "free_quantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.UpdatePurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreatePurchaseOrderLine"
+                    }
+                },
+                "supplier_id": {
+                    "type": "string"
                 }
             }
         },
@@ -2868,18 +10928,12 @@ const docTemplate = `{
                 "client_id": {
                     "type": "string"
                 },
-                "debd": {
-                    "type": "number"
-                },
                 "id": {
                     "type": "string"
                 },
                 "increment_id": {
                     "type": "string"
                 },
-                "paid": {
-                    "type": "number"
-                },
                 "total_price": {
                     "type": "number"
                 }
@@ -2910,6 +10964,98 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "models.UpdateSupplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "tin": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateTransfer": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransferLine"
+                    }
+                },
+                "to_branch_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateUser": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_type": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "client_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cast"
+)
+
+// Keys the Auth middleware sets on the gin context.
+const (
+	ctxUserID     = "user_id"
+	ctxClientType = "client_type"
+	ctxBranchID   = "branch_id"
+)
+
+// @Summary Login
+// @Description Exchange login and password for an access token.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param object body models.LoginRequest true "Login"
+// @Success 200 {object} models.LoginResponse "Access token"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Wrong login or password"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /login [post]
+func (h *Handler) Login(c *gin.Context) {
+
+	var login models.LoginRequest
+	err := c.ShouldBindJSON(&login)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if len(login.Login) == 0 || len(login.Password) == 0 {
+		handleResponse(c, http.StatusBadRequest, "login and password are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	user, err := h.strg.User().GetByID(ctx, &models.UserPrimaryKey{Login: login.Login})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusUnauthorized, "wrong login or password")
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !security.CheckPassword(user.Password, login.Password) {
+		handleResponse(c, http.StatusUnauthorized, "wrong login or password")
+		return
+	}
+
+	token, err := security.GenerateJWT(map[string]interface{}{
+		ctxUserID:     user.Id,
+		ctxClientType: user.ClientType,
+		ctxBranchID:   user.BranchID,
+	}, config.ExpiredTime, h.cfg.SecretKey)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, models.LoginResponse{
+		AccessToken: token,
+		User:        user,
+	})
+}
+
+// Auth rejects requests without a valid "Authorization: Bearer <token>"
+// header, and with 403 those of a BRANCH or CASSIER token without a
+// branch, and puts the user id, client type and branch id of the token
+// into the context.
+func (h *Handler) Auth() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		token, err := security.ExtractToken(c.GetHeader("Authorization"))
+		if err != nil {
+			handleResponse(c, http.StatusUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+
+		claims, err := security.ParseClaims(token, h.cfg.SecretKey)
+		if err != nil {
+			handleResponse(c, http.StatusUnauthorized, "invalid token")
+			c.Abort()
+			return
+		}
+
+		var (
+			clientType = cast.ToString(claims[ctxClientType])
+			branchID   = cast.ToString(claims[ctxBranchID])
+		)
+
+		if !contains(config.ClientTypes, clientType) {
+			handleResponse(c, http.StatusUnauthorized, "invalid token")
+			c.Abort()
+			return
+		}
+
+		// an empty scope means every branch, only SUPER-ADMIN goes without one
+		if clientType != config.SuperAdmin && len(branchID) == 0 {
+			handleResponse(c, http.StatusForbidden, clientType+" has no branch")
+			c.Abort()
+			return
+		}
+
+		c.Set(ctxUserID, cast.ToString(claims[ctxUserID]))
+		c.Set(ctxClientType, clientType)
+		c.Set(ctxBranchID, branchID)
+
+		c.Next()
+	}
+}
+
+// Permission lets a request through when config.Permissions allows the
+// route to the client type of the user, and answers 403 otherwise.
+func (h *Handler) Permission() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		var (
+			clientType = c.GetString(ctxClientType)
+			route      = c.Request.Method + " " + c.FullPath()
+		)
+
+		for _, allowed := range config.Permissions[clientType] {
+			if allowed == "*" || allowed == route {
+				c.Next()
+				return
+			}
+		}
+
+		handleResponse(c, http.StatusForbidden, clientType+" can not "+route)
+		c.Abort()
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/security"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuth(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{SecretKey: "secret"}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/login", h.Login)
+	r.GET("/branch", h.Auth(), h.GetListBranch)
+
+	password, _ := security.HashPassword("admin123")
+	_, _ = strg.User().Create(context.Background(), &models.CreateUser{Login: "superadmin", Password: password, ClientType: config.SuperAdmin})
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.LoginRequest{Login: "superadmin", Password: password})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+		return w
+	}
+
+	if w := login("wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login with wrong password: status = %d", w.Code)
+	}
+
+	w := login("admin123")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Data.AccessToken) == 0 {
+		t.Fatalf("no access token in %s", w.Body.String())
+	}
+
+	branchless, _ := security.GenerateJWT(map[string]interface{}{
+		"user_id":     "f4877292-4468-44e2-a27f-9743c7a35802",
+		"client_type": config.Cassier,
+	}, config.ExpiredTime, "secret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{name: "no token", authorization: "", status: http.StatusUnauthorized},
+		{name: "cashier without a branch", authorization: "Bearer " + branchless, status: http.StatusForbidden},
+		{name: "bad token", authorization: "Bearer nonsense", status: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer " + resp.Data.AccessToken, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := httptest.NewRequest(http.MethodGet, "/branch", nil)
+			req.Header.Set("Authorization", tt.authorization)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPermission(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{SecretKey: "secret"}, strg)
+		r    = gin.New()
+	)
+
+	auth := r.Group("/", h.Auth(), h.Permission())
+	auth.GET("/sale", h.GetListSale)
+	auth.POST("/sale", h.CreateSale)
+	auth.GET("/user", h.GetListUser)
+	auth.GET("/client/:id", h.GetByIDClient)
+	auth.GET("/client/:id/statement", h.ClientStatement)
+	auth.GET("/payment/:id", h.GetByIDPayment)
+
+	own, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	other, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yunusobod"})
+	_, _ = strg.Sale().Create(ctx, &models.CreateSale{BranchID: own.Id, IncrementID: "S-0000001"})
+	otherSale, _ := strg.Sale().Create(ctx, &models.CreateSale{BranchID: other.Id, IncrementID: "S-0000002", TotalPrice: 1000})
+	otherPayment, _ := strg.Payment().Create(ctx, &models.CreatePayment{SaleID: otherSale.Id, Amount: 1000, Method: "cash"})
+	otherClient, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "1999-05-01", BranchID: other.Id})
+
+	token, _ := security.GenerateJWT(map[string]interface{}{
+		"user_id":     "f4877292-4468-44e2-a27f-9743c7a35802",
+		"client_type": config.Branch,
+		"branch_id":   own.Id,
+	}, config.ExpiredTime, "secret")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{name: "list is limited to own branch", method: http.MethodGet, path: "/sale?branch_id=" + other.Id, status: http.StatusOK},
+		{name: "sale of own branch", method: http.MethodPost, path: "/sale", body: models.CreateSale{BranchID: own.Id}, status: http.StatusCreated},
+		{name: "sale of another branch", method: http.MethodPost, path: "/sale", body: models.CreateSale{BranchID: other.Id}, status: http.StatusForbidden},
+		{name: "route not in permissions", method: http.MethodGet, path: "/user", status: http.StatusForbidden},
+		{name: "client of another branch", method: http.MethodGet, path: "/client/" + otherClient.Id + "?id=" + otherClient.Id, status: http.StatusForbidden},
+		{name: "statement of a client of another branch", method: http.MethodGet, path: "/client/" + otherClient.Id + "/statement", status: http.StatusForbidden},
+		{name: "payment of a sale of another branch", method: http.MethodGet, path: "/payment/" + otherPayment.Id, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			if tt.method != http.MethodGet || w.Code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data models.GetListSaleResponse `json:"data"`
+			}
+			_ = json.Unmarshal(w.Body.Bytes(), &resp)
+			for _, sale := range resp.Data.Sales {
+				if sale.BranchID != own.Id {
+					t.Fatalf("sale %s of branch %s is listed", sale.IncrementID, sale.BranchID)
+				}
+			}
+			if len(resp.Data.Sales) == 0 {
+				t.Fatalf("no sales listed: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"context"
-	"database/sql"
 	"net/http"
 
 	"market_system/config"
@@ -68,11 +67,6 @@ func (h *Handler) GetByIDBranch(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
-		return
-	}
-
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -89,6 +83,8 @@ func (h *Handler) GetByIDBranch(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query string false "search"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.Branch "Branch details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Branch not found"
@@ -114,6 +110,12 @@ func (h *Handler) GetListBranch(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.BranchFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -126,6 +128,7 @@ func (h *Handler) GetListBranch(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Set Branch Product
+// @Description Put a product of the catalog in the assortment of a branch, or change its price there. Without a price the branch sells it at the catalog price.
+// @Tags BranchProduct
+// @Accept json
+// @Produce json
+// @Param object body models.SetBranchProduct true "Branch Product"
+// @Success 200 {object} models.BranchProduct "Branch Product"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /branch_product [put]
+func (h *Handler) SetBranchProduct(c *gin.Context) {
+
+	var setBranchProduct models.SetBranchProduct
+	err := c.ShouldBindJSON(&setBranchProduct)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if setBranchProduct.Price != nil && *setBranchProduct.Price < 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "price must not be negative").WithField("price", "must not be negative"))
+		return
+	}
+
+	if !inScope(c, setBranchProduct.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.validBranch(ctx, c, "branch_id", setBranchProduct.BranchID) {
+		return
+	}
+
+	if !h.validProduct(ctx, c, "product_id", setBranchProduct.ProductID) {
+		return
+	}
+
+	resp, err := h.strg.BranchProduct().Set(ctx, &setBranchProduct)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Branch Product
+// @Description Get the assortment of branches with the price each sells at, by product name.
+// @Tags BranchProduct
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "product name"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Success 200 {object} models.GetListBranchProductResponse "Branch Products"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /branch_product [get]
+func (h *Handler) GetListBranchProduct(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.BranchProductFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.BranchProduct().GetList(ctx, &models.GetListBranchProductRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Delete Branch Product
+// @Description Take a product out of the assortment of a branch, the catalog keeps it.
+// @Tags BranchProduct
+// @Accept json
+// @Produce json
+// @Param branch_id query string true "branch_id"
+// @Param product_id query string true "product_id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /branch_product [delete]
+func (h *Handler) DeleteBranchProduct(c *gin.Context) {
+
+	var key = models.BranchProductPrimaryKey{
+		BranchID:  c.Query("branch_id"),
+		ProductID: c.Query("product_id"),
+	}
+
+	if !helpers.IsValidUUID(key.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if !helpers.IsValidUUID(key.ProductID) {
+		handleResponse(c, http.StatusBadRequest, "product_id is not uuid")
+		return
+	}
+
+	if !inScope(c, key.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	err := h.strg.BranchProduct().Delete(ctx, &key)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Copy a Branch catalog
+// @Description Copy the assortment and price overrides of another branch to this one, typically a newly opened branch. Products the branch already has take the price of the other branch.
+// @Tags BranchProduct
+// @Accept json
+// @Produce json
+// @Param id path string true "branch to copy to"
+// @Param object body models.CopyCatalog true "branch to copy from"
+// @Success 200 {object} models.CopyCatalogResponse "Copied"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /branch/{id}/catalog [post]
+func (h *Handler) CopyBranchCatalog(c *gin.Context) {
+
+	var copyCatalog models.CopyCatalog
+	err := c.ShouldBindJSON(&copyCatalog)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	copyCatalog.ToBranchID = c.Param("id")
+	if copyCatalog.ToBranchID == copyCatalog.FromBranchID {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "a branch can not copy its own catalog").WithField("from_branch_id", "must be another branch"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.validBranch(ctx, c, "id", copyCatalog.ToBranchID) || !h.validBranch(ctx, c, "from_branch_id", copyCatalog.FromBranchID) {
+		return
+	}
+
+	resp, err := h.strg.BranchProduct().Copy(ctx, &copyCatalog)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// validBranch answers 400 and returns false unless id is an existing
+// branch, reported as field.
+func (h *Handler) validBranch(ctx context.Context, c *gin.Context, field, id string) bool {
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "must be uuid"))
+		return false
+	}
+
+	_, err := h.strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "does not exist"))
+		return false
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
+
+// notCarried is the validation error of a product the branch does not
+// carry, reported as field, nil when it does. Stock is only received,
+// adjusted in and sold for the assortment of a branch.
+func notCarried(ctx context.Context, strg storage.StorageI, field, branchId, productId string) error {
+
+	_, err := strg.BranchProduct().GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: branchId, ProductID: productId})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.New(apperror.Validation, field+" is invalid").WithField(field, "is not in the assortment of the branch")
+	}
+
+	return err
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBranchCatalog(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.PUT("/branch_product", h.SetBranchProduct)
+	r.GET("/branch_product", h.GetListBranchProduct)
+	r.POST("/branch/:id/catalog", h.CopyBranchCatalog)
+	r.POST("/picking_list", h.CreatePickingList)
+	r.POST("/coming/:id/finish", h.FinishComing)
+
+	central, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Central"})
+	opened, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yakkasaroy"})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000})
+	vitamin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Vitamin C", Price: 9000})
+
+	var (
+		negative = -1.0
+		price    = 4500.0
+	)
+
+	if w := serve(r, http.MethodPut, "/branch_product", models.SetBranchProduct{BranchID: central.Id, ProductID: aspirin.Id, Price: &negative}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("negative price: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPut, "/branch_product", models.SetBranchProduct{BranchID: central.Id, ProductID: central.Id}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown product: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var set models.BranchProduct
+	if w := serve(r, http.MethodPut, "/branch_product", models.SetBranchProduct{BranchID: central.Id, ProductID: aspirin.Id, Price: &price}, &set); w.Code != http.StatusOK || set.SalePrice != 4500 || set.CatalogPrice != 4000 {
+		t.Fatalf("set: status %d: %s", w.Code, w.Body.String())
+	}
+	serve(r, http.MethodPut, "/branch_product", models.SetBranchProduct{BranchID: central.Id, ProductID: vitamin.Id}, nil)
+
+	if w := serve(r, http.MethodPost, "/branch/"+central.Id+"/catalog", models.CopyCatalog{FromBranchID: central.Id}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("copy to itself: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var copied models.CopyCatalogResponse
+	if w := serve(r, http.MethodPost, "/branch/"+opened.Id+"/catalog", models.CopyCatalog{FromBranchID: central.Id}, &copied); w.Code != http.StatusOK || copied.Copied != 2 || copied.ToBranchID != opened.Id {
+		t.Fatalf("copy: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var list models.GetListBranchProductResponse
+	if w := serve(r, http.MethodGet, "/branch_product?branch_id="+opened.Id+"&search=asp", nil, &list); w.Code != http.StatusOK || list.Count != 1 || list.BranchProducts[0].SalePrice != 4500 {
+		t.Fatalf("assortment of the new branch: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// stock received in the new branch is sold at the copied price
+	coming, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00011", BranchID: opened.Id})
+	if w := serve(r, http.MethodPost, "/picking_list", models.CreatePickingList{Product_ID: aspirin.Id, Quantity: 10, Price: 3000, ComingIncrementID: coming.IncrementID}, nil); w.Code != http.StatusCreated {
+		t.Fatalf("picking list: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/coming/"+coming.Id+"/finish", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("finish: status %d: %s", w.Code, w.Body.String())
+	}
+
+	remainders, _ := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {opened.Id}}},
+	})
+	if remainders.Count != 1 || remainders.Remainders[0].SalePrice != 4500 {
+		t.Fatalf("remainder of the new branch %+v", remainders)
+	}
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"market_system/config"
 	"market_system/models"
 	"net/http"
@@ -13,13 +12,15 @@ import (
 )
 
 // @Summary MakePay
-// @Description Pay client's Sale.
+// @Description Pay client's Sale by its increment id. Payments add up, a negative money is a refund.
 // @Tags Pay
 // @Accept json
 // @Produce json
-// @Param sale_id query string ture "sale_id"
+// @Param sale_id query string true "sale increment_id"
 // @Param money query float64 true "pay_money"
-// @Success 200 {object} models.Coming "Payed"
+// @Param method query string false "cash, card or transfer, cash by default"
+// @Param cashier query string false "cashier"
+// @Success 201 {object} models.Payment "Payed"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Sale not found"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
@@ -29,47 +30,38 @@ func (h *Handler) MakePay(c *gin.Context) {
 	var (
 		incrementId = c.Query("sale_id")
 		money       = (cast.ToFloat64(c.Query("money")))
-		Id          string
-		ClientID    string
-		BranchID    string
-		IncrementID string
-		TotalPrice  float64
+		method      = c.DefaultQuery("method", "cash")
 	)
 
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	saleList, err := h.strg.Sale().GetList(ctx, &models.GetListSaleRequest{Limit: 10000})
-	for _, v := range saleList.Sales {
-		if v.IncrementID == incrementId {
-			if v.TotalPrice/2 < money {
-				if err != nil {
-					handleResponse(c, http.StatusInternalServerError, errors.New("not enough money"))
-					return
-				}
-				Id = v.Id
-				ClientID = v.ClientID
-				BranchID = v.BranchID
-				IncrementID = v.IncrementID
-				TotalPrice = v.TotalPrice
-			}
-		}
+	var filter = models.Filter{Fields: map[string][]string{"increment_id": {incrementId}}}
+	if scope := branchScope(c); len(scope) > 0 {
+		// numbers may repeat across branches, look in the user's one
+		filter.Fields["branch_id"] = []string{scope}
 	}
-	_, err = h.strg.Sale().Update(ctx, &models.UpdateSale{
-		Id:          Id,
-		ClientID:    ClientID,
-		BranchID:    BranchID,
-		IncrementID: IncrementID,
-		TotalPrice:  TotalPrice,
-		Paid:        money,
-		Debd:        TotalPrice - money,
+
+	saleList, err := h.strg.Sale().GetList(ctx, &models.GetListSaleRequest{
+		Limit:  1,
+		Filter: filter,
 	})
 	if err != nil {
-		handleResponse(c, 500, err.Error())
+		handleResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	handleResponse(c, 202, "successful payment")
+	if len(saleList.Sales) == 0 {
+		handleResponse(c, http.StatusNotFound, "no such sale")
+		return
+	}
+
+	h.pay(c, &models.CreatePayment{
+		SaleID:  saleList.Sales[0].Id,
+		Amount:  money,
+		Method:  method,
+		Cashier: c.Query("cashier"),
+	})
 }
 
 // @Summary Registration
@@ -128,7 +120,7 @@ func (h *Handler) Registration(c *gin.Context) {
 }
 
 // @Summary Branch
-// @Description Get Branch sales.
+// @Description Get Branch sales, gross and net of returns.
 // @Tags Branch Sales
 // @Accept json
 // @Produce json
@@ -143,9 +135,13 @@ func (h *Handler) BranchDoc(c *gin.Context) {
 	var (
 		branchId = c.Query("branch_id")
 		resp     = models.Doc{}
-		sale_id  = ""
+		sale_ids = map[string]bool{}
 	)
 
+	if !inScope(c, branchId) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -167,17 +163,31 @@ func (h *Handler) BranchDoc(c *gin.Context) {
 		handleResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	returnList, err := h.strg.SaleReturn().GetList(ctx, &models.GetListSaleReturnRequest{
+		Limit:  1000000,
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchId}}},
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
 	for _, v := range salesList.Sales {
 		if v.BranchID == branchId {
 			resp.TotalSalePrice += v.TotalPrice
-			sale_id = v.Id
+			sale_ids[v.Id] = true
 		}
 	}
 	for _, v := range salesProductList.SaleProducts {
-		if v.SaleID == sale_id {
+		if sale_ids[v.SaleID] {
 			resp.TotalSaleQuantity += v.Quantity
 		}
 	}
+	for _, v := range returnList.SaleReturns {
+		resp.TotalReturnPrice += v.TotalPrice
+		resp.TotalReturnQuantity += v.Quantity
+	}
+	resp.NetSalePrice = resp.TotalSalePrice - resp.TotalReturnPrice
+	resp.NetSaleQuantity = resp.TotalSaleQuantity - resp.TotalReturnQuantity
 
 	handleResponse(c, http.StatusOK, resp)
 }
@@ -0,0 +1,423 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Create a Category
+// @Description Create a Category under parent_id, a root category when it is empty.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param object body models.CreateCategory true "Category"
+// @Success 201 {object} models.Category "Created Category"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category [post]
+func (h *Handler) CreateCategory(c *gin.Context) {
+
+	var createCategory models.CreateCategory
+	err := c.ShouldBindJSON(&createCategory)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	createCategory.Name = strings.TrimSpace(createCategory.Name)
+	if len(createCategory.Name) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "name is required").WithField("name", "must not be empty"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if len(createCategory.ParentID) > 0 && !h.validCategory(ctx, c, "parent_id", createCategory.ParentID) {
+		return
+	}
+
+	resp, err := h.strg.Category().Create(ctx, &createCategory)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a Category by ID
+// @Description Get a Category by its ID.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} models.Category "Category details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/{id} [get]
+func (h *Handler) GetByIDCategory(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Category().GetByID(ctx, &models.CategoryPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Category
+// @Description Get categories, newest first. Filter by parent_id for the children of a category.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param parent_id query string false "parent_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListCategoryResponse "Categories"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category [get]
+func (h *Handler) GetListCategory(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.CategoryFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Category().GetList(ctx, &models.GetListCategoryRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Category tree
+// @Description Every category nested under its parent, siblings by name.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.CategoryNode "Root categories"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/tree [get]
+func (h *Handler) GetCategoryTree(c *gin.Context) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Category().GetTree(ctx)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update Category
+// @Description Rename a Category or move it with its descendants under another parent, not under itself or one of its descendants.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param object body models.UpdateCategory true "models.UpdateCategory"
+// @Param id path string true "id"
+// @Success 202 {object} models.Category "Category details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/{id} [put]
+func (h *Handler) UpdateCategory(c *gin.Context) {
+
+	var updateCategory models.UpdateCategory
+
+	err := c.ShouldBindJSON(&updateCategory)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	updateCategory.Name = strings.TrimSpace(updateCategory.Name)
+	if len(updateCategory.Name) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "name is required").WithField("name", "must not be empty"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if len(updateCategory.ParentID) > 0 && !h.validCategory(ctx, c, "parent_id", updateCategory.ParentID) {
+		return
+	}
+
+	updateCategory.Id = id
+
+	rowsAffected, err := h.strg.Category().Update(ctx, &updateCategory)
+	if errors.Is(err, storage.ErrCategoryCycle) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "a category can not be moved under itself").WithField("parent_id", "must not be the category or one of its descendants"))
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.strg.Category().GetByID(ctx, &models.CategoryPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete Category
+// @Description Delete a Category without children and products.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "Category is in use"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/{id} [delete]
+func (h *Handler) DeleteCategory(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	children, err := h.strg.Category().GetList(ctx, &models.GetListCategoryRequest{
+		Limit:  1,
+		Filter: models.Filter{Fields: map[string][]string{"parent_id": {id}}},
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	products, err := h.strg.Product().GetList(ctx, &models.GetListProductRequest{
+		Limit:  1,
+		Filter: models.Filter{Fields: map[string][]string{"category_id": {id}}},
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if children.Count > 0 || products.Count > 0 {
+		handleResponse(c, http.StatusConflict, apperror.New(apperror.Conflict, "category has children or products"))
+		return
+	}
+
+	err = h.strg.Category().Delete(ctx, &models.CategoryPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Sales by category
+// @Description Sold quantity and amount for every child of parent_id, the root categories when it is empty, each with all of its descendants. A last row with the parent's id holds what was sold directly in the parent, or without a category at the root level. Each row is gross, with what came back of those sales and the net of returns.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param parent_id query string false "parent category, the roots when empty"
+// @Param branch_id query string false "branch_id, all branches when empty"
+// @Param from query string false "sales from, date or RFC3339"
+// @Param to query string false "sales to, date or RFC3339"
+// @Success 200 {object} models.CategorySalesResponse "Sales by category"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/sales [get]
+func (h *Handler) CategorySales(c *gin.Context) {
+
+	req, ok := categoryReport(c)
+	if !ok {
+		return
+	}
+
+	var err error
+
+	req.From, err = getTimeQuery(c, "from", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.To, err = getTimeQuery(c, "to", true)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Category().Sales(ctx, req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Stock by category
+// @Description Stock quantity, cost and sale value for every child of parent_id, the root categories when it is empty, each with all of its descendants. A last row with the parent's id holds the stock directly in the parent, or without a category at the root level.
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param parent_id query string false "parent category, the roots when empty"
+// @Param branch_id query string false "branch_id, all branches when empty"
+// @Success 200 {object} models.CategoryStockResponse "Stock by category"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /category/stock [get]
+func (h *Handler) CategoryStock(c *gin.Context) {
+
+	req, ok := categoryReport(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Category().Stock(ctx, req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+func categoryReport(c *gin.Context) (*models.CategoryReportRequest, bool) {
+
+	var parentID = c.Query("parent_id")
+	if len(parentID) > 0 && !helpers.IsValidUUID(parentID) {
+		handleResponse(c, http.StatusBadRequest, "parent_id is not uuid")
+		return nil, false
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return nil, false
+	}
+
+	return &models.CategoryReportRequest{ParentID: parentID, BranchID: branchID}, true
+}
+
+// validCategory answers 400 and returns false unless id is an existing
+// category, reported as field.
+func (h *Handler) validCategory(ctx context.Context, c *gin.Context, field, id string) bool {
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "must be uuid"))
+		return false
+	}
+
+	_, err := h.strg.Category().GetByID(ctx, &models.CategoryPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "does not exist"))
+		return false
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
+
+// expandCategories replaces the category_id filter with the categories and
+// all of their descendants.
+func (h *Handler) expandCategories(ctx context.Context, filter *models.Filter) error {
+
+	var categories []string
+	for _, id := range filter.Fields["category_id"] {
+		subtree, err := h.strg.Category().Subtree(ctx, &models.CategoryPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		categories = append(categories, subtree...)
+	}
+
+	if len(categories) > 0 {
+		filter.Fields["category_id"] = categories
+	}
+
+	return nil
+}
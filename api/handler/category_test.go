@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCategory(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/category", h.CreateCategory)
+	r.GET("/category/sales", h.CategorySales)
+	r.GET("/category/stock", h.CategoryStock)
+	r.PUT("/category/:id", h.UpdateCategory)
+	r.DELETE("/category/:id", h.DeleteCategory)
+	r.GET("/product", h.GetListProduct)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+
+	if w := serve(r, http.MethodPost, "/category", models.CreateCategory{Name: " "}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("create without a name: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var medicine, pain, tablets models.Category
+	if w := serve(r, http.MethodPost, "/category", models.CreateCategory{Name: "Medicine"}, &medicine); w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	serve(r, http.MethodPost, "/category", models.CreateCategory{Name: "Pain", ParentID: medicine.Id}, &pain)
+	if w := serve(r, http.MethodPost, "/category", models.CreateCategory{Name: "Tablets", ParentID: pain.Id}, &tablets); w.Code != http.StatusCreated || tablets.ParentID != pain.Id {
+		t.Fatalf("create child: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPut, "/category/"+medicine.Id, models.UpdateCategory{Name: "Medicine", ParentID: tablets.Id}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("move under a descendant: status %d: %s", w.Code, w.Body.String())
+	}
+
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id, CategoryID: tablets.Id})
+	_, _ = strg.Product().Create(ctx, &models.CreateProduct{Name: "Bandage", Price: 2000, BranchID: branch.Id})
+	_, _ = strg.Remainder().Create(ctx, &models.Remainder{ProductID: aspirin.Id, Quantity: 10, ComingPrice: 3000, SalePrice: 4000, BranchID: branch.Id})
+
+	if _, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{ClientID: client.Id, BranchID: branch.Id, IncrementID: "S-0000001", Products: []*models.CheckoutProduct{{ProductID: aspirin.Id, Quantity: 2}}}); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if w := serve(r, http.MethodDelete, "/category/"+tablets.Id, nil, nil); w.Code != http.StatusConflict {
+		t.Fatalf("delete with products: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodDelete, "/category/"+pain.Id, nil, nil); w.Code != http.StatusConflict {
+		t.Fatalf("delete with children: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var products models.GetListProductResponse
+	if w := serve(r, http.MethodGet, "/product?category_id="+medicine.Id, nil, &products); w.Code != http.StatusOK || products.Count != 1 || products.Products[0].Id != aspirin.Id {
+		t.Fatalf("products under medicine: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var sales models.CategorySalesResponse
+	if w := serve(r, http.MethodGet, "/category/sales?parent_id="+medicine.Id+"&branch_id="+branch.Id, nil, &sales); w.Code != http.StatusOK || sales.Amount != 8000 || sales.Categories[0].CategoryID != pain.Id {
+		t.Fatalf("sales: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var stock models.CategoryStockResponse
+	if w := serve(r, http.MethodGet, "/category/stock?branch_id="+branch.Id, nil, &stock); w.Code != http.StatusOK || stock.Quantity != 8 || stock.Value != 32000 {
+		t.Fatalf("stock: status %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -2,7 +2,7 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,6 +11,7 @@ import (
 	"market_system/pkg/helpers"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // @Summary create a Client
@@ -70,13 +71,12 @@ func (h *Handler) GetByIDClient(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Client().GetByID(ctx, &models.ClientPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !inScope(c, resp.BranchID) {
 		return
 	}
 
@@ -91,6 +91,11 @@ func (h *Handler) GetByIDClient(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query string false "search"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param gender query string false "gender, comma separated for several"
+// @Param active query string false "active, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.Client "Client details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Client not found"
@@ -117,6 +122,12 @@ func (h *Handler) GetListClient(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.ClientFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -129,6 +140,7 @@ func (h *Handler) GetListClient(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -171,6 +183,10 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if !inScope(c, updateClient.BranchID) || !h.clientInScope(ctx, c, id) {
+		return
+	}
+
 	updateClient.Id = id
 
 	rowsAffected, err := h.strg.Client().Update(ctx, &updateClient)
@@ -227,3 +243,113 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 	handleResponse(c, http.StatusOK, "deleted")
 
 }
+
+// @Summary Client statement
+// @Description Sales and payments of a Client with a running balance.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param from query string false "from, date or RFC3339"
+// @Param to query string false "to, date or RFC3339"
+// @Success 200 {object} models.ClientStatement "Client statement"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Client not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /client/{id}/statement [get]
+func (h *Handler) ClientStatement(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	from, err := getTimeQuery(c, "from", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := getTimeQuery(c, "to", true)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	client, err := h.strg.Client().GetByID(ctx, &models.ClientPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusNotFound, "no such client")
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, client.BranchID) {
+		return
+	}
+
+	resp, err := h.strg.Client().Statement(ctx, &models.ClientStatementRequest{
+		ClientID: id,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Debt aging
+// @Description Outstanding debt of clients by days since the sale: 0-30, 31-60, 61-90 and 90+.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "Branch Id"
+// @Param at query string false "count the age to this day, now by default"
+// @Success 200 {object} models.DebtAgingResponse "Debt aging"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /debt_aging [get]
+func (h *Handler) DebtAging(c *gin.Context) {
+
+	var branchId = c.Query("branch_id")
+
+	if scope := branchScope(c); len(scope) > 0 {
+		branchId = scope
+	}
+
+	if len(branchId) > 0 && !helpers.IsValidUUID(branchId) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	at, err := getTimeQuery(c, "at", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Sale().DebtAging(ctx, &models.DebtAgingRequest{
+		BranchID: branchId,
+		At:       at,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -2,14 +2,17 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"net/http"
 
 	"market_system/config"
 	"market_system/models"
+	"market_system/pkg/apperror"
 	"market_system/pkg/helpers"
+	"market_system/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // @Summary Create a new Coming
@@ -32,17 +35,23 @@ func (h *Handler) CreateComing(c *gin.Context) {
 		return
 	}
 
+	if !inScope(c, createComing.BranchID) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	incrementId, err := h.strg.IncrementID().GetLast(ctx, "coming", "increment_id")
+	if !h.validSupplier(ctx, c, createComing.SupplierID) {
+		return
+	}
+
+	createComing.IncrementID, err = h.nextNumber(ctx, "coming", h.cfg.ComingNumbering, createComing.BranchID)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	createComing.IncrementID= "C-" + incrementId
-
 	resp, err := h.strg.Coming().Create(ctx, &createComing)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
@@ -76,13 +85,12 @@ func (h *Handler) GetByIDComing(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !inScope(c, resp.BranchID) {
 		return
 	}
 
@@ -97,6 +105,12 @@ func (h *Handler) GetByIDComing(c *gin.Context) {
 // @Param limit query int false "Number of items to return (default 10)"
 // @Param offset query int false "Number of items to skip (default 0)"
 // @Param search query string false "Search term"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param supplier_id query string false "supplier_id, comma separated for several"
+// @Param purchase_order_id query string false "purchase_order_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {array} models.Coming "List of Comings"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
@@ -122,6 +136,12 @@ func (h *Handler) GetListComing(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.ComingFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -134,6 +154,7 @@ func (h *Handler) GetListComing(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -182,6 +203,21 @@ func (h *Handler) UpdateComing(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if len(updateComing.Status) > 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "status is changed by finish and reverse").WithField("status", "must be empty"))
+		return
+	}
+
+	if !inScope(c, updateComing.BranchID) || !h.editableComing(ctx, c, id) {
+		return
+	}
+
+	if len(updateComing.SupplierID) > 0 && !h.validSupplier(ctx, c, updateComing.SupplierID) {
+		return
+	}
+
+	updateComing.Id = id
+
 	rowsAffected, err := h.strg.Coming().Update(ctx, &updateComing)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
@@ -227,6 +263,10 @@ func (h *Handler) DeleteComing(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if !h.editableComing(ctx, c, id) {
+		return
+	}
+
 	err := h.strg.Coming().Delete(ctx, &models.ComingPrimaryKey{Id: id})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
@@ -235,3 +275,316 @@ func (h *Handler) DeleteComing(c *gin.Context) {
 
 	handleResponse(c, http.StatusNoContent, nil)
 }
+
+// @Summary Finish a Coming
+// @Description Post every picking list of a draft Coming to the remainder of its branch and lock it against edits. Every product must be in the assortment of the branch.
+// @Tags Coming
+// @Accept json
+// @Produce json
+// @Param id path string true "Coming ID"
+// @Success 200 {object} models.Coming "Finished Coming"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Coming not found"
+// @Failure 409 {object} ErrorResponse "Coming is not a draft"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /coming/{id}/finish [post]
+func (h *Handler) FinishComing(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.comingInScope(ctx, c, id) {
+		return
+	}
+
+	var resp *models.Coming
+
+	err := h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		coming, err := tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := tx.Coming().SetStatus(ctx, &models.ComingStatus{Id: id, From: models.ComingDraft, To: models.ComingFinished})
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return notDraft(coming)
+		}
+
+		lines, err := comingLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(lines) == 0 {
+			return apperror.New(apperror.Validation, "coming has no picking lists")
+		}
+
+		for _, line := range lines {
+			if line.Quantity <= 0 || line.Price < 0 {
+				return apperror.New(apperror.Validation, "picking list "+line.ID+" is invalid").
+					WithField("quantity", "must be positive").
+					WithField("price", "must not be negative")
+			}
+
+			product, err := tx.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: line.Product_ID})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apperror.New(apperror.Validation, "picking list "+line.ID+" is invalid").WithField("product_id", "does not exist")
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err = notCarried(ctx, tx, "product_id", coming.BranchID, product.Id); err != nil {
+				return err
+			}
+
+			salePrice, err := tx.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: coming.BranchID, ProductID: product.Id})
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+				BranchID:       coming.BranchID,
+				ProductID:      product.Id,
+				Quantity:       line.Quantity,
+				Type:           models.MovementReceipt,
+				DocumentID:     coming.Id,
+				DocumentNumber: coming.IncrementID,
+				UserID:         c.GetString(ctxUserID),
+				LotNumber:      line.LotNumber,
+				ExpiryDate:     line.ExpiryDate,
+				Name:           product.Name,
+				ComingPrice:    line.Price,
+				SalePrice:      salePrice,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Coming().CreateEvent(ctx, &models.CreateComingEvent{
+			ComingID: id,
+			Action:   models.ComingEventFinish,
+			UserID:   c.GetString(ctxUserID),
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(coming.PurchaseOrderID) > 0 {
+			if err = refreshPurchaseOrder(ctx, tx, coming.PurchaseOrderID); err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+		return err
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Reverse a Coming
+// @Description Take the picking lists of a finished Coming back out of the remainder of its branch.
+// @Tags Coming
+// @Accept json
+// @Produce json
+// @Param id path string true "Coming ID"
+// @Param object body models.ReverseComing true "Reason"
+// @Success 200 {object} models.Coming "Reversed Coming"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Coming not found"
+// @Failure 409 {object} ErrorResponse "Coming is not finished or its stock is already sold"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /coming/{id}/reverse [post]
+func (h *Handler) ReverseComing(c *gin.Context) {
+
+	var reverseComing models.ReverseComing
+	err := c.ShouldBindJSON(&reverseComing)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if len(reverseComing.Reason) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "reason is required").WithField("reason", "is required"))
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.comingInScope(ctx, c, id) {
+		return
+	}
+
+	var resp *models.Coming
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		coming, err := tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := tx.Coming().SetStatus(ctx, &models.ComingStatus{Id: id, From: models.ComingFinished, To: models.ComingReversed})
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return apperror.New(apperror.Conflict, "coming "+coming.IncrementID+" is "+coming.Status).WithField("status", "must be finished")
+		}
+
+		lines, err := comingLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+				BranchID:       coming.BranchID,
+				ProductID:      line.Product_ID,
+				Quantity:       -line.Quantity,
+				Type:           models.MovementReceipt,
+				DocumentID:     coming.Id,
+				DocumentNumber: coming.IncrementID,
+				UserID:         c.GetString(ctxUserID),
+				Comment:        reverseComing.Reason,
+				LotNumber:      line.LotNumber,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Coming().CreateEvent(ctx, &models.CreateComingEvent{
+			ComingID: id,
+			Action:   models.ComingEventReverse,
+			UserID:   c.GetString(ctxUserID),
+			Reason:   reverseComing.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(coming.PurchaseOrderID) > 0 {
+			if err = refreshPurchaseOrder(ctx, tx, coming.PurchaseOrderID); err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+		return err
+	})
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Coming events
+// @Description Who finished and reversed a Coming, and why.
+// @Tags Coming
+// @Accept json
+// @Produce json
+// @Param id path string true "Coming ID"
+// @Success 200 {object} []models.ComingEvent "Coming events"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /coming/{id}/events [get]
+func (h *Handler) GetComingEvents(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.comingInScope(ctx, c, id) {
+		return
+	}
+
+	resp, err := h.strg.Coming().GetEvents(ctx, &models.ComingPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// editableComing answers and returns false unless the coming is a draft
+// of the user's branch.
+func (h *Handler) editableComing(ctx context.Context, c *gin.Context, id string) bool {
+
+	coming, err := h.strg.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+	if !storedInScope(c, err, func() string { return coming.BranchID }) {
+		return false
+	}
+
+	if err == nil && coming.Status != models.ComingDraft {
+		handleResponse(c, http.StatusConflict, notDraft(coming))
+		return false
+	}
+
+	return true
+}
+
+func notDraft(coming *models.Coming) *apperror.Error {
+	return apperror.New(apperror.Conflict, "coming "+coming.IncrementID+" is "+coming.Status).WithField("status", "must be draft")
+}
+
+// comingLines reads every picking list of a coming.
+func comingLines(ctx context.Context, strg storage.StorageI, comingID string) ([]*models.PickingList, error) {
+
+	var lines []*models.PickingList
+
+	for {
+		list, err := strg.PickingList().GetList(ctx, &models.GetListPickingListRequest{
+			Offset: int64(len(lines)),
+			Limit:  100,
+			Filter: models.Filter{Fields: map[string][]string{"coming_id": {comingID}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, list.Pickinges...)
+		if len(list.Pickinges) == 0 || len(lines) >= list.Count {
+			return lines, nil
+		}
+	}
+}
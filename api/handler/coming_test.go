@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestComingLifecycle(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/coming/:id/finish", h.FinishComing)
+	r.POST("/coming/:id/reverse", h.ReverseComing)
+	r.PUT("/picking_list/:id", h.UpdatePickingList)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: branch.Id})
+	coming, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00001", BranchID: branch.Id})
+	line, _ := strg.PickingList().Create(ctx, &models.PickingList{Product_ID: product.Id, Price: 2500, Quantity: 4, ComingID: coming.Id, ComingIncrementID: coming.IncrementID})
+	uncarried, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Citramon", Price: 2000})
+	other, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00002", BranchID: branch.Id})
+	_, _ = strg.PickingList().Create(ctx, &models.PickingList{Product_ID: uncarried.Id, Price: 1500, Quantity: 2, ComingID: other.Id, ComingIncrementID: other.IncrementID})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+		stock  int
+	}{
+		{name: "finish draft", method: http.MethodPost, path: "/coming/" + coming.Id + "/finish", status: http.StatusOK, stock: 4},
+		{name: "finish a product the branch does not carry", method: http.MethodPost, path: "/coming/" + other.Id + "/finish", status: http.StatusBadRequest, stock: 4},
+		{name: "finish twice", method: http.MethodPost, path: "/coming/" + coming.Id + "/finish", status: http.StatusConflict, stock: 4},
+		{name: "edit finished line", method: http.MethodPut, path: "/picking_list/" + line.ID + "?id=" + line.ID, body: models.PickingList{Product_ID: product.Id, Quantity: 10}, status: http.StatusConflict, stock: 4},
+		{name: "reverse without reason", method: http.MethodPost, path: "/coming/" + coming.Id + "/reverse", body: models.ReverseComing{}, status: http.StatusBadRequest, stock: 4},
+		{name: "reverse", method: http.MethodPost, path: "/coming/" + coming.Id + "/reverse", body: models.ReverseComing{Reason: "wrong supplier"}, status: http.StatusOK, stock: 0},
+		{name: "reverse twice", method: http.MethodPost, path: "/coming/" + coming.Id + "/reverse", body: models.ReverseComing{Reason: "again"}, status: http.StatusConflict, stock: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(tt.body)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			remainders, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+				Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}, "product_id": {product.Id}}},
+			})
+			if err != nil || len(remainders.Remainders) != 1 {
+				t.Fatalf("remainders: %+v %v", remainders, err)
+			}
+
+			if remainders.Remainders[0].Quantity != tt.stock {
+				t.Fatalf("stock = %d, want %d", remainders.Remainders[0].Quantity, tt.stock)
+			}
+		})
+	}
+}
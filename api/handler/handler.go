@@ -1,12 +1,22 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
 	"market_system/storage"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
 )
 
 type Handler struct {
@@ -15,9 +25,11 @@ type Handler struct {
 
 }
 
+// ErrorResponse is the body of every error response.
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Status      int            `json:"status"`
+	Description string         `json:"description"`
+	Data        apperror.Error `json:"data"`
 }
 
 // Response - Json model response
@@ -34,6 +46,18 @@ func NewHandler(cfg *config.Config, strg storage.StorageI) *Handler {
 	}
 }
 
+// nextNumber hands out the next number of document as configured by numbering.
+func (h *Handler) nextNumber(ctx context.Context, document string, numbering config.Numbering, branchID string) (string, error) {
+	return h.strg.DocumentNumber().Next(ctx, &models.NextDocumentNumber{
+		Document:    document,
+		BranchID:    branchID,
+		Prefix:      numbering.Prefix,
+		Width:       numbering.Width,
+		PerBranch:   numbering.PerBranch,
+		YearlyReset: numbering.YearlyReset,
+	})
+}
+
 func getIntegerOrDefaultValue(value string, defaultValue int64) (int64, error) {
 
 	if len(value) <= 0 {
@@ -44,6 +68,107 @@ func getIntegerOrDefaultValue(value string, defaultValue int64) (int64, error) {
 	return int64(number), err
 }
 
+// getListFilter reads the filters declared by spec from the query string:
+// <field>=a,b for equality or IN, <number>_min and <number>_max for ranges,
+// and created_from/created_to as a date or an RFC3339 time.
+func getListFilter(c *gin.Context, spec models.FilterSpec) (models.Filter, error) {
+
+	var filter models.Filter
+
+	for _, column := range spec.Fields {
+		value := c.Query(column)
+		if len(value) == 0 {
+			continue
+		}
+
+		values := strings.Split(value, ",")
+		if strings.HasSuffix(column, "_id") && !strings.HasSuffix(column, "increment_id") {
+			for _, v := range values {
+				if !helpers.IsValidUUID(v) {
+					return filter, fmt.Errorf("%s is not uuid", column)
+				}
+			}
+		}
+
+		if filter.Fields == nil {
+			filter.Fields = map[string][]string{}
+		}
+		filter.Fields[column] = values
+	}
+
+	// BRANCH and CASSIER users only ever see their own branch
+	if scope := branchScope(c); len(scope) > 0 && contains(spec.Fields, "branch_id") {
+		if filter.Fields == nil {
+			filter.Fields = map[string][]string{}
+		}
+		filter.Fields["branch_id"] = []string{scope}
+	}
+
+	for _, column := range spec.Numbers {
+		var numberRange models.NumberRange
+
+		for suffix, bound := range map[string]**float64{"_min": &numberRange.Min, "_max": &numberRange.Max} {
+			value := c.Query(column + suffix)
+			if len(value) == 0 {
+				continue
+			}
+
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid query %s%s", column, suffix)
+			}
+			*bound = &number
+		}
+
+		if numberRange.Min != nil || numberRange.Max != nil {
+			if filter.Ranges == nil {
+				filter.Ranges = map[string]models.NumberRange{}
+			}
+			filter.Ranges[column] = numberRange
+		}
+	}
+
+	var err error
+
+	filter.CreatedFrom, err = getTimeQuery(c, "created_from", false)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.CreatedTo, err = getTimeQuery(c, "created_to", true)
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// getTimeQuery reads a date or an RFC3339 time, nil when it is not given.
+// A date that ends a range includes the whole day.
+func getTimeQuery(c *gin.Context, name string, end bool) (*time.Time, error) {
+
+	value := c.Query(name)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %s", name)
+		}
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+
+	return &t, nil
+}
+
+// handleResponse writes data as the response. An error response always
+// carries an apperror.Error: typed errors set the status themselves, other
+// errors and messages get the code of status.
 func handleResponse(c *gin.Context, status int, data interface{}) {
 	var description string
 	switch code := status; {
@@ -51,15 +176,30 @@ func handleResponse(c *gin.Context, status int, data interface{}) {
 		description = "success"
 	default:
 		description = "error"
+
+		var appErr *apperror.Error
+		switch v := data.(type) {
+		case *apperror.Error:
+			appErr = v
+		case error:
+			if !errors.As(apperror.Translate(v), &appErr) {
+				appErr = &apperror.Error{Code: apperror.CodeOf(status), Message: v.Error(), Err: v}
+			}
+		default:
+			appErr = apperror.New(apperror.CodeOf(status), cast.ToString(v))
+		}
+
 		log.Println(config.Error, "error while:", Response{
 			Status:      status,
 			Description: description,
-			Data:        data,
+			Data:        appErr.Error(),
 		})
 
-		if code == 500 {
-			data = "Internal Server Error"
+		status = appErr.Status()
+		if status == http.StatusInternalServerError {
+			appErr = apperror.New(apperror.Internal, "Internal Server Error")
 		}
+		data = appErr
 	}
 
 	c.JSON(status, Response{
@@ -68,3 +208,12 @@ func handleResponse(c *gin.Context, status int, data interface{}) {
 		Data:        data,
 	})
 }
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// serve sends body to r as JSON and decodes the data of the response into
+// data when it is not nil.
+func serve(r http.Handler, method, path string, body interface{}, data interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(payload)))
+	if data != nil {
+		_ = json.Unmarshal(w.Body.Bytes(), &struct {
+			Data interface{} `json:"data"`
+		}{Data: data})
+	}
+	return w
+}
+
+func TestHandleResponse(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		status  int
+		data    interface{}
+		want    int
+		code    apperror.Code
+		message string
+		fields  map[string]string
+	}{
+		{
+			name:   "missing row",
+			status: http.StatusInternalServerError,
+			data:   pgx.ErrNoRows,
+			want:   http.StatusNotFound,
+			code:   apperror.NotFound,
+		},
+		{
+			name:   "unique violation",
+			status: http.StatusInternalServerError,
+			data:   &pgconn.PgError{Code: "23505", Detail: "Key (login)=(superadmin) already exists."},
+			want:   http.StatusConflict,
+			code:   apperror.Conflict,
+			fields: map[string]string{"login": "already exists"},
+		},
+		{
+			name:   "foreign key violation",
+			status: http.StatusInternalServerError,
+			data:   &pgconn.PgError{Code: "23503", Detail: `Key (branch_id)=(f4877292-4468-44e2-a27f-9743c7a35802) is not present in table "branch".`},
+			want:   http.StatusConflict,
+			code:   apperror.ForeignKeyViolation,
+			fields: map[string]string{"branch_id": "does not exist"},
+		},
+		{
+			name:   "check violation",
+			status: http.StatusInternalServerError,
+			data:   &pgconn.PgError{Code: "23514", TableName: "payment", ConstraintName: "payment_method_check"},
+			want:   http.StatusBadRequest,
+			code:   apperror.Validation,
+			fields: map[string]string{"method": "is out of the allowed values"},
+		},
+		{
+			name:    "message",
+			status:  http.StatusBadRequest,
+			data:    "id is not uuid",
+			want:    http.StatusBadRequest,
+			code:    apperror.Validation,
+			message: "id is not uuid",
+		},
+		{
+			name:    "unknown error",
+			status:  http.StatusInternalServerError,
+			data:    errors.New("connection refused"),
+			want:    http.StatusInternalServerError,
+			code:    apperror.Internal,
+			message: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			handleResponse(c, tt.status, tt.data)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode %s: %v", w.Body.String(), err)
+			}
+
+			if resp.Status != tt.want || resp.Data.Code != tt.code {
+				t.Fatalf("unexpected body %s", w.Body.String())
+			}
+			if len(tt.message) > 0 && resp.Data.Message != tt.message {
+				t.Fatalf("message = %q, want %q", resp.Data.Message, tt.message)
+			}
+			for field, message := range tt.fields {
+				if resp.Data.Fields[field] != message {
+					t.Fatalf("fields = %v, want %v", resp.Data.Fields, tt.fields)
+				}
+			}
+		})
+	}
+}
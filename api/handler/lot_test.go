@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLotComing(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+		day  = func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02") }
+	)
+
+	r.POST("/picking_list", h.CreatePickingList)
+	r.POST("/coming/:id/finish", h.FinishComing)
+	r.POST("/coming/:id/reverse", h.ReverseComing)
+	r.GET("/remainder/expiring", h.GetExpiringRemainder)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yunusobod"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Amoxicillin", Price: 9000, BranchID: branch.Id})
+	coming, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00002", BranchID: branch.Id})
+
+	line := func(lot, expiry string, quantity int) models.CreatePickingList {
+		return models.CreatePickingList{Product_ID: product.Id, Quantity: quantity, Price: 7000, ComingIncrementID: coming.IncrementID, LotNumber: lot, ExpiryDate: expiry}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+		lots   map[string]int
+	}{
+		{name: "expiry is not a date", method: http.MethodPost, path: "/picking_list", body: line("L1", "31.12.2030", 5), status: http.StatusBadRequest},
+		{name: "no such coming", method: http.MethodPost, path: "/picking_list", body: models.CreatePickingList{Product_ID: product.Id, Quantity: 5, Price: 7000, ComingIncrementID: "C-99999"}, status: http.StatusBadRequest},
+		{name: "expiry without lot", method: http.MethodPost, path: "/picking_list", body: line("", day(20), 5), status: http.StatusBadRequest},
+		{name: "near lot", method: http.MethodPost, path: "/picking_list", body: line("L1", day(20), 5), status: http.StatusCreated},
+		{name: "far lot", method: http.MethodPost, path: "/picking_list", body: line("L2", day(200), 3), status: http.StatusCreated},
+		{name: "finish", method: http.MethodPost, path: "/coming/" + coming.Id + "/finish", status: http.StatusOK, lots: map[string]int{"L1": 5, "L2": 3}},
+		{name: "expiring", method: http.MethodGet, path: "/remainder/expiring?days=30&branch_id=" + branch.Id, status: http.StatusOK, lots: map[string]int{"L1": 5}},
+		{name: "expiring bad days", method: http.MethodGet, path: "/remainder/expiring?days=-1", status: http.StatusBadRequest},
+		{name: "reverse", method: http.MethodPost, path: "/coming/" + coming.Id + "/reverse", body: models.ReverseComing{Reason: "recalled"}, status: http.StatusOK, lots: map[string]int{"L1": 0, "L2": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(tt.body)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			if tt.lots == nil {
+				return
+			}
+
+			var got = map[string]int{}
+
+			if tt.method == http.MethodGet {
+				var resp struct {
+					Data models.ExpiringResponse `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				for _, lot := range resp.Data.Lots {
+					got[lot.LotNumber] = lot.Quantity
+				}
+			} else {
+				remainders, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+					Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}, "product_id": {product.Id}}},
+				})
+				if err != nil {
+					t.Fatalf("remainders: %v", err)
+				}
+				for _, rm := range remainders.Remainders {
+					got[rm.LotNumber] = rm.Quantity
+				}
+			}
+
+			if len(got) != len(tt.lots) {
+				t.Fatalf("lots = %v, want %v", got, tt.lots)
+			}
+			for lot, quantity := range tt.lots {
+				if got[lot] != quantity {
+					t.Fatalf("lots = %v, want %v", got, tt.lots)
+				}
+			}
+		})
+	}
+}
+
+func TestLotCarriedOver(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+		day  = func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02") }
+	)
+
+	r.POST("/transfer/:id/send", h.SendTransfer)
+	r.POST("/transfer/:id/receive", h.ReceiveTransfer)
+	r.POST("/sale_return", h.CreateSaleReturn)
+
+	from, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	to, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Olmazor"})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", BranchID: from.Id})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Amoxicillin", Price: 9000, BranchID: from.Id})
+	_, _ = strg.BranchProduct().Set(ctx, &models.SetBranchProduct{BranchID: to.Id, ProductID: product.Id})
+
+	for lot, expiry := range map[string]string{"X1": day(-1), "L1": day(200)} {
+		_, err := strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID: from.Id, ProductID: product.Id, Quantity: 3, Type: models.MovementReceipt,
+			LotNumber: lot, ExpiryDate: expiry, Name: product.Name, ComingPrice: 7000, SalePrice: product.Price,
+		})
+		if err != nil {
+			t.Fatalf("opening stock: %v", err)
+		}
+	}
+
+	lots := func(branchID string) map[string]*models.Remainder {
+		remainders, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchID}, "product_id": {product.Id}}},
+		})
+		if err != nil {
+			t.Fatalf("remainders: %v", err)
+		}
+		var got = map[string]*models.Remainder{}
+		for _, rm := range remainders.Remainders {
+			got[rm.LotNumber] = rm
+		}
+		return got
+	}
+
+	// the expired lot leaves by transfer and must stay expired on arrival
+	transfer, _ := strg.Transfer().Create(ctx, &models.CreateTransfer{
+		IncrementID:  "T-0000001",
+		FromBranchID: from.Id,
+		ToBranchID:   to.Id,
+		Lines:        []*models.CreateTransferLine{{ProductID: product.Id, Quantity: 3}},
+	})
+
+	if w := serve(r, http.MethodPost, "/transfer/"+transfer.Id+"/send", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("send: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPost, "/transfer/"+transfer.Id+"/receive", models.ReceiveTransfer{Lines: []*models.ReceiveTransferLine{{LineID: transfer.Lines[0].Id, Quantity: 3}}}, nil); w.Code != http.StatusOK {
+		t.Fatalf("receive: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if got := lots(to.Id); len(got) != 1 || got["X1"] == nil || got["X1"].Quantity != 3 || got["X1"].ExpiryDate[:10] != day(-1) {
+		t.Fatalf("received lots %v", got)
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    from.Id,
+		IncrementID: "S-0000001",
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	for _, quantity := range []int{1, 1} {
+		w := serve(r, http.MethodPost, "/sale_return", models.CreateSaleReturn{SaleID: checkout.Sale.Id, Lines: []*models.CreateSaleReturnLine{{SaleProductID: checkout.SaleProducts[0].Id, Quantity: quantity}}}, nil)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("return: status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	if got := lots(from.Id); got["L1"] == nil || got["L1"].Quantity != 3 || got["L1"].ExpiryDate[:10] != day(200) || got[""] != nil && got[""].Quantity != 0 {
+		t.Fatalf("returned lots %v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary create a Payment
+// @Description Pay a Sale. A negative amount is a refund.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param object body models.CreatePayment true "Payment"
+// @Success 201 {object} models.Payment "Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /payment [post]
+func (h *Handler) CreatePayment(c *gin.Context) {
+
+	var createPayment models.CreatePayment
+	err := c.ShouldBindJSON(&createPayment)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if createPayment.Method == "" {
+		createPayment.Method = "cash"
+	}
+
+	h.pay(c, &createPayment)
+}
+
+// pay validates and records a payment of a sale of the user's branch.
+func (h *Handler) pay(c *gin.Context, req *models.CreatePayment) {
+
+	if !helpers.IsValidUUID(req.SaleID) {
+		handleResponse(c, http.StatusBadRequest, "sale_id is not uuid")
+		return
+	}
+
+	if req.Amount == 0 {
+		handleResponse(c, http.StatusBadRequest, "amount must not be zero")
+		return
+	}
+
+	if !contains(models.PaymentMethods, req.Method) {
+		handleResponse(c, http.StatusBadRequest, "method must be one of cash, card, transfer")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.saleInScope(ctx, c, req.SaleID) {
+		return
+	}
+
+	resp, err := h.strg.Payment().Create(ctx, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, "no such sale")
+		return
+	}
+
+	if errors.Is(err, storage.ErrOverpayment) || errors.Is(err, storage.ErrOverRefund) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a Payment by ID
+// @Description Get Payment details by its ID.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} models.Payment "Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Payment not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /payment/{id} [get]
+func (h *Handler) GetByIDPayment(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Payment().GetByID(ctx, &models.PaymentPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.saleInScope(ctx, c, resp.SaleID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Payment
+// @Description Get payment history, newest first. Filter by sale_id for the history of one sale.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param sale_id query string false "sale_id, comma separated for several"
+// @Param method query string false "method, comma separated for several"
+// @Param cashier query string false "cashier, comma separated for several"
+// @Param branch_id query string false "branch_id of the sale, comma separated for several"
+// @Param amount_min query number false "min amount"
+// @Param amount_max query number false "max amount"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListPaymentResponse "Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /payment [get]
+func (h *Handler) GetListPayment(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.PaymentFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Payment().GetList(ctx, &models.GetListPaymentRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -2,18 +2,21 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"market_system/config"
 	"market_system/models"
+	"market_system/pkg/apperror"
 	"market_system/pkg/helpers"
+	"market_system/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary create a PickingList
-// @Description Create PickingList
+// @Description Create a PickingList line of a draft coming. Quantity and price are in unit, a pack of the product or its base unit when empty, and are kept in the base unit.
 // @Tags PickingList
 // @Accept json
 // @Produce json
@@ -32,97 +35,74 @@ func (h *Handler) CreatePickingList(c *gin.Context) {
 		return
 	}
 
+	if err = validateLot(createPickingList.LotNumber, createPickingList.ExpiryDate); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	// get Coming List
-	coming, err := h.strg.Coming().GetList(ctx, &models.GetListComingRequest{
-		Limit: 10000,
-	})
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	unit, ok := h.productUnit(ctx, c, "unit", createPickingList.Product_ID, createPickingList.Unit)
+	if !ok {
 		return
 	}
-	var (
-		cominggg     models.Coming
-		id           sql.NullString
-		productID    sql.NullString
-		productName  sql.NullString
-		quantity     sql.NullInt64
-		coming_price sql.NullFloat64
-		salePrice    sql.NullFloat64
-		branchID     sql.NullString
-	)
-	for _, v := range coming.Cominges {
-		if v.IncrementID == createPickingList.ComingIncrementID {
-			createPickingList.ComingID = v.Id
-			cominggg.BranchID = v.BranchID
-		}
-	}
+	inBaseUnit(&createPickingList, unit)
 
-	// create picking_list
-	resp, err := h.strg.PickingList().Create(ctx, &createPickingList)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
-		return
-	}
+	var resp *models.PickingList
 
-	// Get product
-	productResp, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: createPickingList.Product_ID})
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, err)
-		return
-	}
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
 
-	listRemainder, err := h.strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{Limit: 1000})
-	if len(listRemainder.Remainders) == 0 {
-		_, err = h.strg.Remainder().Create(ctx, &models.Remainder{
-			ProductID:   productResp.Id,
-			Name:        productResp.Name,
-			Quantity:    createPickingList.Quantity,
-			ComingPrice: createPickingList.Price,
-			SalePrice:   productResp.Price,
-			BranchID:    cominggg.BranchID,
+		// get Coming
+		coming, err := tx.Coming().GetList(ctx, &models.GetListComingRequest{
+			Limit: 1,
+			Filter: models.Filter{
+				Fields: map[string][]string{"increment_id": {createPickingList.ComingIncrementID}},
+			},
 		})
-		handleResponse(c, http.StatusAccepted, err)
-		return
-	}
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, err)
-		return
-	}
+		if err != nil {
+			return err
+		}
 
-	for _, v := range listRemainder.Remainders {
-
-		if v.BranchID == cominggg.BranchID && v.ProductID == createPickingList.Product_ID {
-			id.String = v.Id
-			productID.String = v.ProductID
-			productName.String = v.Name
-			quantity.Int64 = int64(v.Quantity)
-			coming_price.Float64 = v.ComingPrice
-			salePrice.Float64 = productResp.Price
-			branchID.String = v.BranchID
-			break
+		if len(coming.Cominges) == 0 {
+			return apperror.New(apperror.Validation, "no such coming "+createPickingList.ComingIncrementID).WithField("coming_increment_id", "is not a coming")
+		}
+
+		if scope := branchScope(c); len(scope) > 0 && scope != coming.Cominges[0].BranchID {
+			return errOutOfScope
 		}
-	}
 
-	_, err = h.strg.Remainder().Update(ctx, &models.Remainder{
-		Id:          id.String,
-		ProductID:   productID.String,
-		Name:        productName.String,
-		Quantity:    int(quantity.Int64) + createPickingList.Quantity,
-		ComingPrice: coming_price.Float64,
-		SalePrice:   salePrice.Float64,
-		BranchID:    branchID.String,
+		// stock is posted to the remainder once the coming is finished
+		if coming.Cominges[0].Status != models.ComingDraft {
+			return notDraft(coming.Cominges[0])
+		}
+		createPickingList.ComingID = coming.Cominges[0].Id
+
+		// create picking_list
+		resp, err = tx.PickingList().Create(ctx, &createPickingList)
+		return err
 	})
+	if errors.Is(err, errOutOfScope) {
+		handleResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
 	if err != nil {
-		handleResponse(c, http.StatusBadRequest, err)
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	handleResponse(c, http.StatusCreated, resp)
 }
 
+// inBaseUnit turns the quantity and price of a line entered in unit into
+// the base unit of the product, the one the stock is kept in.
+func inBaseUnit(line *models.PickingList, unit *models.ProductUnit) {
+	line.Unit, line.UnitQuantity = unit.Name, line.Quantity
+	line.Quantity *= unit.Factor
+	line.Price /= float64(unit.Factor)
+}
+
 // @Summary Get a PickingList by ID
 // @Description Get PickingList details by its ID.
 // @Tags PickingList
@@ -147,13 +127,12 @@ func (h *Handler) GetByIDPickingList(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.PickingList().GetByID(ctx, &models.PickingListPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !h.comingInScope(ctx, c, resp.ComingID) {
 		return
 	}
 
@@ -168,6 +147,19 @@ func (h *Handler) GetByIDPickingList(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query int false "search"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param coming_id query string false "coming_id, comma separated for several"
+// @Param coming_increment_id query string false "coming_increment_id, comma separated for several"
+// @Param lot_number query string false "lot_number, comma separated for several"
+// @Param branch_id query string false "branch_id of the coming, comma separated for several"
+// @Param price_min query number false "min price"
+// @Param price_max query number false "max price"
+// @Param quantity_min query number false "min quantity"
+// @Param quantity_max query number false "max quantity"
+// @Param total_price_min query number false "min total_price"
+// @Param total_price_max query number false "max total_price"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.PickingList "PickingList details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "PickingList not found"
@@ -193,6 +185,12 @@ func (h *Handler) GetListPickingList(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.PickingListFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -205,6 +203,7 @@ func (h *Handler) GetListPickingList(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -238,6 +237,11 @@ func (h *Handler) UpdatePickingList(c *gin.Context) {
 		return
 	}
 
+	if err = validateLot(updatePickingList.LotNumber, updatePickingList.ExpiryDate); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
 	var id = c.Query("id")
 	if !helpers.IsValidUUID(id) {
 		handleResponse(c, http.StatusBadRequest, "id is not uuid")
@@ -247,7 +251,25 @@ func (h *Handler) UpdatePickingList(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	pickingList, err := h.strg.PickingList().GetByID(ctx, &models.PickingListPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.editableComing(ctx, c, pickingList.ComingID) {
+		return
+	}
+
+	unit, ok := h.productUnit(ctx, c, "unit", updatePickingList.Product_ID, updatePickingList.Unit)
+	if !ok {
+		return
+	}
+	inBaseUnit(&updatePickingList, unit)
+
 	updatePickingList.ID = id
+	updatePickingList.ComingID = pickingList.ComingID
+	updatePickingList.ComingIncrementID = pickingList.ComingIncrementID
 
 	rowsAffected, err := h.strg.PickingList().Update(ctx, &updatePickingList)
 	if err != nil {
@@ -294,7 +316,17 @@ func (h *Handler) DeletePickingList(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	err := h.strg.PickingList().Delete(ctx, &models.PickingListPrimaryKey{Id: id})
+	pickingList, err := h.strg.PickingList().GetByID(ctx, &models.PickingListPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.editableComing(ctx, c, pickingList.ComingID) {
+		return
+	}
+
+	err = h.strg.PickingList().Delete(ctx, &models.PickingListPrimaryKey{Id: id})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -303,3 +335,22 @@ func (h *Handler) DeletePickingList(c *gin.Context) {
 	handleResponse(c, http.StatusOK, "deleted")
 
 }
+
+// validateLot checks the lot of a picking list or a stock movement, an
+// expiry date is a YYYY-MM-DD date of a numbered lot.
+func validateLot(lotNumber, expiryDate string) error {
+
+	if len(expiryDate) == 0 {
+		return nil
+	}
+
+	if _, err := time.Parse("2006-01-02", expiryDate); err != nil {
+		return apperror.New(apperror.Validation, "expiry_date is not a date").WithField("expiry_date", "must be YYYY-MM-DD")
+	}
+
+	if len(lotNumber) == 0 {
+		return apperror.New(apperror.Validation, "expiry_date needs a lot_number").WithField("lot_number", "is required with expiry_date")
+	}
+
+	return nil
+}
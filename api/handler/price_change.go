@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Schedule a Price change
+// @Description Change the catalog price of a product, or its price in branch_id. Without effective_from, or with a past one, the change is applied right away, otherwise when it becomes due. A null price puts the branch back on the catalog price.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param object body models.CreatePriceChange true "Price change"
+// @Success 201 {object} models.PriceChange "Price change"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /price_change [post]
+func (h *Handler) CreatePriceChange(c *gin.Context) {
+
+	var createPriceChange models.CreatePriceChange
+	err := c.ShouldBindJSON(&createPriceChange)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if createPriceChange.Price == nil && len(createPriceChange.BranchID) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "price is required").WithField("price", "required for the catalog"))
+		return
+	}
+
+	if createPriceChange.Price != nil && *createPriceChange.Price < 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "price must not be negative").WithField("price", "must not be negative"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.validProduct(ctx, c, "product_id", createPriceChange.ProductID) {
+		return
+	}
+
+	if len(createPriceChange.BranchID) > 0 && !h.validBranch(ctx, c, "branch_id", createPriceChange.BranchID) {
+		return
+	}
+
+	resp, err := h.strg.PriceChange().Create(ctx, &createPriceChange)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a Price change by ID
+// @Description Get a change of the price history, applied_at is empty while it waits for effective_from.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param id path string true "Price change ID"
+// @Success 200 {object} models.PriceChange "Price change"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Price change not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /price_change/{id} [get]
+func (h *Handler) GetByIDPriceChange(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.PriceChange().GetByID(ctx, &models.PriceChangePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get the Price history
+// @Description Get the price changes of products, latest effective_from first. Catalog changes have an empty branch_id.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "product name"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param document_id query string false "repricing id, comma separated for several"
+// @Param price_min query number false "min price"
+// @Param price_max query number false "max price"
+// @Param pending query bool false "only the changes not applied yet"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListPriceChangeResponse "Price changes"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /price_change [get]
+func (h *Handler) GetListPriceChange(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.PriceChangeFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.PriceChange().GetList(ctx, &models.GetListPriceChangeRequest{
+		Limit:   limit,
+		Offset:  offset,
+		Search:  c.Query("search"),
+		Filter:  filter,
+		Pending: c.Query("pending") == "true",
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Cancel a Price change
+// @Description Cancel a scheduled price change before it is applied, an applied one stays in the history.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param id path string true "Price change ID"
+// @Success 200 {object} Response "cancelled"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "Already applied"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /price_change/{id} [delete]
+func (h *Handler) CancelPriceChange(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	rowsAffected, err := h.strg.PriceChange().Cancel(ctx, &models.PriceChangePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected <= 0 {
+		_, err = h.strg.PriceChange().GetByID(ctx, &models.PriceChangePrimaryKey{Id: id})
+		if err == nil {
+			handleResponse(c, http.StatusConflict, apperror.New(apperror.Conflict, "the price change is already applied"))
+			return
+		}
+		handleResponse(c, http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "cancelled")
+}
+
+// @Summary Get a Price as of a time
+// @Description Get the price of a product in a branch at a time, from its price history, the catalog price when the branch had no override then. Without branch_id the catalog price, without at the price now. Scheduled changes count from their effective_from.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param product_id query string true "product_id"
+// @Param branch_id query string false "branch_id"
+// @Param at query string false "date or RFC3339, now when empty"
+// @Success 200 {object} models.PriceAsOf "Price"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "No price at that time"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /price_change/as_of [get]
+func (h *Handler) GetPriceAsOf(c *gin.Context) {
+
+	var productID = c.Query("product_id")
+
+	if !helpers.IsValidUUID(productID) {
+		handleResponse(c, http.StatusBadRequest, "product_id is not uuid")
+		return
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	at, err := getTimeQuery(c, "at", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if at == nil {
+		now := time.Now()
+		at = &now
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.PriceChange().AsOf(ctx, &models.PriceAsOfRequest{ProductID: productID, BranchID: branchID, At: *at})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusNotFound, apperror.New(apperror.NotFound, "the product had no price at that time"))
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// validProduct answers 400 and returns false unless id is an existing
+// product, reported as field.
+func (h *Handler) validProduct(ctx context.Context, c *gin.Context, field, id string) bool {
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "must be uuid"))
+		return false
+	}
+
+	_, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, field+" is invalid").WithField(field, "does not exist"))
+		return false
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
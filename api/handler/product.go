@@ -2,18 +2,21 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"net/http"
+	"strings"
 
 	"market_system/config"
 	"market_system/models"
+	"market_system/pkg/apperror"
 	"market_system/pkg/helpers"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // @Summary create a Product
-// @Description Create Product
+// @Description Create a Product of the catalog with its barcodes, its base unit (pcs when empty) and the packs it is received and sold in. A product created for a branch_id is put in the assortment of that branch.
 // @Tags Product
 // @Accept json
 // @Produce json
@@ -32,9 +35,28 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	if err = validateBarcodes(createProduct.Barcodes); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	createProduct.Unit = strings.TrimSpace(createProduct.Unit)
+	if len(createProduct.Unit) == 0 {
+		createProduct.Unit = models.DefaultUnit
+	}
+
+	if err = validateUnits(createProduct.Unit, createProduct.Units); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if len(createProduct.CategoryID) > 0 && !h.validCategory(ctx, c, "category_id", createProduct.CategoryID) {
+		return
+	}
+
 	resp, err := h.strg.Product().Create(ctx, &createProduct)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
@@ -68,11 +90,6 @@ func (h *Handler) GetByIDProduct(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
-		return
-	}
-
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -89,6 +106,12 @@ func (h *Handler) GetByIDProduct(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query string false "search"
+// @Param branch_id query string false "products the branch carries, comma separated for several branches"
+// @Param category_id query string false "category_id with its descendants, comma separated for several"
+// @Param price_min query number false "min price"
+// @Param price_max query number false "max price"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.Product "Product details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Product not found"
@@ -114,9 +137,20 @@ func (h *Handler) GetListProduct(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.ProductFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if err = h.expandCategories(ctx, &filter); err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
 	var (
 		resp = &models.GetListProductResponse{}
 	)
@@ -126,6 +160,7 @@ func (h *Handler) GetListProduct(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -165,9 +200,49 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	if err = validateBarcodes(updateProduct.Barcodes); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if len(updateProduct.CategoryID) > 0 && !h.validCategory(ctx, c, "category_id", updateProduct.CategoryID) {
+		return
+	}
+
+	updateProduct.Unit = strings.TrimSpace(updateProduct.Unit)
+	if updateProduct.Units != nil || len(updateProduct.Unit) > 0 {
+		base, units := updateProduct.Unit, updateProduct.Units
+
+		// the units left out keep what the product has
+		if len(base) == 0 || units == nil {
+			product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: id})
+			if errors.Is(err, pgx.ErrNoRows) {
+				handleResponse(c, http.StatusBadRequest, "no rows affected")
+				return
+			}
+
+			if err != nil {
+				handleResponse(c, http.StatusInternalServerError, err)
+				return
+			}
+
+			if len(base) == 0 {
+				base = product.Unit
+			}
+			if units == nil {
+				units = product.Units
+			}
+		}
+
+		if err = validateUnits(base, units); err != nil {
+			handleResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
 	updateProduct.Id = id
 
 	rowsAffected, err := h.strg.Product().Update(ctx, &updateProduct)
@@ -224,3 +299,109 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 	handleResponse(c, http.StatusOK, "deleted")
 
 }
+
+// validateBarcodes normalizes the barcodes and checks them, a code may be
+// given once.
+func validateBarcodes(barcodes []*models.Barcode) error {
+
+	var codes = map[string]bool{}
+	for _, barcode := range barcodes {
+		if barcode == nil {
+			return apperror.New(apperror.Validation, "barcode is empty").WithField("barcodes", "must not be null")
+		}
+
+		barcode.Normalize()
+		if err := barcode.Validate(); err != nil {
+			return apperror.New(apperror.Validation, err.Error()).WithField("barcodes", "invalid "+barcode.Type)
+		}
+
+		if codes[barcode.Code] {
+			return apperror.New(apperror.Validation, "barcode "+barcode.Code+" is listed twice").WithField("barcodes", "must be unique")
+		}
+		codes[barcode.Code] = true
+	}
+
+	return nil
+}
+
+// validateUnits checks the pack units of a product with the base unit.
+func validateUnits(base string, units []*models.ProductUnit) error {
+
+	if len(base) > 16 {
+		return apperror.New(apperror.Validation, "unit is too long").WithField("unit", "must be at most 16 characters")
+	}
+
+	if err := models.ValidateUnits(base, units); err != nil {
+		return apperror.New(apperror.Validation, err.Error()).WithField("units", "invalid unit")
+	}
+
+	return nil
+}
+
+// productUnit answers 400 and returns false unless the product exists and
+// has the unit, reported as field. An empty unit is the base unit.
+func (h *Handler) productUnit(ctx context.Context, c *gin.Context, field, productId, unit string) (*models.ProductUnit, bool) {
+
+	if !helpers.IsValidUUID(productId) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_id is invalid").WithField("product_id", "must be uuid"))
+		return nil, false
+	}
+
+	product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: productId})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_id is invalid").WithField("product_id", "does not exist"))
+		return nil, false
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	found, ok := product.FindUnit(strings.TrimSpace(unit))
+	if !ok {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "unknown unit "+unit).WithField(field, "must be "+product.Unit+" or a pack of the product"))
+		return nil, false
+	}
+
+	return found, true
+}
+
+// @Summary Scan a Product barcode
+// @Description Find the product of a barcode for the POS, with the sale price of the lot a sale takes first and the sellable stock of the branch. The sale price is the price of the branch when nothing is in stock. EAN codes with a wrong check digit are rejected as misreads.
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param code path string true "barcode"
+// @Param branch_id query string false "branch_id, the user's branch for branch users, all branches when empty"
+// @Success 200 {object} models.ProductByBarcode "Product with price and stock"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Barcode not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /product/by-barcode/{code} [get]
+func (h *Handler) GetProductByBarcode(c *gin.Context) {
+
+	var barcode = models.Barcode{Code: c.Param("code")}
+
+	barcode.Normalize()
+	if err := barcode.Validate(); err != nil {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, err.Error()).WithField("code", "invalid "+barcode.Type))
+		return
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Product().GetByBarcode(ctx, &models.ProductByBarcodeRequest{Code: barcode.Code, BranchID: branchID})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProductBarcode(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/product", h.CreateProduct)
+	r.PUT("/product/:id", h.UpdateProduct)
+	r.GET("/product/by-barcode/:code", h.GetProductByBarcode)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+
+	for _, barcodes := range [][]*models.Barcode{
+		{{Code: "4006381333932"}},
+		{{Code: "96385075"}},
+		{{Code: "400638133393", Type: models.BarcodeEAN13}},
+		{{Code: "ABC", Type: "qr"}},
+		{{Code: "4006381333931"}, {Code: " 4006381333931 "}},
+	} {
+		create := models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id, Barcodes: barcodes}
+		if w := serve(r, http.MethodPost, "/product", create, nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("barcodes %+v: status %d: %s", barcodes[0], w.Code, w.Body.String())
+		}
+	}
+
+	var aspirin models.Product
+	create := models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id, Barcodes: []*models.Barcode{{Code: "4006381333931"}, {Code: "ASP-10", Type: models.BarcodeInternal}}}
+	if w := serve(r, http.MethodPost, "/product", create, &aspirin); w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	if len(aspirin.Barcodes) != 2 || aspirin.Barcodes[0].Type != models.BarcodeEAN13 || aspirin.Barcodes[1].Type != models.BarcodeInternal {
+		t.Fatalf("unexpected barcodes %+v %+v", aspirin.Barcodes[0], aspirin.Barcodes[1])
+	}
+
+	create = models.CreateProduct{Name: "Vitamin C", Price: 9000, BranchID: branch.Id, Barcodes: []*models.Barcode{{Code: "4006381333931"}}}
+	if w := serve(r, http.MethodPost, "/product", create, nil); w.Code != http.StatusConflict {
+		t.Fatalf("taken barcode: status %d: %s", w.Code, w.Body.String())
+	}
+
+	strg.Remainder().Create(ctx, &models.Remainder{ProductID: aspirin.Id, Name: "Aspirin", Quantity: 12, ComingPrice: 3000, SalePrice: 4200, BranchID: branch.Id})
+
+	var scan models.ProductByBarcode
+	if w := serve(r, http.MethodGet, "/product/by-barcode/4006381333931?branch_id="+branch.Id, nil, &scan); w.Code != http.StatusOK {
+		t.Fatalf("scan: status %d: %s", w.Code, w.Body.String())
+	}
+	if scan.Product.Id != aspirin.Id || scan.SalePrice != 4200 || scan.Quantity != 12 || scan.BranchID != branch.Id {
+		t.Fatalf("unexpected scan %+v", scan)
+	}
+
+	if w := serve(r, http.MethodGet, "/product/by-barcode/4006381333932", nil, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("misread check digit: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodGet, "/product/by-barcode/96385074", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown barcode: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// barcodes left out of an update are kept
+	update := models.UpdateProduct{Name: "Aspirin 500", Price: 4000, BranchID: branch.Id}
+	if w := serve(r, http.MethodPut, "/product/x?id="+aspirin.Id, update, &aspirin); w.Code != http.StatusAccepted || len(aspirin.Barcodes) != 2 {
+		t.Fatalf("update: status %d: %s", w.Code, w.Body.String())
+	}
+
+	update.Barcodes = []*models.Barcode{{Code: "96385074"}}
+	if w := serve(r, http.MethodPut, "/product/x?id="+aspirin.Id, update, &aspirin); w.Code != http.StatusAccepted || len(aspirin.Barcodes) != 1 || aspirin.Barcodes[0].Type != models.BarcodeEAN8 {
+		t.Fatalf("replace barcodes: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodGet, "/product/by-barcode/ASP-10", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("replaced barcode: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProductUnit(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/product", h.CreateProduct)
+	r.PUT("/product/:id", h.UpdateProduct)
+	r.POST("/picking_list", h.CreatePickingList)
+	r.POST("/coming/:id/finish", h.FinishComing)
+	r.POST("/checkout", h.Checkout)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Olmazor"})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	coming, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00007", BranchID: branch.Id})
+
+	create := models.CreateProduct{
+		Name:     "Analgin",
+		Price:    1500,
+		BranchID: branch.Id,
+		Unit:     "blister",
+		Units:    []*models.ProductUnit{{Name: "box", Factor: 10}, {Name: "Box", Factor: 12}},
+	}
+	if w := serve(r, http.MethodPost, "/product", create, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("unit given twice: status %d: %s", w.Code, w.Body.String())
+	}
+
+	create.Units[1] = &models.ProductUnit{Name: "blister", Factor: 2}
+	if w := serve(r, http.MethodPost, "/product", create, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("pack named as the base unit: status %d: %s", w.Code, w.Body.String())
+	}
+
+	create.Units = create.Units[:1]
+	var product models.Product
+	if w := serve(r, http.MethodPost, "/product", create, &product); w.Code != http.StatusCreated || product.Unit != "blister" || len(product.Units) != 1 {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPut, "/product/x?id="+product.Id, models.UpdateProduct{Name: "Analgin", Price: 1500, BranchID: branch.Id, Units: []*models.ProductUnit{{Name: "box", Factor: 1}}}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("pack of one: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// 3 boxes at 12000 a box are received as 30 blisters at 1200
+	var line models.PickingList
+	if w := serve(r, http.MethodPost, "/picking_list", models.CreatePickingList{Product_ID: product.Id, Quantity: 3, Price: 12000, Unit: "box", ComingIncrementID: coming.IncrementID}, &line); w.Code != http.StatusCreated {
+		t.Fatalf("picking list in boxes: status %d: %s", w.Code, w.Body.String())
+	}
+	if line.Quantity != 30 || line.Price != 1200 || line.Total_price != 36000 || line.Unit != "box" || line.UnitQuantity != 3 {
+		t.Fatalf("unexpected line %+v", line)
+	}
+
+	if w := serve(r, http.MethodPost, "/picking_list", models.CreatePickingList{Product_ID: product.Id, Quantity: 1, Price: 100, Unit: "crate", ComingIncrementID: coming.IncrementID}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown unit: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPost, "/coming/"+coming.Id+"/finish", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("finish: status %d: %s", w.Code, w.Body.String())
+	}
+
+	remainders, _ := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"product_id": {product.Id}}},
+	})
+	if remainders.Count != 1 || remainders.Remainders[0].Quantity != 30 {
+		t.Fatalf("stock in blisters %+v", remainders)
+	}
+
+	var checkout models.Checkout
+	w := serve(r, http.MethodPost, "/checkout", models.CreateCheckout{
+		ClientID: client.Id,
+		BranchID: branch.Id,
+		Products: []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 2, Unit: "BOX"}, {ProductID: product.Id, Quantity: 4}},
+	}, &checkout)
+	if w.Code != http.StatusCreated || len(checkout.SaleProducts) != 2 {
+		t.Fatalf("checkout: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if sold := checkout.SaleProducts[0]; sold.Unit != "box" || sold.UnitQuantity != 2 || sold.Quantity != 20 || sold.UnitPrice != 10*sold.Price {
+		t.Fatalf("unexpected box line %+v", sold)
+	}
+	if sold := checkout.SaleProducts[1]; sold.Unit != "blister" || sold.Quantity != 4 {
+		t.Fatalf("unexpected blister line %+v", sold)
+	}
+
+	if w := serve(r, http.MethodPost, "/checkout", models.CreateCheckout{
+		ClientID: client.Id,
+		BranchID: branch.Id,
+		Products: []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 1, Unit: "box"}},
+	}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("a box out of 6 blisters: status %d: %s", w.Code, w.Body.String())
+	}
+}
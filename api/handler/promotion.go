@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Create a Promotion
+// @Description Create a discount given at checkout from starts_at, now when empty, until ends_at, for good when empty. percent takes value percent off a line, fixed takes value off each unit sold and buy_x_get_y gives free_quantity units of every buy_quantity + free_quantity sold. branch_id, product_id, category_id with its subcategories and client_group limit where it applies when set. A line gets the promotion taking the most off it.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param object body models.CreatePromotion true "Promotion"
+// @Success 201 {object} models.Promotion "Created Promotion"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion [post]
+func (h *Handler) CreatePromotion(c *gin.Context) {
+
+	var createPromotion models.CreatePromotion
+	err := c.ShouldBindJSON(&createPromotion)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.validPromotion(ctx, c, &createPromotion) {
+		return
+	}
+
+	resp, err := h.strg.Promotion().Create(ctx, &createPromotion)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// validPromotion answers 400 and returns false unless req is a rule that
+// can apply, its name is trimmed.
+func (h *Handler) validPromotion(ctx context.Context, c *gin.Context, req *models.CreatePromotion) bool {
+
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "name is required").WithField("name", "must not be empty"))
+		return false
+	}
+
+	switch req.Type {
+	case models.PromotionPercent:
+		if req.Value <= 0 || req.Value > 100 {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "value is invalid").WithField("value", "must be a percent above 0 and up to 100"))
+			return false
+		}
+	case models.PromotionFixed:
+		if req.Value <= 0 {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "value is invalid").WithField("value", "must be positive"))
+			return false
+		}
+	case models.PromotionBuyGet:
+		if req.BuyQuantity <= 0 || req.FreeQuantity <= 0 {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "buy_quantity and free_quantity are required").WithField("free_quantity", "must be positive, as buy_quantity"))
+			return false
+		}
+	default:
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "type is invalid").WithField("type", "must be percent, fixed or buy_x_get_y"))
+		return false
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "ends_at is invalid").WithField("ends_at", "must be after starts_at"))
+		return false
+	}
+
+	if req.StartsAt == nil && req.EndsAt != nil && !req.EndsAt.After(time.Now()) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "ends_at is invalid").WithField("ends_at", "must be in the future"))
+		return false
+	}
+
+	if len(req.ProductID) > 0 && len(req.CategoryID) > 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "either product_id or category_id can be set").WithField("category_id", "must be empty when product_id is set"))
+		return false
+	}
+
+	if len(req.BranchID) > 0 && !h.validBranch(ctx, c, "branch_id", req.BranchID) {
+		return false
+	}
+
+	if len(req.ProductID) > 0 && !h.validProduct(ctx, c, "product_id", req.ProductID) {
+		return false
+	}
+
+	if len(req.CategoryID) > 0 && !h.validCategory(ctx, c, "category_id", req.CategoryID) {
+		return false
+	}
+
+	req.ClientGroup = strings.TrimSpace(req.ClientGroup)
+
+	return true
+}
+
+// @Summary Get a Promotion by ID
+// @Description Get a promotion rule.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} models.Promotion "Promotion"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion/{id} [get]
+func (h *Handler) GetByIDPromotion(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Promotion().GetByID(ctx, &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Promotion
+// @Description Get the promotion rules, newest first.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "name"
+// @Param type query string false "type, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param category_id query string false "category_id, comma separated for several"
+// @Param client_group query string false "client_group, comma separated for several"
+// @Param value_min query number false "min value"
+// @Param value_max query number false "max value"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListPromotionResponse "Promotions"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion [get]
+func (h *Handler) GetListPromotion(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.PromotionFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Promotion().GetList(ctx, &models.GetListPromotionRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update Promotion
+// @Description Change a promotion rule, the sales it already discounted keep their discount.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param object body models.UpdatePromotion true "models.UpdatePromotion"
+// @Param id path string true "id"
+// @Success 202 {object} models.Promotion "Promotion"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion/{id} [put]
+func (h *Handler) UpdatePromotion(c *gin.Context) {
+
+	var updatePromotion models.UpdatePromotion
+
+	err := c.ShouldBindJSON(&updatePromotion)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	rule := models.CreatePromotion{
+		Name:         updatePromotion.Name,
+		Type:         updatePromotion.Type,
+		Value:        updatePromotion.Value,
+		BuyQuantity:  updatePromotion.BuyQuantity,
+		FreeQuantity: updatePromotion.FreeQuantity,
+		BranchID:     updatePromotion.BranchID,
+		ProductID:    updatePromotion.ProductID,
+		CategoryID:   updatePromotion.CategoryID,
+		ClientGroup:  updatePromotion.ClientGroup,
+		StartsAt:     updatePromotion.StartsAt,
+		EndsAt:       updatePromotion.EndsAt,
+	}
+	if !h.validPromotion(ctx, c, &rule) {
+		return
+	}
+
+	updatePromotion.Id = id
+	updatePromotion.Name = rule.Name
+	updatePromotion.ClientGroup = rule.ClientGroup
+
+	rowsAffected, err := h.strg.Promotion().Update(ctx, &updatePromotion)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected <= 0 {
+		handleResponse(c, http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.strg.Promotion().GetByID(ctx, &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete Promotion
+// @Description Delete a promotion rule. It ends now, or never starts when it has not, and stays on the sales it discounted and in the report.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion/{id} [delete]
+func (h *Handler) DeletePromotion(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	err := h.strg.Promotion().Delete(ctx, &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Promotion costs
+// @Description The discount every promotion gave on the sales of branch_id, all branches when empty, created in [from, to), with the sales, lines and base units it was given on. Returned units are left out with their share of the discount, deleted promotions are kept. The costliest promotion comes first.
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "branch_id, all branches when empty"
+// @Param from query string false "sales from, date or RFC3339"
+// @Param to query string false "sales to, date or RFC3339"
+// @Success 200 {object} models.PromotionReportResponse "Promotion costs"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /promotion/report [get]
+func (h *Handler) PromotionReport(c *gin.Context) {
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	var (
+		req = models.PromotionReportRequest{BranchID: branchID}
+		err error
+	)
+
+	req.From, err = getTimeQuery(c, "from", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.To, err = getTimeQuery(c, "to", true)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Promotion().Report(ctx, &req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// matchPromotions gives every line of a checkout the promotions running in
+// its branch now that it qualifies for, by product, category subtree and
+// the group of the client.
+func (h *Handler) matchPromotions(ctx context.Context, checkout *models.CreateCheckout) error {
+
+	promotions, err := h.strg.Promotion().Active(ctx, checkout.BranchID, time.Now())
+	if err != nil || len(promotions) == 0 {
+		return err
+	}
+
+	var (
+		group    string
+		subtrees = map[string][]string{}
+	)
+
+	client, err := h.strg.Client().GetByID(ctx, &models.ClientPrimaryKey{Id: checkout.ClientID})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if client != nil {
+		group = client.Group
+	}
+
+	for _, promotion := range promotions {
+		if len(promotion.CategoryID) > 0 && subtrees[promotion.CategoryID] == nil {
+			subtrees[promotion.CategoryID], err = h.strg.Category().Subtree(ctx, &models.CategoryPrimaryKey{Id: promotion.CategoryID})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, line := range checkout.Products {
+		product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: line.ProductID})
+		if err != nil {
+			return err
+		}
+
+		for _, promotion := range promotions {
+			switch {
+			case len(promotion.ClientGroup) > 0 && promotion.ClientGroup != group:
+			case len(promotion.ProductID) > 0 && promotion.ProductID != product.Id:
+			case len(promotion.CategoryID) > 0 && !contains(subtrees[promotion.CategoryID], product.CategoryID):
+			default:
+				line.Promotions = append(line.Promotions, promotion)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPromotion(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/promotion", h.CreatePromotion)
+	r.GET("/promotion/report", h.PromotionReport)
+	r.POST("/checkout", h.Checkout)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+	medicine, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Medicine"})
+	painkillers, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Painkillers", ParentID: medicine.Id})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id, CategoryID: painkillers.Id})
+	cream, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Cream", Price: 20000, BranchID: branch.Id})
+	vip, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "1999-05-01", BranchID: branch.Id, Group: "vip"})
+	regular, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "1999-05-01", BranchID: branch.Id})
+	_, _ = strg.Remainder().Create(ctx, &models.Remainder{ProductID: aspirin.Id, Quantity: 10, SalePrice: 4000, BranchID: branch.Id})
+	_, _ = strg.Remainder().Create(ctx, &models.Remainder{ProductID: cream.Id, Quantity: 10, SalePrice: 20000, BranchID: branch.Id})
+
+	var (
+		now       = time.Now()
+		yesterday = now.Add(-24 * time.Hour)
+	)
+
+	for name, body := range map[string]models.CreatePromotion{
+		"no name":              {Type: models.PromotionPercent, Value: 10},
+		"unknown type":         {Name: "Sale", Type: "gift", Value: 10},
+		"percent above 100":    {Name: "Sale", Type: models.PromotionPercent, Value: 150},
+		"buy without free":     {Name: "Sale", Type: models.PromotionBuyGet, BuyQuantity: 2},
+		"ends before starts":   {Name: "Sale", Type: models.PromotionFixed, Value: 100, StartsAt: &now, EndsAt: &yesterday},
+		"product and category": {Name: "Sale", Type: models.PromotionFixed, Value: 100, ProductID: aspirin.Id, CategoryID: medicine.Id},
+		"unknown category":     {Name: "Sale", Type: models.PromotionFixed, Value: 100, CategoryID: branch.Id},
+	} {
+		if w := serve(r, http.MethodPost, "/promotion", body, nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	var percent, fixed models.Promotion
+	if w := serve(r, http.MethodPost, "/promotion", models.CreatePromotion{Name: "Medicine week", Type: models.PromotionPercent, Value: 10, CategoryID: medicine.Id}, &percent); w.Code != http.StatusCreated {
+		t.Fatalf("create percent: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/promotion", models.CreatePromotion{Name: "VIP", Type: models.PromotionFixed, Value: 1000, BranchID: branch.Id, ClientGroup: " vip "}, &fixed); w.Code != http.StatusCreated || fixed.ClientGroup != "vip" {
+		t.Fatalf("create fixed: status %d: %s", w.Code, w.Body.String())
+	}
+
+	checkout := func(client *models.Client) *models.Checkout {
+		var resp models.Checkout
+		w := serve(r, http.MethodPost, "/checkout", models.CreateCheckout{
+			ClientID: client.Id,
+			BranchID: branch.Id,
+			Products: []*models.CheckoutProduct{{ProductID: aspirin.Id, Quantity: 2}, {ProductID: cream.Id, Quantity: 1}},
+		}, &resp)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("checkout: status %d: %s", w.Code, w.Body.String())
+		}
+		return &resp
+	}
+
+	// 1000 off each unit beats 10% of the painkillers for the group
+	resp := checkout(vip)
+	if resp.Sale.TotalPrice != 25000 || resp.SaleProducts[0].Discount != 2000 || resp.SaleProducts[0].PromotionID != fixed.Id || resp.SaleProducts[1].TotalPrice != 19000 {
+		t.Fatalf("vip checkout %+v %+v %+v", resp.Sale, resp.SaleProducts[0], resp.SaleProducts[1])
+	}
+
+	// the category promotion covers its subcategories only
+	resp = checkout(regular)
+	if resp.Sale.TotalPrice != 27200 || resp.SaleProducts[0].PromotionID != percent.Id || resp.SaleProducts[1].Discount != 0 || resp.SaleProducts[1].PromotionID != "" {
+		t.Fatalf("regular checkout %+v %+v %+v", resp.Sale, resp.SaleProducts[0], resp.SaleProducts[1])
+	}
+
+	var report models.PromotionReportResponse
+	if w := serve(r, http.MethodGet, "/promotion/report?branch_id="+branch.Id, nil, &report); w.Code != http.StatusOK || report.Discount != 3800 || len(report.Promotions) != 2 {
+		t.Fatalf("report: status %d: %s", w.Code, w.Body.String())
+	}
+	if cost := report.Promotions[0]; cost.PromotionID != fixed.Id || cost.Sales != 1 || cost.Lines != 2 || cost.Discount != 3000 {
+		t.Fatalf("costliest promotion %+v", cost)
+	}
+}
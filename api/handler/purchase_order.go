@@ -0,0 +1,467 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create a Purchase Order
+// @Description Order products from a supplier for a branch at agreed prices.
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param object body models.CreatePurchaseOrder true "Purchase Order"
+// @Success 201 {object} models.PurchaseOrder "Created Purchase Order"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order [post]
+func (h *Handler) CreatePurchaseOrder(c *gin.Context) {
+
+	var createPurchaseOrder models.CreatePurchaseOrder
+	err := c.ShouldBindJSON(&createPurchaseOrder)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(createPurchaseOrder.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if err = validatePurchaseOrderLines(createPurchaseOrder.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !inScope(c, createPurchaseOrder.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if !h.validSupplier(ctx, c, createPurchaseOrder.SupplierID) {
+		return
+	}
+
+	createPurchaseOrder.IncrementID, err = h.nextNumber(ctx, "purchase_order", h.cfg.PurchaseOrderNumbering, createPurchaseOrder.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createPurchaseOrder.UserID = c.GetString(ctxUserID)
+
+	resp, err := h.strg.PurchaseOrder().Create(ctx, &createPurchaseOrder)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+func validatePurchaseOrderLines(lines []*models.CreatePurchaseOrderLine) error {
+
+	if len(lines) == 0 {
+		return apperror.New(apperror.Validation, "a purchase order needs lines").WithField("lines", "must not be empty")
+	}
+
+	var products = map[string]bool{}
+	for _, line := range lines {
+		if !helpers.IsValidUUID(line.ProductID) {
+			return apperror.New(apperror.Validation, "product_id is not uuid").WithField("product_id", "must be uuid")
+		}
+
+		if line.Quantity <= 0 {
+			return apperror.New(apperror.Validation, "quantity must be positive").WithField("quantity", "must be positive")
+		}
+
+		if line.Price < 0 {
+			return apperror.New(apperror.Validation, "price must not be negative").WithField("price", "must not be negative")
+		}
+
+		if products[line.ProductID] {
+			return apperror.New(apperror.Validation, "product "+line.ProductID+" is listed twice").WithField("product_id", "must be unique")
+		}
+		products[line.ProductID] = true
+	}
+
+	return nil
+}
+
+// @Summary Get a Purchase Order by ID
+// @Description Get a Purchase Order with its lines: ordered and received quantities, the average received price and the deviations (under, over, price).
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase Order ID"
+// @Success 200 {object} models.PurchaseOrder "Purchase Order details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Purchase Order not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order/{id} [get]
+func (h *Handler) GetByIDPurchaseOrder(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, resp.BranchID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Purchase Order
+// @Description Get purchase orders without their lines, newest first.
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param supplier_id query string false "supplier_id, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param status query string false "open or closed, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListPurchaseOrderResponse "Purchase Orders"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order [get]
+func (h *Handler) GetListPurchaseOrder(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.PurchaseOrderFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.PurchaseOrder().GetList(ctx, &models.GetListPurchaseOrderRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update Purchase Order
+// @Description Change the supplier and the lines of a Purchase Order nothing was received against yet.
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param object body models.UpdatePurchaseOrder true "models.UpdatePurchaseOrder"
+// @Param id path string true "id"
+// @Success 202 {object} models.PurchaseOrder "Purchase Order details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Purchase Order has comings"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order/{id} [put]
+func (h *Handler) UpdatePurchaseOrder(c *gin.Context) {
+
+	var updatePurchaseOrder models.UpdatePurchaseOrder
+
+	err := c.ShouldBindJSON(&updatePurchaseOrder)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	purchaseOrder, ok := h.editablePurchaseOrder(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	if len(updatePurchaseOrder.SupplierID) == 0 {
+		updatePurchaseOrder.SupplierID = purchaseOrder.SupplierID
+	}
+
+	if updatePurchaseOrder.Lines == nil {
+		updatePurchaseOrder.Lines = []*models.CreatePurchaseOrderLine{}
+		for _, line := range purchaseOrder.Lines {
+			updatePurchaseOrder.Lines = append(updatePurchaseOrder.Lines, &models.CreatePurchaseOrderLine{ProductID: line.ProductID, Quantity: line.Quantity, Price: line.Price})
+		}
+	}
+
+	if err = validatePurchaseOrderLines(updatePurchaseOrder.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !h.validSupplier(ctx, c, updatePurchaseOrder.SupplierID) {
+		return
+	}
+
+	updatePurchaseOrder.Id = id
+
+	rowsAffected, err := h.strg.PurchaseOrder().Update(ctx, &updatePurchaseOrder)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusConflict, hasComings(purchaseOrder))
+		return
+	}
+
+	resp, err := h.strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete Purchase Order
+// @Description Delete a Purchase Order nothing was received against yet.
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Purchase Order has comings"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order/{id} [delete]
+func (h *Handler) DeletePurchaseOrder(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if _, ok := h.editablePurchaseOrder(ctx, c, id); !ok {
+		return
+	}
+
+	err := h.strg.PurchaseOrder().Delete(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// editablePurchaseOrder answers and returns false unless the purchase order
+// is of the user's branch and has no comings.
+func (h *Handler) editablePurchaseOrder(ctx context.Context, c *gin.Context, id string) (*models.PurchaseOrder, bool) {
+
+	purchaseOrder, err := h.strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !inScope(c, purchaseOrder.BranchID) {
+		return nil, false
+	}
+
+	comings, err := h.strg.Coming().GetList(ctx, &models.GetListComingRequest{
+		Limit:  1,
+		Filter: models.Filter{Fields: map[string][]string{"purchase_order_id": {id}}},
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if comings.Count > 0 {
+		handleResponse(c, http.StatusConflict, hasComings(purchaseOrder))
+		return nil, false
+	}
+
+	return purchaseOrder, true
+}
+
+func hasComings(purchaseOrder *models.PurchaseOrder) *apperror.Error {
+	return apperror.New(apperror.Conflict, "purchase order "+purchaseOrder.IncrementID+" has comings")
+}
+
+// @Summary Create a Coming from a Purchase Order
+// @Description Draft a Coming of the supplier and branch of an open Purchase Order with a picking list for every outstanding line at the agreed price. Edit the picking lists to what arrived, then finish the Coming.
+// @Tags PurchaseOrder
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase Order ID"
+// @Success 201 {object} models.Coming "Created Coming"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Purchase Order not found"
+// @Failure 409 {object} ErrorResponse "Purchase Order is closed"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /purchase_order/{id}/coming [post]
+func (h *Handler) CreateComingFromPurchaseOrder(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	purchaseOrder, err := h.strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, purchaseOrder.BranchID) {
+		return
+	}
+
+	incrementId, err := h.nextNumber(ctx, "coming", h.cfg.ComingNumbering, purchaseOrder.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var resp *models.Coming
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		purchaseOrder, err := tx.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if purchaseOrder.Status != models.PurchaseOrderOpen || !hasOutstanding(purchaseOrder) {
+			return apperror.New(apperror.Conflict, "purchase order "+purchaseOrder.IncrementID+" is "+purchaseOrder.Status).WithField("status", "must be open")
+		}
+
+		coming, err := tx.Coming().Create(ctx, &models.CreateComing{
+			IncrementID:     incrementId,
+			BranchID:        purchaseOrder.BranchID,
+			SupplierID:      purchaseOrder.SupplierID,
+			PurchaseOrderID: purchaseOrder.Id,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range purchaseOrder.Lines {
+			if line.Outstanding == 0 {
+				continue
+			}
+
+			_, err = tx.PickingList().Create(ctx, &models.PickingList{
+				Product_ID:        line.ProductID,
+				Quantity:          line.Outstanding,
+				Price:             line.Price,
+				ComingID:          coming.Id,
+				ComingIncrementID: coming.IncrementID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: coming.Id})
+		return err
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+func hasOutstanding(purchaseOrder *models.PurchaseOrder) bool {
+	for _, line := range purchaseOrder.Lines {
+		if line.Outstanding > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshPurchaseOrder closes the purchase order once it is fully received
+// and opens it again when a reversal leaves something outstanding.
+func refreshPurchaseOrder(ctx context.Context, tx storage.StorageI, id string) error {
+
+	purchaseOrder, err := tx.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+	if err != nil {
+		return err
+	}
+
+	var status = models.PurchaseOrderOpen
+	if purchaseOrder.FullyReceived() {
+		status = models.PurchaseOrderClosed
+	}
+
+	if status == purchaseOrder.Status {
+		return nil
+	}
+
+	_, err = tx.PurchaseOrder().SetStatus(ctx, &models.PurchaseOrderStatus{Id: id, From: purchaseOrder.Status, To: status})
+	return err
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPurchaseOrder(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/purchase_order", h.CreatePurchaseOrder)
+	r.PUT("/purchase_order/:id", h.UpdatePurchaseOrder)
+	r.POST("/purchase_order/:id/coming", h.CreateComingFromPurchaseOrder)
+	r.POST("/coming/:id/finish", h.FinishComing)
+	r.POST("/coming/:id/reverse", h.ReverseComing)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+	supplier, _ := strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Farm Import"})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id})
+	vitamin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Vitamin C", Price: 9000, BranchID: branch.Id})
+
+	purchaseOrder := func(id string) *models.PurchaseOrder {
+		po, err := strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: id})
+		if err != nil {
+			t.Fatalf("get purchase order: %v", err)
+		}
+		return po
+	}
+
+	create := models.CreatePurchaseOrder{
+		SupplierID: supplier.Id,
+		BranchID:   branch.Id,
+		Lines: []*models.CreatePurchaseOrderLine{
+			{ProductID: aspirin.Id, Quantity: 10, Price: 3000},
+			{ProductID: aspirin.Id, Quantity: 5, Price: 3000},
+		},
+	}
+
+	if w := serve(r, http.MethodPost, "/purchase_order", create, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("product listed twice: status %d: %s", w.Code, w.Body.String())
+	}
+
+	create.Lines[1] = &models.CreatePurchaseOrderLine{ProductID: vitamin.Id, Quantity: 5, Price: 7000}
+
+	var po models.PurchaseOrder
+	if w := serve(r, http.MethodPost, "/purchase_order", create, &po); w.Code != http.StatusCreated || po.Status != models.PurchaseOrderOpen || len(po.Lines) != 2 {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// the first delivery brings 6 aspirin at a higher price and 5 vitamin
+	var first models.Coming
+	if w := serve(r, http.MethodPost, "/purchase_order/"+po.Id+"/coming", nil, &first); w.Code != http.StatusCreated || first.SupplierID != supplier.Id || first.TotalPrice != 65000 {
+		t.Fatalf("coming from purchase order: status %d: %s", w.Code, w.Body.String())
+	}
+
+	lines, _ := strg.PickingList().GetList(ctx, &models.GetListPickingListRequest{
+		Filter: models.Filter{Fields: map[string][]string{"coming_id": {first.Id}, "product_id": {aspirin.Id}}},
+	})
+	aspirinLine := lines.Pickinges[0]
+	aspirinLine.Quantity, aspirinLine.Price = 6, 3100
+	if _, err := strg.PickingList().Update(ctx, aspirinLine); err != nil {
+		t.Fatalf("update picking list: %v", err)
+	}
+
+	if w := serve(r, http.MethodPost, "/coming/"+first.Id+"/finish", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("finish: status %d: %s", w.Code, w.Body.String())
+	}
+
+	received := purchaseOrder(po.Id)
+	if received.Status != models.PurchaseOrderOpen || received.Lines[0].Received != 6 || received.Lines[0].Outstanding != 4 || received.Lines[0].ReceivedPrice != 3100 {
+		t.Fatalf("after the first coming %+v %+v", received, received.Lines[0])
+	}
+	if flags := received.Lines[0].Flags; len(flags) != 2 || flags[0] != models.DeliveryUnder || flags[1] != models.DeliveryPrice {
+		t.Fatalf("aspirin flags %v", flags)
+	}
+	if len(received.Lines[1].Flags) != 0 || received.Lines[1].Outstanding != 0 {
+		t.Fatalf("vitamin line %+v", received.Lines[1])
+	}
+
+	if w := serve(r, http.MethodPut, "/purchase_order/"+po.Id, models.UpdatePurchaseOrder{}, nil); w.Code != http.StatusConflict {
+		t.Fatalf("update with comings: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// the second coming is pre-filled with what is outstanding
+	var second models.Coming
+	if w := serve(r, http.MethodPost, "/purchase_order/"+po.Id+"/coming", nil, &second); w.Code != http.StatusCreated || second.TotalPrice != 12000 {
+		t.Fatalf("second coming: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPost, "/coming/"+second.Id+"/finish", nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("finish second: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if closed := purchaseOrder(po.Id); closed.Status != models.PurchaseOrderClosed || closed.ClosedAt == "" {
+		t.Fatalf("fully received order %+v", closed)
+	}
+
+	if w := serve(r, http.MethodPost, "/purchase_order/"+po.Id+"/coming", nil, nil); w.Code != http.StatusConflict {
+		t.Fatalf("coming from a closed order: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodPost, "/coming/"+second.Id+"/reverse", models.ReverseComing{Reason: "wrong batch"}, nil); w.Code != http.StatusOK {
+		t.Fatalf("reverse: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if reopened := purchaseOrder(po.Id); reopened.Status != models.PurchaseOrderOpen || reopened.ClosedAt != "" || reopened.Lines[0].Outstanding != 4 {
+		t.Fatalf("reopened order %+v", reopened)
+	}
+}
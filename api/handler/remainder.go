@@ -2,19 +2,20 @@ package handler
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
+	"errors"
 	"net/http"
 
 	"market_system/config"
 	"market_system/models"
+	"market_system/pkg/apperror"
 	"market_system/pkg/helpers"
+	"market_system/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary create a Remainder
-// @Description Create Remainder
+// @Description Create the opening stock of a product in a branch. The sale price defaults to the price of the product in the branch.
 // @Tags Remainder
 // @Accept json
 // @Produce json
@@ -33,18 +34,54 @@ func (h *Handler) CreateRemainder(c *gin.Context) {
 		return
 	}
 
+	if !inScope(c, createRemainder.BranchID) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if createRemainder.Quantity <= 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "quantity must be positive").WithField("quantity", "must be positive"))
+		return
+	}
+
 	product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: createRemainder.ProductID})
 	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err.Error())
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
-	createRemainder.Name = product.Name
-	fmt.Println(product)
 
-	resp, err := h.strg.Remainder().Create(ctx, &createRemainder)
+	if createRemainder.SalePrice == 0 {
+		createRemainder.SalePrice, err = h.strg.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: createRemainder.BranchID, ProductID: product.Id})
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	var resp *models.Remainder
+
+	// opening stock is an adjustment of the journal like any other
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		_, err := tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID:    createRemainder.BranchID,
+			ProductID:   product.Id,
+			Quantity:    createRemainder.Quantity,
+			Type:        models.MovementAdjustment,
+			UserID:      c.GetString(ctxUserID),
+			Name:        product.Name,
+			ComingPrice: createRemainder.ComingPrice,
+			SalePrice:   createRemainder.SalePrice,
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err = stockOf(ctx, tx, createRemainder.BranchID, product.Id)
+		return err
+	})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -77,13 +114,12 @@ func (h *Handler) GetByIDRemainder(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Remainder().GetByID(ctx, &models.RemainderPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !inScope(c, resp.BranchID) {
 		return
 	}
 
@@ -98,6 +134,17 @@ func (h *Handler) GetByIDRemainder(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query int false "search"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param lot_number query string false "lot_number, comma separated for several"
+// @Param quantity_min query number false "min quantity"
+// @Param quantity_max query number false "max quantity"
+// @Param coming_price_min query number false "min coming_price"
+// @Param coming_price_max query number false "max coming_price"
+// @Param sale_price_min query number false "min sale_price"
+// @Param sale_price_max query number false "max sale_price"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.Remainder "Remainder details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Remainder not found"
@@ -123,6 +170,12 @@ func (h *Handler) GetListRemainder(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.RemainderFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -135,6 +188,7 @@ func (h *Handler) GetListRemainder(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -177,16 +231,51 @@ func (h *Handler) UpdateRemainder(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if !inScope(c, updateRemainder.BranchID) || !h.remainderInScope(ctx, c, id) {
+		return
+	}
+
 	updateRemainder.Id = id
 
-	rowsAffected, err := h.strg.Remainder().Update(ctx, &updateRemainder)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		remainder, err := tx.Remainder().GetByID(ctx, &models.RemainderPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		// the quantity only changes through an adjustment of the journal
+		var quantity = updateRemainder.Quantity
+		updateRemainder.BranchID = remainder.BranchID
+		updateRemainder.ProductID = remainder.ProductID
+		updateRemainder.Quantity = remainder.Quantity
+
+		if _, err = tx.Remainder().Update(ctx, &updateRemainder); err != nil {
+			return err
+		}
+
+		if quantity == remainder.Quantity {
+			return nil
+		}
+
+		_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID:   remainder.BranchID,
+			ProductID:  remainder.ProductID,
+			Quantity:   quantity - remainder.Quantity,
+			Type:       models.MovementAdjustment,
+			UserID:     c.GetString(ctxUserID),
+			LotNumber:  remainder.LotNumber,
+			ExpiryDate: remainder.ExpiryDate,
+		})
+		return err
+	})
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if rowsAffected == 0 {
-		handleResponse(c, http.StatusBadRequest, "no rows affected")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -224,7 +313,17 @@ func (h *Handler) DeleteRemainder(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	err := h.strg.Remainder().Delete(ctx, &models.RemainderPrimaryKey{Id: id})
+	remainder, err := h.strg.Remainder().GetByID(ctx, &models.RemainderPrimaryKey{Id: id})
+	if !storedInScope(c, err, func() string { return remainder.BranchID }) {
+		return
+	}
+
+	if err == nil && remainder.Quantity != 0 {
+		handleResponse(c, http.StatusConflict, apperror.New(apperror.Conflict, "write the stock off before deleting the remainder").WithField("quantity", "must be zero"))
+		return
+	}
+
+	err = h.strg.Remainder().Delete(ctx, &models.RemainderPrimaryKey{Id: id})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -233,3 +332,39 @@ func (h *Handler) DeleteRemainder(c *gin.Context) {
 	handleResponse(c, http.StatusOK, "deleted")
 
 }
+
+// @Summary Expiring stock
+// @Description Lots in stock that expire within the given days, already expired ones included, the earliest expiry first, with their cost at coming_price.
+// @Tags Remainder
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "branch_id, all branches when empty"
+// @Param days query int false "days from today, 30 by default"
+// @Success 200 {object} models.ExpiringResponse "Expiring lots"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /remainder/expiring [get]
+func (h *Handler) GetExpiringRemainder(c *gin.Context) {
+
+	days, err := getIntegerOrDefaultValue(c.Query("days"), 30)
+	if err != nil || days < 0 {
+		handleResponse(c, http.StatusBadRequest, "invalid query days")
+		return
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Remainder().Expiring(ctx, &models.ExpiringRequest{BranchID: branchID, Days: int(days)})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Create a Repricing
+// @Description Change the prices of the products of category_id and its subcategories, or of product_ids, in branch_id or the catalog when it is empty. The new price is price, or the current one changed by percent and rounded to cents. A branch only reprices the products it carries. The changes are applied at effective_from, right away when it is empty or past.
+// @Tags Repricing
+// @Accept json
+// @Produce json
+// @Param object body models.CreateRepricing true "Repricing"
+// @Success 201 {object} models.Repricing "Created Repricing"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /repricing [post]
+func (h *Handler) CreateRepricing(c *gin.Context) {
+
+	var createRepricing models.CreateRepricing
+	err := c.ShouldBindJSON(&createRepricing)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if (createRepricing.Percent == nil) == (createRepricing.Price == nil) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "either percent or price is required").WithField("percent", "required unless price is set"))
+		return
+	}
+
+	if createRepricing.Price != nil && *createRepricing.Price < 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "price must not be negative").WithField("price", "must not be negative"))
+		return
+	}
+
+	if createRepricing.Percent != nil && *createRepricing.Percent <= -100 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "percent must be greater than -100").WithField("percent", "must be greater than -100"))
+		return
+	}
+
+	if (len(createRepricing.CategoryID) == 0) == (len(createRepricing.ProductIDs) == 0) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "either category_id or product_ids is required").WithField("category_id", "required unless product_ids are set"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if len(createRepricing.BranchID) > 0 && !h.validBranch(ctx, c, "branch_id", createRepricing.BranchID) {
+		return
+	}
+
+	var products []*models.Product
+	if len(createRepricing.CategoryID) > 0 {
+		if !h.validCategory(ctx, c, "category_id", createRepricing.CategoryID) {
+			return
+		}
+
+		products, err = h.categoryProducts(ctx, createRepricing.CategoryID)
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	for _, productID := range createRepricing.ProductIDs {
+		if !helpers.IsValidUUID(productID) {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_id is not uuid").WithField("product_ids", "must be uuids"))
+			return
+		}
+
+		product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: productID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_ids are invalid").WithField("product_ids", productID+" does not exist"))
+			return
+		}
+
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		products = append(products, product)
+	}
+
+	for _, product := range products {
+		var price = product.Price
+
+		if len(createRepricing.BranchID) > 0 {
+			branchProduct, err := h.strg.BranchProduct().GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: createRepricing.BranchID, ProductID: product.Id})
+			if errors.Is(err, pgx.ErrNoRows) && len(createRepricing.CategoryID) > 0 {
+				continue
+			}
+
+			if errors.Is(err, pgx.ErrNoRows) {
+				handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_ids are invalid").WithField("product_ids", product.Id+" is not sold by the branch"))
+				return
+			}
+
+			if err != nil {
+				handleResponse(c, http.StatusInternalServerError, err)
+				return
+			}
+
+			price = branchProduct.SalePrice
+		}
+
+		if createRepricing.Price != nil {
+			price = *createRepricing.Price
+		} else {
+			price = math.Round(price*(100+*createRepricing.Percent)) / 100
+		}
+
+		createRepricing.Lines = append(createRepricing.Lines, &models.CreatePriceChange{
+			ProductID: product.Id,
+			BranchID:  createRepricing.BranchID,
+			Price:     &price,
+		})
+	}
+
+	if len(createRepricing.Lines) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "there is nothing to reprice").WithField("category_id", "has no products"))
+		return
+	}
+
+	createRepricing.IncrementID, err = h.nextNumber(ctx, "repricing", h.cfg.RepricingNumbering, createRepricing.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createRepricing.UserID = c.GetString(ctxUserID)
+
+	resp, err := h.strg.Repricing().Create(ctx, &createRepricing)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// categoryProducts returns the products of a category and of all its
+// descendants.
+func (h *Handler) categoryProducts(ctx context.Context, categoryID string) ([]*models.Product, error) {
+
+	var (
+		products []*models.Product
+		filter   = models.Filter{Fields: map[string][]string{"category_id": {categoryID}}}
+	)
+
+	if err := h.expandCategories(ctx, &filter); err != nil {
+		return nil, err
+	}
+
+	for offset := int64(0); ; offset += 100 {
+		list, err := h.strg.Product().GetList(ctx, &models.GetListProductRequest{Offset: offset, Limit: 100, Filter: filter})
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, list.Products...)
+
+		if len(list.Products) < 100 {
+			return products, nil
+		}
+	}
+}
+
+// @Summary Get a Repricing by ID
+// @Description Get a repricing with the price changes it made.
+// @Tags Repricing
+// @Accept json
+// @Produce json
+// @Param id path string true "Repricing ID"
+// @Success 200 {object} models.Repricing "Repricing"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Repricing not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /repricing/{id} [get]
+func (h *Handler) GetByIDRepricing(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Repricing().GetByID(ctx, &models.RepricingPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Repricing
+// @Description Get the repricings, newest first, without their lines.
+// @Tags Repricing
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "increment_id or comment"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param category_id query string false "category_id, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListRepricingResponse "Repricings"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /repricing [get]
+func (h *Handler) GetListRepricing(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.RepricingFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Repricing().GetList(ctx, &models.GetListRepricingRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRepricing(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{RepricingNumbering: config.Numbering{Prefix: "RP-", Width: 4}}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/repricing", h.CreateRepricing)
+	r.POST("/price_change", h.CreatePriceChange)
+	r.GET("/price_change", h.GetListPriceChange)
+	r.GET("/price_change/as_of", h.GetPriceAsOf)
+	r.GET("/sale/:id/lines", h.GetSaleLines)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Olmazor"})
+	medicine, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Medicine"})
+	painkillers, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Painkillers", ParentID: medicine.Id})
+	other, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Cosmetics"})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, CategoryID: painkillers.Id})
+	syrup, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Syrup", Price: 12345, CategoryID: medicine.Id})
+	cream, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Cream", Price: 20000, CategoryID: other.Id})
+
+	sale, _ := strg.Sale().Create(ctx, &models.CreateSale{BranchID: branch.Id, IncrementID: "S-00001"})
+	_, _ = strg.SaleProduct().Create(ctx, &models.CreateSaleProduct{SaleID: sale.Id, ProcutID: aspirin.Id, Quantity: 2, Price: 4000, TotalPrice: 8000})
+	time.Sleep(10 * time.Millisecond)
+
+	var (
+		percent = 10.0
+		price   = 5000.0
+	)
+
+	for name, body := range map[string]models.CreateRepricing{
+		"percent and price": {CategoryID: medicine.Id, Percent: &percent, Price: &price},
+		"no products":       {Percent: &percent},
+		"unknown category":  {CategoryID: branch.Id, Percent: &percent},
+		"branch lacks them": {BranchID: branch.Id, ProductIDs: []string{aspirin.Id}, Percent: &percent},
+	} {
+		if w := serve(r, http.MethodPost, "/repricing", body, nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	var repricing models.Repricing
+	if w := serve(r, http.MethodPost, "/repricing", models.CreateRepricing{CategoryID: medicine.Id, Percent: &percent}, &repricing); w.Code != http.StatusCreated || repricing.IncrementID != "RP-0001" || len(repricing.Lines) != 2 {
+		t.Fatalf("reprice category: status %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, tt := range []struct {
+		product *models.Product
+		price   float64
+	}{
+		{aspirin, 4400},
+		{syrup, 13579.5},
+		{cream, 20000},
+	} {
+		got, _ := strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: tt.product.Id})
+		if got.Price != tt.price {
+			t.Fatalf("%s price = %v, want %v", tt.product.Name, got.Price, tt.price)
+		}
+	}
+
+	if w := serve(r, http.MethodPost, "/price_change", models.CreatePriceChange{ProductID: aspirin.Id}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("catalog change without price: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var (
+		future    = time.Now().Add(48 * time.Hour).UTC()
+		scheduled models.PriceChange
+	)
+	if w := serve(r, http.MethodPost, "/price_change", models.CreatePriceChange{ProductID: aspirin.Id, Price: &price, EffectiveFrom: &future}, &scheduled); w.Code != http.StatusCreated || scheduled.AppliedAt != "" {
+		t.Fatalf("schedule: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var pending models.GetListPriceChangeResponse
+	if w := serve(r, http.MethodGet, "/price_change?pending=true&product_id="+aspirin.Id, nil, &pending); w.Code != http.StatusOK || pending.Count != 1 || pending.PriceChanges[0].Id != scheduled.Id {
+		t.Fatalf("pending: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var asOf models.PriceAsOf
+	if w := serve(r, http.MethodGet, "/price_change/as_of?product_id="+aspirin.Id+"&at="+future.Add(time.Hour).Format(time.RFC3339), nil, &asOf); w.Code != http.StatusOK || asOf.Price != 5000 {
+		t.Fatalf("as of the scheduled change: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodGet, "/price_change/as_of?product_id="+aspirin.Id+"&at=2000-01-01", nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("as of before the product: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// the sale is rendered with the price of its time, not of today
+	var lines models.SaleLines
+	if w := serve(r, http.MethodGet, "/sale/"+sale.Id+"/lines", nil, &lines); w.Code != http.StatusOK || len(lines.Lines) != 1 || lines.Lines[0].ListPrice != 4000 || lines.Lines[0].SaleProduct.ProcutID != aspirin.Id {
+		t.Fatalf("sale lines: status %d: %s", w.Code, w.Body.String())
+	}
+}
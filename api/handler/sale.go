@@ -2,14 +2,17 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"market_system/config"
 	"market_system/models"
 	"market_system/pkg/helpers"
+	"market_system/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // @Summary create a Sale
@@ -32,17 +35,19 @@ func (h *Handler) CreateSale(c *gin.Context) {
 		return
 	}
 
+	if !inScope(c, createSale.BranchID) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	incrementId, err := h.strg.IncrementID().GetLast(ctx, "sale", "increment_id")
+	createSale.IncrementID, err = h.nextNumber(ctx, "sale", h.cfg.SaleNumbering, createSale.BranchID)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	createSale.IncrementID = "S-" + incrementId
-
 	resp, err := h.strg.Sale().Create(ctx, &createSale)
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
@@ -52,6 +57,102 @@ func (h *Handler) CreateSale(c *gin.Context) {
 	handleResponse(c, http.StatusCreated, resp)
 }
 
+// @Summary Checkout
+// @Description Create a Sale with its products and decrement branch stock in one transaction, from the lots expiring first. Expired lots are not sold. A quantity may be in any unit of the product, the stock is taken in the base unit and a pack with a price of its own sells for it. Every line gets the running promotion taking the most off it, its total_price is net of the discount. Only products in the assortment of the branch are sold.
+// @Tags Sale
+// @Accept json
+// @Produce json
+// @Param object body models.CreateCheckout true "Checkout"
+// @Success 201 {object} models.Checkout "Checkout details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /checkout [post]
+func (h *Handler) Checkout(c *gin.Context) {
+
+	var createCheckout models.CreateCheckout
+	err := c.ShouldBindJSON(&createCheckout)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !inScope(c, createCheckout.BranchID) {
+		return
+	}
+
+	if !helpers.IsValidUUID(createCheckout.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if !helpers.IsValidUUID(createCheckout.ClientID) {
+		handleResponse(c, http.StatusBadRequest, "client_id is not uuid")
+		return
+	}
+
+	if len(createCheckout.Products) == 0 {
+		handleResponse(c, http.StatusBadRequest, "products is empty")
+		return
+	}
+
+	for _, product := range createCheckout.Products {
+		if !helpers.IsValidUUID(product.ProductID) {
+			handleResponse(c, http.StatusBadRequest, "product_id is not uuid")
+			return
+		}
+		if product.Quantity <= 0 {
+			handleResponse(c, http.StatusBadRequest, "quantity must be positive")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	for _, product := range createCheckout.Products {
+		if err = notCarried(ctx, h.strg, "product_id", createCheckout.BranchID, product.ProductID); err != nil {
+			handleResponse(c, http.StatusBadRequest, err)
+			return
+		}
+
+		unit, ok := h.productUnit(ctx, c, "unit", product.ProductID, product.Unit)
+		if !ok {
+			return
+		}
+
+		product.Unit = unit.Name
+		if unit.Factor > 1 {
+			product.Pack = unit
+		}
+	}
+
+	if err = h.matchPromotions(ctx, &createCheckout); err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createCheckout.IncrementID, err = h.nextNumber(ctx, "sale", h.cfg.SaleNumbering, createCheckout.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createCheckout.UserID = c.GetString(ctxUserID)
+
+	resp, err := h.strg.Sale().Checkout(ctx, &createCheckout)
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
 // @Summary Get a Sale by ID
 // @Description Get Sale details by its ID.
 // @Tags Sale
@@ -76,13 +177,12 @@ func (h *Handler) GetByIDSale(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !inScope(c, resp.BranchID) {
 		return
 	}
 
@@ -97,6 +197,17 @@ func (h *Handler) GetByIDSale(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query string false "search"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param client_id query string false "client_id, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param total_price_min query number false "min total_price"
+// @Param total_price_max query number false "max total_price"
+// @Param paid_min query number false "min paid"
+// @Param paid_max query number false "max paid"
+// @Param debt_min query number false "min debt"
+// @Param debt_max query number false "max debt"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.Sale "Sale details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Sale not found"
@@ -122,6 +233,12 @@ func (h *Handler) GetListSale(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.SaleFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -135,6 +252,7 @@ func (h *Handler) GetListSale(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -177,6 +295,10 @@ func (h *Handler) UpdateSale(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
+	if !inScope(c, updateSale.BranchID) || !h.saleInScope(ctx, c, id) {
+		return
+	}
+
 	updateSale.Id = id
 
 	rowsAffected, err := h.strg.Sale().Update(ctx, &updateSale)
@@ -211,6 +333,7 @@ func (h *Handler) UpdateSale(c *gin.Context) {
 // @Success 200 {object} models.Sale "Sale details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "Sale not found"
+// @Failure 409 {object} ErrorResponse "Sale has lines, payments or returns"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
 // @Router /sale/{id} [delete]
 func (h *Handler) DeleteSale(c *gin.Context) {
@@ -224,7 +347,22 @@ func (h *Handler) DeleteSale(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	err := h.strg.Sale().Delete(ctx, &models.SalePrimaryKey{Id: id})
+	sale, err := h.strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, sale.BranchID) {
+		return
+	}
+
+	if err = h.saleHasHistory(ctx, sale); err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = h.strg.Sale().Delete(ctx, &models.SalePrimaryKey{Id: id})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -233,3 +371,78 @@ func (h *Handler) DeleteSale(c *gin.Context) {
 	handleResponse(c, http.StatusOK, "deleted")
 
 }
+
+// @Summary Get the lines of a Sale
+// @Description Get a sale with its lines and the price its branch listed each product at when the sale was made, from the price history, to render an old sale as it was.
+// @Tags Sale
+// @Accept json
+// @Produce json
+// @Param id path string true "Sale ID"
+// @Success 200 {object} models.SaleLines "Sale lines"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Sale not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /sale/{id}/lines [get]
+func (h *Handler) GetSaleLines(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	sale, err := h.strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, sale.BranchID) {
+		return
+	}
+
+	soldAt, err := time.Parse(time.RFC3339Nano, sale.CreatedAt)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var (
+		resp   = models.SaleLines{Sale: sale}
+		filter = models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}}
+	)
+
+	for offset := int64(0); ; offset += 100 {
+		list, err := h.strg.SaleProduct().GetList(ctx, &models.GetListSaleProductRequest{Offset: offset, Limit: 100, Filter: filter})
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, saleProduct := range list.SaleProducts {
+			line := &models.SaleLine{SaleProduct: saleProduct}
+
+			price, err := h.strg.PriceChange().AsOf(ctx, &models.PriceAsOfRequest{ProductID: saleProduct.ProcutID, BranchID: sale.BranchID, At: soldAt})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				handleResponse(c, http.StatusInternalServerError, err)
+				return
+			}
+
+			if err == nil {
+				line.ListPrice = price.Price
+			}
+
+			resp.Lines = append(resp.Lines, line)
+		}
+
+		if len(list.SaleProducts) < 100 {
+			break
+		}
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
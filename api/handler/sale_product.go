@@ -2,12 +2,13 @@ package handler
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"net/http"
 
 	"market_system/config"
 	"market_system/models"
 	"market_system/pkg/helpers"
+	"market_system/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -35,7 +36,26 @@ func (h *Handler) CreateSaleProduct(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	resp, err := h.strg.SaleProduct().Create(ctx, &createSaleProduct)
+	if !h.saleInScope(ctx, c, createSaleProduct.SaleID) {
+		return
+	}
+
+	var resp *models.SaleProduct
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		resp, err = tx.SaleProduct().Create(ctx, &createSaleProduct)
+		if err != nil {
+			return err
+		}
+
+		return saleMovement(ctx, tx, c, resp.SaleID, resp.ProcutID, -resp.Quantity)
+	})
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
@@ -68,13 +88,12 @@ func (h *Handler) GetByIDSaleProduct(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.strg.SaleProduct().GetByID(ctx, &models.SaleProductPrimaryKey{Id: id})
-	if err == sql.ErrNoRows {
-		handleResponse(c, http.StatusBadRequest, "no rows in result set >>>")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	if !h.saleInScope(ctx, c, resp.SaleID) {
 		return
 	}
 
@@ -89,6 +108,18 @@ func (h *Handler) GetByIDSaleProduct(c *gin.Context) {
 // @Param offset query int false "Offset"
 // @Param limit query int false "Limit"
 // @Param search query string false "search"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param sale_id query string false "sale_id, comma separated for several"
+// @Param sale_increment_id query string false "sale_increment_id, comma separated for several"
+// @Param branch_id query string false "branch_id of the sale, comma separated for several"
+// @Param quantity_min query number false "min quantity"
+// @Param quantity_max query number false "max quantity"
+// @Param price_min query number false "min price"
+// @Param price_max query number false "max price"
+// @Param total_price_min query number false "min total_price"
+// @Param total_price_max query number false "max total_price"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
 // @Success 200 {object} models.SaleProduct "SaleProduct details"
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "SaleProduct not found"
@@ -114,6 +145,12 @@ func (h *Handler) GetListSaleProduct(c *gin.Context) {
 		return
 	}
 
+	filter, err := getListFilter(c, models.SaleProductFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
@@ -126,6 +163,7 @@ func (h *Handler) GetListSaleProduct(c *gin.Context) {
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
+			Filter: filter,
 		})
 		if err != nil {
 			handleResponse(c, http.StatusInternalServerError, err)
@@ -148,6 +186,7 @@ func (h *Handler) GetListSaleProduct(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "SaleProduct not found"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 409 {object} ErrorResponse "Goods of the line were returned"
 // @Router /saleproduct/{id} [put]
 func (h *Handler) UpdateSaleProduct(c *gin.Context) {
 
@@ -170,14 +209,40 @@ func (h *Handler) UpdateSaleProduct(c *gin.Context) {
 
 	updateSaleProduct.Id = id
 
-	rowsAffected, err := h.strg.SaleProduct().Update(ctx, &updateSaleProduct)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, err)
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		saleProduct, err := tx.SaleProduct().GetByID(ctx, &models.SaleProductPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if err = notReturned(ctx, tx, saleProduct); err != nil {
+			return err
+		}
+
+		if _, err = tx.SaleProduct().Update(ctx, &updateSaleProduct); err != nil {
+			return err
+		}
+
+		// give back what the line took before and take what it takes now
+		if saleProduct.SaleID == updateSaleProduct.SaleID && saleProduct.ProcutID == updateSaleProduct.ProcutID {
+			return saleMovement(ctx, tx, c, saleProduct.SaleID, saleProduct.ProcutID, saleProduct.Quantity-updateSaleProduct.Quantity)
+		}
+
+		err = saleMovement(ctx, tx, c, saleProduct.SaleID, saleProduct.ProcutID, saleProduct.Quantity)
+		if err != nil {
+			return err
+		}
+
+		return saleMovement(ctx, tx, c, updateSaleProduct.SaleID, updateSaleProduct.ProcutID, -updateSaleProduct.Quantity)
+	})
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if rowsAffected == 0 {
-		handleResponse(c, http.StatusBadRequest, "no rows affected")
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -203,6 +268,7 @@ func (h *Handler) UpdateSaleProduct(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse "Bad Request"
 // @Failure 404 {object} ErrorResponse "SaleProduct not found"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 409 {object} ErrorResponse "Goods of the line were returned"
 // @Router /saleproduct/{id} [delete]
 func (h *Handler) DeleteSaleProduct(c *gin.Context) {
 	var id = c.Query("id")
@@ -215,7 +281,23 @@ func (h *Handler) DeleteSaleProduct(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
 	defer cancel()
 
-	err := h.strg.SaleProduct().Delete(ctx, &models.SaleProductPrimaryKey{Id: id})
+	err := h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		saleProduct, err := tx.SaleProduct().GetByID(ctx, &models.SaleProductPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if err = notReturned(ctx, tx, saleProduct); err != nil {
+			return err
+		}
+
+		if err = tx.SaleProduct().Delete(ctx, &models.SaleProductPrimaryKey{Id: id}); err != nil {
+			return err
+		}
+
+		return saleMovement(ctx, tx, c, saleProduct.SaleID, saleProduct.ProcutID, saleProduct.Quantity)
+	})
 	if err != nil {
 		handleResponse(c, http.StatusInternalServerError, err)
 		return
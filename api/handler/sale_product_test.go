@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSaleProductStock(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/saleproduct", h.CreateSaleProduct)
+	r.PUT("/saleproduct/:id", h.UpdateSaleProduct)
+	r.DELETE("/saleproduct/:id", h.DeleteSaleProduct)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: branch.Id})
+	sale, _ := strg.Sale().Create(ctx, &models.CreateSale{BranchID: branch.Id, IncrementID: "S-00001"})
+	_, _ = strg.StockMovement().Create(ctx, &models.CreateStockMovement{BranchID: branch.Id, ProductID: product.Id, Quantity: 10, Type: models.MovementReceipt, Name: product.Name})
+
+	serve := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+
+	stock := func() int {
+		list, _ := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}, "product_id": {product.Id}}},
+		})
+		return list.Remainders[0].Quantity
+	}
+
+	w := serve(http.MethodPost, "/saleproduct", models.CreateSaleProduct{ProcutID: product.Id, SaleID: sale.Id, Quantity: 4})
+	if w.Code != http.StatusCreated || stock() != 6 {
+		t.Fatalf("create: status %d stock %d: %s", w.Code, stock(), w.Body.String())
+	}
+
+	var created struct {
+		Data models.SaleProduct `json:"data"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/saleproduct/" + created.Data.Id + "?id=" + created.Data.Id
+
+	w = serve(http.MethodPut, path, models.UpdateSaleProduct{ProcutID: product.Id, SaleID: sale.Id, Quantity: 7, Price: 3000})
+	if w.Code != http.StatusAccepted || stock() != 3 {
+		t.Fatalf("update: status %d stock %d: %s", w.Code, stock(), w.Body.String())
+	}
+
+	w = serve(http.MethodPut, path, models.UpdateSaleProduct{ProcutID: product.Id, SaleID: sale.Id, Quantity: 11, Price: 3000})
+	if w.Code != http.StatusBadRequest || stock() != 3 {
+		t.Fatalf("update over stock: status %d stock %d: %s", w.Code, stock(), w.Body.String())
+	}
+
+	w = serve(http.MethodDelete, path, nil)
+	if w.Code != http.StatusOK || stock() != 10 {
+		t.Fatalf("delete: status %d stock %d: %s", w.Code, stock(), w.Body.String())
+	}
+
+	history, _ := strg.StockMovement().GetList(ctx, &models.GetListStockMovementRequest{
+		Filter: models.Filter{Fields: map[string][]string{"type": {models.MovementSale}}},
+	})
+	if history.Count != 3 {
+		t.Fatalf("sale movements = %d, want 3", history.Count)
+	}
+}
@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create a Sale return
+// @Description Take back goods of sale lines. They go back to the remainder of the branch, their value reduces the debt of the sale and what is left over is refunded by method.
+// @Tags SaleReturn
+// @Accept json
+// @Produce json
+// @Param object body models.CreateSaleReturn true "Sale return"
+// @Success 201 {object} models.SaleReturn "Created Sale return"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Sale not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /sale_return [post]
+func (h *Handler) CreateSaleReturn(c *gin.Context) {
+
+	var createSaleReturn models.CreateSaleReturn
+	err := c.ShouldBindJSON(&createSaleReturn)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if createSaleReturn.Method == "" {
+		createSaleReturn.Method = "cash"
+	}
+
+	if err = validateSaleReturn(&createSaleReturn); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	sale, err := h.strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: createSaleReturn.SaleID})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, sale.BranchID) {
+		return
+	}
+
+	if err = h.returnable(ctx, sale, createSaleReturn.Lines); err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createSaleReturn.IncrementID, err = h.nextNumber(ctx, "sale_return", h.cfg.SaleReturnNumbering, sale.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createSaleReturn.UserID = c.GetString(ctxUserID)
+
+	var resp *models.SaleReturn
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		saleReturn, err := tx.SaleReturn().Create(ctx, &createSaleReturn)
+		if err != nil {
+			return err
+		}
+
+		// the goods go back to the lots they were sold from, expiry and all
+		sold, err := soldLots(ctx, tx, sale)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range saleReturn.Lines {
+			for _, lot := range takeLots(sold[line.ProductID], line.Quantity) {
+
+				movement := &models.CreateStockMovement{
+					BranchID:       saleReturn.BranchID,
+					ProductID:      line.ProductID,
+					Quantity:       lot.Quantity,
+					Type:           models.MovementReturn,
+					DocumentID:     saleReturn.Id,
+					DocumentNumber: saleReturn.IncrementID,
+					UserID:         createSaleReturn.UserID,
+					Comment:        createSaleReturn.Reason,
+					LotNumber:      lot.LotNumber,
+					ExpiryDate:     lot.ExpiryDate,
+				}
+
+				if err = withOpeningDetails(ctx, tx, movement, 0); err != nil {
+					return err
+				}
+
+				if _, err = tx.StockMovement().Create(ctx, movement); err != nil {
+					return err
+				}
+			}
+		}
+
+		resp = saleReturn
+		return nil
+	})
+	if errors.Is(err, storage.ErrOverReturn) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// soldLots is what the sale took out of each lot less what its returns
+// already put back.
+func soldLots(ctx context.Context, strg storage.StorageI, sale *models.Sale) (map[string][]*models.StockMovement, error) {
+
+	sold, err := documentLots(ctx, strg, sale.BranchID, sale.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var returns []*models.SaleReturn
+
+	for {
+		list, err := strg.SaleReturn().GetList(ctx, &models.GetListSaleReturnRequest{
+			Offset: int64(len(returns)),
+			Limit:  100,
+			Filter: models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		returns = append(returns, list.SaleReturns...)
+		if len(list.SaleReturns) == 0 || len(returns) >= list.Count {
+			break
+		}
+	}
+
+	for _, saleReturn := range returns {
+		returned, err := documentLots(ctx, strg, sale.BranchID, saleReturn.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		// a return puts stock in, its lots count negative
+		for productID, lots := range returned {
+			for _, back := range lots {
+				for _, lot := range sold[productID] {
+					if lot.LotNumber == back.LotNumber {
+						lot.Quantity += back.Quantity
+					}
+				}
+			}
+		}
+	}
+
+	return sold, nil
+}
+
+func validateSaleReturn(req *models.CreateSaleReturn) error {
+
+	if !helpers.IsValidUUID(req.SaleID) {
+		return apperror.New(apperror.Validation, "sale_id is not uuid").WithField("sale_id", "must be uuid")
+	}
+
+	if !contains(models.PaymentMethods, req.Method) {
+		return apperror.New(apperror.Validation, "method must be one of cash, card, transfer").WithField("method", "must be cash, card or transfer")
+	}
+
+	if len(req.Lines) == 0 {
+		return apperror.New(apperror.Validation, "a return needs lines").WithField("lines", "must not be empty")
+	}
+
+	var lines = map[string]bool{}
+	for _, line := range req.Lines {
+		if !helpers.IsValidUUID(line.SaleProductID) {
+			return apperror.New(apperror.Validation, "sale_product_id is not uuid").WithField("sale_product_id", "must be uuid")
+		}
+
+		if line.Quantity <= 0 {
+			return apperror.New(apperror.Validation, "quantity must be positive").WithField("quantity", "must be positive")
+		}
+
+		if lines[line.SaleProductID] {
+			return apperror.New(apperror.Validation, "line "+line.SaleProductID+" is listed twice").WithField("sale_product_id", "must be unique")
+		}
+		lines[line.SaleProductID] = true
+	}
+
+	return nil
+}
+
+// returnable tells which line can not be returned: one of another sale or
+// one returned beyond what was sold. The repo checks the quantity again
+// with the sale locked.
+func (h *Handler) returnable(ctx context.Context, sale *models.Sale, lines []*models.CreateSaleReturnLine) error {
+
+	saleProducts, err := h.strg.SaleProduct().GetList(ctx, &models.GetListSaleProductRequest{
+		Limit:  1000000,
+		Filter: models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	returned, err := h.strg.SaleReturn().Returned(ctx, &models.SalePrimaryKey{Id: sale.Id})
+	if err != nil {
+		return err
+	}
+
+	var sold = map[string]int{}
+	for _, saleProduct := range saleProducts.SaleProducts {
+		sold[saleProduct.Id] = saleProduct.Quantity
+	}
+
+	for _, line := range lines {
+		quantity, ok := sold[line.SaleProductID]
+		if !ok {
+			return apperror.New(apperror.Validation, "line "+line.SaleProductID+" is not a line of sale "+sale.IncrementID).WithField("sale_product_id", "must be a line of the sale")
+		}
+
+		if left := quantity - returned[line.SaleProductID]; line.Quantity > left {
+			return apperror.New(apperror.Validation, "only "+strconv.Itoa(left)+" of line "+line.SaleProductID+" can be returned").WithField("quantity", "must not exceed the sold quantity less returns")
+		}
+	}
+
+	return nil
+}
+
+// notReturned fails with a conflict when goods of the sale line were
+// returned, the line can then only be corrected by another return.
+func notReturned(ctx context.Context, tx storage.StorageI, saleProduct *models.SaleProduct) error {
+
+	returned, err := tx.SaleReturn().Returned(ctx, &models.SalePrimaryKey{Id: saleProduct.SaleID})
+	if err != nil {
+		return err
+	}
+
+	if returned[saleProduct.Id] > 0 {
+		return apperror.New(apperror.Conflict, "goods of line "+saleProduct.Id+" were returned").WithField("sale_product_id", "must have no returns")
+	}
+
+	return nil
+}
+
+// @Summary Get a Sale return by ID
+// @Description Get a sale return with its lines.
+// @Tags SaleReturn
+// @Accept json
+// @Produce json
+// @Param id path string true "Sale return ID"
+// @Success 200 {object} models.SaleReturn "Sale return details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Sale return not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /sale_return/{id} [get]
+func (h *Handler) GetByIDSaleReturn(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.SaleReturn().GetByID(ctx, &models.SaleReturnPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, resp.BranchID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Sale return
+// @Description Get sale returns without their lines, newest first. Filter by sale_id for the returns of one sale.
+// @Tags SaleReturn
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param sale_id query string false "sale_id, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param client_id query string false "client_id, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param total_price_min query number false "min total_price"
+// @Param total_price_max query number false "max total_price"
+// @Param refund_min query number false "min refund"
+// @Param refund_max query number false "max refund"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListSaleReturnResponse "Sale returns"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /sale_return [get]
+func (h *Handler) GetListSaleReturn(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.SaleReturnFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.SaleReturn().GetList(ctx, &models.GetListSaleReturnRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// saleHasHistory fails with a conflict when a sale has lines, payments or
+// returns. Deleting it would lose them, its goods are returned instead.
+func (h *Handler) saleHasHistory(ctx context.Context, sale *models.Sale) error {
+
+	var filter = models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}}
+
+	saleProducts, err := h.strg.SaleProduct().GetList(ctx, &models.GetListSaleProductRequest{Limit: 1, Filter: filter})
+	if err != nil {
+		return err
+	}
+
+	payments, err := h.strg.Payment().GetList(ctx, &models.GetListPaymentRequest{Limit: 1, Filter: filter})
+	if err != nil {
+		return err
+	}
+
+	saleReturns, err := h.strg.SaleReturn().GetList(ctx, &models.GetListSaleReturnRequest{Limit: 1, Filter: filter})
+	if err != nil {
+		return err
+	}
+
+	if saleProducts.Count > 0 || payments.Count > 0 || saleReturns.Count > 0 {
+		return apperror.New(apperror.Conflict, "sale "+sale.IncrementID+" has lines, payments or returns, return its goods instead").WithField("id", "must be a sale without lines, payments and returns")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSaleReturn(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/sale_return", h.CreateSaleReturn)
+	r.DELETE("/sale/:id", h.DeleteSale)
+	r.DELETE("/saleproduct/:id", h.DeleteSaleProduct)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: branch.Id})
+	other, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 2000, BranchID: branch.Id})
+
+	for _, p := range []*models.Product{product, other} {
+		_, err := strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID: branch.Id, ProductID: p.Id, Quantity: 10, Type: models.MovementAdjustment,
+			Name: p.Name, ComingPrice: 2500, SalePrice: p.Price,
+		})
+		if err != nil {
+			t.Fatalf("opening stock: %v", err)
+		}
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-0000001",
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 4}, {ProductID: other.Id, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	var (
+		sale = checkout.Sale
+		line = checkout.SaleProducts[0]
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+		stock  int
+	}{
+		{name: "line of another sale", method: http.MethodPost, path: "/sale_return", body: models.CreateSaleReturn{SaleID: sale.Id, Lines: []*models.CreateSaleReturnLine{{SaleProductID: sale.Id, Quantity: 1}}}, status: http.StatusBadRequest, stock: 6},
+		{name: "more than sold", method: http.MethodPost, path: "/sale_return", body: models.CreateSaleReturn{SaleID: sale.Id, Lines: []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 5}}}, status: http.StatusBadRequest, stock: 6},
+		{name: "return", method: http.MethodPost, path: "/sale_return", body: models.CreateSaleReturn{SaleID: sale.Id, Reason: "damaged box", Lines: []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 3}}}, status: http.StatusCreated, stock: 9},
+		{name: "more than left", method: http.MethodPost, path: "/sale_return", body: models.CreateSaleReturn{SaleID: sale.Id, Lines: []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 2}}}, status: http.StatusBadRequest, stock: 9},
+		{name: "delete returned line", method: http.MethodDelete, path: "/saleproduct/" + line.Id + "?id=" + line.Id, status: http.StatusConflict, stock: 9},
+		{name: "delete sale with history", method: http.MethodDelete, path: "/sale/" + sale.Id + "?id=" + sale.Id, status: http.StatusConflict, stock: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(tt.body)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			stock, err := stockOf(ctx, strg, branch.Id, product.Id)
+			if err != nil || stock.Quantity != tt.stock {
+				t.Fatalf("stock = %+v %v, want %d", stock, err, tt.stock)
+			}
+		})
+	}
+
+	// the 9000 returned came off the debt of 14000, nothing was refunded
+	sale, err = strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: sale.Id})
+	if err != nil || sale.Returned != 9000 || sale.Debd != 5000 || sale.Paid != 0 {
+		t.Fatalf("sale after return: %+v %v", sale, err)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckout(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/checkout", h.Checkout)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: branch.Id})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "1999-05-01", BranchID: branch.Id})
+	_, _ = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 4, SalePrice: 3500, BranchID: branch.Id})
+	other, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Citramon", Price: 2000})
+	_, _ = strg.Remainder().Create(ctx, &models.Remainder{ProductID: other.Id, Quantity: 4, SalePrice: 2000, BranchID: branch.Id})
+
+	tests := []struct {
+		name      string
+		productID string
+		quantity  int
+		status    int
+	}{
+		{name: "enough stock", productID: product.Id, quantity: 3, status: http.StatusCreated},
+		{name: "not enough stock", productID: product.Id, quantity: 3, status: http.StatusBadRequest},
+		{name: "non positive quantity", productID: product.Id, quantity: 0, status: http.StatusBadRequest},
+		{name: "not in the assortment", productID: other.Id, quantity: 1, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(models.CreateCheckout{
+				ClientID: client.Id,
+				BranchID: branch.Id,
+				Products: []*models.CheckoutProduct{{ProductID: tt.productID, Quantity: tt.quantity}},
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
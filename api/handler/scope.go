@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+var errOutOfScope = errors.New("branch_id is not the branch of the user")
+
+// branchScope returns the branch a BRANCH or CASSIER user is limited to,
+// empty for SUPER-ADMIN and on routes without the Auth middleware.
+func branchScope(c *gin.Context) string {
+
+	switch c.GetString(ctxClientType) {
+	case "", config.SuperAdmin:
+		return ""
+	}
+
+	return c.GetString(ctxBranchID)
+}
+
+// inScope answers 403 and returns false when one of branchIDs is not the
+// branch of the user.
+func inScope(c *gin.Context, branchIDs ...string) bool {
+
+	scope := branchScope(c)
+	if len(scope) == 0 {
+		return true
+	}
+
+	for _, branchID := range branchIDs {
+		if branchID != scope {
+			handleResponse(c, http.StatusForbidden, errOutOfScope.Error())
+			return false
+		}
+	}
+
+	return true
+}
+
+// storedInScope is inScope for a record that was read with err. A missing
+// record is left to the handler to report.
+func storedInScope(c *gin.Context, err error, branchID func() string) bool {
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return inScope(c, branchID())
+}
+
+func (h *Handler) saleInScope(ctx context.Context, c *gin.Context, id string) bool {
+
+	if len(branchScope(c)) == 0 {
+		return true
+	}
+
+	sale, err := h.strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: id})
+	return storedInScope(c, err, func() string { return sale.BranchID })
+}
+
+func (h *Handler) comingInScope(ctx context.Context, c *gin.Context, id string) bool {
+
+	if len(branchScope(c)) == 0 {
+		return true
+	}
+
+	coming, err := h.strg.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: id})
+	return storedInScope(c, err, func() string { return coming.BranchID })
+}
+
+func (h *Handler) remainderInScope(ctx context.Context, c *gin.Context, id string) bool {
+
+	if len(branchScope(c)) == 0 {
+		return true
+	}
+
+	remainder, err := h.strg.Remainder().GetByID(ctx, &models.RemainderPrimaryKey{Id: id})
+	return storedInScope(c, err, func() string { return remainder.BranchID })
+}
+
+func (h *Handler) clientInScope(ctx context.Context, c *gin.Context, id string) bool {
+
+	if len(branchScope(c)) == 0 {
+		return true
+	}
+
+	client, err := h.strg.Client().GetByID(ctx, &models.ClientPrimaryKey{Id: id})
+	return storedInScope(c, err, func() string { return client.BranchID })
+}
+
+// queryBranch reads the branch_id query, which a BRANCH or CASSIER user
+// can only set to their own branch.
+func queryBranch(c *gin.Context) (string, bool) {
+
+	var branchID = c.Query("branch_id")
+	if len(branchID) > 0 && !helpers.IsValidUUID(branchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return "", false
+	}
+
+	if scope := branchScope(c); len(scope) > 0 {
+		if len(branchID) > 0 && !inScope(c, branchID) {
+			return "", false
+		}
+		branchID = scope
+	}
+
+	return branchID, true
+}
@@ -0,0 +1,456 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Set Stock Level
+// @Description Create or replace the min and max stock of a product in a branch, counted over all of its lots.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param object body models.SetStockLevel true "Stock Level"
+// @Success 200 {object} models.StockLevel "Stock Level"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_level [put]
+func (h *Handler) SetStockLevel(c *gin.Context) {
+
+	var setStockLevel models.SetStockLevel
+	err := c.ShouldBindJSON(&setStockLevel)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(setStockLevel.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if !helpers.IsValidUUID(setStockLevel.ProductID) {
+		handleResponse(c, http.StatusBadRequest, "product_id is not uuid")
+		return
+	}
+
+	if setStockLevel.MinQuantity < 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "min_quantity must not be negative").WithField("min_quantity", "must not be negative"))
+		return
+	}
+
+	if setStockLevel.MaxQuantity <= 0 || setStockLevel.MaxQuantity < setStockLevel.MinQuantity {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "max_quantity must be positive and at least min_quantity").WithField("max_quantity", "must be positive and at least min_quantity"))
+		return
+	}
+
+	if !inScope(c, setStockLevel.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockLevel().Set(ctx, &setStockLevel)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Stock Level
+// @Description Get the min and max stock of products per branch, newest first.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param min_quantity_min query number false "min min_quantity"
+// @Param min_quantity_max query number false "max min_quantity"
+// @Param max_quantity_min query number false "min max_quantity"
+// @Param max_quantity_max query number false "max max_quantity"
+// @Success 200 {object} models.GetListStockLevelResponse "Stock Levels"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_level [get]
+func (h *Handler) GetListStockLevel(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.StockLevelFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockLevel().GetList(ctx, &models.GetListStockLevelRequest{
+		Limit:  limit,
+		Offset: offset,
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Delete Stock Level
+// @Description Stop watching the stock of a product in a branch.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param branch_id query string true "branch_id"
+// @Param product_id query string true "product_id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_level [delete]
+func (h *Handler) DeleteStockLevel(c *gin.Context) {
+
+	var key = models.StockLevelPrimaryKey{
+		BranchID:  c.Query("branch_id"),
+		ProductID: c.Query("product_id"),
+	}
+
+	if !helpers.IsValidUUID(key.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if !helpers.IsValidUUID(key.ProductID) {
+		handleResponse(c, http.StatusBadRequest, "product_id is not uuid")
+		return
+	}
+
+	if !inScope(c, key.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	err := h.strg.StockLevel().Delete(ctx, &key)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Low stock
+// @Description Products whose lots together are below the min_quantity of their stock level, the largest shortage first.
+// @Tags Remainder
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "branch_id, all branches when empty"
+// @Success 200 {object} models.LowStockResponse "Low stock"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /remainder/low [get]
+func (h *Handler) GetLowStock(c *gin.Context) {
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockLevel().Low(ctx, &models.LowStockRequest{BranchID: branchID})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Reorder suggestions
+// @Description Order quantities for the products of a branch whose stock, less the average daily sales over window_days, net of returns, times lead_days, falls below min_quantity. Each is proposed back to max_quantity at the coming price of its newest lot.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param branch_id query string true "branch_id, the user's branch for branch users"
+// @Param window_days query int false "days of sales to average, REORDER_WINDOW_DAYS by default"
+// @Param lead_days query int false "days an order takes to arrive, REORDER_LEAD_DAYS by default"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Success 200 {object} models.ReorderResponse "Reorder suggestions"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /reorder [get]
+func (h *Handler) GetReorder(c *gin.Context) {
+
+	windowDays, err := getIntegerOrDefaultValue(c.Query("window_days"), int64(h.cfg.ReorderWindowDays))
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query window_days")
+		return
+	}
+
+	leadDays, err := getIntegerOrDefaultValue(c.Query("lead_days"), int64(h.cfg.ReorderLeadDays))
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query lead_days")
+		return
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	var req = models.ReorderRequest{
+		BranchID:   branchID,
+		WindowDays: int(windowDays),
+		LeadDays:   int(leadDays),
+	}
+
+	if productIDs := c.Query("product_id"); len(productIDs) > 0 {
+		req.ProductIDs = strings.Split(productIDs, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, ok := h.reorder(ctx, c, &req)
+	if !ok {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// reorder answers and returns false unless req is valid and its suggestions
+// could be read.
+func (h *Handler) reorder(ctx context.Context, c *gin.Context, req *models.ReorderRequest) (*models.ReorderResponse, bool) {
+
+	if !helpers.IsValidUUID(req.BranchID) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "branch_id is required").WithField("branch_id", "must be uuid"))
+		return nil, false
+	}
+
+	if req.WindowDays <= 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "window_days must be positive").WithField("window_days", "must be positive"))
+		return nil, false
+	}
+
+	if req.LeadDays < 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "lead_days must not be negative").WithField("lead_days", "must not be negative"))
+		return nil, false
+	}
+
+	for _, productID := range req.ProductIDs {
+		if !helpers.IsValidUUID(productID) {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_id is not uuid").WithField("product_id", "must be uuid"))
+			return nil, false
+		}
+	}
+
+	resp, err := h.strg.StockLevel().Reorder(ctx, req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// reorderFor reads the suggestions a CreateReorder converts, answering 409
+// when there is nothing to order.
+func (h *Handler) reorderFor(ctx context.Context, c *gin.Context, req *models.CreateReorder) (*models.ReorderResponse, bool) {
+
+	if !helpers.IsValidUUID(req.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return nil, false
+	}
+
+	if !inScope(c, req.BranchID) || !h.validSupplier(ctx, c, req.SupplierID) {
+		return nil, false
+	}
+
+	if req.WindowDays == 0 {
+		req.WindowDays = h.cfg.ReorderWindowDays
+	}
+
+	if req.LeadDays == 0 {
+		req.LeadDays = h.cfg.ReorderLeadDays
+	}
+
+	resp, ok := h.reorder(ctx, c, &models.ReorderRequest{
+		BranchID:   req.BranchID,
+		WindowDays: req.WindowDays,
+		LeadDays:   req.LeadDays,
+		ProductIDs: req.ProductIDs,
+	})
+	if !ok {
+		return nil, false
+	}
+
+	if len(resp.Lines) == 0 {
+		handleResponse(c, http.StatusConflict, apperror.New(apperror.Conflict, "nothing to reorder in the branch"))
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// @Summary Purchase Order from reorder suggestions
+// @Description Order the suggested quantities of a branch from a supplier at the coming price of their newest lot. Zero window_days and lead_days take the configured defaults.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param object body models.CreateReorder true "Reorder"
+// @Success 201 {object} models.PurchaseOrder "Created Purchase Order"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Nothing to reorder"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /reorder/purchase_order [post]
+func (h *Handler) CreateReorderPurchaseOrder(c *gin.Context) {
+
+	var createReorder models.CreateReorder
+	err := c.ShouldBindJSON(&createReorder)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	reorder, ok := h.reorderFor(ctx, c, &createReorder)
+	if !ok {
+		return
+	}
+
+	var createPurchaseOrder = models.CreatePurchaseOrder{
+		SupplierID: createReorder.SupplierID,
+		BranchID:   createReorder.BranchID,
+		UserID:     c.GetString(ctxUserID),
+	}
+
+	for _, line := range reorder.Lines {
+		createPurchaseOrder.Lines = append(createPurchaseOrder.Lines, &models.CreatePurchaseOrderLine{
+			ProductID: line.ProductID,
+			Quantity:  line.Suggested,
+			Price:     line.Price,
+		})
+	}
+
+	createPurchaseOrder.IncrementID, err = h.nextNumber(ctx, "purchase_order", h.cfg.PurchaseOrderNumbering, createPurchaseOrder.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp, err := h.strg.PurchaseOrder().Create(ctx, &createPurchaseOrder)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Coming from reorder suggestions
+// @Description Draft a Coming of a supplier for a branch with a picking list for every suggested quantity at the coming price of its newest lot. Zero window_days and lead_days take the configured defaults.
+// @Tags StockLevel
+// @Accept json
+// @Produce json
+// @Param object body models.CreateReorder true "Reorder"
+// @Success 201 {object} models.Coming "Created Coming"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Nothing to reorder"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /reorder/coming [post]
+func (h *Handler) CreateReorderComing(c *gin.Context) {
+
+	var createReorder models.CreateReorder
+	err := c.ShouldBindJSON(&createReorder)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	reorder, ok := h.reorderFor(ctx, c, &createReorder)
+	if !ok {
+		return
+	}
+
+	incrementId, err := h.nextNumber(ctx, "coming", h.cfg.ComingNumbering, createReorder.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var resp *models.Coming
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		coming, err := tx.Coming().Create(ctx, &models.CreateComing{
+			IncrementID: incrementId,
+			BranchID:    createReorder.BranchID,
+			SupplierID:  createReorder.SupplierID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range reorder.Lines {
+			_, err = tx.PickingList().Create(ctx, &models.PickingList{
+				Product_ID:        line.ProductID,
+				Quantity:          line.Suggested,
+				Price:             line.Price,
+				ComingID:          coming.Id,
+				ComingIncrementID: coming.IncrementID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: coming.Id})
+		return err
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReorder(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{ReorderWindowDays: 30, ReorderLeadDays: 10}, strg)
+		r    = gin.New()
+	)
+
+	r.PUT("/stock_level", h.SetStockLevel)
+	r.GET("/remainder/low", h.GetLowStock)
+	r.GET("/reorder", h.GetReorder)
+	r.POST("/reorder/purchase_order", h.CreateReorderPurchaseOrder)
+	r.POST("/reorder/coming", h.CreateReorderComing)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+	client, _ := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	supplier, _ := strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Farm Import"})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: branch.Id})
+	vitamin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Vitamin C", Price: 9000, BranchID: branch.Id})
+
+	strg.Remainder().Create(ctx, &models.Remainder{ProductID: aspirin.Id, Name: "Aspirin", Quantity: 20, ComingPrice: 3000, SalePrice: 4000, BranchID: branch.Id})
+	strg.Remainder().Create(ctx, &models.Remainder{ProductID: vitamin.Id, Name: "Vitamin C", Quantity: 50, ComingPrice: 7000, SalePrice: 9000, BranchID: branch.Id})
+
+	_, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-0000001",
+		Products:    []*models.CheckoutProduct{{ProductID: aspirin.Id, Quantity: 6}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if w := serve(r, http.MethodPut, "/stock_level", models.SetStockLevel{BranchID: branch.Id, ProductID: aspirin.Id, MinQuantity: 15, MaxQuantity: 10}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("max below min: status %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, level := range []models.SetStockLevel{
+		{BranchID: branch.Id, ProductID: aspirin.Id, MinQuantity: 15, MaxQuantity: 40},
+		{BranchID: branch.Id, ProductID: vitamin.Id, MinQuantity: 10, MaxQuantity: 60},
+	} {
+		if w := serve(r, http.MethodPut, "/stock_level", level, nil); w.Code != http.StatusOK {
+			t.Fatalf("set stock level: status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	var low models.LowStockResponse
+	if w := serve(r, http.MethodGet, "/remainder/low?branch_id="+branch.Id, nil, &low); w.Code != http.StatusOK || len(low.Products) != 1 || low.Products[0].ProductID != aspirin.Id || low.Products[0].Shortage != 1 {
+		t.Fatalf("low stock: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodGet, "/reorder", nil, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("reorder without a branch: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, http.MethodGet, "/reorder?branch_id="+branch.Id+"&window_days=0", nil, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("reorder over no days: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// 6 aspirin sold in the 30 day window leave 12 after the 10 lead days
+	var reorder models.ReorderResponse
+	if w := serve(r, http.MethodGet, "/reorder?branch_id="+branch.Id, nil, &reorder); w.Code != http.StatusOK || len(reorder.Lines) != 1 {
+		t.Fatalf("reorder: status %d: %s", w.Code, w.Body.String())
+	}
+	if line := reorder.Lines[0]; line.ProductID != aspirin.Id || line.Projected != 12 || line.Suggested != 28 || reorder.Cost != 84000 {
+		t.Fatalf("unexpected reorder line %+v", line)
+	}
+
+	if w := serve(r, http.MethodPost, "/reorder/purchase_order", models.CreateReorder{BranchID: branch.Id}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("purchase order without a supplier: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var po models.PurchaseOrder
+	if w := serve(r, http.MethodPost, "/reorder/purchase_order", models.CreateReorder{BranchID: branch.Id, SupplierID: supplier.Id}, &po); w.Code != http.StatusCreated {
+		t.Fatalf("purchase order: status %d: %s", w.Code, w.Body.String())
+	}
+	if po.SupplierID != supplier.Id || len(po.Lines) != 1 || po.Lines[0].Quantity != 28 || po.Lines[0].Price != 3000 {
+		t.Fatalf("unexpected purchase order %+v", po)
+	}
+
+	var coming models.Coming
+	if w := serve(r, http.MethodPost, "/reorder/coming", models.CreateReorder{BranchID: branch.Id, SupplierID: supplier.Id}, &coming); w.Code != http.StatusCreated {
+		t.Fatalf("coming: status %d: %s", w.Code, w.Body.String())
+	}
+	if coming.Status != models.ComingDraft || coming.TotalPrice != 84000 {
+		t.Fatalf("unexpected coming %+v", coming)
+	}
+
+	// the vitamin is well stocked
+	if w := serve(r, http.MethodPost, "/reorder/coming", models.CreateReorder{BranchID: branch.Id, SupplierID: supplier.Id, ProductIDs: []string{vitamin.Id}}, nil); w.Code != http.StatusConflict {
+		t.Fatalf("nothing to reorder: status %d: %s", w.Code, w.Body.String())
+	}
+}
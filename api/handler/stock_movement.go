@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/cast"
+)
+
+// @Summary Write off or adjust stock
+// @Description Record a write_off (negative quantity) or an adjustment of the remainder of a product, of one lot when lot_number is set and from the lots expiring first otherwise. Stock is only adjusted in for products in the assortment of the branch. Receipts, sales, returns and transfers are recorded by their documents.
+// @Tags StockMovement
+// @Accept json
+// @Produce json
+// @Param object body models.CreateStockMovement true "Stock movement"
+// @Success 201 {object} models.StockMovement "Stock movement"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_movement [post]
+func (h *Handler) CreateStockMovement(c *gin.Context) {
+
+	var createStockMovement models.CreateStockMovement
+	err := c.ShouldBindJSON(&createStockMovement)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(createStockMovement.BranchID) || !helpers.IsValidUUID(createStockMovement.ProductID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id and product_id must be uuid")
+		return
+	}
+
+	switch {
+	case createStockMovement.Type != models.MovementWriteOff && createStockMovement.Type != models.MovementAdjustment:
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "type must be write_off or adjustment").WithField("type", "must be write_off or adjustment"))
+		return
+	case createStockMovement.Quantity == 0:
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "quantity must not be zero").WithField("quantity", "must not be zero"))
+		return
+	case createStockMovement.Type == models.MovementWriteOff && createStockMovement.Quantity > 0:
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "a write off takes stock out").WithField("quantity", "must be negative"))
+		return
+	}
+
+	if err = validateLot(createStockMovement.LotNumber, createStockMovement.ExpiryDate); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !inScope(c, createStockMovement.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	product, err := h.strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: createStockMovement.ProductID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "no such product").WithField("product_id", "does not exist"))
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// stock of a product the branch no longer carries can still be taken out
+	if createStockMovement.Quantity > 0 {
+		if err = notCarried(ctx, h.strg, "product_id", createStockMovement.BranchID, product.Id); err != nil {
+			handleResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	createStockMovement.DocumentID = ""
+	createStockMovement.UserID = c.GetString(ctxUserID)
+	createStockMovement.Name = product.Name
+	createStockMovement.SalePrice, err = h.strg.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: createStockMovement.BranchID, ProductID: product.Id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp, err := h.strg.StockMovement().Create(ctx, &createStockMovement)
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a StockMovement by ID
+// @Description Get StockMovement details by its ID.
+// @Tags StockMovement
+// @Accept json
+// @Produce json
+// @Param id path string true "StockMovement ID"
+// @Success 200 {object} models.StockMovement "StockMovement details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "StockMovement not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_movement/{id} [get]
+func (h *Handler) GetByIDStockMovement(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockMovement().GetByID(ctx, &models.StockMovementPrimaryKey{Id: id})
+	if !storedInScope(c, err, func() string { return resp.BranchID }) {
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List StockMovement
+// @Description Get the stock journal, newest first.
+// @Tags StockMovement
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param product_id query string false "product_id, comma separated for several"
+// @Param type query string false "type, comma separated for several"
+// @Param document_id query string false "document_id, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param lot_number query string false "lot_number, comma separated for several"
+// @Param quantity_min query number false "min quantity"
+// @Param quantity_max query number false "max quantity"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListStockMovementResponse "Stock movements"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_movement [get]
+func (h *Handler) GetListStockMovement(c *gin.Context) {
+	h.listStockMovements(c, "")
+}
+
+// @Summary Product movement history
+// @Description Every receipt, sale, return, transfer, write off and adjustment of a product, newest first.
+// @Tags StockMovement
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param type query string false "type, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListStockMovementResponse "Stock movements"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /product/{id}/movements [get]
+func (h *Handler) GetProductMovements(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	h.listStockMovements(c, id)
+}
+
+func (h *Handler) listStockMovements(c *gin.Context, productID string) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.StockMovementFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(productID) > 0 {
+		if filter.Fields == nil {
+			filter.Fields = map[string][]string{}
+		}
+		filter.Fields["product_id"] = []string{productID}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockMovement().GetList(ctx, &models.GetListStockMovementRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Rebuild remainder
+// @Description Compare remainder with the stock journal and, unless dry_run, set it to the journal.
+// @Tags Remainder
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "only this branch"
+// @Param dry_run query bool false "only report the discrepancies"
+// @Success 200 {object} models.RebuildRemainderResponse "Discrepancies"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /remainder/rebuild [post]
+func (h *Handler) RebuildRemainder(c *gin.Context) {
+
+	var req = models.RebuildRemainderRequest{
+		BranchID: c.Query("branch_id"),
+		DryRun:   cast.ToBool(c.Query("dry_run")),
+	}
+
+	if len(req.BranchID) > 0 && !helpers.IsValidUUID(req.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if scope := branchScope(c); len(scope) > 0 {
+		if len(req.BranchID) > 0 && !inScope(c, req.BranchID) {
+			return
+		}
+		req.BranchID = scope
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockMovement().RebuildRemainder(ctx, &req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// saleMovement records quantity leaving (negative) or coming back
+// (positive) to the branch of a sale.
+func saleMovement(ctx context.Context, tx storage.StorageI, c *gin.Context, saleID, productID string, quantity int) error {
+
+	if quantity == 0 {
+		return nil
+	}
+
+	sale, err := tx.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: saleID})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+		BranchID:       sale.BranchID,
+		ProductID:      productID,
+		Quantity:       quantity,
+		Type:           models.MovementSale,
+		DocumentID:     sale.Id,
+		DocumentNumber: sale.IncrementID,
+		UserID:         c.GetString(ctxUserID),
+	})
+	return err
+}
+
+// stockOf returns the remainder the journal posts to for a product of a
+// branch, pgx.ErrNoRows when there is none.
+func stockOf(ctx context.Context, strg storage.StorageI, branchID, productID string) (*models.Remainder, error) {
+
+	list, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Limit: 100,
+		Filter: models.Filter{Fields: map[string][]string{
+			"branch_id":  {branchID},
+			"product_id": {productID},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Remainders) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	// oldest first, the row the journal posts to
+	return list.Remainders[0], nil
+}
+
+// withOpeningDetails fills in the name and prices of m when it is the first
+// stock of the product in the branch, so the remainder it opens is complete.
+func withOpeningDetails(ctx context.Context, tx storage.StorageI, m *models.CreateStockMovement, comingPrice float64) error {
+
+	_, err := stockOf(ctx, tx, m.BranchID, m.ProductID)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	product, err := tx.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: m.ProductID})
+	if err != nil {
+		return err
+	}
+
+	m.Name = product.Name
+	m.ComingPrice = comingPrice
+	m.SalePrice, err = tx.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: m.BranchID, ProductID: m.ProductID})
+
+	return err
+}
+
+// documentLots nets what documentID took out of branchID by product and
+// lot, in the order the lots were first moved. Stock the document put in
+// counts negative. Only LotNumber, ExpiryDate and Quantity are set.
+func documentLots(ctx context.Context, strg storage.StorageI, branchID, documentID string) (map[string][]*models.StockMovement, error) {
+
+	var movements []*models.StockMovement
+
+	for {
+		list, err := strg.StockMovement().GetList(ctx, &models.GetListStockMovementRequest{
+			Offset: int64(len(movements)),
+			Limit:  100,
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchID}, "document_id": {documentID}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		movements = append(movements, list.StockMovements...)
+		if len(list.StockMovements) == 0 || len(movements) >= list.Count {
+			break
+		}
+	}
+
+	var lots = map[string][]*models.StockMovement{}
+
+	// the journal is listed newest first
+	for i := len(movements) - 1; i >= 0; i-- {
+		m := movements[i]
+
+		var lot *models.StockMovement
+		for _, l := range lots[m.ProductID] {
+			if l.LotNumber == m.LotNumber {
+				lot = l
+			}
+		}
+
+		if lot == nil {
+			lot = &models.StockMovement{LotNumber: m.LotNumber, ExpiryDate: m.ExpiryDate}
+			lots[m.ProductID] = append(lots[m.ProductID], lot)
+		}
+
+		lot.Quantity -= m.Quantity
+	}
+
+	return lots, nil
+}
+
+// takeLots takes quantity out of lots, the first ones first, and returns
+// the part taken of each. What the lots do not cover comes without a lot.
+func takeLots(lots []*models.StockMovement, quantity int) []*models.StockMovement {
+
+	var taken []*models.StockMovement
+
+	for _, lot := range lots {
+		if quantity == 0 {
+			break
+		}
+
+		if lot.Quantity <= 0 {
+			continue
+		}
+
+		part := *lot
+		if part.Quantity > quantity {
+			part.Quantity = quantity
+		}
+
+		lot.Quantity -= part.Quantity
+		quantity -= part.Quantity
+		taken = append(taken, &part)
+	}
+
+	if quantity > 0 {
+		taken = append(taken, &models.StockMovement{Quantity: quantity})
+	}
+
+	return taken
+}
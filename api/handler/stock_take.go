@@ -0,0 +1,627 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create a Stock-take
+// @Description Open a count of a branch with the remainder of its products, or of product_ids only, as the expected quantities.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param object body models.CreateStockTake true "Stock-take"
+// @Success 201 {object} models.StockTake "Created Stock-take"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take [post]
+func (h *Handler) CreateStockTake(c *gin.Context) {
+
+	var createStockTake models.CreateStockTake
+	err := c.ShouldBindJSON(&createStockTake)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if !helpers.IsValidUUID(createStockTake.BranchID) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "branch_id is not uuid").WithField("branch_id", "must be uuid"))
+		return
+	}
+
+	for _, productID := range createStockTake.ProductIDs {
+		if !helpers.IsValidUUID(productID) {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product_id is not uuid").WithField("product_ids", "must be uuids"))
+			return
+		}
+	}
+
+	if !inScope(c, createStockTake.BranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	createStockTake.Lines, err = h.stockSnapshot(ctx, createStockTake.BranchID, createStockTake.ProductIDs)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(createStockTake.Lines) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "the branch has nothing to count").WithField("product_ids", "must not be empty when the branch has no remainder"))
+		return
+	}
+
+	createStockTake.IncrementID, err = h.nextNumber(ctx, "stock_take", h.cfg.StockTakeNumbering, createStockTake.BranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createStockTake.UserID = c.GetString(ctxUserID)
+
+	resp, err := h.strg.StockTake().Create(ctx, &createStockTake)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// onHand sums the remainder of a product in a branch over all of its lots.
+func onHand(ctx context.Context, strg storage.StorageI, branchID, productID string) (int, error) {
+
+	var quantity int
+
+	for offset := int64(0); ; offset += 100 {
+		list, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+			Offset: offset,
+			Limit:  100,
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchID}, "product_id": {productID}}},
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, remainder := range list.Remainders {
+			quantity += remainder.Quantity
+		}
+
+		if len(list.Remainders) < 100 {
+			return quantity, nil
+		}
+	}
+}
+
+// stockSnapshot sums the remainder of a branch per product at the coming_price
+// of the row the journal posts to. Listed products without remainder are
+// expected at zero.
+func (h *Handler) stockSnapshot(ctx context.Context, branchID string, productIDs []string) ([]*models.CreateStockTakeLine, error) {
+
+	var (
+		lines  []*models.CreateStockTakeLine
+		byID   = map[string]*models.CreateStockTakeLine{}
+		filter = models.Filter{Fields: map[string][]string{"branch_id": {branchID}}}
+	)
+
+	if len(productIDs) > 0 {
+		filter.Fields["product_id"] = productIDs
+	}
+
+	for offset := int64(0); ; offset += 100 {
+		list, err := h.strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{Offset: offset, Limit: 100, Filter: filter})
+		if err != nil {
+			return nil, err
+		}
+
+		// oldest first, so the first row of a product is the one the journal posts to
+		for _, remainder := range list.Remainders {
+			line, ok := byID[remainder.ProductID]
+			if !ok {
+				line = &models.CreateStockTakeLine{ProductID: remainder.ProductID, ComingPrice: remainder.ComingPrice}
+				byID[remainder.ProductID] = line
+				lines = append(lines, line)
+			}
+			line.Expected += remainder.Quantity
+		}
+
+		if len(list.Remainders) < 100 {
+			break
+		}
+	}
+
+	for _, productID := range productIDs {
+		if _, ok := byID[productID]; !ok {
+			byID[productID] = &models.CreateStockTakeLine{ProductID: productID}
+			lines = append(lines, byID[productID])
+		}
+	}
+
+	return lines, nil
+}
+
+// @Summary Get a Stock-take by ID
+// @Description Get a stock-take with its lines, their variance and the cost impact at coming_price.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param id path string true "Stock-take ID"
+// @Success 200 {object} models.StockTake "Stock-take details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Stock-take not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take/{id} [get]
+func (h *Handler) GetByIDStockTake(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, resp.BranchID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Stock-take
+// @Description Get stock-takes without their lines, newest first.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param status query string false "open or approved, comma separated for several"
+// @Param increment_id query string false "increment_id, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListStockTakeResponse "Stock-takes"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take [get]
+func (h *Handler) GetListStockTake(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.StockTakeFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.StockTake().GetList(ctx, &models.GetListStockTakeRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Delete Stock-take
+// @Description Delete an open stock-take.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "Stock-take is approved"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take/{id} [delete]
+func (h *Handler) DeleteStockTake(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if _, ok := h.openStockTake(ctx, c, id); !ok {
+		return
+	}
+
+	err := h.strg.StockTake().Delete(ctx, &models.StockTakePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Set Stock-take counts
+// @Description Set the counted quantity of products of an open stock-take. Products left out keep their count.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param id path string true "Stock-take ID"
+// @Param object body models.SetStockTakeCounts true "Counts"
+// @Success 200 {object} models.StockTake "Stock-take details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Stock-take not found"
+// @Failure 409 {object} ErrorResponse "Stock-take is approved"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take/{id}/counts [put]
+func (h *Handler) SetStockTakeCounts(c *gin.Context) {
+
+	var setCounts models.SetStockTakeCounts
+	err := c.ShouldBindJSON(&setCounts)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	h.setStockTakeCounts(c, setCounts.Counts)
+}
+
+// @Summary Import Stock-take counts
+// @Description Set counts from the CSV of a scanner, as a multipart "file" or as the body. Each row is product_id,quantity with "," or ";" between, a header row is skipped and the rows of a product are added up.
+// @Tags StockTake
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Stock-take ID"
+// @Param file formData file false "CSV file"
+// @Success 200 {object} models.StockTake "Stock-take details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Stock-take not found"
+// @Failure 409 {object} ErrorResponse "Stock-take is approved"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take/{id}/import [post]
+func (h *Handler) ImportStockTakeCounts(c *gin.Context) {
+
+	var body io.Reader = c.Request.Body
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			handleResponse(c, http.StatusBadRequest, "file: "+err.Error())
+			return
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			handleResponse(c, http.StatusBadRequest, "file: "+err.Error())
+			return
+		}
+		defer f.Close()
+
+		body = f
+	}
+
+	counts, err := parseStockTakeCSV(body)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.setStockTakeCounts(c, counts)
+}
+
+// parseStockTakeCSV reads product_id,quantity rows, adding up the rows of
+// the same product as a scanner writes one row per scan or per shelf.
+func parseStockTakeCSV(r io.Reader) ([]*models.StockTakeCount, error) {
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	if firstLine, _, _ := strings.Cut(string(data), "\n"); strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+
+	var (
+		counts []*models.StockTakeCount
+		byID   = map[string]*models.StockTakeCount{}
+	)
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, apperror.New(apperror.Validation, "invalid csv: "+err.Error()).WithField("file", "must be csv")
+		}
+
+		if len(record) == 1 && len(strings.TrimSpace(record[0])) == 0 {
+			continue
+		}
+
+		if len(record) < 2 {
+			return nil, apperror.New(apperror.Validation, "row "+strconv.Itoa(line)+" needs product_id and quantity").WithField("file", "must have product_id,quantity rows")
+		}
+
+		productID, quantity := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+
+		if line == 1 && !helpers.IsValidUUID(productID) {
+			// header
+			continue
+		}
+
+		if !helpers.IsValidUUID(productID) {
+			return nil, apperror.New(apperror.Validation, "row "+strconv.Itoa(line)+": product_id is not uuid").WithField("product_id", "must be uuid")
+		}
+
+		counted, err := strconv.Atoi(quantity)
+		if err != nil {
+			return nil, apperror.New(apperror.Validation, "row "+strconv.Itoa(line)+": quantity is not a whole number").WithField("quantity", "must be a whole number")
+		}
+
+		count, ok := byID[productID]
+		if !ok {
+			count = &models.StockTakeCount{ProductID: productID}
+			byID[productID] = count
+			counts = append(counts, count)
+		}
+		count.Counted += counted
+	}
+
+	if len(counts) == 0 {
+		return nil, apperror.New(apperror.Validation, "the csv has no counts").WithField("file", "must not be empty")
+	}
+
+	return counts, nil
+}
+
+func (h *Handler) setStockTakeCounts(c *gin.Context, counts []*models.StockTakeCount) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	if len(counts) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "no counts given").WithField("counts", "must not be empty"))
+		return
+	}
+
+	for _, count := range counts {
+		if count.Counted < 0 {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "counted must not be negative").WithField("counted", "must not be negative"))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	stockTake, ok := h.openStockTake(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	var products = map[string]bool{}
+	for _, line := range stockTake.Lines {
+		products[line.ProductID] = true
+	}
+
+	for _, count := range counts {
+		if !products[count.ProductID] {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "product "+count.ProductID+" is not in stock-take "+stockTake.IncrementID).WithField("product_id", "must be a product of the stock-take"))
+			return
+		}
+	}
+
+	rowsAffected, err := h.strg.StockTake().SetCounts(ctx, &models.SetStockTakeCounts{Id: id, Counts: counts})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusConflict, notOpenStockTake(stockTake))
+		return
+	}
+
+	resp, err := h.strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// openStockTake answers and returns false unless the stock-take is open
+// and of the user's branch.
+func (h *Handler) openStockTake(ctx context.Context, c *gin.Context, id string) (*models.StockTake, bool) {
+
+	stockTake, err := h.strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !inScope(c, stockTake.BranchID) {
+		return nil, false
+	}
+
+	if stockTake.Status != models.StockTakeOpen {
+		handleResponse(c, http.StatusConflict, notOpenStockTake(stockTake))
+		return nil, false
+	}
+
+	return stockTake, true
+}
+
+func notOpenStockTake(stockTake *models.StockTake) *apperror.Error {
+	return apperror.New(apperror.Conflict, "stock-take "+stockTake.IncrementID+" is "+stockTake.Status).WithField("status", "must be open")
+}
+
+// @Summary Approve a Stock-take
+// @Description Post the variance of every counted line against the stock on hand at approval as an adjustment, so the remainder matches the count. Lines not counted are left as they are.
+// @Tags StockTake
+// @Accept json
+// @Produce json
+// @Param id path string true "Stock-take ID"
+// @Success 200 {object} models.StockTake "Approved Stock-take"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Stock-take not found"
+// @Failure 409 {object} ErrorResponse "Stock-take is approved"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /stock_take/{id}/approve [post]
+func (h *Handler) ApproveStockTake(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	var resp *models.StockTake
+
+	err := h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		stockTake, err := tx.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if scope := branchScope(c); len(scope) > 0 && scope != stockTake.BranchID {
+			return errOutOfScope
+		}
+
+		// what sold or arrived since the snapshot is already in the count,
+		// the variance is taken against the stock on hand now
+		var expected = models.SetStockTakeExpected{Id: id}
+		for _, line := range stockTake.Lines {
+			if line.Counted == nil {
+				continue
+			}
+
+			quantity, err := onHand(ctx, tx, stockTake.BranchID, line.ProductID)
+			if err != nil {
+				return err
+			}
+
+			expected.Expected = append(expected.Expected, &models.StockTakeExpected{ProductID: line.ProductID, Expected: quantity})
+		}
+
+		if _, err = tx.StockTake().SetExpected(ctx, &expected); err != nil {
+			return err
+		}
+
+		if stockTake, err = tx.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id}); err != nil {
+			return err
+		}
+
+		rowsAffected, err := tx.StockTake().SetStatus(ctx, &models.StockTakeStatus{Id: id, From: models.StockTakeOpen, To: models.StockTakeApproved})
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return notOpenStockTake(stockTake)
+		}
+
+		for _, line := range stockTake.Lines {
+
+			if line.Counted == nil || line.Variance == 0 {
+				continue
+			}
+
+			movement := &models.CreateStockMovement{
+				BranchID:       stockTake.BranchID,
+				ProductID:      line.ProductID,
+				Quantity:       line.Variance,
+				Type:           models.MovementAdjustment,
+				DocumentID:     stockTake.Id,
+				DocumentNumber: stockTake.IncrementID,
+				UserID:         c.GetString(ctxUserID),
+			}
+
+			// a product found where the branch had none opens a remainder
+			if err = withOpeningDetails(ctx, tx, movement, line.ComingPrice); err != nil {
+				return err
+			}
+
+			_, err = tx.StockMovement().Create(ctx, movement)
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: id})
+		return err
+	})
+	if errors.Is(err, errOutOfScope) {
+		handleResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
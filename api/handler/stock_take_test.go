@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStockTake(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/stock_take", h.CreateStockTake)
+	r.PUT("/stock_take/:id/counts", h.SetStockTakeCounts)
+	r.POST("/stock_take/:id/import", h.ImportStockTakeCounts)
+	r.POST("/stock_take/:id/approve", h.ApproveStockTake)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	analgin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: branch.Id})
+	aspirin, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 2000, BranchID: branch.Id})
+	citramon, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Citramon", Price: 1000, BranchID: branch.Id})
+
+	for _, p := range []*models.Product{analgin, aspirin} {
+		_, err := strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID: branch.Id, ProductID: p.Id, Quantity: 10, Type: models.MovementAdjustment,
+			Name: p.Name, ComingPrice: 2500, SalePrice: p.Price,
+		})
+		if err != nil {
+			t.Fatalf("opening stock: %v", err)
+		}
+	}
+
+	var stockTake models.StockTake
+	lineOf := func(productID string) *models.StockTakeLine {
+		for _, line := range stockTake.Lines {
+			if line.ProductID == productID {
+				return line
+			}
+		}
+		t.Fatalf("no line of %s in %+v", productID, stockTake)
+		return nil
+	}
+	serve := func(t *testing.T, req *http.Request, status int) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != status {
+			t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body.String())
+		}
+
+		if w.Code < 300 {
+			var resp struct {
+				Data models.StockTake `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			stockTake = resp.Data
+		}
+	}
+
+	jsonBody := func(v interface{}) *bytes.Reader {
+		body, _ := json.Marshal(v)
+		return bytes.NewReader(body)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		serve(t, httptest.NewRequest(http.MethodPost, "/stock_take", jsonBody(models.CreateStockTake{
+			BranchID:   branch.Id,
+			ProductIDs: []string{analgin.Id, aspirin.Id, citramon.Id},
+		})), http.StatusCreated)
+
+		if len(stockTake.Lines) != 3 || lineOf(analgin.Id).Expected != 10 || lineOf(citramon.Id).Expected != 0 || stockTake.Uncounted != 3 {
+			t.Fatalf("unexpected snapshot %+v", stockTake)
+		}
+	})
+
+	id := stockTake.Id
+
+	t.Run("count a product not in the take", func(t *testing.T) {
+		other, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Other", BranchID: branch.Id})
+		serve(t, httptest.NewRequest(http.MethodPut, "/stock_take/"+id+"/counts", jsonBody(models.SetStockTakeCounts{
+			Counts: []*models.StockTakeCount{{ProductID: other.Id, Counted: 1}},
+		})), http.StatusBadRequest)
+	})
+
+	t.Run("import csv", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, _ := form.CreateFormFile("file", "scan.csv")
+		file.Write([]byte("product_id;quantity\n" + analgin.Id + ";5\n" + citramon.Id + ";2\n" + analgin.Id + ";2\n"))
+		form.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/stock_take/"+id+"/import", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		serve(t, req, http.StatusOK)
+
+		if line := lineOf(analgin.Id); line.Counted == nil || *line.Counted != 7 || line.Variance != -3 || line.CostImpact != -7500 {
+			t.Fatalf("unexpected analgin line %+v", line)
+		}
+		if stockTake.Uncounted != 1 {
+			t.Fatalf("uncounted = %d, want 1", stockTake.Uncounted)
+		}
+	})
+
+	t.Run("import bad csv", func(t *testing.T) {
+		serve(t, httptest.NewRequest(http.MethodPost, "/stock_take/"+id+"/import", bytes.NewBufferString(analgin.Id+",many\n")), http.StatusBadRequest)
+	})
+
+	t.Run("sale after the snapshot", func(t *testing.T) {
+		_, err := strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID: branch.Id, ProductID: analgin.Id, Quantity: -3, Type: models.MovementSale,
+		})
+		if err != nil {
+			t.Fatalf("sale: %v", err)
+		}
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		serve(t, httptest.NewRequest(http.MethodPost, "/stock_take/"+id+"/approve", nil), http.StatusOK)
+
+		if stockTake.Status != models.StockTakeApproved {
+			t.Fatalf("status = %s", stockTake.Status)
+		}
+		if line := lineOf(analgin.Id); line.Expected != 7 || line.Variance != 0 {
+			t.Fatalf("analgin line not taken against the stock on hand %+v", line)
+		}
+
+		for product, want := range map[string]int{analgin.Id: 7, aspirin.Id: 10, citramon.Id: 2} {
+			stock, err := stockOf(ctx, strg, branch.Id, product)
+			if err != nil || stock.Quantity != want {
+				t.Fatalf("stock of %s = %+v %v, want %d", product, stock, err, want)
+			}
+		}
+	})
+
+	t.Run("approve twice", func(t *testing.T) {
+		serve(t, httptest.NewRequest(http.MethodPost, "/stock_take/"+id+"/approve", nil), http.StatusConflict)
+	})
+
+	t.Run("count approved", func(t *testing.T) {
+		serve(t, httptest.NewRequest(http.MethodPut, "/stock_take/"+id+"/counts", jsonBody(models.SetStockTakeCounts{
+			Counts: []*models.StockTakeCount{{ProductID: aspirin.Id, Counted: 9}},
+		})), http.StatusConflict)
+	})
+}
@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary create a Supplier
+// @Description Create Supplier
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param object body models.CreateSupplier true "Supplier"
+// @Success 201 {object} models.Supplier "Supplier details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "TIN is taken"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier [post]
+func (h *Handler) CreateSupplier(c *gin.Context) {
+
+	var createSupplier models.CreateSupplier
+	err := c.ShouldBindJSON(&createSupplier)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if len(strings.TrimSpace(createSupplier.Name)) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "name is required").WithField("name", "is required"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Supplier().Create(ctx, &createSupplier)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a Supplier by ID
+// @Description Get Supplier details by its ID.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} models.Supplier "Supplier details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Supplier not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier/{id} [get]
+func (h *Handler) GetByIDSupplier(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Supplier().GetByID(ctx, &models.SupplierPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Supplier
+// @Description Get List Supplier, newest first.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search by name, tin, phone or address"
+// @Param tin query string false "tin, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListSupplierResponse "Supplier details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier [get]
+func (h *Handler) GetListSupplier(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.SupplierFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Supplier().GetList(ctx, &models.GetListSupplierRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update Supplier
+// @Description Update an existing Supplier.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param object body models.UpdateSupplier true "models.UpdateSupplier"
+// @Param id path string true "id"
+// @Success 202 {object} models.Supplier "Supplier details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "TIN is taken"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier/{id} [put]
+func (h *Handler) UpdateSupplier(c *gin.Context) {
+
+	var updateSupplier models.UpdateSupplier
+
+	err := c.ShouldBindJSON(&updateSupplier)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	if len(strings.TrimSpace(updateSupplier.Name)) == 0 {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "name is required").WithField("name", "is required"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	updateSupplier.Id = id
+
+	rowsAffected, err := h.strg.Supplier().Update(ctx, &updateSupplier)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.strg.Supplier().GetByID(ctx, &models.SupplierPrimaryKey{Id: updateSupplier.Id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete Supplier
+// @Description Delete a Supplier without comings and payments.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier/{id} [delete]
+func (h *Handler) DeleteSupplier(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	err := h.strg.Supplier().Delete(ctx, &models.SupplierPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// @Summary Supplier statement
+// @Description Finished comings, their reversals and payments of a Supplier with the running balance we owe.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Param branch_id query string false "Branch Id, all branches by default"
+// @Param from query string false "from, date or RFC3339"
+// @Param to query string false "to, date or RFC3339"
+// @Success 200 {object} models.SupplierStatement "Supplier statement"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Supplier not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier/{id}/statement [get]
+func (h *Handler) SupplierStatement(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	branchId, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	from, err := getTimeQuery(c, "from", false)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := getTimeQuery(c, "to", true)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	_, err = h.strg.Supplier().GetByID(ctx, &models.SupplierPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusNotFound, "no such supplier")
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp, err := h.strg.Supplier().Statement(ctx, &models.SupplierStatementRequest{
+		SupplierID: id,
+		BranchID:   branchId,
+		From:       from,
+		To:         to,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Supplier payables
+// @Description What is owed to each supplier: finished comings less payments, the largest balance first.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "Branch Id, all branches by default"
+// @Success 200 {object} models.PayablesResponse "Payables"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier_payable [get]
+func (h *Handler) GetPayables(c *gin.Context) {
+
+	branchId, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Supplier().Payables(ctx, &models.PayablesRequest{BranchID: branchId})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// validSupplier answers 400 and returns false when supplierId is not the
+// id of a supplier.
+func (h *Handler) validSupplier(ctx context.Context, c *gin.Context, supplierId string) bool {
+
+	if !helpers.IsValidUUID(supplierId) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "supplier_id is invalid").WithField("supplier_id", "must be uuid"))
+		return false
+	}
+
+	_, err := h.strg.Supplier().GetByID(ctx, &models.SupplierPrimaryKey{Id: supplierId})
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "supplier_id is invalid").WithField("supplier_id", "does not exist"))
+		return false
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary create a Supplier Payment
+// @Description Pay a Supplier from a branch, at most what the branch owes it.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param object body models.CreateSupplierPayment true "Supplier Payment"
+// @Success 201 {object} models.SupplierPayment "Supplier Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier_payment [post]
+func (h *Handler) CreateSupplierPayment(c *gin.Context) {
+
+	var createSupplierPayment models.CreateSupplierPayment
+	err := c.ShouldBindJSON(&createSupplierPayment)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if createSupplierPayment.Method == "" {
+		createSupplierPayment.Method = "cash"
+	}
+
+	if !helpers.IsValidUUID(createSupplierPayment.SupplierID) {
+		handleResponse(c, http.StatusBadRequest, "supplier_id is not uuid")
+		return
+	}
+
+	if !helpers.IsValidUUID(createSupplierPayment.BranchID) {
+		handleResponse(c, http.StatusBadRequest, "branch_id is not uuid")
+		return
+	}
+
+	if createSupplierPayment.Amount <= 0 {
+		handleResponse(c, http.StatusBadRequest, "amount must be positive")
+		return
+	}
+
+	if !contains(models.PaymentMethods, createSupplierPayment.Method) {
+		handleResponse(c, http.StatusBadRequest, "method must be one of cash, card, transfer")
+		return
+	}
+
+	if !inScope(c, createSupplierPayment.BranchID) {
+		return
+	}
+
+	createSupplierPayment.UserID = c.GetString(ctxUserID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.SupplierPayment().Create(ctx, &createSupplierPayment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		handleResponse(c, http.StatusBadRequest, "no such supplier")
+		return
+	}
+
+	if errors.Is(err, storage.ErrOverpaySupplier) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a Supplier Payment by ID
+// @Description Get Supplier Payment details by its ID.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier Payment ID"
+// @Success 200 {object} models.SupplierPayment "Supplier Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Supplier Payment not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier_payment/{id} [get]
+func (h *Handler) GetByIDSupplierPayment(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.SupplierPayment().GetByID(ctx, &models.SupplierPaymentPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !inScope(c, resp.BranchID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Supplier Payment
+// @Description Get supplier payments, newest first.
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search by comment"
+// @Param supplier_id query string false "supplier_id, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param method query string false "method, comma separated for several"
+// @Param user_id query string false "user_id, comma separated for several"
+// @Param amount_min query number false "min amount"
+// @Param amount_max query number false "max amount"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListSupplierPaymentResponse "Supplier Payment details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /supplier_payment [get]
+func (h *Handler) GetListSupplierPayment(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.SupplierPaymentFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.SupplierPayment().GetList(ctx, &models.GetListSupplierPaymentRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestSupplier(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/supplier", h.CreateSupplier)
+	r.GET("/supplier/:id/statement", h.SupplierStatement)
+	r.GET("/supplier_payable", h.GetPayables)
+	r.POST("/supplier_payment", h.CreateSupplierPayment)
+	r.POST("/coming", h.CreateComing)
+	r.POST("/coming/:id/finish", h.FinishComing)
+
+	branch, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Ibuprofen", Price: 70, BranchID: branch.Id})
+	supplier, _ := strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Med Supply", TIN: "301234567"})
+	coming, _ := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-00003", BranchID: branch.Id, SupplierID: supplier.Id})
+	strg.PickingList().Create(ctx, &models.PickingList{Product_ID: product.Id, Quantity: 20, Price: 50, ComingID: coming.Id, ComingIncrementID: coming.IncrementID})
+
+	pay := func(amount float64) models.CreateSupplierPayment {
+		return models.CreateSupplierPayment{SupplierID: supplier.Id, BranchID: branch.Id, Amount: amount}
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    interface{}
+		status  int
+		payable float64
+	}{
+		{name: "supplier without name", method: http.MethodPost, path: "/supplier", body: models.CreateSupplier{TIN: "301234568"}, status: http.StatusBadRequest},
+		{name: "tin taken", method: http.MethodPost, path: "/supplier", body: models.CreateSupplier{Name: "Med Supply 2", TIN: "301234567"}, status: http.StatusConflict},
+		{name: "coming without supplier", method: http.MethodPost, path: "/coming", body: models.CreateComing{BranchID: branch.Id}, status: http.StatusBadRequest},
+		{name: "coming of unknown supplier", method: http.MethodPost, path: "/coming", body: models.CreateComing{BranchID: branch.Id, SupplierID: uuid.New().String()}, status: http.StatusBadRequest},
+		{name: "coming", method: http.MethodPost, path: "/coming", body: models.CreateComing{BranchID: branch.Id, SupplierID: supplier.Id}, status: http.StatusCreated},
+		{name: "pay before receiving", method: http.MethodPost, path: "/supplier_payment", body: pay(100), status: http.StatusBadRequest},
+		{name: "finish", method: http.MethodPost, path: "/coming/" + coming.Id + "/finish", status: http.StatusOK},
+		{name: "zero amount", method: http.MethodPost, path: "/supplier_payment", body: pay(0), status: http.StatusBadRequest},
+		{name: "overpay", method: http.MethodPost, path: "/supplier_payment", body: pay(1001), status: http.StatusBadRequest},
+		{name: "pay", method: http.MethodPost, path: "/supplier_payment", body: pay(600), status: http.StatusCreated},
+		{name: "payables", method: http.MethodGet, path: "/supplier_payable?branch_id=" + branch.Id, status: http.StatusOK, payable: 400},
+		{name: "statement", method: http.MethodGet, path: "/supplier/" + supplier.Id + "/statement?branch_id=" + branch.Id, status: http.StatusOK},
+		{name: "statement of unknown supplier", method: http.MethodGet, path: "/supplier/" + uuid.New().String() + "/statement", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			body, _ := json.Marshal(tt.body)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+
+			if tt.payable == 0 {
+				return
+			}
+
+			var resp struct {
+				Data models.PayablesResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Data.Total != tt.payable || len(resp.Data.Suppliers) != 1 || resp.Data.Suppliers[0].Paid != 600 {
+				t.Fatalf("unexpected payables %+v", resp.Data)
+			}
+		})
+	}
+}
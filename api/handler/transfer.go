@@ -0,0 +1,629 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/pkg/helpers"
+	"market_system/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// @Summary Create a Transfer
+// @Description Draft a transfer of goods from one branch to another. The receiving branch must carry every product.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param object body models.CreateTransfer true "Transfer"
+// @Success 201 {object} models.Transfer "Created Transfer"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer [post]
+func (h *Handler) CreateTransfer(c *gin.Context) {
+
+	var createTransfer models.CreateTransfer
+	err := c.ShouldBindJSON(&createTransfer)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if err = validateTransfer(createTransfer.FromBranchID, createTransfer.ToBranchID, createTransfer.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !inScope(c, createTransfer.FromBranchID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if err = notCarriedTo(ctx, h.strg, createTransfer.ToBranchID, createTransfer.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	createTransfer.IncrementID, err = h.nextNumber(ctx, "transfer", h.cfg.TransferNumbering, createTransfer.FromBranchID)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	createTransfer.UserID = c.GetString(ctxUserID)
+
+	resp, err := h.strg.Transfer().Create(ctx, &createTransfer)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+func validateTransfer(fromBranchID, toBranchID string, lines []*models.CreateTransferLine) error {
+
+	if !helpers.IsValidUUID(fromBranchID) || !helpers.IsValidUUID(toBranchID) {
+		return apperror.New(apperror.Validation, "from_branch_id and to_branch_id must be uuid")
+	}
+
+	if fromBranchID == toBranchID {
+		return apperror.New(apperror.Validation, "a transfer goes to another branch").WithField("to_branch_id", "must differ from from_branch_id")
+	}
+
+	if len(lines) == 0 {
+		return apperror.New(apperror.Validation, "a transfer needs lines").WithField("lines", "must not be empty")
+	}
+
+	var products = map[string]bool{}
+	for _, line := range lines {
+		if !helpers.IsValidUUID(line.ProductID) {
+			return apperror.New(apperror.Validation, "product_id is not uuid").WithField("product_id", "must be uuid")
+		}
+
+		if line.Quantity <= 0 {
+			return apperror.New(apperror.Validation, "quantity must be positive").WithField("quantity", "must be positive")
+		}
+
+		if products[line.ProductID] {
+			return apperror.New(apperror.Validation, "product "+line.ProductID+" is listed twice").WithField("product_id", "must be unique")
+		}
+		products[line.ProductID] = true
+	}
+
+	return nil
+}
+
+// notCarriedTo is the validation error of the first line the receiving
+// branch does not carry, nil when it carries them all.
+func notCarriedTo(ctx context.Context, strg storage.StorageI, toBranchID string, lines []*models.CreateTransferLine) error {
+
+	for _, line := range lines {
+		if err := notCarried(ctx, strg, "product_id", toBranchID, line.ProductID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// @Summary Get a Transfer by ID
+// @Description Get Transfer details with its lines.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} models.Transfer "Transfer details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Transfer not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/{id} [get]
+func (h *Handler) GetByIDTransfer(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if scope := branchScope(c); len(scope) > 0 && resp.FromBranchID != scope && !inScope(c, resp.ToBranchID) {
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List Transfer
+// @Description Get transfers, newest first.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param branch_id query string false "sent from or to this branch"
+// @Param from_branch_id query string false "from_branch_id, comma separated for several"
+// @Param to_branch_id query string false "to_branch_id, comma separated for several"
+// @Param status query string false "draft, sent or received, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListTransferResponse "Transfers"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer [get]
+func (h *Handler) GetListTransfer(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.TransferFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Transfer().GetList(ctx, &models.GetListTransferRequest{
+		Limit:    limit,
+		Offset:   offset,
+		Search:   c.Query("search"),
+		Filter:   filter,
+		BranchID: branchID,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update Transfer
+// @Description Change the destination and the lines of a draft transfer.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param object body models.UpdateTransfer true "models.UpdateTransfer"
+// @Param id path string true "id"
+// @Success 202 {object} models.Transfer "Transfer details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Transfer not found"
+// @Failure 409 {object} ErrorResponse "Transfer is not a draft"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/{id} [put]
+func (h *Handler) UpdateTransfer(c *gin.Context) {
+
+	var updateTransfer models.UpdateTransfer
+
+	err := c.ShouldBindJSON(&updateTransfer)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	transfer, ok := h.draftTransfer(ctx, c, id)
+	if !ok {
+		return
+	}
+
+	if updateTransfer.Lines == nil {
+		updateTransfer.Lines = []*models.CreateTransferLine{}
+		for _, line := range transfer.Lines {
+			updateTransfer.Lines = append(updateTransfer.Lines, &models.CreateTransferLine{ProductID: line.ProductID, Quantity: line.Quantity})
+		}
+	}
+
+	if err = validateTransfer(transfer.FromBranchID, updateTransfer.ToBranchID, updateTransfer.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = notCarriedTo(ctx, h.strg, updateTransfer.ToBranchID, updateTransfer.Lines); err != nil {
+		handleResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	updateTransfer.Id = id
+
+	rowsAffected, err := h.strg.Transfer().Update(ctx, &updateTransfer)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusConflict, notDraftTransfer(transfer))
+		return
+	}
+
+	resp, err := h.strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete Transfer
+// @Description Delete a draft transfer.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 409 {object} ErrorResponse "Transfer is not a draft"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/{id} [delete]
+func (h *Handler) DeleteTransfer(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	if _, ok := h.draftTransfer(ctx, c, id); !ok {
+		return
+	}
+
+	err := h.strg.Transfer().Delete(ctx, &models.TransferPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// draftTransfer answers and returns false unless the transfer is a draft
+// sent from the user's branch.
+func (h *Handler) draftTransfer(ctx context.Context, c *gin.Context, id string) (*models.Transfer, bool) {
+
+	transfer, err := h.strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !inScope(c, transfer.FromBranchID) {
+		return nil, false
+	}
+
+	if transfer.Status != models.TransferDraft {
+		handleResponse(c, http.StatusConflict, notDraftTransfer(transfer))
+		return nil, false
+	}
+
+	return transfer, true
+}
+
+func notDraftTransfer(transfer *models.Transfer) *apperror.Error {
+	return apperror.New(apperror.Conflict, "transfer "+transfer.IncrementID+" is "+transfer.Status).WithField("status", "must be draft")
+}
+
+// @Summary Send a Transfer
+// @Description Take the lines of a draft transfer out of the remainder of the sending branch. They stay in transit until received.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} models.Transfer "Sent Transfer"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Transfer not found"
+// @Failure 409 {object} ErrorResponse "Transfer is not a draft"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/{id}/send [post]
+func (h *Handler) SendTransfer(c *gin.Context) {
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	var resp *models.Transfer
+
+	err := h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		transfer, err := tx.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if scope := branchScope(c); len(scope) > 0 && scope != transfer.FromBranchID {
+			return errOutOfScope
+		}
+
+		rowsAffected, err := tx.Transfer().SetStatus(ctx, &models.TransferStatus{Id: id, From: models.TransferDraft, To: models.TransferSent})
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return notDraftTransfer(transfer)
+		}
+
+		for _, line := range transfer.Lines {
+
+			stock, err := stockOf(ctx, tx, transfer.FromBranchID, line.ProductID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: product %s", storage.ErrNotEnoughQuantity, line.ProductID)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+				BranchID:       transfer.FromBranchID,
+				ProductID:      line.ProductID,
+				Quantity:       -line.Quantity,
+				Type:           models.MovementTransfer,
+				DocumentID:     transfer.Id,
+				DocumentNumber: transfer.IncrementID,
+				UserID:         c.GetString(ctxUserID),
+			})
+			if err != nil {
+				return err
+			}
+
+			// the goods keep their cost on the way
+			_, err = tx.Transfer().UpdateLine(ctx, &models.UpdateTransferLine{Id: line.Id, ComingPrice: stock.ComingPrice})
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = tx.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+		return err
+	})
+	if errors.Is(err, errOutOfScope) {
+		handleResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, storage.ErrNotEnoughQuantity) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Receive a Transfer
+// @Description Add what arrived of a sent transfer to the remainder of the receiving branch at the carried coming_price. Lines left out arrived in full, the rest is recorded as shortage.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Param object body models.ReceiveTransfer true "Received quantities"
+// @Success 200 {object} models.Transfer "Received Transfer"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "Transfer not found"
+// @Failure 409 {object} ErrorResponse "Transfer is not sent"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/{id}/receive [post]
+func (h *Handler) ReceiveTransfer(c *gin.Context) {
+
+	var receiveTransfer models.ReceiveTransfer
+	err := c.ShouldBindJSON(&receiveTransfer)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	var received = map[string]int{}
+	for _, line := range receiveTransfer.Lines {
+		if line.Quantity < 0 {
+			handleResponse(c, http.StatusBadRequest, apperror.New(apperror.Validation, "quantity must not be negative").WithField("quantity", "must not be negative"))
+			return
+		}
+		received[line.LineID] = line.Quantity
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	var resp *models.Transfer
+
+	err = h.strg.WithTx(ctx, func(tx storage.StorageI) error {
+
+		transfer, err := tx.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+
+		if scope := branchScope(c); len(scope) > 0 && scope != transfer.ToBranchID {
+			return errOutOfScope
+		}
+
+		rowsAffected, err := tx.Transfer().SetStatus(ctx, &models.TransferStatus{Id: id, From: models.TransferSent, To: models.TransferReceived})
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return apperror.New(apperror.Conflict, "transfer "+transfer.IncrementID+" is "+transfer.Status).WithField("status", "must be sent")
+		}
+
+		var lines = map[string]bool{}
+		for _, line := range transfer.Lines {
+			lines[line.Id] = true
+		}
+
+		for lineID := range received {
+			if !lines[lineID] {
+				return apperror.New(apperror.Validation, "line "+lineID+" is not a line of the transfer").WithField("line_id", "must be a line of the transfer")
+			}
+		}
+
+		// the goods arrive in the lots they were sent from, expiry and all
+		sent, err := documentLots(ctx, tx, transfer.FromBranchID, transfer.Id)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range transfer.Lines {
+
+			quantity, ok := received[line.Id]
+			if !ok {
+				quantity = line.Quantity
+			}
+
+			if quantity > line.Quantity {
+				return apperror.New(apperror.Validation, "more arrived than was sent of line "+line.Id).WithField("quantity", "must not exceed the sent quantity")
+			}
+
+			_, err = tx.Transfer().UpdateLine(ctx, &models.UpdateTransferLine{Id: line.Id, ComingPrice: line.ComingPrice, ReceivedQuantity: &quantity})
+			if err != nil {
+				return err
+			}
+
+			if quantity == 0 {
+				continue
+			}
+
+			// the branch may have dropped the product while it was on the way
+			if err = notCarried(ctx, tx, "product_id", transfer.ToBranchID, line.ProductID); err != nil {
+				return err
+			}
+
+			product, err := tx.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: line.ProductID})
+			if err != nil {
+				return err
+			}
+
+			// the stock is sold at the price of the receiving branch
+			salePrice, err := tx.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: transfer.ToBranchID, ProductID: line.ProductID})
+			if err != nil {
+				return err
+			}
+
+			for _, lot := range takeLots(sent[line.ProductID], quantity) {
+				_, err = tx.StockMovement().Create(ctx, &models.CreateStockMovement{
+					BranchID:       transfer.ToBranchID,
+					ProductID:      line.ProductID,
+					Quantity:       lot.Quantity,
+					Type:           models.MovementTransfer,
+					DocumentID:     transfer.Id,
+					DocumentNumber: transfer.IncrementID,
+					UserID:         c.GetString(ctxUserID),
+					LotNumber:      lot.LotNumber,
+					ExpiryDate:     lot.ExpiryDate,
+					Name:           product.Name,
+					ComingPrice:    line.ComingPrice,
+					SalePrice:      salePrice,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		resp, err = tx.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: id})
+		return err
+	})
+	if errors.Is(err, errOutOfScope) {
+		handleResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Goods in transit
+// @Description Lines of sent transfers that are not received yet, the oldest first.
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param branch_id query string false "sent from or to this branch"
+// @Success 200 {object} models.InTransitResponse "Goods in transit"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /transfer/in_transit [get]
+func (h *Handler) GetInTransit(c *gin.Context) {
+
+	branchID, ok := queryBranch(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.Transfer().InTransit(ctx, &models.InTransitRequest{BranchID: branchID})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
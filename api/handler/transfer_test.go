@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/storage/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTransfer(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		ctx  = context.Background()
+		strg = memory.NewStore()
+		h    = NewHandler(&config.Config{}, strg)
+		r    = gin.New()
+	)
+
+	r.POST("/transfer", h.CreateTransfer)
+	r.POST("/transfer/:id/send", h.SendTransfer)
+	r.POST("/transfer/:id/receive", h.ReceiveTransfer)
+
+	from, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	to, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Olmazor"})
+	product, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Analgin", Price: 3000, BranchID: from.Id})
+	_, _ = strg.StockMovement().Create(ctx, &models.CreateStockMovement{BranchID: from.Id, ProductID: product.Id, Quantity: 3, Type: models.MovementReceipt, Name: product.Name, ComingPrice: 2500})
+
+	transfer, _ := strg.Transfer().Create(ctx, &models.CreateTransfer{
+		IncrementID:  "T-0000001",
+		FromBranchID: from.Id,
+		ToBranchID:   to.Id,
+		Lines:        []*models.CreateTransferLine{{ProductID: product.Id, Quantity: 5}},
+	})
+
+	serve := func(path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+		return w
+	}
+
+	stock := func(branchID string) *models.Remainder {
+		remainder, _ := stockOf(ctx, strg, branchID, product.Id)
+		return remainder
+	}
+
+	if w := serve("/transfer", models.CreateTransfer{
+		FromBranchID: from.Id,
+		ToBranchID:   to.Id,
+		Lines:        []*models.CreateTransferLine{{ProductID: product.Id, Quantity: 1}},
+	}); w.Code != http.StatusBadRequest {
+		t.Fatalf("transfer a product the branch does not carry: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve("/transfer/"+transfer.Id+"/send", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("send more than in stock: status %d: %s", w.Code, w.Body.String())
+	}
+
+	_, _ = strg.StockMovement().Create(ctx, &models.CreateStockMovement{BranchID: from.Id, ProductID: product.Id, Quantity: 7, Type: models.MovementReceipt})
+
+	if w := serve("/transfer/"+transfer.Id+"/send", nil); w.Code != http.StatusOK || stock(from.Id).Quantity != 5 {
+		t.Fatalf("send: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if transit, _ := strg.Transfer().InTransit(ctx, &models.InTransitRequest{BranchID: to.Id}); len(transit.Lines) != 1 || transit.Lines[0].Quantity != 5 {
+		t.Fatalf("in transit: %+v", transit.Lines)
+	}
+
+	line := transfer.Lines[0].Id
+	if w := serve("/transfer/"+transfer.Id+"/receive", models.ReceiveTransfer{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("receive a product the branch does not carry: status %d: %s", w.Code, w.Body.String())
+	}
+
+	_, _ = strg.BranchProduct().Set(ctx, &models.SetBranchProduct{BranchID: to.Id, ProductID: product.Id})
+
+	if w := serve("/transfer/"+transfer.Id+"/receive", models.ReceiveTransfer{Lines: []*models.ReceiveTransferLine{{LineID: line, Quantity: 6}}}); w.Code != http.StatusBadRequest {
+		t.Fatalf("receive more than sent: status %d: %s", w.Code, w.Body.String())
+	}
+
+	w := serve("/transfer/"+transfer.Id+"/receive", models.ReceiveTransfer{Lines: []*models.ReceiveTransferLine{{LineID: line, Quantity: 4}}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("receive: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if remainder := stock(to.Id); remainder.Quantity != 4 || remainder.ComingPrice != 2500 || remainder.SalePrice != 3000 {
+		t.Fatalf("received stock %+v", remainder)
+	}
+
+	received, _ := strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: transfer.Id})
+	if received.Status != models.TransferReceived || received.Lines[0].Shortage != 1 {
+		t.Fatalf("received transfer %+v %+v", received, received.Lines[0])
+	}
+
+	if w := serve("/transfer/"+transfer.Id+"/receive", models.ReceiveTransfer{}); w.Code != http.StatusConflict {
+		t.Fatalf("receive twice: status %d: %s", w.Code, w.Body.String())
+	}
+}
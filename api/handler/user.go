@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/helpers"
+	"market_system/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary create a User
+// @Description Create User, the password is stored as a bcrypt hash.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param object body models.CreateUser true "User"
+// @Success 201 {object} models.User "User details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /user [post]
+func (h *Handler) CreateUser(c *gin.Context) {
+
+	var createUser models.CreateUser
+	err := c.ShouldBindJSON(&createUser)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	if len(createUser.Login) == 0 || len(createUser.Password) == 0 {
+		handleResponse(c, http.StatusBadRequest, "login and password are required")
+		return
+	}
+
+	if err = validateUser(createUser.ClientType, createUser.BranchID); err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	createUser.Password, err = security.HashPassword(createUser.Password)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.User().Create(ctx, &createUser)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// @Summary Get a User by ID
+// @Description Get User details by its ID.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User "User details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /user/{id} [get]
+func (h *Handler) GetByIDUser(c *gin.Context) {
+
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.User().GetByID(ctx, &models.UserPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Get List User
+// @Description Get List User details.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param search query string false "search"
+// @Param client_type query string false "client_type, comma separated for several"
+// @Param branch_id query string false "branch_id, comma separated for several"
+// @Param created_from query string false "created_at from, date or RFC3339"
+// @Param created_to query string false "created_at to, date or RFC3339"
+// @Success 200 {object} models.GetListUserResponse "User details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /user [get]
+func (h *Handler) GetListUser(c *gin.Context) {
+
+	limit, err := getIntegerOrDefaultValue(c.Query("limit"), 10)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query limit")
+		return
+	}
+
+	offset, err := getIntegerOrDefaultValue(c.Query("offset"), 0)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "invalid query offset")
+		return
+	}
+
+	filter, err := getListFilter(c, models.UserFilterSpec)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	resp, err := h.strg.User().GetList(ctx, &models.GetListUserRequest{
+		Limit:  limit,
+		Offset: offset,
+		Search: c.Query("search"),
+		Filter: filter,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// @Summary Update User
+// @Description Update User, an empty password keeps the current one.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param object body models.UpdateUser true "models.UpdateUser"
+// @Param id path string true "id"
+// @Success 202 {object} models.User "User details"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /user/{id} [put]
+func (h *Handler) UpdateUser(c *gin.Context) {
+
+	var updateUser models.UpdateUser
+
+	err := c.ShouldBindJSON(&updateUser)
+	if err != nil {
+		handleResponse(c, 400, "ShouldBindJSON err:"+err.Error())
+		return
+	}
+
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	if len(updateUser.Login) == 0 {
+		handleResponse(c, http.StatusBadRequest, "login is required")
+		return
+	}
+
+	if err = validateUser(updateUser.ClientType, updateUser.BranchID); err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(updateUser.Password) > 0 {
+		updateUser.Password, err = security.HashPassword(updateUser.Password)
+		if err != nil {
+			handleResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	updateUser.Id = id
+
+	rowsAffected, err := h.strg.User().Update(ctx, &updateUser)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		handleResponse(c, http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.strg.User().GetByID(ctx, &models.UserPrimaryKey{Id: updateUser.Id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// @Summary Delete User
+// @Description Delete User
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response "deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /user/{id} [delete]
+func (h *Handler) DeleteUser(c *gin.Context) {
+	var id = c.Param("id")
+
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.CtxTimeout)
+	defer cancel()
+
+	err := h.strg.User().Delete(ctx, &models.UserPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	handleResponse(c, http.StatusOK, "deleted")
+}
+
+// validateUser checks the client type, branch users must belong to a branch.
+func validateUser(clientType, branchID string) error {
+
+	if !contains(config.ClientTypes, clientType) {
+		return errors.New("client_type must be one of SUPER-ADMIN, CASSIER, BRANCH")
+	}
+
+	if clientType != config.SuperAdmin && !helpers.IsValidUUID(branchID) {
+		return errors.New("branch_id is required for " + clientType)
+	}
+
+	if len(branchID) > 0 && !helpers.IsValidUUID(branchID) {
+		return errors.New("branch_id is not uuid")
+	}
+
+	return nil
+}
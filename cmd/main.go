@@ -1,27 +1,48 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"market_system/api"
 	"market_system/config"
+	"market_system/models"
+	"market_system/pkg/security"
+	"market_system/storage"
+	"market_system/storage/memory"
 	"market_system/storage/postgres"
-	
 )
 
 func main() {
 
 	var cfg = config.Load()
+
+	// the admin password only seeds superadmin, keep it out of the log
+	adminPassword := cfg.AdminPassword
+	cfg.AdminPassword = ""
 	fmt.Println(cfg)
-	pgStorage, err := postgres.NewConnectionPostgres(&cfg)
-	if err != nil {
+
+	var strg storage.StorageI
+	if cfg.StorageDriver == "memory" {
+		strg = memory.NewStore()
+	} else {
+		pgStorage, err := postgres.NewConnectionPostgres(&cfg)
+		if err != nil {
+			panic(err)
+		}
+		strg = pgStorage
+	}
+
+	if err := seedSuperAdmin(strg, adminPassword); err != nil {
 		panic(err)
 	}
 
-	
+	go applyPriceChanges(strg, cfg.PriceScheduleInterval)
 
 	// gin.SetMode(gin.ReleaseMode)
 
@@ -29,10 +50,64 @@ func main() {
 
 	r.Use(gin.Logger(), gin.Recovery())
 
-	api.SetUpApi(r, &cfg, pgStorage)
+	api.SetUpApi(r, &cfg, strg)
 
 	log.Println("Listening:", cfg.ServiceHost+cfg.ServiceHTTPPort, "...")
 	if err := r.Run(cfg.ServiceHost + cfg.ServiceHTTPPort); err != nil {
 		panic("Listent and service panic:" + err.Error())
 	}
 }
+
+// seedSuperAdmin adds the superadmin user with password when there is no
+// SUPER-ADMIN yet, so an install never starts with a well-known password.
+func seedSuperAdmin(strg storage.StorageI, password string) error {
+
+	var ctx = context.Background()
+
+	admins, err := strg.User().GetList(ctx, &models.GetListUserRequest{
+		Limit:  1,
+		Filter: models.Filter{Fields: map[string][]string{"client_type": {config.SuperAdmin}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if admins.Count > 0 {
+		return nil
+	}
+
+	if len(password) == 0 {
+		return errors.New("there is no SUPER-ADMIN user, set ADMIN_PASSWORD to add superadmin")
+	}
+
+	hash, err := security.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = strg.User().Create(ctx, &models.CreateUser{
+		Login:      "superadmin",
+		Password:   hash,
+		ClientType: config.SuperAdmin,
+	})
+	return err
+}
+
+// applyPriceChanges applies the scheduled price changes as they become due.
+func applyPriceChanges(strg storage.StorageI, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := strg.PriceChange().ApplyDue(context.Background(), time.Now())
+		switch {
+		case err != nil:
+			log.Println(config.Error, "applying price changes:", err)
+		case applied > 0:
+			log.Println(config.Info, "applied", applied, "price changes")
+		}
+
+		<-ticker.C
+	}
+}
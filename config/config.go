@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -14,7 +15,17 @@ const (
 	Log   = "log >>> "
 )
 
+// Numbering configures how document numbers such as S-0000001 are made.
+type Numbering struct {
+	Prefix      string
+	Width       int
+	PerBranch   bool
+	YearlyReset bool
+}
+
 type Config struct {
+	StorageDriver string
+
 	PostgresHost          string
 	PostgresUser          string
 	PostgresDatabase      string
@@ -31,6 +42,27 @@ type Config struct {
 	ServiceHTTPPort string
 
 	SecretKey string
+
+	// AdminPassword is the password of the superadmin user added on start
+	// when there is no SUPER-ADMIN yet, it is not used afterwards.
+	AdminPassword string
+
+	SaleNumbering          Numbering
+	SaleReturnNumbering    Numbering
+	ComingNumbering        Numbering
+	TransferNumbering      Numbering
+	StockTakeNumbering     Numbering
+	PurchaseOrderNumbering Numbering
+	RepricingNumbering     Numbering
+
+	// ReorderWindowDays of sales give the average daily sales of reorder
+	// suggestions, ReorderLeadDays is how long an order takes to arrive.
+	ReorderWindowDays int
+	ReorderLeadDays   int
+
+	// PriceScheduleInterval is how often scheduled price changes that became
+	// due are applied.
+	PriceScheduleInterval time.Duration
 }
 
 func Load() Config {
@@ -44,6 +76,8 @@ func Load() Config {
 	cfg.ServiceHost = cast.ToString(getValueOrDefault("SERVICE_HOST", "localhost"))
 	cfg.ServiceHTTPPort = cast.ToString(getValueOrDefault("SERVICE_HTTP_PORT", ":8080"))
 
+	cfg.StorageDriver = cast.ToString(getValueOrDefault("STORAGE_DRIVER", "postgres"))
+
 	cfg.PostgresHost = cast.ToString(getValueOrDefault("POSTGRES_HOST", "localhost"))
 	cfg.PostgresUser = cast.ToString(getValueOrDefault("POSTGRES_USER", "zafar"))
 	cfg.PostgresDatabase = cast.ToString(getValueOrDefault("POSTGRES_DATABASE", "clinic_exam"))
@@ -52,10 +86,33 @@ func Load() Config {
 	cfg.PostgresMaxConnection = cast.ToInt32(getValueOrDefault("POSTGRES_MAX_CONN", 30))
 
 	cfg.SecretKey = cast.ToString(getValueOrDefault("SECRET_KEY", "q6T6LlwdRk"))
+	cfg.AdminPassword = cast.ToString(getValueOrDefault("ADMIN_PASSWORD", ""))
+
+	cfg.SaleNumbering = loadNumbering("SALE", "S-")
+	cfg.SaleReturnNumbering = loadNumbering("SALE_RETURN", "R-")
+	cfg.ComingNumbering = loadNumbering("COMING", "C-")
+	cfg.TransferNumbering = loadNumbering("TRANSFER", "T-")
+	cfg.StockTakeNumbering = loadNumbering("STOCK_TAKE", "I-")
+	cfg.PurchaseOrderNumbering = loadNumbering("PURCHASE_ORDER", "P-")
+	cfg.RepricingNumbering = loadNumbering("REPRICING", "RP-")
+
+	cfg.ReorderWindowDays = cast.ToInt(getValueOrDefault("REORDER_WINDOW_DAYS", 30))
+	cfg.ReorderLeadDays = cast.ToInt(getValueOrDefault("REORDER_LEAD_DAYS", 7))
+
+	cfg.PriceScheduleInterval = time.Duration(cast.ToInt(getValueOrDefault("PRICE_SCHEDULE_INTERVAL_SECONDS", 60))) * time.Second
 
 	return cfg
 }
 
+func loadNumbering(document string, prefix string) Numbering {
+	return Numbering{
+		Prefix:      cast.ToString(getValueOrDefault(document+"_NUMBER_PREFIX", prefix)),
+		Width:       cast.ToInt(getValueOrDefault(document+"_NUMBER_WIDTH", 7)),
+		PerBranch:   cast.ToBool(getValueOrDefault(document+"_NUMBER_PER_BRANCH", false)),
+		YearlyReset: cast.ToBool(getValueOrDefault(document+"_NUMBER_YEARLY_RESET", false)),
+	}
+}
+
 func getValueOrDefault(key string, defaultValue interface{}) interface{} {
 
 	val, exists := os.LookupEnv(key)
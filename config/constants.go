@@ -8,4 +8,10 @@ const (
 	ExpiredTime = time.Hour * 24
 )
 
-var ClientTypes = []string{"SUPER-ADMIN", "CASSIER", "BRANCH"}
+const (
+	SuperAdmin = "SUPER-ADMIN"
+	Cassier    = "CASSIER"
+	Branch     = "BRANCH"
+)
+
+var ClientTypes = []string{SuperAdmin, Cassier, Branch}
@@ -0,0 +1,171 @@
+package config
+
+// Permissions lists the routes each client type may call as "METHOD /path",
+// with the path as registered in api.SetUpApi. "*" allows every route.
+// BRANCH and CASSIER users are further limited to their own branch.
+var Permissions = map[string][]string{
+	SuperAdmin: {"*"},
+	Branch: {
+		"GET /branch",
+		"GET /branch/:id",
+		"GET /branch_doc",
+		"GET /registration",
+		"GET /debt_aging",
+
+		"GET /client",
+		"GET /client/:id",
+		"POST /client",
+		"PUT /client/:id",
+		"GET /client/:id/statement",
+
+		"GET /product",
+		"GET /product/:id",
+		"GET /product/:id/movements",
+		"GET /product/by-barcode/:code",
+
+		"GET /category",
+		"GET /category/:id",
+		"GET /category/tree",
+		"GET /category/sales",
+		"GET /category/stock",
+		"GET /branch_product",
+		"GET /price_change",
+		"GET /price_change/:id",
+		"GET /price_change/as_of",
+		"GET /repricing",
+		"GET /repricing/:id",
+		"GET /promotion",
+		"GET /promotion/:id",
+		"GET /promotion/report",
+
+		"GET /coming",
+		"GET /coming/:id",
+		"POST /coming",
+		"PUT /coming/:id",
+		"DELETE /coming/:id",
+		"POST /coming/:id/finish",
+		"POST /coming/:id/reverse",
+		"GET /coming/:id/events",
+
+		"GET /supplier",
+		"GET /supplier/:id",
+		"GET /supplier/:id/statement",
+		"GET /supplier_payable",
+		"GET /supplier_payment",
+		"GET /supplier_payment/:id",
+		"POST /supplier_payment",
+
+		"GET /purchase_order",
+		"GET /purchase_order/:id",
+		"POST /purchase_order",
+		"PUT /purchase_order/:id",
+		"DELETE /purchase_order/:id",
+		"POST /purchase_order/:id/coming",
+
+		"GET /stock_level",
+		"PUT /stock_level",
+		"DELETE /stock_level",
+		"GET /reorder",
+		"POST /reorder/purchase_order",
+		"POST /reorder/coming",
+
+		"GET /picking_list",
+		"GET /picking_list/:id",
+		"POST /picking_list",
+		"PUT /picking_list/:id",
+		"DELETE /picking_list/:id",
+
+		"GET /remainder",
+		"GET /remainder/:id",
+		"GET /remainder/expiring",
+		"GET /remainder/low",
+		"POST /remainder",
+		"PUT /remainder/:id",
+		"DELETE /remainder/:id",
+
+		"GET /stock_movement",
+		"GET /stock_movement/:id",
+		"POST /stock_movement",
+
+		"GET /transfer",
+		"GET /transfer/:id",
+		"GET /transfer/in_transit",
+		"POST /transfer",
+		"PUT /transfer/:id",
+		"DELETE /transfer/:id",
+		"POST /transfer/:id/send",
+		"POST /transfer/:id/receive",
+
+		"GET /stock_take",
+		"GET /stock_take/:id",
+		"POST /stock_take",
+		"DELETE /stock_take/:id",
+		"PUT /stock_take/:id/counts",
+		"POST /stock_take/:id/import",
+		"POST /stock_take/:id/approve",
+
+		"GET /sale",
+		"GET /sale/:id",
+		"GET /sale/:id/lines",
+		"POST /sale",
+		"PUT /sale/:id",
+		"DELETE /sale/:id",
+		"POST /checkout",
+
+		"GET /sale_return",
+		"GET /sale_return/:id",
+		"POST /sale_return",
+
+		"GET /saleproduct",
+		"GET /saleproduct/:id",
+		"POST /saleproduct",
+
+		"GET /payment",
+		"GET /payment/:id",
+		"POST /payment",
+		"PUT /make_pay",
+	},
+	Cassier: {
+		"GET /branch/:id",
+
+		"GET /client",
+		"GET /client/:id",
+		"POST /client",
+		"GET /client/:id/statement",
+
+		"GET /product",
+		"GET /product/:id",
+		"GET /product/by-barcode/:code",
+
+		"GET /category",
+		"GET /category/:id",
+		"GET /category/tree",
+		"GET /branch_product",
+		"GET /price_change/as_of",
+		"GET /promotion",
+		"GET /promotion/:id",
+
+		"GET /remainder",
+		"GET /remainder/:id",
+		"GET /remainder/expiring",
+		"GET /remainder/low",
+
+		"GET /sale",
+		"GET /sale/:id",
+		"GET /sale/:id/lines",
+		"POST /sale",
+		"POST /checkout",
+
+		"GET /sale_return",
+		"GET /sale_return/:id",
+		"POST /sale_return",
+
+		"GET /saleproduct",
+		"GET /saleproduct/:id",
+
+		"GET /payment",
+		"GET /payment/:id",
+		"POST /payment",
+		"PUT /make_pay",
+	},
+}
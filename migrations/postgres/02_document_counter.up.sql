@@ -0,0 +1,87 @@
+CREATE TABLE "document_counter" (
+    "document" VARCHAR(24) NOT NULL,
+    -- branch id for per-branch counters, empty for a shared one
+    "scope" VARCHAR(36) NOT NULL DEFAULT '',
+    -- 0 when the counter is never reset
+    "year" INT NOT NULL DEFAULT 0,
+    "value" BIGINT NOT NULL DEFAULT 0,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("document", "scope", "year")
+);
+
+ALTER TABLE "sale" ALTER COLUMN "increment_id" TYPE VARCHAR(48);
+ALTER TABLE "sale_product" ALTER COLUMN "sale_increment_id" TYPE VARCHAR(48);
+ALTER TABLE "coming" ALTER COLUMN "increment_id" TYPE VARCHAR(48);
+ALTER TABLE "picking_list" ALTER COLUMN "coming_increment_id" TYPE VARCHAR(48);
+
+-- the old SELECT MAX numbering could hand a number out twice, keep it on the
+-- oldest document and move the others after the last number
+WITH "last" AS (
+    SELECT COALESCE(MAX(SUBSTRING("increment_id" FROM 3)::BIGINT), 0) AS "value"
+    FROM "sale" WHERE "increment_id" ~ '^S-[0-9]+$'
+), "numbered" AS (
+    SELECT "id", "created_at",
+        ROW_NUMBER() OVER (PARTITION BY "branch_id", "increment_id" ORDER BY "created_at", "id") AS "n"
+    FROM "sale" WHERE "increment_id" IS NOT NULL
+), "duplicate" AS (
+    SELECT "id", ROW_NUMBER() OVER (ORDER BY "created_at", "id") AS "n"
+    FROM "numbered" WHERE "n" > 1
+)
+UPDATE "sale" SET "increment_id" = 'S-' || LPAD(("last"."value" + "duplicate"."n")::TEXT, 7, '0')
+FROM "last", "duplicate"
+WHERE "sale"."id" = "duplicate"."id";
+
+UPDATE "sale_product" SET "sale_increment_id" = "sale"."increment_id"
+FROM "sale"
+WHERE "sale_product"."sale_id" = "sale"."id" AND "sale_product"."sale_increment_id" IS DISTINCT FROM "sale"."increment_id";
+
+WITH "last" AS (
+    SELECT COALESCE(MAX(SUBSTRING("increment_id" FROM 3)::BIGINT), 0) AS "value"
+    FROM "coming" WHERE "increment_id" ~ '^C-[0-9]+$'
+), "numbered" AS (
+    SELECT "id", "created_at",
+        ROW_NUMBER() OVER (PARTITION BY "branch_id", "increment_id" ORDER BY "created_at", "id") AS "n"
+    FROM "coming" WHERE "increment_id" IS NOT NULL
+), "duplicate" AS (
+    SELECT "id", ROW_NUMBER() OVER (ORDER BY "created_at", "id") AS "n"
+    FROM "numbered" WHERE "n" > 1
+)
+UPDATE "coming" SET "increment_id" = 'C-' || LPAD(("last"."value" + "duplicate"."n")::TEXT, 7, '0')
+FROM "last", "duplicate"
+WHERE "coming"."id" = "duplicate"."id";
+
+UPDATE "picking_list" SET "coming_increment_id" = "coming"."increment_id"
+FROM "coming"
+WHERE "picking_list"."coming_id" = "coming"."id" AND "picking_list"."coming_increment_id" IS DISTINCT FROM "coming"."increment_id";
+
+-- numbers are unique per branch, which also covers a shared counter
+ALTER TABLE "sale" ADD CONSTRAINT "sale_branch_increment_id_key" UNIQUE ("branch_id", "increment_id");
+ALTER TABLE "coming" ADD CONSTRAINT "coming_branch_increment_id_key" UNIQUE ("branch_id", "increment_id");
+
+-- continue the numbers already handed out, for the shared counter and for
+-- every branch, so turning on *_NUMBER_PER_BRANCH does not start over; a
+-- number with a year (S-2024-0000001) seeds the counter of that year, the
+-- old ones have none and seed year 0
+INSERT INTO "document_counter" ("document", "scope", "year", "value")
+SELECT 'sale', COALESCE("branch_id"::TEXT, ''), "year", MAX("value")
+FROM (
+    SELECT
+        "branch_id",
+        COALESCE(SUBSTRING("increment_id" FROM '^S-([0-9]{4})-')::INT, 0) AS "year",
+        SUBSTRING("increment_id" FROM '([0-9]+)$')::BIGINT AS "value"
+    FROM "sale" WHERE "increment_id" ~ '^S-([0-9]{4}-)?[0-9]+$'
+) AS "number"
+GROUP BY GROUPING SETS (("year"), ("branch_id", "year"))
+HAVING GROUPING("branch_id") = 1 OR "branch_id" IS NOT NULL;
+
+INSERT INTO "document_counter" ("document", "scope", "year", "value")
+SELECT 'coming', COALESCE("branch_id"::TEXT, ''), "year", MAX("value")
+FROM (
+    SELECT
+        "branch_id",
+        COALESCE(SUBSTRING("increment_id" FROM '^C-([0-9]{4})-')::INT, 0) AS "year",
+        SUBSTRING("increment_id" FROM '([0-9]+)$')::BIGINT AS "value"
+    FROM "coming" WHERE "increment_id" ~ '^C-([0-9]{4}-)?[0-9]+$'
+) AS "number"
+GROUP BY GROUPING SETS (("year"), ("branch_id", "year"))
+HAVING GROUPING("branch_id") = 1 OR "branch_id" IS NOT NULL;
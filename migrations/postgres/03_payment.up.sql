@@ -0,0 +1,18 @@
+CREATE TABLE "payment" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "sale_id" UUID NOT NULL REFERENCES "sale"("id"),
+    -- refunds are recorded as negative payments
+    "amount" NUMERIC NOT NULL CHECK ("amount" <> 0),
+    "method" VARCHAR(16) NOT NULL CHECK ("method" IN ('cash', 'card', 'transfer')),
+    "cashier" VARCHAR(64),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "payment_sale_id_idx" ON "payment"("sale_id");
+
+-- carry over what MakePay stored directly on the sale
+INSERT INTO "payment" ("id", "sale_id", "amount", "method", "created_at")
+SELECT gen_random_uuid(), "id", "paid", 'cash', COALESCE("updated_at", "created_at")
+FROM "sale" WHERE "paid" > 0;
+
+UPDATE "sale" SET "paid" = COALESCE("paid", 0), "debt" = COALESCE("total_price", 0) - COALESCE("paid", 0);
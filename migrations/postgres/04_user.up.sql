@@ -0,0 +1,14 @@
+CREATE TABLE "user" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "first_name" VARCHAR(48),
+    "last_name" VARCHAR(48),
+    "login" VARCHAR(48) NOT NULL,
+    -- bcrypt hash, never the password itself
+    "password" VARCHAR(72) NOT NULL,
+    "client_type" VARCHAR(16) NOT NULL CHECK ("client_type" IN ('SUPER-ADMIN', 'CASSIER', 'BRANCH')),
+    "branch_id" UUID REFERENCES "branch"("id"),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "user_login_idx" ON "user"("login");
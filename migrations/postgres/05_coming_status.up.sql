@@ -0,0 +1,17 @@
+ALTER TABLE "coming"
+    ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'draft'
+    CHECK ("status" IN ('draft', 'finished', 'reversed'));
+
+-- comings made so far were posted to remainder line by line already
+UPDATE "coming" SET "status" = 'finished';
+
+CREATE TABLE "coming_event" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "coming_id" UUID NOT NULL REFERENCES "coming"("id"),
+    "action" VARCHAR(16) NOT NULL CHECK ("action" IN ('finish', 'reverse')),
+    "user_id" UUID REFERENCES "user"("id"),
+    "reason" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "coming_event_coming_id_idx" ON "coming_event"("coming_id");
@@ -0,0 +1,25 @@
+CREATE TABLE "stock_movement" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "product_id" UUID NOT NULL REFERENCES "product"("id"),
+    -- incoming stock is positive, outgoing negative
+    "quantity" INT NOT NULL CHECK ("quantity" <> 0),
+    "type" VARCHAR(16) NOT NULL
+    CHECK ("type" IN ('receipt', 'sale', 'return', 'transfer', 'write_off', 'adjustment')),
+    "document_id" UUID,
+    "document_number" VARCHAR(64),
+    "user_id" UUID REFERENCES "user"("id"),
+    "comment" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "stock_movement_product_idx" ON "stock_movement"("product_id", "branch_id", "created_at");
+CREATE INDEX "stock_movement_document_id_idx" ON "stock_movement"("document_id");
+
+-- open the journal with what remainder holds today
+INSERT INTO "stock_movement" ("id", "branch_id", "product_id", "quantity", "type", "comment", "created_at")
+SELECT gen_random_uuid(), "branch_id", "product_id", SUM("quantity"), 'adjustment', 'opening balance', MIN("created_at")
+FROM "remainder"
+WHERE "branch_id" IS NOT NULL AND "product_id" IS NOT NULL
+GROUP BY "branch_id", "product_id"
+HAVING SUM("quantity") <> 0;
@@ -0,0 +1,32 @@
+CREATE TABLE "transfer" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "increment_id" VARCHAR(64) NOT NULL,
+    "from_branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "to_branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "status" VARCHAR(16) NOT NULL DEFAULT 'draft' CHECK ("status" IN ('draft', 'sent', 'received')),
+    "user_id" UUID REFERENCES "user"("id"),
+    "sent_at" TIMESTAMP,
+    "received_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP,
+    CONSTRAINT "transfer_branch_check" CHECK ("from_branch_id" <> "to_branch_id")
+);
+
+CREATE INDEX "transfer_from_branch_id_idx" ON "transfer"("from_branch_id");
+CREATE INDEX "transfer_to_branch_id_idx" ON "transfer"("to_branch_id");
+
+CREATE TABLE "transfer_line" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "transfer_id" UUID NOT NULL REFERENCES "transfer"("id") ON DELETE CASCADE,
+    "product_id" UUID NOT NULL REFERENCES "product"("id"),
+    "quantity" INT NOT NULL,
+    -- the coming_price of the source remainder when the transfer was sent
+    "coming_price" NUMERIC NOT NULL DEFAULT 0,
+    -- NULL until the transfer is received, less than quantity on a shortage
+    "received_quantity" INT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "transfer_line_quantity_check" CHECK ("quantity" > 0),
+    CONSTRAINT "transfer_line_received_quantity_check" CHECK ("received_quantity" BETWEEN 0 AND "quantity")
+);
+
+CREATE INDEX "transfer_line_transfer_id_idx" ON "transfer_line"("transfer_id");
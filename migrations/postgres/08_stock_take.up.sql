@@ -0,0 +1,26 @@
+CREATE TABLE "stock_take" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "increment_id" VARCHAR(64) NOT NULL,
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "status" VARCHAR(16) NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'approved')),
+    "user_id" UUID REFERENCES "user"("id"),
+    "approved_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP
+);
+
+CREATE INDEX "stock_take_branch_id_idx" ON "stock_take"("branch_id");
+
+CREATE TABLE "stock_take_line" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "stock_take_id" UUID NOT NULL REFERENCES "stock_take"("id") ON DELETE CASCADE,
+    "product_id" UUID NOT NULL REFERENCES "product"("id"),
+    -- remainder quantity and coming_price when the stock-take was opened
+    "expected" INT NOT NULL,
+    "coming_price" NUMERIC NOT NULL DEFAULT 0,
+    -- NULL until counted
+    "counted" INT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "stock_take_line_counted_check" CHECK ("counted" >= 0),
+    UNIQUE ("stock_take_id", "product_id")
+);
@@ -0,0 +1,36 @@
+-- the value of the goods returned from a sale, debt is total_price - returned - paid
+ALTER TABLE "sale" ADD COLUMN "returned" NUMERIC NOT NULL DEFAULT 0;
+
+CREATE TABLE "sale_return" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "increment_id" VARCHAR(64) NOT NULL,
+    "sale_id" UUID NOT NULL REFERENCES "sale"("id"),
+    "branch_id" UUID REFERENCES "branch"("id"),
+    "client_id" UUID REFERENCES "client"("id"),
+    "total_price" NUMERIC NOT NULL,
+    -- what the return took off the debt of the sale, the rest was refunded
+    "debt_reduced" NUMERIC NOT NULL DEFAULT 0,
+    "refund" NUMERIC NOT NULL DEFAULT 0,
+    "refund_payment_id" UUID REFERENCES "payment"("id"),
+    "reason" TEXT,
+    "user_id" UUID REFERENCES "user"("id"),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "sale_return_sale_id_idx" ON "sale_return"("sale_id");
+CREATE INDEX "sale_return_branch_id_idx" ON "sale_return"("branch_id");
+
+CREATE TABLE "sale_return_line" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "sale_return_id" UUID NOT NULL REFERENCES "sale_return"("id") ON DELETE CASCADE,
+    "sale_product_id" UUID NOT NULL REFERENCES "sale_product"("id"),
+    "product_id" UUID REFERENCES "product"("id"),
+    "quantity" INT NOT NULL,
+    "price" NUMERIC NOT NULL,
+    "total_price" NUMERIC NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "sale_return_line_quantity_check" CHECK ("quantity" > 0)
+);
+
+CREATE INDEX "sale_return_line_sale_return_id_idx" ON "sale_return_line"("sale_return_id");
+CREATE INDEX "sale_return_line_sale_product_id_idx" ON "sale_return_line"("sale_product_id");
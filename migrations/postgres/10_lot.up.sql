@@ -0,0 +1,16 @@
+-- a remainder row is one lot of a product in a branch, stock received
+-- before lots were tracked stays in a row without lot_number
+ALTER TABLE "picking_list"
+    ADD COLUMN "lot_number" VARCHAR(64),
+    ADD COLUMN "expiry_date" DATE;
+
+ALTER TABLE "remainder"
+    ADD COLUMN "lot_number" VARCHAR(64),
+    ADD COLUMN "expiry_date" DATE;
+
+ALTER TABLE "stock_movement"
+    ADD COLUMN "lot_number" VARCHAR(64),
+    ADD COLUMN "expiry_date" DATE;
+
+CREATE INDEX "remainder_lot_idx" ON "remainder"("branch_id", "product_id", "expiry_date");
+CREATE INDEX "remainder_expiry_date_idx" ON "remainder"("expiry_date") WHERE "quantity" > 0;
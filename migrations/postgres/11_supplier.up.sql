@@ -0,0 +1,31 @@
+CREATE TABLE "supplier" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "name" VARCHAR(128) NOT NULL,
+    -- taxpayer identification number
+    "tin" VARCHAR(16),
+    "phone" VARCHAR(24),
+    "address" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "supplier_tin_idx" ON "supplier"("tin") WHERE "tin" IS NOT NULL;
+
+-- comings made so far have no supplier, the total is summed from the picking lists
+ALTER TABLE "coming" ADD COLUMN "supplier_id" UUID REFERENCES "supplier"("id");
+
+CREATE INDEX "coming_supplier_id_idx" ON "coming"("supplier_id");
+
+-- what we paid a supplier, the payable is the total of its finished comings less these
+CREATE TABLE "supplier_payment" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "supplier_id" UUID NOT NULL REFERENCES "supplier"("id"),
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "amount" NUMERIC NOT NULL CHECK ("amount" > 0),
+    "method" VARCHAR(16) NOT NULL CHECK ("method" IN ('cash', 'card', 'transfer')),
+    "comment" TEXT,
+    "user_id" UUID REFERENCES "user"("id"),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "supplier_payment_supplier_id_idx" ON "supplier_payment"("supplier_id", "branch_id");
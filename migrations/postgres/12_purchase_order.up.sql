@@ -0,0 +1,35 @@
+CREATE TABLE "purchase_order" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "increment_id" VARCHAR(64) NOT NULL,
+    "supplier_id" UUID NOT NULL REFERENCES "supplier"("id"),
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    -- closed once every line is fully received, open again when a coming
+    -- of the order is reversed
+    "status" VARCHAR(16) NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'closed')),
+    "user_id" UUID REFERENCES "user"("id"),
+    "closed_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP
+);
+
+CREATE INDEX "purchase_order_supplier_id_idx" ON "purchase_order"("supplier_id");
+CREATE INDEX "purchase_order_branch_id_idx" ON "purchase_order"("branch_id");
+
+CREATE TABLE "purchase_order_line" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "purchase_order_id" UUID NOT NULL REFERENCES "purchase_order"("id") ON DELETE CASCADE,
+    "product_id" UUID NOT NULL REFERENCES "product"("id"),
+    "quantity" INT NOT NULL,
+    -- the agreed price
+    "price" NUMERIC NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "purchase_order_line_quantity_check" CHECK ("quantity" > 0),
+    CONSTRAINT "purchase_order_line_price_check" CHECK ("price" >= 0),
+    CONSTRAINT "purchase_order_line_product_key" UNIQUE ("purchase_order_id", "product_id")
+);
+
+-- what is received against an order is the picking lists of its finished
+-- comings
+ALTER TABLE "coming" ADD COLUMN "purchase_order_id" UUID REFERENCES "purchase_order"("id");
+
+CREATE INDEX "coming_purchase_order_id_idx" ON "coming"("purchase_order_id");
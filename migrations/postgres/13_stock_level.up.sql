@@ -0,0 +1,15 @@
+-- min and max stock of a product in a branch, counted over all of its lots
+-- in remainder
+CREATE TABLE "stock_level" (
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id"),
+    "product_id" UUID NOT NULL REFERENCES "product"("id"),
+    "min_quantity" INT NOT NULL DEFAULT 0,
+    "max_quantity" INT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP,
+    PRIMARY KEY ("branch_id", "product_id"),
+    CONSTRAINT "stock_level_quantity_check" CHECK ("min_quantity" >= 0 AND "max_quantity" >= "min_quantity")
+);
+
+-- reorder suggestions sum the recent sales of a product
+CREATE INDEX "sale_product_product_id_created_at_idx" ON "sale_product"("product_id", "created_at");
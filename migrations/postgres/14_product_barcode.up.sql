@@ -0,0 +1,9 @@
+-- the codes a product is scanned by, a code belongs to one product only
+CREATE TABLE "product_barcode" (
+    "code" VARCHAR(64) PRIMARY KEY,
+    "product_id" UUID NOT NULL REFERENCES "product"("id") ON DELETE CASCADE,
+    "type" VARCHAR(16) NOT NULL CHECK ("type" IN ('ean13', 'ean8', 'code128', 'internal')),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "product_barcode_product_id_idx" ON "product_barcode"("product_id");
@@ -0,0 +1,14 @@
+-- a tree of product categories of any depth
+CREATE TABLE "category" (
+    "id" UUID PRIMARY KEY,
+    "name" VARCHAR NOT NULL,
+    "parent_id" UUID REFERENCES "category"("id"),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP
+);
+
+CREATE INDEX "category_parent_id_idx" ON "category"("parent_id");
+
+ALTER TABLE "product" ADD COLUMN "category_id" UUID REFERENCES "category"("id");
+
+CREATE INDEX "product_category_id_idx" ON "product"("category_id");
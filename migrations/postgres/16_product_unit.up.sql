@@ -0,0 +1,19 @@
+-- stock is kept in the base unit of a product, packs hold a whole number of it
+ALTER TABLE "product" ADD COLUMN "unit" VARCHAR(16) NOT NULL DEFAULT 'pcs';
+
+CREATE TABLE "product_unit" (
+    "product_id" UUID NOT NULL REFERENCES "product"("id") ON DELETE CASCADE,
+    "name" VARCHAR(16) NOT NULL,
+    "factor" INT NOT NULL CHECK ("factor" > 1),
+    "price" NUMERIC NOT NULL DEFAULT 0 CHECK ("price" >= 0),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("product_id", "name")
+);
+
+-- what was entered, the quantity and price columns stay in the base unit
+ALTER TABLE "picking_list" ADD COLUMN "unit" VARCHAR(16);
+ALTER TABLE "picking_list" ADD COLUMN "unit_quantity" INT;
+
+ALTER TABLE "sale_product" ADD COLUMN "unit" VARCHAR(16);
+ALTER TABLE "sale_product" ADD COLUMN "unit_quantity" INT;
+ALTER TABLE "sale_product" ADD COLUMN "unit_price" NUMERIC;
@@ -0,0 +1,16 @@
+-- the assortment of a branch, a product of the catalog is sold at its own
+-- price unless the branch overrides it
+CREATE TABLE "branch_product" (
+    "branch_id" UUID NOT NULL REFERENCES "branch"("id") ON DELETE CASCADE,
+    "product_id" UUID NOT NULL REFERENCES "product"("id") ON DELETE CASCADE,
+    "price" NUMERIC CHECK ("price" >= 0),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP,
+    PRIMARY KEY ("branch_id", "product_id")
+);
+
+CREATE INDEX "branch_product_product_id_idx" ON "branch_product"("product_id");
+
+-- a product was made for one branch, it stays in its assortment
+INSERT INTO "branch_product"("branch_id", "product_id", "updated_at")
+SELECT "branch_id", "id", NOW() FROM "product" WHERE "branch_id" IS NOT NULL;
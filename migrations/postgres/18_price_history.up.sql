@@ -0,0 +1,42 @@
+-- a bulk change of prices, its changes are the rows of price_history
+-- pointing at it
+CREATE TABLE "repricing" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "increment_id" VARCHAR(64) NOT NULL,
+    -- NULL reprices the catalog
+    "branch_id" UUID REFERENCES "branch"("id") ON DELETE CASCADE,
+    "category_id" UUID REFERENCES "category"("id") ON DELETE SET NULL,
+    "percent" NUMERIC,
+    "price" NUMERIC CHECK ("price" >= 0),
+    "effective_from" TIMESTAMP NOT NULL,
+    "comment" VARCHAR,
+    "user_id" UUID REFERENCES "user"("id"),
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- every price a product had, in the catalog when branch_id is NULL and as the
+-- override of a branch otherwise. A NULL price puts the branch back on the
+-- catalog price. A change effective in the future waits with a NULL
+-- applied_at until it is due.
+CREATE TABLE "price_history" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "product_id" UUID NOT NULL REFERENCES "product"("id") ON DELETE CASCADE,
+    "branch_id" UUID REFERENCES "branch"("id") ON DELETE CASCADE,
+    "price" NUMERIC CHECK ("price" >= 0),
+    "effective_from" TIMESTAMP NOT NULL,
+    "document_id" UUID REFERENCES "repricing"("id") ON DELETE CASCADE,
+    "applied_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "price_history_catalog_price_check" CHECK ("branch_id" IS NOT NULL OR "price" IS NOT NULL)
+);
+
+CREATE INDEX "price_history_product_id_idx" ON "price_history"("product_id", "branch_id", "effective_from");
+CREATE INDEX "price_history_pending_idx" ON "price_history"("effective_from") WHERE "applied_at" IS NULL;
+
+-- the prices of today are where the history starts
+INSERT INTO "price_history"("id", "product_id", "price", "effective_from", "applied_at")
+SELECT gen_random_uuid(), "id", COALESCE("price", 0), COALESCE("updated_at", "created_at", NOW()), NOW() FROM "product";
+
+INSERT INTO "price_history"("id", "product_id", "branch_id", "price", "effective_from", "applied_at")
+SELECT gen_random_uuid(), "product_id", "branch_id", "price", COALESCE("updated_at", "created_at", NOW()), NOW()
+FROM "branch_product" WHERE "price" IS NOT NULL;
@@ -0,0 +1,36 @@
+-- clients of a group share the promotions offered to it
+ALTER TABLE "client" ADD COLUMN "group" VARCHAR(64);
+
+-- a discount given at checkout between starts_at and ends_at, in branch_id or
+-- every branch when it is NULL. It applies to the lines of product_id, or of
+-- category_id and its subcategories, or to every line when both are NULL,
+-- and only to the clients of client_group when it is set. percent takes
+-- value percent off, fixed takes value off each unit sold and buy_x_get_y
+-- gives free_quantity units of every buy_quantity + free_quantity. A deleted
+-- promotion ends, one that never started gets an empty window.
+CREATE TABLE "promotion" (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "name" VARCHAR NOT NULL,
+    "type" VARCHAR(16) NOT NULL CHECK ("type" IN ('percent', 'fixed', 'buy_x_get_y')),
+    "value" NUMERIC NOT NULL DEFAULT 0 CHECK ("value" >= 0),
+    "buy_quantity" INT NOT NULL DEFAULT 0 CHECK ("buy_quantity" >= 0),
+    "free_quantity" INT NOT NULL DEFAULT 0 CHECK ("free_quantity" >= 0),
+    "branch_id" UUID REFERENCES "branch"("id") ON DELETE CASCADE,
+    "product_id" UUID REFERENCES "product"("id") ON DELETE CASCADE,
+    "category_id" UUID REFERENCES "category"("id") ON DELETE CASCADE,
+    "client_group" VARCHAR(64),
+    "starts_at" TIMESTAMP NOT NULL,
+    "ends_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP,
+    CONSTRAINT "promotion_window_check" CHECK ("ends_at" IS NULL OR "ends_at" >= "starts_at")
+);
+
+CREATE INDEX "promotion_window_idx" ON "promotion"("starts_at", "ends_at");
+
+-- total_price of a line is net of discount, promotions are never deleted so
+-- the line keeps the one that gave it
+ALTER TABLE "sale_product" ADD COLUMN "discount" NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE "sale_product" ADD COLUMN "promotion_id" UUID REFERENCES "promotion"("id");
+
+CREATE INDEX "sale_product_promotion_id_idx" ON "sale_product"("promotion_id");
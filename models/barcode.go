@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	BarcodeEAN13    = "ean13"
+	BarcodeEAN8     = "ean8"
+	BarcodeCode128  = "code128"
+	BarcodeInternal = "internal"
+)
+
+// Barcode is one of the codes a product is scanned by, unique over all
+// products. An empty Type is detected from the code.
+type Barcode struct {
+	Code string `json:"code"`
+	Type string `json:"type"`
+}
+
+// Normalize trims the code and fills in a missing type: 13 and 8 digit
+// codes are EAN, anything else Code128.
+func (b *Barcode) Normalize() {
+
+	b.Code = strings.TrimSpace(b.Code)
+	b.Type = strings.ToLower(strings.TrimSpace(b.Type))
+	if len(b.Type) > 0 {
+		return
+	}
+
+	switch {
+	case len(b.Code) == 13 && digits(b.Code):
+		b.Type = BarcodeEAN13
+	case len(b.Code) == 8 && digits(b.Code):
+		b.Type = BarcodeEAN8
+	default:
+		b.Type = BarcodeCode128
+	}
+}
+
+// Validate checks the length and characters of the code for its type and
+// the check digit of EAN codes.
+func (b Barcode) Validate() error {
+
+	switch b.Type {
+	case BarcodeEAN13, BarcodeEAN8:
+		length := 13
+		if b.Type == BarcodeEAN8 {
+			length = 8
+		}
+
+		if len(b.Code) != length || !digits(b.Code) {
+			return fmt.Errorf("%s %q must be %d digits", b.Type, b.Code, length)
+		}
+
+		if want := checkDigit(b.Code[:length-1]); b.Code[length-1] != want {
+			return fmt.Errorf("%s %q has check digit %c, want %c", b.Type, b.Code, b.Code[length-1], want)
+		}
+
+	case BarcodeCode128:
+		if len(b.Code) == 0 || len(b.Code) > 64 {
+			return fmt.Errorf("code128 %q must be 1 to 64 characters", b.Code)
+		}
+
+		for _, r := range b.Code {
+			if r < 32 || r > 126 {
+				return fmt.Errorf("code128 %q must be printable ASCII", b.Code)
+			}
+		}
+
+	case BarcodeInternal:
+		if len(b.Code) == 0 || len(b.Code) > 64 {
+			return fmt.Errorf("internal code %q must be 1 to 64 characters", b.Code)
+		}
+
+	default:
+		return fmt.Errorf("unknown barcode type %q", b.Type)
+	}
+
+	return nil
+}
+
+// checkDigit is the GS1 mod 10 check digit of the digits before it:
+// weights 3 and 1 alternate from the right.
+func checkDigit(code string) byte {
+
+	var sum int
+	for i := len(code) - 1; i >= 0; i -= 2 {
+		sum += 3 * int(code[i]-'0')
+		if i > 0 {
+			sum += int(code[i-1] - '0')
+		}
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func digits(code string) bool {
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type ProductByBarcodeRequest struct {
+	Code string `json:"code"`
+	// BranchID is the branch whose price and stock are returned, all
+	// branches together when empty.
+	BranchID string `json:"branch_id"`
+}
+
+// ProductByBarcode is what a scan at the POS shows: the product with the
+// price its next sale is charged at and the sellable stock of the branch.
+// SalePrice falls back to the product price when nothing is in stock.
+type ProductByBarcode struct {
+	Product   *Product `json:"product"`
+	Barcode   Barcode  `json:"barcode"`
+	BranchID  string   `json:"branch_id"`
+	SalePrice float64  `json:"sale_price"`
+	Quantity  int      `json:"quantity"`
+}
@@ -30,7 +30,7 @@ type GetListBranchRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListBranchResponse struct {
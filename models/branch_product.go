@@ -0,0 +1,53 @@
+package models
+
+type BranchProductPrimaryKey struct {
+	BranchID  string `json:"branch_id"`
+	ProductID string `json:"product_id"`
+}
+
+// SetBranchProduct puts a product of the catalog in the assortment of a
+// branch. A nil Price sells it at the catalog price.
+type SetBranchProduct struct {
+	BranchID  string   `json:"branch_id"`
+	ProductID string   `json:"product_id"`
+	Price     *float64 `json:"price"`
+}
+
+// BranchProduct is a product in the assortment of a branch. Price is the
+// override of the branch, nil when it sells at CatalogPrice, and SalePrice
+// what the branch sells it at.
+type BranchProduct struct {
+	BranchID     string   `json:"branch_id"`
+	ProductID    string   `json:"product_id"`
+	Name         string   `json:"name"`
+	CatalogPrice float64  `json:"catalog_price"`
+	Price        *float64 `json:"price"`
+	SalePrice    float64  `json:"sale_price"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+type GetListBranchProductRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListBranchProductResponse struct {
+	Count          int              `json:"count"`
+	BranchProducts []*BranchProduct `json:"branch_products"`
+}
+
+// CopyCatalog copies the assortment and price overrides of a branch to
+// another, what the target already has is overwritten.
+type CopyCatalog struct {
+	FromBranchID string `json:"from_branch_id"`
+	ToBranchID   string `json:"-"`
+}
+
+type CopyCatalogResponse struct {
+	FromBranchID string `json:"from_branch_id"`
+	ToBranchID   string `json:"to_branch_id"`
+	Copied       int    `json:"copied"`
+}
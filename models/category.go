@@ -0,0 +1,147 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+type CategoryPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateCategory struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
+
+// Category is a node of the category tree, a root when ParentID is empty.
+type Category struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	ParentID  string `json:"parent_id"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type UpdateCategory struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
+
+type GetListCategoryRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListCategoryResponse struct {
+	Count      int         `json:"count"`
+	Categories []*Category `json:"categories"`
+}
+
+type CategoryNode struct {
+	*Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// CategoryTree nests the categories under their parents, siblings by name.
+// A category whose parent is not in the list is a root.
+func CategoryTree(categories []*Category) []*CategoryNode {
+
+	var (
+		roots []*CategoryNode
+		nodes = map[string]*CategoryNode{}
+	)
+
+	sort.SliceStable(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+
+	for _, category := range categories {
+		nodes[category.Id] = &CategoryNode{Category: category, Children: []*CategoryNode{}}
+	}
+
+	for _, category := range categories {
+		if parent, ok := nodes[category.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[category.Id])
+			continue
+		}
+		roots = append(roots, nodes[category.Id])
+	}
+
+	return roots
+}
+
+// CategoryReportRequest rolls a report up to the children of ParentID, the
+// roots when it is empty. Sales are those created in [From, To).
+type CategoryReportRequest struct {
+	ParentID string     `json:"parent_id"`
+	BranchID string     `json:"branch_id"`
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+}
+
+// CategorySales is what was sold of a category and all of its descendants.
+// The row of the parent itself holds what is sold directly in it, or
+// without a category at the root level. Quantity and Amount are gross,
+// Returned* is what came back of those sales and Net* is less it.
+type CategorySales struct {
+	CategoryID       string  `json:"category_id"`
+	Name             string  `json:"name"`
+	Quantity         int     `json:"quantity"`
+	Amount           float64 `json:"amount"`
+	ReturnedQuantity int     `json:"returned_quantity"`
+	ReturnedAmount   float64 `json:"returned_amount"`
+	NetQuantity      int     `json:"net_quantity"`
+	NetAmount        float64 `json:"net_amount"`
+}
+
+type CategorySalesResponse struct {
+	ParentID         string           `json:"parent_id"`
+	Quantity         int              `json:"quantity"`
+	Amount           float64          `json:"amount"`
+	ReturnedQuantity int              `json:"returned_quantity"`
+	ReturnedAmount   float64          `json:"returned_amount"`
+	NetQuantity      int              `json:"net_quantity"`
+	NetAmount        float64          `json:"net_amount"`
+	Categories       []*CategorySales `json:"categories"`
+}
+
+// Add nets the returns of row out and adds it to the totals.
+func (r *CategorySalesResponse) Add(row *CategorySales) {
+	row.NetQuantity = row.Quantity - row.ReturnedQuantity
+	row.NetAmount = row.Amount - row.ReturnedAmount
+
+	r.Quantity += row.Quantity
+	r.Amount += row.Amount
+	r.ReturnedQuantity += row.ReturnedQuantity
+	r.ReturnedAmount += row.ReturnedAmount
+	r.NetQuantity += row.NetQuantity
+	r.NetAmount += row.NetAmount
+	r.Categories = append(r.Categories, row)
+}
+
+// CategoryStock is the stock of a category and all of its descendants at
+// coming (Cost) and sale (Value) prices.
+type CategoryStock struct {
+	CategoryID string  `json:"category_id"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	Cost       float64 `json:"cost"`
+	Value      float64 `json:"value"`
+}
+
+type CategoryStockResponse struct {
+	ParentID   string           `json:"parent_id"`
+	Quantity   int              `json:"quantity"`
+	Cost       float64          `json:"cost"`
+	Value      float64          `json:"value"`
+	Categories []*CategoryStock `json:"categories"`
+}
+
+func (r *CategoryStockResponse) Add(row *CategoryStock) {
+	r.Quantity += row.Quantity
+	r.Cost += row.Cost
+	r.Value += row.Value
+	r.Categories = append(r.Categories, row)
+}
@@ -0,0 +1,69 @@
+package models
+
+import "math"
+
+// CheckoutProduct is a line of a sale, Quantity is in Unit, a pack of the
+// product or its base unit when empty. Pack is the unit looked up from the
+// product, nil for the base unit. Promotions are those the line qualifies
+// for.
+type CheckoutProduct struct {
+	ProductID  string       `json:"product_id"`
+	Quantity   int          `json:"quantity"`
+	Unit       string       `json:"unit"`
+	Pack       *ProductUnit `json:"-"`
+	Promotions []*Promotion `json:"-"`
+}
+
+// BaseQuantity is the quantity in the base unit, the stock taken.
+func (p *CheckoutProduct) BaseQuantity() int {
+	if p.Pack == nil {
+		return p.Quantity
+	}
+	return p.Quantity * p.Pack.Factor
+}
+
+// Prices returns the price of a base unit and of the unit sold, given the
+// sale price of a base unit. A pack with a price of its own sells for it.
+func (p *CheckoutProduct) Prices(salePrice float64) (price, unitPrice float64) {
+
+	switch {
+	case p.Pack == nil:
+		return salePrice, salePrice
+	case p.Pack.Price > 0:
+		return p.Pack.Price / float64(p.Pack.Factor), p.Pack.Price
+	default:
+		return salePrice, salePrice * float64(p.Pack.Factor)
+	}
+}
+
+// Discount picks the promotion taking the most off the line when its unit
+// sells at unitPrice, nil when none takes anything. The discount never
+// exceeds the line.
+func (p *CheckoutProduct) Discount(unitPrice float64) (*Promotion, float64) {
+
+	var (
+		best     *Promotion
+		discount float64
+	)
+
+	for _, promotion := range p.Promotions {
+		if d := promotion.Discount(p.Quantity, unitPrice); d > discount {
+			best, discount = promotion, d
+		}
+	}
+
+	return best, math.Min(discount, unitPrice*float64(p.Quantity))
+}
+
+type CreateCheckout struct {
+	ClientID    string             `json:"client_id"`
+	BranchID    string             `json:"branch_id"`
+	IncrementID string             `json:"increment_id"`
+	Products    []*CheckoutProduct `json:"products"`
+	UserID      string             `json:"-"`
+}
+
+type Checkout struct {
+	Sale         *Sale          `json:"sale"`
+	SaleProducts []*SaleProduct `json:"sale_products"`
+}
@@ -13,6 +13,7 @@ type CreateClient struct {
 	Gender     string `json:"gender"`
 	BranchID   string `json:"branch_id"`
 	Active     string `json:"active"`
+	Group      string `json:"group"`
 }
 
 type Client struct {
@@ -25,6 +26,7 @@ type Client struct {
 	Gender     string `json:"gender"`
 	BranchID   string `json:"branch_id"`
 	Active     string `json:"active"`
+	Group      string `json:"group"`
 	CreatedAt  string `json:"created_at"`
 	UpdatedAt  string `json:"updated_at"`
 }
@@ -39,13 +41,14 @@ type UpdateClient struct {
 	Gender     string `json:"gender"`
 	BranchID   string `json:"branch_id"`
 	Active     string `json:"active"`
+	Group      string `json:"group"`
 }
 
 type GetListClientRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListClientResponse struct {
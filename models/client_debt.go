@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type ClientStatementRequest struct {
+	ClientID string `json:"client_id"`
+	// From and To limit the entries to [From, To), a nil bound is open.
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+// ClientStatementEntry is a sale (debit), a return or a payment (credit) of
+// a client, a refund is a negative credit.
+type ClientStatementEntry struct {
+	Type        string  `json:"type"`
+	Id          string  `json:"id"`
+	SaleID      string  `json:"sale_id"`
+	IncrementID string  `json:"increment_id"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Balance     float64 `json:"balance"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+type ClientStatement struct {
+	ClientID       string                  `json:"client_id"`
+	OpeningBalance float64                 `json:"opening_balance"`
+	Debit          float64                 `json:"debit"`
+	Credit         float64                 `json:"credit"`
+	ClosingBalance float64                 `json:"closing_balance"`
+	Entries        []*ClientStatementEntry `json:"entries"`
+}
+
+// AddEntry appends e and sets its running balance.
+func (s *ClientStatement) AddEntry(e *ClientStatementEntry) {
+	s.Debit += e.Debit
+	s.Credit += e.Credit
+	s.ClosingBalance += e.Debit - e.Credit
+	e.Balance = s.ClosingBalance
+	s.Entries = append(s.Entries, e)
+}
+
+type DebtAgingRequest struct {
+	BranchID string `json:"branch_id"`
+	// At is the day the age of a debt is counted to, now when nil.
+	At *time.Time `json:"at"`
+}
+
+// DebtAging is the outstanding debt of a client bucketed by days since the sale.
+type DebtAging struct {
+	ClientID   string  `json:"client_id"`
+	FirstName  string  `json:"first_name"`
+	LastName   string  `json:"last_name"`
+	Phone      string  `json:"phone"`
+	Days0To30  float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Days90Plus float64 `json:"days_90_plus"`
+	Total      float64 `json:"total"`
+}
+
+// Add puts debt of a sale days old into its bucket.
+func (a *DebtAging) Add(days int, debt float64) {
+	switch {
+	case days <= 30:
+		a.Days0To30 += debt
+	case days <= 60:
+		a.Days31To60 += debt
+	case days <= 90:
+		a.Days61To90 += debt
+	default:
+		a.Days90Plus += debt
+	}
+	a.Total += debt
+}
+
+type DebtAgingResponse struct {
+	Clients []*DebtAging `json:"clients"`
+	Total   DebtAging    `json:"total"`
+}
@@ -1,25 +1,43 @@
 package models
 
+// A coming starts as a draft whose picking lists can be edited. Finishing it
+// posts the lines to the remainder of its branch, reversing takes them back.
+const (
+	ComingDraft    = "draft"
+	ComingFinished = "finished"
+	ComingReversed = "reversed"
+)
+
 type ComingPrimaryKey struct {
 	Id string `json:"id"`
 }
 
 type CreateComing struct {
-	IncrementID string `json:"increment_id"`
-	BranchID    string `json:"branch_id"`
+	IncrementID     string `json:"increment_id"`
+	BranchID        string `json:"branch_id"`
+	SupplierID      string `json:"supplier_id"`
+	// PurchaseOrderID is set when the coming is made from a purchase order.
+	PurchaseOrderID string `json:"-"`
 }
 
+// Coming is a delivery from a supplier, TotalPrice is the sum of its
+// picking lists.
 type Coming struct {
-	Id          string `json:"id"`
-	IncrementID string `json:"increment_id"`
-	BranchID    string `json:"branch_id"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	Id              string  `json:"id"`
+	IncrementID     string  `json:"increment_id"`
+	BranchID        string  `json:"branch_id"`
+	SupplierID      string  `json:"supplier_id"`
+	PurchaseOrderID string  `json:"purchase_order_id"`
+	TotalPrice      float64 `json:"total_price"`
+	Status          string  `json:"status"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
 }
 
 type UpdateComing struct {
 	Id          string `json:"id"`
 	BranchID    string `json:"branch_id"`
+	SupplierID  string `json:"supplier_id"`
 	Status      string `json:"status"`
 }
 
@@ -27,10 +45,43 @@ type GetListComingRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListComingResponse struct {
 	Count    int       `json:"count"`
 	Cominges []*Coming `json:"cominges"`
 }
+
+// ComingStatus moves a coming from status From to status To.
+type ComingStatus struct {
+	Id   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ReverseComing struct {
+	Reason string `json:"reason"`
+}
+
+const (
+	ComingEventFinish  = "finish"
+	ComingEventReverse = "reverse"
+)
+
+// ComingEvent is the audit trail of finishing and reversing a coming.
+type ComingEvent struct {
+	Id        string `json:"id"`
+	ComingID  string `json:"coming_id"`
+	Action    string `json:"action"`
+	UserID    string `json:"user_id"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateComingEvent struct {
+	ComingID string `json:"coming_id"`
+	Action   string `json:"action"`
+	UserID   string `json:"user_id"`
+	Reason   string `json:"reason"`
+}
@@ -1,8 +1,14 @@
 package models
 
+// Doc sums the sales of a branch. TotalSale* are gross, Net* are less the
+// goods returned.
 type Doc struct {
-	BranchID          string  `json:"branch_id"`
-	BranchName        string  `json:"branch_name"`
-	TotalSalePrice    float64 `json:"total_sale_price"`
-	TotalSaleQuantity int     `json:"total_sale_quantity"`
+	BranchID            string  `json:"branch_id"`
+	BranchName          string  `json:"branch_name"`
+	TotalSalePrice      float64 `json:"total_sale_price"`
+	TotalSaleQuantity   int     `json:"total_sale_quantity"`
+	TotalReturnPrice    float64 `json:"total_return_price"`
+	TotalReturnQuantity int     `json:"total_return_quantity"`
+	NetSalePrice        float64 `json:"net_sale_price"`
+	NetSaleQuantity     int     `json:"net_sale_quantity"`
 }
@@ -0,0 +1,11 @@
+package models
+
+type NextDocumentNumber struct {
+	// Document names the counter, e.g. "sale" or "coming".
+	Document    string `json:"document"`
+	BranchID    string `json:"branch_id"`
+	Prefix      string `json:"prefix"`
+	Width       int    `json:"width"`
+	PerBranch   bool   `json:"per_branch"`
+	YearlyReset bool   `json:"yearly_reset"`
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NumberRange is an inclusive range, a nil bound is open.
+type NumberRange struct {
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+}
+
+// Filter is the typed WHERE clause of every GetList request.
+type Filter struct {
+	// Fields holds column = value, or column IN (...) when several values are given.
+	Fields map[string][]string `json:"fields"`
+	// Ranges holds numeric column ranges.
+	Ranges map[string]NumberRange `json:"ranges"`
+	// CreatedFrom and CreatedTo limit created_at to [CreatedFrom, CreatedTo).
+	CreatedFrom *time.Time `json:"created_from"`
+	CreatedTo   *time.Time `json:"created_to"`
+}
+
+// FilterSpec declares which columns of an entity can be filtered on.
+type FilterSpec struct {
+	Fields  []string
+	Numbers []string
+	Search  []string
+}
+
+var (
+	BranchFilterSpec = FilterSpec{
+		Search: []string{"name", "address", "phone"},
+	}
+
+	ClientFilterSpec = FilterSpec{
+		Fields: []string{"branch_id", "gender", "active", "group"},
+		Search: []string{"first_name", "last_name", "father_name", "phone"},
+	}
+
+	ProductFilterSpec = FilterSpec{
+		Fields:  []string{"branch_id", "category_id"},
+		Numbers: []string{"price"},
+		Search:  []string{"product.name", "branch.name"},
+	}
+
+	CategoryFilterSpec = FilterSpec{
+		Fields: []string{"parent_id"},
+		Search: []string{"name"},
+	}
+
+	ComingFilterSpec = FilterSpec{
+		Fields: []string{"branch_id", "increment_id", "status", "supplier_id", "purchase_order_id"},
+		Search: []string{"increment_id"},
+	}
+
+	SupplierFilterSpec = FilterSpec{
+		Fields: []string{"tin"},
+		Search: []string{"name", "tin", "phone", "address"},
+	}
+
+	SupplierPaymentFilterSpec = FilterSpec{
+		Fields:  []string{"supplier_id", "branch_id", "method", "user_id"},
+		Numbers: []string{"amount"},
+		Search:  []string{"comment"},
+	}
+
+	TransferFilterSpec = FilterSpec{
+		Fields: []string{"from_branch_id", "to_branch_id", "status", "increment_id", "user_id"},
+		Search: []string{"increment_id"},
+	}
+
+	StockLevelFilterSpec = FilterSpec{
+		Fields:  []string{"branch_id", "product_id"},
+		Numbers: []string{"min_quantity", "max_quantity"},
+	}
+
+	BranchProductFilterSpec = FilterSpec{
+		Fields: []string{"branch_id", "product_id"},
+		Search: []string{"product.name"},
+	}
+
+	PriceChangeFilterSpec = FilterSpec{
+		Fields:  []string{"product_id", "branch_id", "document_id"},
+		Numbers: []string{"price"},
+		Search:  []string{"product.name"},
+	}
+
+	RepricingFilterSpec = FilterSpec{
+		Fields: []string{"branch_id", "category_id", "increment_id", "user_id"},
+		Search: []string{"increment_id", "comment"},
+	}
+
+	PromotionFilterSpec = FilterSpec{
+		Fields:  []string{"type", "branch_id", "product_id", "category_id", "client_group"},
+		Numbers: []string{"value"},
+		Search:  []string{"name"},
+	}
+
+	PurchaseOrderFilterSpec = FilterSpec{
+		Fields: []string{"supplier_id", "branch_id", "status", "increment_id", "user_id"},
+		Search: []string{"increment_id"},
+	}
+
+	SaleReturnFilterSpec = FilterSpec{
+		Fields:  []string{"sale_id", "branch_id", "client_id", "increment_id", "user_id"},
+		Numbers: []string{"total_price", "refund"},
+		Search:  []string{"increment_id"},
+	}
+
+	StockTakeFilterSpec = FilterSpec{
+		Fields: []string{"branch_id", "status", "increment_id", "user_id"},
+		Search: []string{"increment_id"},
+	}
+
+	PickingListFilterSpec = FilterSpec{
+		Fields:  []string{"product_id", "coming_id", "coming_increment_id", "lot_number", "branch_id"},
+		Numbers: []string{"price", "quantity", "total_price"},
+		Search:  []string{"coming_increment_id"},
+	}
+
+	RemainderFilterSpec = FilterSpec{
+		Fields:  []string{"product_id", "branch_id", "lot_number"},
+		Numbers: []string{"quantity", "coming_price", "sale_price"},
+		Search:  []string{"name", "lot_number"},
+	}
+
+	SaleFilterSpec = FilterSpec{
+		Fields:  []string{"branch_id", "client_id", "increment_id"},
+		Numbers: []string{"total_price", "returned", "paid", "debt"},
+		Search:  []string{"increment_id"},
+	}
+
+	StockMovementFilterSpec = FilterSpec{
+		Fields:  []string{"branch_id", "product_id", "type", "document_id", "user_id", "lot_number"},
+		Numbers: []string{"quantity"},
+		Search:  []string{"document_number", "comment", "lot_number"},
+	}
+
+	PaymentFilterSpec = FilterSpec{
+		Fields:  []string{"sale_id", "method", "cashier", "branch_id"},
+		Numbers: []string{"amount"},
+		Search:  []string{"cashier"},
+	}
+
+	UserFilterSpec = FilterSpec{
+		Fields: []string{"client_type", "branch_id"},
+		Search: []string{"login", "first_name", "last_name"},
+	}
+
+	SaleProductFilterSpec = FilterSpec{
+		Fields:  []string{"product_id", "sale_id", "sale_increment_id", "branch_id"},
+		Numbers: []string{"quantity", "price", "total_price"},
+		Search:  []string{"sale_increment_id"},
+	}
+)
+
+// Validate rejects columns the spec does not declare, so they never reach SQL.
+func (f Filter) Validate(spec FilterSpec) error {
+
+	for column := range f.Fields {
+		if !contains(spec.Fields, column) {
+			return fmt.Errorf("can not filter by %q", column)
+		}
+	}
+
+	for column := range f.Ranges {
+		if !contains(spec.Numbers, column) {
+			return fmt.Errorf("can not filter by range of %q", column)
+		}
+	}
+
+	return nil
+}
+
+// Without splits the values of column off the filter, for a repo that
+// filters by it other than by equality.
+func (f Filter) Without(column string) (Filter, []string) {
+
+	values, ok := f.Fields[column]
+	if !ok {
+		return f, nil
+	}
+
+	fields := make(map[string][]string, len(f.Fields)-1)
+	for c, v := range f.Fields {
+		if c != column {
+			fields[c] = v
+		}
+	}
+	f.Fields = fields
+
+	return f, values
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
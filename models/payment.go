@@ -0,0 +1,35 @@
+package models
+
+var PaymentMethods = []string{"cash", "card", "transfer"}
+
+type PaymentPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreatePayment struct {
+	SaleID  string  `json:"sale_id"`
+	Amount  float64 `json:"amount"`
+	Method  string  `json:"method"`
+	Cashier string  `json:"cashier"`
+}
+
+type Payment struct {
+	Id        string  `json:"id"`
+	SaleID    string  `json:"sale_id"`
+	Amount    float64 `json:"amount"`
+	Method    string  `json:"method"`
+	Cashier   string  `json:"cashier"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type GetListPaymentRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListPaymentResponse struct {
+	Count    int        `json:"count"`
+	Payments []*Payment `json:"payments"`
+}
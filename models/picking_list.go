@@ -6,17 +6,27 @@ type PickingList struct {
 	Price             float64 `json:"price"`
 	Quantity          int     `json:"quantity"`
 	Total_price       float64 `json:"total_price"`
+	Unit              string  `json:"unit"`
+	UnitQuantity      int     `json:"unit_quantity"`
 	ComingID          string  `json:"coming_id"`
 	ComingIncrementID string  `json:"coming_increment_id"`
+	LotNumber         string  `json:"lot_number"`
+	ExpiryDate        string  `json:"expiry_date"`
 	CreatedAt         string  `json:"created_at"`
 	UpdatedAt         string  `json:"updated_at"`
 }
 
+// CreatePickingList is a line of a coming. LotNumber and ExpiryDate
+// (YYYY-MM-DD) are optional, the stock is received into that lot. Quantity
+// and Price are in Unit, a pack of the product or its base unit when empty.
 type CreatePickingList struct {
 	Product_ID        string  `json:"product_id"`
 	Quantity          int     `json:"quantity"`
 	Price             float64 `json:"price"`
+	Unit              string  `json:"unit"`
 	ComingIncrementID string  `json:"coming_increment_id"`
+	LotNumber         string  `json:"lot_number"`
+	ExpiryDate        string  `json:"expiry_date"`
 }
 
 type PickingListPrimaryKey struct {
@@ -29,13 +39,16 @@ type UpdatePickingList struct {
 	Price             float64 `json:"price"`
 	ComingIncrementID string  `json:"coming_increment_id"`
 	Quantity          int     `json:"quantity"`
+	Unit              string  `json:"unit"`
+	LotNumber         string  `json:"lot_number"`
+	ExpiryDate        string  `json:"expiry_date"`
 }
 
 type GetListPickingListRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListPickingListResponse struct {
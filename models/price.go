@@ -0,0 +1,122 @@
+package models
+
+import "time"
+
+type PriceChangePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreatePriceChange schedules the price of a product in the catalog, or of
+// its override in BranchID. A nil Price puts the branch back on the catalog
+// price. The change is applied right away when EffectiveFrom is nil or past.
+type CreatePriceChange struct {
+	ProductID     string     `json:"product_id"`
+	BranchID      string     `json:"branch_id"`
+	Price         *float64   `json:"price"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	DocumentID    string     `json:"-"`
+}
+
+// PriceChange is a row of the price history of a product, of the catalog
+// when BranchID is empty. AppliedAt is empty while the change waits for
+// EffectiveFrom, DocumentID is the repricing that made it.
+type PriceChange struct {
+	Id            string   `json:"id"`
+	ProductID     string   `json:"product_id"`
+	BranchID      string   `json:"branch_id"`
+	Price         *float64 `json:"price"`
+	EffectiveFrom string   `json:"effective_from"`
+	DocumentID    string   `json:"document_id"`
+	AppliedAt     string   `json:"applied_at"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+type GetListPriceChangeRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+	// Pending limits the list to the changes not applied yet.
+	Pending bool `json:"pending"`
+}
+
+type GetListPriceChangeResponse struct {
+	Count        int            `json:"count"`
+	PriceChanges []*PriceChange `json:"price_changes"`
+}
+
+// PriceAsOfRequest asks the price of a product in a branch, or in the
+// catalog when BranchID is empty, at At.
+type PriceAsOfRequest struct {
+	ProductID string    `json:"product_id"`
+	BranchID  string    `json:"branch_id"`
+	At        time.Time `json:"at"`
+}
+
+// PriceAsOf is the price of a product at a time and the change that set it.
+type PriceAsOf struct {
+	ProductID     string  `json:"product_id"`
+	BranchID      string  `json:"branch_id"`
+	At            string  `json:"at"`
+	Price         float64 `json:"price"`
+	ChangeID      string  `json:"change_id"`
+	EffectiveFrom string  `json:"effective_from"`
+}
+
+type RepricingPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateRepricing changes the prices of the products of CategoryID and its
+// subcategories, or of ProductIDs, in BranchID or in the catalog when it is
+// empty. The new price is Price, or the current one changed by Percent.
+type CreateRepricing struct {
+	IncrementID   string               `json:"-"`
+	BranchID      string               `json:"branch_id"`
+	CategoryID    string               `json:"category_id"`
+	ProductIDs    []string             `json:"product_ids"`
+	Percent       *float64             `json:"percent"`
+	Price         *float64             `json:"price"`
+	EffectiveFrom *time.Time           `json:"effective_from"`
+	Comment       string               `json:"comment"`
+	UserID        string               `json:"-"`
+	Lines         []*CreatePriceChange `json:"-"`
+}
+
+type Repricing struct {
+	Id            string         `json:"id"`
+	IncrementID   string         `json:"increment_id"`
+	BranchID      string         `json:"branch_id"`
+	CategoryID    string         `json:"category_id"`
+	Percent       *float64       `json:"percent"`
+	Price         *float64       `json:"price"`
+	EffectiveFrom string         `json:"effective_from"`
+	Comment       string         `json:"comment"`
+	UserID        string         `json:"user_id"`
+	CreatedAt     string         `json:"created_at"`
+	Lines         []*PriceChange `json:"lines"`
+}
+
+type GetListRepricingRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListRepricingResponse struct {
+	Count      int          `json:"count"`
+	Repricings []*Repricing `json:"repricings"`
+}
+
+// SaleLine is a line of a sale with the price its branch listed the product
+// at when the sale was made.
+type SaleLine struct {
+	SaleProduct *SaleProduct `json:"sale_product"`
+	ListPrice   float64      `json:"list_price"`
+}
+
+type SaleLines struct {
+	Sale  *Sale       `json:"sale"`
+	Lines []*SaleLine `json:"lines"`
+}
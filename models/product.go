@@ -5,32 +5,46 @@ type ProductPrimaryKey struct {
 }
 
 type CreateProduct struct {
-	Name     string  `json:"name"`
-	Price    float64 `json:"price"`
-	BranchID string  `json:"branch_id"`
+	Name       string         `json:"name"`
+	Price      float64        `json:"price"`
+	BranchID   string         `json:"branch_id"`
+	CategoryID string         `json:"category_id"`
+	Unit       string         `json:"unit"`
+	Units      []*ProductUnit `json:"units"`
+	Barcodes   []*Barcode     `json:"barcodes"`
 }
 
 type Product struct {
-	Id        string  `json:"id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	BranchID  string  `json:"branch_id"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	Id         string         `json:"id"`
+	Name       string         `json:"name"`
+	Price      float64        `json:"price"`
+	BranchID   string         `json:"branch_id"`
+	CategoryID string         `json:"category_id"`
+	Unit       string         `json:"unit"`
+	Units      []*ProductUnit `json:"units"`
+	Barcodes   []*Barcode     `json:"barcodes"`
+	CreatedAt  string         `json:"created_at"`
+	UpdatedAt  string         `json:"updated_at"`
 }
 
+// UpdateProduct replaces the barcodes and the pack units of the product
+// unless Barcodes or Units is nil, an empty Unit keeps the base unit.
 type UpdateProduct struct {
-	Id       string  `json:"id"`
-	Name     string  `json:"name"`
-	Price    float64 `json:"price"`
-	BranchID string  `json:"branch_id"`
+	Id         string         `json:"id"`
+	Name       string         `json:"name"`
+	Price      float64        `json:"price"`
+	BranchID   string         `json:"branch_id"`
+	CategoryID string         `json:"category_id"`
+	Unit       string         `json:"unit"`
+	Units      []*ProductUnit `json:"units"`
+	Barcodes   []*Barcode     `json:"barcodes"`
 }
 
 type GetListProductRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListProductResponse struct {
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// PromotionPercent takes Value percent off the line.
+	PromotionPercent = "percent"
+	// PromotionFixed takes Value off each unit sold, down to a free unit.
+	PromotionFixed = "fixed"
+	// PromotionBuyGet gives FreeQuantity units of every BuyQuantity +
+	// FreeQuantity sold.
+	PromotionBuyGet = "buy_x_get_y"
+)
+
+type PromotionPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreatePromotion is a discount given at checkout from StartsAt, now when it
+// is nil, until EndsAt, for good when it is nil. Empty BranchID, ProductID,
+// CategoryID and ClientGroup do not limit where it applies.
+type CreatePromotion struct {
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Value        float64    `json:"value"`
+	BuyQuantity  int        `json:"buy_quantity"`
+	FreeQuantity int        `json:"free_quantity"`
+	BranchID     string     `json:"branch_id"`
+	ProductID    string     `json:"product_id"`
+	CategoryID   string     `json:"category_id"`
+	ClientGroup  string     `json:"client_group"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+}
+
+// Promotion is a discount rule, CategoryID covers its subcategories too.
+type Promotion struct {
+	Id           string  `json:"id"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Value        float64 `json:"value"`
+	BuyQuantity  int     `json:"buy_quantity"`
+	FreeQuantity int     `json:"free_quantity"`
+	BranchID     string  `json:"branch_id"`
+	ProductID    string  `json:"product_id"`
+	CategoryID   string  `json:"category_id"`
+	ClientGroup  string  `json:"client_group"`
+	StartsAt     string  `json:"starts_at"`
+	EndsAt       string  `json:"ends_at"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+// Discount is what the promotion takes off quantity units sold at price,
+// rounded to cents.
+func (p *Promotion) Discount(quantity int, price float64) float64 {
+
+	var discount float64
+
+	switch p.Type {
+	case PromotionPercent:
+		discount = price * float64(quantity) * p.Value / 100
+	case PromotionFixed:
+		discount = math.Min(p.Value, price) * float64(quantity)
+	case PromotionBuyGet:
+		if set := p.BuyQuantity + p.FreeQuantity; set > 0 {
+			discount = float64(quantity/set*p.FreeQuantity) * price
+		}
+	}
+
+	return math.Round(discount*100) / 100
+}
+
+type UpdatePromotion struct {
+	Id           string     `json:"id"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Value        float64    `json:"value"`
+	BuyQuantity  int        `json:"buy_quantity"`
+	FreeQuantity int        `json:"free_quantity"`
+	BranchID     string     `json:"branch_id"`
+	ProductID    string     `json:"product_id"`
+	CategoryID   string     `json:"category_id"`
+	ClientGroup  string     `json:"client_group"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+}
+
+type GetListPromotionRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListPromotionResponse struct {
+	Count      int          `json:"count"`
+	Promotions []*Promotion `json:"promotions"`
+}
+
+// PromotionReportRequest sums the discounts of the sales of BranchID, or of
+// every branch when it is empty, created in [From, To).
+type PromotionReportRequest struct {
+	BranchID string     `json:"branch_id"`
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+}
+
+// PromotionCost is what a promotion gave away, on Lines lines of Sales
+// sales selling Quantity base units, returned units excluded.
+type PromotionCost struct {
+	PromotionID string  `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Sales       int     `json:"sales"`
+	Lines       int     `json:"lines"`
+	Quantity    int     `json:"quantity"`
+	Discount    float64 `json:"discount"`
+}
+
+type PromotionReportResponse struct {
+	Discount   float64          `json:"discount"`
+	Promotions []*PromotionCost `json:"promotions"`
+}
+
+func (r *PromotionReportResponse) Add(row *PromotionCost) {
+	r.Discount += row.Discount
+	r.Promotions = append(r.Promotions, row)
+}
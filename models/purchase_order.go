@@ -0,0 +1,137 @@
+package models
+
+import "math"
+
+// A purchase order is open until every line is received in full by its
+// finished comings, then it closes. Reversing one of its comings opens it
+// again.
+const (
+	PurchaseOrderOpen   = "open"
+	PurchaseOrderClosed = "closed"
+)
+
+// Flags of a purchase order line that was not delivered as ordered.
+const (
+	DeliveryUnder = "under"
+	DeliveryOver  = "over"
+	DeliveryPrice = "price"
+)
+
+type PurchaseOrderPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreatePurchaseOrderLine struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type CreatePurchaseOrder struct {
+	IncrementID string                     `json:"increment_id"`
+	SupplierID  string                     `json:"supplier_id"`
+	BranchID    string                     `json:"branch_id"`
+	UserID      string                     `json:"-"`
+	Lines       []*CreatePurchaseOrderLine `json:"lines"`
+}
+
+// PurchaseOrderLine is a product ordered at an agreed Price. Received is
+// what the finished comings of the order brought at an average
+// ReceivedPrice, Outstanding what is still to come.
+type PurchaseOrderLine struct {
+	Id              string   `json:"id"`
+	PurchaseOrderID string   `json:"purchase_order_id"`
+	ProductID       string   `json:"product_id"`
+	Quantity        int      `json:"quantity"`
+	Price           float64  `json:"price"`
+	Received        int      `json:"received"`
+	ReceivedPrice   float64  `json:"received_price"`
+	Outstanding     int      `json:"outstanding"`
+	Flags           []string `json:"flags"`
+	CreatedAt       string   `json:"created_at"`
+}
+
+type PurchaseOrder struct {
+	Id          string               `json:"id"`
+	IncrementID string               `json:"increment_id"`
+	SupplierID  string               `json:"supplier_id"`
+	BranchID    string               `json:"branch_id"`
+	Status      string               `json:"status"`
+	UserID      string               `json:"user_id"`
+	ClosedAt    string               `json:"closed_at"`
+	CreatedAt   string               `json:"created_at"`
+	UpdatedAt   string               `json:"updated_at"`
+	Lines       []*PurchaseOrderLine `json:"lines"`
+}
+
+// Receive sets what the order's finished comings brought of each line, by
+// product: quantity and amount paid, and flags the deviations. Lines not
+// received yet are flagged under once anything of the order arrived.
+func (po *PurchaseOrder) Receive(quantity map[string]int, amount map[string]float64) {
+
+	var delivered = len(quantity) > 0
+
+	for _, line := range po.Lines {
+		line.Received = quantity[line.ProductID]
+		line.ReceivedPrice = 0
+		line.Outstanding = 0
+		line.Flags = []string{}
+
+		if line.Received > 0 {
+			line.ReceivedPrice = math.Round(amount[line.ProductID]/float64(line.Received)*100) / 100
+		}
+
+		if line.Received < line.Quantity {
+			line.Outstanding = line.Quantity - line.Received
+		}
+
+		switch {
+		case line.Received > line.Quantity:
+			line.Flags = append(line.Flags, DeliveryOver)
+		case delivered && line.Received < line.Quantity:
+			line.Flags = append(line.Flags, DeliveryUnder)
+		}
+
+		if line.Received > 0 && line.ReceivedPrice != line.Price {
+			line.Flags = append(line.Flags, DeliveryPrice)
+		}
+	}
+}
+
+// FullyReceived tells whether nothing of the order is outstanding.
+func (po *PurchaseOrder) FullyReceived() bool {
+	for _, line := range po.Lines {
+		if line.Outstanding > 0 {
+			return false
+		}
+	}
+	return len(po.Lines) > 0
+}
+
+// UpdatePurchaseOrder replaces the supplier and the lines of an order that
+// nothing was received against yet.
+type UpdatePurchaseOrder struct {
+	Id         string                     `json:"id"`
+	SupplierID string                     `json:"supplier_id"`
+	Lines      []*CreatePurchaseOrderLine `json:"lines"`
+}
+
+type GetListPurchaseOrderRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListPurchaseOrderResponse struct {
+	Count          int              `json:"count"`
+	PurchaseOrders []*PurchaseOrder `json:"purchase_orders"`
+}
+
+// PurchaseOrderStatus moves an order from status From to status To and
+// stamps or clears closed_at.
+type PurchaseOrderStatus struct {
+	Id   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
@@ -12,6 +12,8 @@ type CreateRemainder struct {
 	BranchID    string  `json:"branch_id"`
 }
 
+// Remainder is the stock of one lot of a product in a branch, LotNumber
+// is empty for stock received without a lot.
 type Remainder struct {
 	Id          string  `json:"id"`
 	ProductID   string  `json:"product_id"`
@@ -20,6 +22,8 @@ type Remainder struct {
 	ComingPrice float64 `json:"coming_price"`
 	SalePrice   float64 `json:"sale_price"`
 	BranchID    string  `json:"branch_id"`
+	LotNumber   string  `json:"lot_number"`
+	ExpiryDate  string  `json:"expiry_date"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
 }
@@ -32,14 +36,71 @@ type UpdateRemainder struct {
 	BranchID    string  `json:"branch_id"`
 }
 
+// AddRemainder changes the quantity of a lot of a product in a branch by
+// Quantity, creating the lot when there is none. Zero prices keep the current
+// ones, an empty ExpiryDate keeps the lot's. Sellable leaves expired lots out.
+type AddRemainder struct {
+	BranchID    string  `json:"branch_id"`
+	ProductID   string  `json:"product_id"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	ComingPrice float64 `json:"coming_price"`
+	SalePrice   float64 `json:"sale_price"`
+	LotNumber   string  `json:"lot_number"`
+	ExpiryDate  string  `json:"expiry_date"`
+	Sellable    bool    `json:"-"`
+}
+
 type GetListRemainderRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListRemainderResponse struct {
 	Count      int          `json:"count"`
 	Remainders []*Remainder `json:"remainders"`
-}
\ No newline at end of file
+}
+
+// ExpiringRequest asks for the lots of a branch, of all branches when
+// BranchID is empty, that expire within Days of today. Lots already expired
+// are included.
+type ExpiringRequest struct {
+	BranchID string `json:"branch_id"`
+	Days     int    `json:"days"`
+}
+
+// ExpiringLot is a lot in stock that expires soon, DaysLeft is negative once
+// it has expired.
+type ExpiringLot struct {
+	RemainderID string  `json:"remainder_id"`
+	BranchID    string  `json:"branch_id"`
+	ProductID   string  `json:"product_id"`
+	Name        string  `json:"name"`
+	LotNumber   string  `json:"lot_number"`
+	ExpiryDate  string  `json:"expiry_date"`
+	DaysLeft    int     `json:"days_left"`
+	Quantity    int     `json:"quantity"`
+	ComingPrice float64 `json:"coming_price"`
+	Cost        float64 `json:"cost"`
+}
+
+type ExpiringResponse struct {
+	Days    int            `json:"days"`
+	Expired float64        `json:"expired"`
+	Cost    float64        `json:"cost"`
+	Lots    []*ExpiringLot `json:"lots"`
+}
+
+// Add appends l, sets its cost at coming_price and adds it to the totals.
+func (r *ExpiringResponse) Add(l *ExpiringLot) {
+
+	l.Cost = float64(l.Quantity) * l.ComingPrice
+
+	r.Lots = append(r.Lots, l)
+	r.Cost += l.Cost
+	if l.DaysLeft < 0 {
+		r.Expired += l.Cost
+	}
+}
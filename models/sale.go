@@ -11,33 +11,36 @@ type CreateSale struct {
 	TotalPrice  float64 `json:"total_price"`
 }
 
+// Sale is TotalPrice of goods sold, Returned of them came back. The client
+// owes Debd = TotalPrice - Returned - Paid.
 type Sale struct {
 	Id          string  `json:"id"`
 	ClientID    string  `json:"client_id"`
 	BranchID    string  `json:"branch_id"`
 	IncrementID string  `json:"increment_id"`
 	TotalPrice  float64 `json:"total_price"`
+	Returned    float64 `json:"returned"`
 	Paid        float64 `json:"paid"`
 	Debd        float64 `json:"debd"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
 }
 
+// UpdateSale leaves paid to the payments of the sale, its debt follows
+// TotalPrice.
 type UpdateSale struct {
 	Id          string  `json:"id"`
 	ClientID    string  `json:"client_id"`
 	BranchID    string  `json:"branch_id"`
 	IncrementID string  `json:"increment_id"`
 	TotalPrice  float64 `json:"total_price"`
-	Paid        float64 `json:"paid"`
-	Debd        float64 `json:"debd"`
 }
 
 type GetListSaleRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListSaleResponse struct {
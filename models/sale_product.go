@@ -13,6 +13,9 @@ type CreateSaleProduct struct {
 	TotalPrice      float64 `json:"total_price"`
 }
 
+// SaleProduct is a line of a sale, Quantity and Price are in the base unit
+// of the product and Unit, UnitQuantity and UnitPrice what was sold.
+// TotalPrice is net of the Discount given by PromotionID.
 type SaleProduct struct {
 	Id              string  `json:"id"`
 	ProcutID        string  `json:"product_id"`
@@ -21,10 +24,24 @@ type SaleProduct struct {
 	Quantity        int     `json:"quantity"`
 	Price           float64 `json:"price"`
 	TotalPrice      float64 `json:"total_price"`
+	Unit            string  `json:"unit"`
+	UnitQuantity    int     `json:"unit_quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	Discount        float64 `json:"discount"`
+	PromotionID     string  `json:"promotion_id"`
 	CreatedAt       string  `json:"created_at"`
 	UpdatedAt       string  `json:"updated_at"`
 }
 
+// NetPrice is what a base unit sold for after the discount, the price a
+// return refunds.
+func (p *SaleProduct) NetPrice() float64 {
+	if p.Quantity == 0 {
+		return p.Price
+	}
+	return p.Price - p.Discount/float64(p.Quantity)
+}
+
 type UpdateSaleProduct struct {
 	Id              string  `json:"id"`
 	ProcutID        string  `json:"product_id"`
@@ -39,7 +56,7 @@ type GetListSaleProductRequest struct {
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 	Search string `json:"search"`
-	Query  string `json:"query"`
+	Filter Filter `json:"filter"`
 }
 
 type GetListSaleProductResponse struct {
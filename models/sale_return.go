@@ -0,0 +1,65 @@
+package models
+
+type SaleReturnPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateSaleReturnLine returns Quantity of a sale_product line.
+type CreateSaleReturnLine struct {
+	SaleProductID string `json:"sale_product_id"`
+	Quantity      int    `json:"quantity"`
+}
+
+// CreateSaleReturn takes goods of a sale back. Their value first reduces
+// the debt of the sale, the rest is refunded by Method.
+type CreateSaleReturn struct {
+	IncrementID string                  `json:"-"`
+	SaleID      string                  `json:"sale_id"`
+	Method      string                  `json:"method"`
+	Reason      string                  `json:"reason"`
+	UserID      string                  `json:"-"`
+	Lines       []*CreateSaleReturnLine `json:"lines"`
+}
+
+type SaleReturnLine struct {
+	Id            string  `json:"id"`
+	SaleReturnID  string  `json:"sale_return_id"`
+	SaleProductID string  `json:"sale_product_id"`
+	ProductID     string  `json:"product_id"`
+	Quantity      int     `json:"quantity"`
+	Price         float64 `json:"price"`
+	TotalPrice    float64 `json:"total_price"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// SaleReturn is Quantity goods worth TotalPrice returned from a sale,
+// DebtReduced of it taken off the debt and Refund of it paid back by
+// RefundPaymentID.
+type SaleReturn struct {
+	Id              string            `json:"id"`
+	IncrementID     string            `json:"increment_id"`
+	SaleID          string            `json:"sale_id"`
+	BranchID        string            `json:"branch_id"`
+	ClientID        string            `json:"client_id"`
+	Quantity        int               `json:"quantity"`
+	TotalPrice      float64           `json:"total_price"`
+	DebtReduced     float64           `json:"debt_reduced"`
+	Refund          float64           `json:"refund"`
+	RefundPaymentID string            `json:"refund_payment_id"`
+	Reason          string            `json:"reason"`
+	UserID          string            `json:"user_id"`
+	CreatedAt       string            `json:"created_at"`
+	Lines           []*SaleReturnLine `json:"lines"`
+}
+
+type GetListSaleReturnRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListSaleReturnResponse struct {
+	Count       int           `json:"count"`
+	SaleReturns []*SaleReturn `json:"sale_returns"`
+}
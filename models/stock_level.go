@@ -0,0 +1,127 @@
+package models
+
+import "math"
+
+type StockLevelPrimaryKey struct {
+	BranchID  string `json:"branch_id"`
+	ProductID string `json:"product_id"`
+}
+
+// SetStockLevel creates or replaces the min and max stock of a product in
+// a branch.
+type SetStockLevel struct {
+	BranchID    string `json:"branch_id"`
+	ProductID   string `json:"product_id"`
+	MinQuantity int    `json:"min_quantity"`
+	MaxQuantity int    `json:"max_quantity"`
+}
+
+type StockLevel struct {
+	BranchID    string `json:"branch_id"`
+	ProductID   string `json:"product_id"`
+	MinQuantity int    `json:"min_quantity"`
+	MaxQuantity int    `json:"max_quantity"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type GetListStockLevelRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListStockLevelResponse struct {
+	Count       int           `json:"count"`
+	StockLevels []*StockLevel `json:"stock_levels"`
+}
+
+type LowStockRequest struct {
+	// BranchID limits the list to one branch, all branches when empty.
+	BranchID string `json:"branch_id"`
+}
+
+// LowStock is a product whose stock in a branch, all lots together, is
+// below its minimum by Shortage.
+type LowStock struct {
+	BranchID    string `json:"branch_id"`
+	ProductID   string `json:"product_id"`
+	Name        string `json:"name"`
+	Quantity    int    `json:"quantity"`
+	MinQuantity int    `json:"min_quantity"`
+	MaxQuantity int    `json:"max_quantity"`
+	Shortage    int    `json:"shortage"`
+}
+
+type LowStockResponse struct {
+	Products []*LowStock `json:"products"`
+}
+
+// ReorderRequest asks for order quantities of the products with a stock
+// level in a branch. Sales of the last WindowDays, less the returns made in
+// them, give the average daily sales, an order is expected to arrive in
+// LeadDays.
+type ReorderRequest struct {
+	BranchID   string   `json:"branch_id"`
+	WindowDays int      `json:"window_days"`
+	LeadDays   int      `json:"lead_days"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+// ReorderLine is the stock of a product with its sales. Projected is the
+// stock expected when an order placed now arrives, Suggested what brings
+// it back to MaxQuantity, priced at the last coming Price.
+type ReorderLine struct {
+	ProductID   string  `json:"product_id"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	MinQuantity int     `json:"min_quantity"`
+	MaxQuantity int     `json:"max_quantity"`
+	Sold        int     `json:"sold"`
+	DailySales  float64 `json:"daily_sales"`
+	Projected   int     `json:"projected"`
+	Suggested   int     `json:"suggested"`
+	Price       float64 `json:"price"`
+}
+
+type ReorderResponse struct {
+	BranchID   string         `json:"branch_id"`
+	WindowDays int            `json:"window_days"`
+	LeadDays   int            `json:"lead_days"`
+	Cost       float64        `json:"cost"`
+	Lines      []*ReorderLine `json:"lines"`
+}
+
+// Propose adds l when its projected stock falls below the minimum, with
+// the quantity that brings it back to the maximum.
+func (r *ReorderResponse) Propose(l *ReorderLine) {
+
+	var demand = math.Ceil(float64(l.Sold) * float64(r.LeadDays) / float64(r.WindowDays))
+
+	l.DailySales = math.Round(float64(l.Sold)/float64(r.WindowDays)*100) / 100
+	l.Projected = l.Quantity - int(demand)
+	if l.Projected < 0 {
+		// what cannot be sold from an empty shelf is not stock to replace
+		l.Projected = 0
+	}
+
+	if l.Projected >= l.MinQuantity {
+		return
+	}
+
+	l.Suggested = l.MaxQuantity - l.Projected
+	r.Cost += float64(l.Suggested) * l.Price
+	r.Lines = append(r.Lines, l)
+}
+
+// CreateReorder turns the reorder suggestions of a branch into a purchase
+// order or a draft coming of the supplier. Zero WindowDays and LeadDays
+// take the configured defaults, ProductIDs limits it to those products.
+type CreateReorder struct {
+	BranchID   string   `json:"branch_id"`
+	SupplierID string   `json:"supplier_id"`
+	WindowDays int      `json:"window_days"`
+	LeadDays   int      `json:"lead_days"`
+	ProductIDs []string `json:"product_ids"`
+}
@@ -0,0 +1,84 @@
+package models
+
+const (
+	MovementReceipt    = "receipt"
+	MovementSale       = "sale"
+	MovementReturn     = "return"
+	MovementTransfer   = "transfer"
+	MovementWriteOff   = "write_off"
+	MovementAdjustment = "adjustment"
+)
+
+var MovementTypes = []string{MovementReceipt, MovementSale, MovementReturn, MovementTransfer, MovementWriteOff, MovementAdjustment}
+
+type StockMovementPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateStockMovement is one signed line of the journal. Name and the prices
+// are only used when the movement opens a new remainder, zero prices keep the
+// current ones. An outgoing movement without LotNumber is taken from the lots
+// earliest expiry first and written as one line per lot.
+type CreateStockMovement struct {
+	BranchID       string  `json:"branch_id"`
+	ProductID      string  `json:"product_id"`
+	Quantity       int     `json:"quantity"`
+	Type           string  `json:"type"`
+	DocumentID     string  `json:"document_id"`
+	DocumentNumber string  `json:"document_number"`
+	UserID         string  `json:"user_id"`
+	Comment        string  `json:"comment"`
+	LotNumber      string  `json:"lot_number"`
+	ExpiryDate     string  `json:"expiry_date"`
+	Name           string  `json:"-"`
+	ComingPrice    float64 `json:"-"`
+	SalePrice      float64 `json:"-"`
+}
+
+type StockMovement struct {
+	Id             string `json:"id"`
+	BranchID       string `json:"branch_id"`
+	ProductID      string `json:"product_id"`
+	Quantity       int    `json:"quantity"`
+	Type           string `json:"type"`
+	DocumentID     string `json:"document_id"`
+	DocumentNumber string `json:"document_number"`
+	UserID         string `json:"user_id"`
+	Comment        string `json:"comment"`
+	LotNumber      string `json:"lot_number"`
+	ExpiryDate     string `json:"expiry_date"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type GetListStockMovementRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListStockMovementResponse struct {
+	Count          int              `json:"count"`
+	StockMovements []*StockMovement `json:"stock_movements"`
+}
+
+type RebuildRemainderRequest struct {
+	BranchID string `json:"branch_id"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// StockDiscrepancy is a lot of a product whose remainder does not match the
+// sum of its journal.
+type StockDiscrepancy struct {
+	BranchID   string `json:"branch_id"`
+	ProductID  string `json:"product_id"`
+	LotNumber  string `json:"lot_number"`
+	Remainder  int    `json:"remainder"`
+	Journal    int    `json:"journal"`
+	Difference int    `json:"difference"`
+}
+
+type RebuildRemainderResponse struct {
+	DryRun        bool                `json:"dry_run"`
+	Discrepancies []*StockDiscrepancy `json:"discrepancies"`
+}
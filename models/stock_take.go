@@ -0,0 +1,124 @@
+package models
+
+// A stock-take is open while it is being counted. Approving it posts the
+// variance of every counted line as an adjustment.
+const (
+	StockTakeOpen     = "open"
+	StockTakeApproved = "approved"
+)
+
+type StockTakePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateStockTake opens a stock-take of a branch. ProductIDs limits it to
+// those products, all products in remainder when empty.
+type CreateStockTake struct {
+	IncrementID string                 `json:"-"`
+	BranchID    string                 `json:"branch_id"`
+	ProductIDs  []string               `json:"product_ids"`
+	UserID      string                 `json:"-"`
+	Lines       []*CreateStockTakeLine `json:"-"`
+}
+
+// CreateStockTakeLine is the snapshot of a product's remainder.
+type CreateStockTakeLine struct {
+	ProductID   string  `json:"product_id"`
+	Expected    int     `json:"expected"`
+	ComingPrice float64 `json:"coming_price"`
+}
+
+// StockTakeLine is a product of a stock-take. Counted is nil until the
+// product is counted, Variance and CostImpact are zero until then. Expected
+// is the snapshot until approval, which retakes it from the stock on hand.
+type StockTakeLine struct {
+	Id          string  `json:"id"`
+	StockTakeID string  `json:"stock_take_id"`
+	ProductID   string  `json:"product_id"`
+	Expected    int     `json:"expected"`
+	Counted     *int    `json:"counted"`
+	Variance    int     `json:"variance"`
+	ComingPrice float64 `json:"coming_price"`
+	CostImpact  float64 `json:"cost_impact"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+type StockTake struct {
+	Id          string           `json:"id"`
+	IncrementID string           `json:"increment_id"`
+	BranchID    string           `json:"branch_id"`
+	Status      string           `json:"status"`
+	UserID      string           `json:"user_id"`
+	ApprovedAt  string           `json:"approved_at"`
+	CreatedAt   string           `json:"created_at"`
+	UpdatedAt   string           `json:"updated_at"`
+	Uncounted   int              `json:"uncounted"`
+	Surplus     float64          `json:"surplus"`
+	Shortage    float64          `json:"shortage"`
+	CostImpact  float64          `json:"cost_impact"`
+	Lines       []*StockTakeLine `json:"lines"`
+}
+
+// AddLine appends l, sets its variance and cost impact at coming_price and
+// adds them to the totals.
+func (t *StockTake) AddLine(l *StockTakeLine) {
+
+	t.Lines = append(t.Lines, l)
+
+	if l.Counted == nil {
+		t.Uncounted++
+		return
+	}
+
+	l.Variance = *l.Counted - l.Expected
+	l.CostImpact = float64(l.Variance) * l.ComingPrice
+
+	if l.CostImpact > 0 {
+		t.Surplus += l.CostImpact
+	} else {
+		t.Shortage -= l.CostImpact
+	}
+	t.CostImpact += l.CostImpact
+}
+
+type GetListStockTakeRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListStockTakeResponse struct {
+	Count      int          `json:"count"`
+	StockTakes []*StockTake `json:"stock_takes"`
+}
+
+type StockTakeCount struct {
+	ProductID string `json:"product_id"`
+	Counted   int    `json:"counted"`
+}
+
+// SetStockTakeCounts sets the counted quantity of products of an open
+// stock-take, other lines keep theirs.
+type SetStockTakeCounts struct {
+	Id     string            `json:"-"`
+	Counts []*StockTakeCount `json:"counts"`
+}
+
+type StockTakeExpected struct {
+	ProductID string `json:"product_id"`
+	Expected  int    `json:"expected"`
+}
+
+// SetStockTakeExpected sets the expected quantity of products of an open
+// stock-take, approving it takes them from the stock on hand by then.
+type SetStockTakeExpected struct {
+	Id       string               `json:"-"`
+	Expected []*StockTakeExpected `json:"expected"`
+}
+
+type StockTakeStatus struct {
+	Id   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
@@ -0,0 +1,153 @@
+package models
+
+import "time"
+
+type SupplierPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateSupplier struct {
+	Name    string `json:"name"`
+	TIN     string `json:"tin"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+type Supplier struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	TIN       string `json:"tin"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type UpdateSupplier struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	TIN     string `json:"tin"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+type GetListSupplierRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListSupplierResponse struct {
+	Count     int         `json:"count"`
+	Suppliers []*Supplier `json:"suppliers"`
+}
+
+type SupplierPaymentPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateSupplierPayment pays a supplier from a branch, it may not exceed
+// what the branch owes the supplier.
+type CreateSupplierPayment struct {
+	SupplierID string  `json:"supplier_id"`
+	BranchID   string  `json:"branch_id"`
+	Amount     float64 `json:"amount"`
+	Method     string  `json:"method"`
+	Comment    string  `json:"comment"`
+	UserID     string  `json:"-"`
+}
+
+type SupplierPayment struct {
+	Id         string  `json:"id"`
+	SupplierID string  `json:"supplier_id"`
+	BranchID   string  `json:"branch_id"`
+	Amount     float64 `json:"amount"`
+	Method     string  `json:"method"`
+	Comment    string  `json:"comment"`
+	UserID     string  `json:"user_id"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+type GetListSupplierPaymentRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListSupplierPaymentResponse struct {
+	Count            int                `json:"count"`
+	SupplierPayments []*SupplierPayment `json:"supplier_payments"`
+}
+
+type SupplierStatementRequest struct {
+	SupplierID string `json:"supplier_id"`
+	// BranchID limits the statement to one branch, all branches when empty.
+	BranchID string `json:"branch_id"`
+	// From and To limit the entries to [From, To), a nil bound is open.
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+// SupplierStatementEntry is a finished coming (credit), its reversal or a
+// payment (debit) with a supplier.
+type SupplierStatementEntry struct {
+	Type        string  `json:"type"`
+	Id          string  `json:"id"`
+	ComingID    string  `json:"coming_id"`
+	IncrementID string  `json:"increment_id"`
+	BranchID    string  `json:"branch_id"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Balance     float64 `json:"balance"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// SupplierStatement runs the balance we owe the supplier, credit less debit.
+type SupplierStatement struct {
+	SupplierID     string                    `json:"supplier_id"`
+	BranchID       string                    `json:"branch_id"`
+	OpeningBalance float64                   `json:"opening_balance"`
+	Debit          float64                   `json:"debit"`
+	Credit         float64                   `json:"credit"`
+	ClosingBalance float64                   `json:"closing_balance"`
+	Entries        []*SupplierStatementEntry `json:"entries"`
+}
+
+// AddEntry appends e and sets its running balance.
+func (s *SupplierStatement) AddEntry(e *SupplierStatementEntry) {
+	s.Debit += e.Debit
+	s.Credit += e.Credit
+	s.ClosingBalance += e.Credit - e.Debit
+	e.Balance = s.ClosingBalance
+	s.Entries = append(s.Entries, e)
+}
+
+type PayablesRequest struct {
+	BranchID string `json:"branch_id"`
+}
+
+// Payable is what a branch, or all branches, owe a supplier: the total of
+// its finished comings less the payments.
+type Payable struct {
+	SupplierID string  `json:"supplier_id"`
+	Name       string  `json:"name"`
+	TIN        string  `json:"tin"`
+	Phone      string  `json:"phone"`
+	Received   float64 `json:"received"`
+	Paid       float64 `json:"paid"`
+	Balance    float64 `json:"balance"`
+}
+
+type PayablesResponse struct {
+	Suppliers []*Payable `json:"suppliers"`
+	Total     float64    `json:"total"`
+}
+
+// Add appends p with its balance and adds it to the total.
+func (r *PayablesResponse) Add(p *Payable) {
+	p.Balance = p.Received - p.Paid
+	r.Total += p.Balance
+	r.Suppliers = append(r.Suppliers, p)
+}
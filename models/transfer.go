@@ -0,0 +1,120 @@
+package models
+
+// A transfer is drafted by the sending branch. Sending takes the lines out
+// of its remainder, receiving adds what arrived to the remainder of the
+// receiving branch. Until then the goods are in transit.
+const (
+	TransferDraft    = "draft"
+	TransferSent     = "sent"
+	TransferReceived = "received"
+)
+
+type TransferPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateTransferLine struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CreateTransfer struct {
+	IncrementID  string                `json:"increment_id"`
+	FromBranchID string                `json:"from_branch_id"`
+	ToBranchID   string                `json:"to_branch_id"`
+	UserID       string                `json:"-"`
+	Lines        []*CreateTransferLine `json:"lines"`
+}
+
+// TransferLine is a product of a transfer. ReceivedQuantity is nil until
+// the transfer is received, Shortage is what did not arrive.
+type TransferLine struct {
+	Id               string  `json:"id"`
+	TransferID       string  `json:"transfer_id"`
+	ProductID        string  `json:"product_id"`
+	Quantity         int     `json:"quantity"`
+	ComingPrice      float64 `json:"coming_price"`
+	ReceivedQuantity *int    `json:"received_quantity"`
+	Shortage         int     `json:"shortage"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+type Transfer struct {
+	Id           string          `json:"id"`
+	IncrementID  string          `json:"increment_id"`
+	FromBranchID string          `json:"from_branch_id"`
+	ToBranchID   string          `json:"to_branch_id"`
+	Status       string          `json:"status"`
+	UserID       string          `json:"user_id"`
+	SentAt       string          `json:"sent_at"`
+	ReceivedAt   string          `json:"received_at"`
+	CreatedAt    string          `json:"created_at"`
+	UpdatedAt    string          `json:"updated_at"`
+	Lines        []*TransferLine `json:"lines"`
+}
+
+// UpdateTransfer replaces the destination and the lines of a draft.
+type UpdateTransfer struct {
+	Id         string                `json:"id"`
+	ToBranchID string                `json:"to_branch_id"`
+	Lines      []*CreateTransferLine `json:"lines"`
+}
+
+type GetListTransferRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+	// BranchID keeps the transfers sent from or to the branch.
+	BranchID string `json:"branch_id"`
+}
+
+type GetListTransferResponse struct {
+	Count     int         `json:"count"`
+	Transfers []*Transfer `json:"transfers"`
+}
+
+// TransferStatus moves a transfer from status From to status To and stamps
+// sent_at or received_at.
+type TransferStatus struct {
+	Id   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// UpdateTransferLine sets what was carried and what arrived of a line.
+type UpdateTransferLine struct {
+	Id               string  `json:"id"`
+	ComingPrice      float64 `json:"coming_price"`
+	ReceivedQuantity *int    `json:"received_quantity"`
+}
+
+type ReceiveTransferLine struct {
+	LineID   string `json:"line_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// ReceiveTransfer lists what arrived. Lines left out arrived in full.
+type ReceiveTransfer struct {
+	Lines []*ReceiveTransferLine `json:"lines"`
+}
+
+type InTransitRequest struct {
+	BranchID string `json:"branch_id"`
+}
+
+// InTransit is a line of a sent transfer that has not been received yet.
+type InTransit struct {
+	TransferID   string  `json:"transfer_id"`
+	IncrementID  string  `json:"increment_id"`
+	FromBranchID string  `json:"from_branch_id"`
+	ToBranchID   string  `json:"to_branch_id"`
+	ProductID    string  `json:"product_id"`
+	Quantity     int     `json:"quantity"`
+	ComingPrice  float64 `json:"coming_price"`
+	SentAt       string  `json:"sent_at"`
+}
+
+type InTransitResponse struct {
+	Lines []*InTransit `json:"lines"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultUnit is the base unit of a product created without one.
+const DefaultUnit = "pcs"
+
+// ProductUnit is a pack of a product holding Factor of its base unit, a box
+// of 10 blisters. A Price above zero is what the pack sells for, otherwise
+// it sells for Factor times the sale price of the base unit.
+type ProductUnit struct {
+	Name   string  `json:"name"`
+	Factor int     `json:"factor"`
+	Price  float64 `json:"price"`
+}
+
+// ValidateUnits trims the names of the pack units of a product with the
+// given base unit and checks them, a name is unique and not the base unit.
+func ValidateUnits(base string, units []*ProductUnit) error {
+
+	var seen = map[string]bool{strings.ToLower(base): true}
+
+	for _, unit := range units {
+		if unit == nil {
+			return fmt.Errorf("unit is empty")
+		}
+
+		unit.Name = strings.TrimSpace(unit.Name)
+
+		switch {
+		case len(unit.Name) == 0 || len(unit.Name) > 16:
+			return fmt.Errorf("unit name %q must be 1 to 16 characters", unit.Name)
+		case seen[strings.ToLower(unit.Name)]:
+			return fmt.Errorf("unit %q is given twice", unit.Name)
+		case unit.Factor < 2:
+			return fmt.Errorf("unit %q must hold at least 2 %s", unit.Name, base)
+		case unit.Price < 0:
+			return fmt.Errorf("unit %q has a negative price", unit.Name)
+		}
+
+		seen[strings.ToLower(unit.Name)] = true
+	}
+
+	return nil
+}
+
+// FindUnit returns the unit of the product by name, the base unit with a
+// factor of 1 and no price of its own when name is empty.
+func (p *Product) FindUnit(name string) (*ProductUnit, bool) {
+
+	if len(name) == 0 || strings.EqualFold(name, p.Unit) {
+		return &ProductUnit{Name: p.Unit, Factor: 1}, true
+	}
+
+	for _, unit := range p.Units {
+		if strings.EqualFold(unit.Name, name) {
+			return unit, true
+		}
+	}
+
+	return nil, false
+}
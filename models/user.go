@@ -0,0 +1,59 @@
+package models
+
+type UserPrimaryKey struct {
+	Id    string `json:"id"`
+	Login string `json:"login"`
+}
+
+type CreateUser struct {
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Login      string `json:"login"`
+	Password   string `json:"password"`
+	ClientType string `json:"client_type"`
+	BranchID   string `json:"branch_id"`
+}
+
+type User struct {
+	Id         string `json:"id"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Login      string `json:"login"`
+	Password   string `json:"-"`
+	ClientType string `json:"client_type"`
+	BranchID   string `json:"branch_id"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type UpdateUser struct {
+	Id         string `json:"id"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Login      string `json:"login"`
+	Password   string `json:"password"`
+	ClientType string `json:"client_type"`
+	BranchID   string `json:"branch_id"`
+}
+
+type GetListUserRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Search string `json:"search"`
+	Filter Filter `json:"filter"`
+}
+
+type GetListUserResponse struct {
+	Count int     `json:"count"`
+	Users []*User `json:"users"`
+}
+
+type LoginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	AccessToken string `json:"access_token"`
+	User        *User  `json:"user"`
+}
@@ -0,0 +1,158 @@
+// Package apperror holds the errors the api reports to clients: a
+// machine-readable code, a message and optional per-field details.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type Code string
+
+const (
+	NotFound            Code = "not_found"
+	Conflict            Code = "conflict"
+	Validation          Code = "validation"
+	ForeignKeyViolation Code = "foreign_key_violation"
+	Unauthorized        Code = "unauthorized"
+	Forbidden           Code = "forbidden"
+	Internal            Code = "internal"
+)
+
+var statuses = map[Code]int{
+	NotFound:            http.StatusNotFound,
+	Conflict:            http.StatusConflict,
+	Validation:          http.StatusBadRequest,
+	ForeignKeyViolation: http.StatusConflict,
+	Unauthorized:        http.StatusUnauthorized,
+	Forbidden:           http.StatusForbidden,
+	Internal:            http.StatusInternalServerError,
+}
+
+type Error struct {
+	Code    Code              `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Err     error             `json:"-"`
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status is the http status the error is reported with.
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// WithField adds a detail about one field of the request.
+func (e *Error) WithField(field, message string) *Error {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	e.Fields[field] = message
+	return e
+}
+
+// CodeOf is the code of an error reported with http status.
+func CodeOf(status int) Code {
+	for code, s := range statuses {
+		if s == status && code != ForeignKeyViolation {
+			return code
+		}
+	}
+	return Internal
+}
+
+// Translate turns pgx.ErrNoRows and the constraint violations of postgres
+// into an *Error that wraps the original error, and returns any other
+// error as it is.
+func Translate(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Code: NotFound, Message: "not found", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		appErr = &Error{Code: Conflict, Message: "already exists", Err: err}
+		for _, column := range keyColumns(pgErr.Detail) {
+			appErr.WithField(column, "already exists")
+		}
+	case "23503":
+		appErr = &Error{Code: ForeignKeyViolation, Message: "referenced record does not exist or is still in use", Err: err}
+		for _, column := range keyColumns(pgErr.Detail) {
+			if strings.Contains(pgErr.Detail, "is still referenced") {
+				appErr.WithField(column, "is still in use")
+			} else {
+				appErr.WithField(column, "does not exist")
+			}
+		}
+	case "23514":
+		appErr = &Error{Code: Validation, Message: "violates check " + pgErr.ConstraintName, Err: err}
+		column := strings.TrimSuffix(strings.TrimPrefix(pgErr.ConstraintName, pgErr.TableName+"_"), "_check")
+		if len(column) > 0 {
+			appErr.WithField(column, "is out of the allowed values")
+		}
+	default:
+		return err
+	}
+
+	return appErr
+}
+
+// keyColumns reads the columns from a detail such as
+// "Key (branch_id, increment_id)=(..., S-0000001) already exists."
+func keyColumns(detail string) []string {
+
+	start := strings.Index(detail, "Key (")
+	if start < 0 {
+		return nil
+	}
+
+	detail = detail[start+len("Key ("):]
+	end := strings.Index(detail, ")=")
+	if end < 0 {
+		return nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(detail[:end], ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+
+	return columns
+}
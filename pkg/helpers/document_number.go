@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"fmt"
+)
+
+// FormatDocumentNumber renders a counter value as a document number such
+// as S-0000001, or S-2024-0000001 when the counter is reset every year.
+func FormatDocumentNumber(prefix string, year int, value int64, width int) string {
+
+	if year > 0 {
+		return fmt.Sprintf("%s%d-%0*d", prefix, year, width, value)
+	}
+
+	return fmt.Sprintf("%s%0*d", prefix, width, value)
+}
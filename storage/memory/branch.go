@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type branchRepo struct {
+	s *Store
+}
+
+func (r *branchRepo) Create(ctx context.Context, req *models.CreateBranch) (*models.Branch, error) {
+
+	branch := models.Branch{
+		Id:        uuid.New().String(),
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.branches = append(r.s.db.branches, branch)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.BranchPrimaryKey{Id: branch.Id})
+}
+
+func (r *branchRepo) GetByID(ctx context.Context, req *models.BranchPrimaryKey) (*models.Branch, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.branches, func(b models.Branch) bool { return b.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	branch := r.s.db.branches[i]
+	return &branch, nil
+}
+
+func (r *branchRepo) GetList(ctx context.Context, req *models.GetListBranchRequest) (*models.GetListBranchResponse, error) {
+
+	if err := req.Filter.Validate(models.BranchFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListBranchResponse
+		found = newest(r.s.db.branches, func(b models.Branch) bool {
+			return match(models.BranchFilterSpec, req.Search, req.Filter, branchRow(b))
+		})
+	)
+
+	for _, b := range page(found, req.Offset, req.Limit) {
+		branch := b
+		resp.Count = len(found)
+		resp.Branches = append(resp.Branches, &branch)
+	}
+
+	return &resp, nil
+}
+
+func (r *branchRepo) Update(ctx context.Context, req *models.UpdateBranch) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.branches, func(b models.Branch) bool { return b.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	branch := &r.s.db.branches[i]
+	branch.Name = req.Name
+	branch.Address = req.Address
+	branch.Phone = req.Phone
+	branch.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *branchRepo) Delete(ctx context.Context, req *models.BranchPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.branches = remove(r.s.db.branches, func(b models.Branch) bool { return b.Id == req.Id })
+	r.s.db.branchProducts = remove(r.s.db.branchProducts, func(b branchProduct) bool { return b.BranchID == req.Id })
+	r.s.db.priceChanges = remove(r.s.db.priceChanges, func(c models.PriceChange) bool { return c.BranchID == req.Id })
+	r.s.db.repricings = remove(r.s.db.repricings, func(p models.Repricing) bool { return p.BranchID == req.Id })
+	r.s.db.promotions = remove(r.s.db.promotions, func(p models.Promotion) bool { return p.BranchID == req.Id })
+
+	return nil
+}
+
+func branchRow(b models.Branch) row {
+	return row{
+		"name":       b.Name,
+		"address":    b.Address,
+		"phone":      b.Phone,
+		"created_at": b.CreatedAt,
+	}
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"market_system/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type branchProductRepo struct {
+	s *Store
+}
+
+// branchProduct is a row of branch_product, Price is nil when the branch
+// sells at the catalog price.
+type branchProduct struct {
+	BranchID  string
+	ProductID string
+	Price     *float64
+	CreatedAt string
+	UpdatedAt string
+}
+
+// setBranchProduct inserts or replaces a row of branch_product and records a
+// changed price in the history, the caller holds the lock.
+func (d *database) setBranchProduct(branchId, productId string, price *float64) {
+
+	if price != nil {
+		p := *price
+		price = &p
+	}
+
+	i := indexOf(d.branchProducts, func(b branchProduct) bool { return b.BranchID == branchId && b.ProductID == productId })
+	if i < 0 {
+		d.branchProducts = append(d.branchProducts, branchProduct{BranchID: branchId, ProductID: productId, CreatedAt: now()})
+		i = len(d.branchProducts) - 1
+	}
+
+	d.branchProducts[i].Price = price
+	d.branchProducts[i].UpdatedAt = now()
+	d.recordPrice(productId, branchId, price)
+	d.repriceStock(branchId, productId)
+}
+
+// repriceStock sets the sale price of the lots of the product in the branch,
+// or in every branch when branchId is empty, to the price the branch sells
+// it at, the caller holds the lock.
+func (d *database) repriceStock(branchId, productId string) {
+
+	for i, rm := range d.remainders {
+		if rm.ProductID != productId || len(branchId) > 0 && rm.BranchID != branchId {
+			continue
+		}
+
+		if price, ok := d.salePrice(rm.BranchID, productId); ok {
+			d.remainders[i].SalePrice = price
+			d.remainders[i].UpdatedAt = now()
+		}
+	}
+}
+
+// branchProductOf joins b with its product, the caller holds the lock.
+func (d *database) branchProductOf(b branchProduct) (*models.BranchProduct, bool) {
+
+	p := indexOf(d.products, func(p models.Product) bool { return p.Id == b.ProductID })
+	if p < 0 {
+		return nil, false
+	}
+
+	resp := &models.BranchProduct{
+		BranchID:     b.BranchID,
+		ProductID:    b.ProductID,
+		Name:         d.products[p].Name,
+		CatalogPrice: d.products[p].Price,
+		SalePrice:    d.products[p].Price,
+		CreatedAt:    b.CreatedAt,
+		UpdatedAt:    b.UpdatedAt,
+	}
+	if b.Price != nil {
+		price := *b.Price
+		resp.Price, resp.SalePrice = &price, price
+	}
+
+	return resp, true
+}
+
+// salePrice is what the branch sells the product at, the caller holds the
+// lock.
+func (d *database) salePrice(branchId, productId string) (float64, bool) {
+
+	p := indexOf(d.products, func(p models.Product) bool { return p.Id == productId })
+	if p < 0 {
+		return 0, false
+	}
+
+	i := indexOf(d.branchProducts, func(b branchProduct) bool { return b.BranchID == branchId && b.ProductID == productId })
+	if i >= 0 && d.branchProducts[i].Price != nil {
+		return *d.branchProducts[i].Price, true
+	}
+
+	return d.products[p].Price, true
+}
+
+func (r *branchProductRepo) Set(ctx context.Context, req *models.SetBranchProduct) (*models.BranchProduct, error) {
+
+	r.s.mu.Lock()
+	r.s.db.setBranchProduct(req.BranchID, req.ProductID, req.Price)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: req.BranchID, ProductID: req.ProductID})
+}
+
+func (r *branchProductRepo) GetByID(ctx context.Context, req *models.BranchProductPrimaryKey) (*models.BranchProduct, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.branchProducts, func(b branchProduct) bool {
+		return b.BranchID == req.BranchID && b.ProductID == req.ProductID
+	})
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	resp, ok := r.s.db.branchProductOf(r.s.db.branchProducts[i])
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	return resp, nil
+}
+
+func (r *branchProductRepo) GetList(ctx context.Context, req *models.GetListBranchProductRequest) (*models.GetListBranchProductResponse, error) {
+
+	if err := req.Filter.Validate(models.BranchProductFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListBranchProductResponse
+		found []*models.BranchProduct
+	)
+
+	for _, b := range r.s.db.branchProducts {
+		branchProduct, ok := r.s.db.branchProductOf(b)
+		if ok && match(models.BranchProductFilterSpec, req.Search, req.Filter, branchProductRow(branchProduct)) {
+			found = append(found, branchProduct)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Name != found[j].Name {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].BranchID < found[j].BranchID
+	})
+
+	resp.Count = len(found)
+	resp.BranchProducts = page(found, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (r *branchProductRepo) Delete(ctx context.Context, req *models.BranchProductPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.branchProducts = remove(r.s.db.branchProducts, func(b branchProduct) bool {
+		return b.BranchID == req.BranchID && b.ProductID == req.ProductID
+	})
+	r.s.db.repriceStock(req.BranchID, req.ProductID)
+
+	return nil
+}
+
+func (r *branchProductRepo) SalePrice(ctx context.Context, req *models.BranchProductPrimaryKey) (float64, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	price, ok := r.s.db.salePrice(req.BranchID, req.ProductID)
+	if !ok {
+		return 0, pgx.ErrNoRows
+	}
+
+	return price, nil
+}
+
+func (r *branchProductRepo) Copy(ctx context.Context, req *models.CopyCatalog) (*models.CopyCatalogResponse, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var resp = models.CopyCatalogResponse{FromBranchID: req.FromBranchID, ToBranchID: req.ToBranchID}
+
+	for _, b := range append([]branchProduct(nil), r.s.db.branchProducts...) {
+		if b.BranchID == req.FromBranchID {
+			r.s.db.setBranchProduct(req.ToBranchID, b.ProductID, b.Price)
+			resp.Copied++
+		}
+	}
+
+	return &resp, nil
+}
+
+func branchProductRow(b *models.BranchProduct) row {
+	return row{
+		"branch_id":    b.BranchID,
+		"product_id":   b.ProductID,
+		"product.name": b.Name,
+		"created_at":   b.CreatedAt,
+	}
+}
@@ -0,0 +1,347 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type categoryRepo struct {
+	s *Store
+}
+
+func (r *categoryRepo) Create(ctx context.Context, req *models.CreateCategory) (*models.Category, error) {
+
+	category := models.Category{
+		Id:        uuid.New().String(),
+		Name:      req.Name,
+		ParentID:  req.ParentID,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.categories = append(r.s.db.categories, category)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.CategoryPrimaryKey{Id: category.Id})
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, req *models.CategoryPrimaryKey) (*models.Category, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.categories, func(c models.Category) bool { return c.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	category := r.s.db.categories[i]
+	return &category, nil
+}
+
+func (r *categoryRepo) GetList(ctx context.Context, req *models.GetListCategoryRequest) (*models.GetListCategoryResponse, error) {
+
+	if err := req.Filter.Validate(models.CategoryFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListCategoryResponse
+		found = newest(r.s.db.categories, func(c models.Category) bool {
+			return match(models.CategoryFilterSpec, req.Search, req.Filter, categoryRow(c))
+		})
+	)
+
+	resp.Count = len(found)
+	for _, c := range page(found, req.Offset, req.Limit) {
+		category := c
+		resp.Categories = append(resp.Categories, &category)
+	}
+
+	return &resp, nil
+}
+
+func (r *categoryRepo) Update(ctx context.Context, req *models.UpdateCategory) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.categories, func(c models.Category) bool { return c.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	for parentId, seen := req.ParentID, map[string]bool{}; len(parentId) > 0 && !seen[parentId]; {
+		if parentId == req.Id {
+			return 0, storage.ErrCategoryCycle
+		}
+		seen[parentId] = true
+
+		p := indexOf(r.s.db.categories, func(c models.Category) bool { return c.Id == parentId })
+		if p < 0 {
+			break
+		}
+		parentId = r.s.db.categories[p].ParentID
+	}
+
+	category := &r.s.db.categories[i]
+	category.Name = req.Name
+	category.ParentID = req.ParentID
+	category.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *categoryRepo) Delete(ctx context.Context, req *models.CategoryPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.categories = remove(r.s.db.categories, func(c models.Category) bool { return c.Id == req.Id })
+	r.s.db.promotions = remove(r.s.db.promotions, func(p models.Promotion) bool { return p.CategoryID == req.Id })
+
+	return nil
+}
+
+func (r *categoryRepo) GetTree(ctx context.Context) ([]*models.CategoryNode, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var categories []*models.Category
+	for _, c := range r.s.db.categories {
+		category := c
+		categories = append(categories, &category)
+	}
+
+	return models.CategoryTree(categories), nil
+}
+
+func (r *categoryRepo) Subtree(ctx context.Context, req *models.CategoryPrimaryKey) ([]string, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if indexOf(r.s.db.categories, func(c models.Category) bool { return c.Id == req.Id }) < 0 {
+		return nil, nil
+	}
+
+	var resp = []string{req.Id}
+	for id := range r.s.db.categoryTops(req.Id) {
+		resp = append(resp, id)
+	}
+
+	return resp, nil
+}
+
+func (r *categoryRepo) Sales(ctx context.Context, req *models.CategoryReportRequest) (*models.CategorySalesResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		tops   = r.s.db.categoryTops(req.ParentID)
+		groups = map[string]*models.CategorySales{}
+	)
+
+	for _, sp := range r.s.db.saleProducts {
+		i := indexOf(r.s.db.sales, func(s models.Sale) bool { return s.Id == sp.SaleID })
+		if i < 0 || len(req.BranchID) > 0 && r.s.db.sales[i].BranchID != req.BranchID {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339Nano, sp.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if req.From != nil && createdAt.Before(*req.From) || req.To != nil && !createdAt.Before(*req.To) {
+			continue
+		}
+
+		top, ok := r.s.db.categoryGroup(tops, req.ParentID, sp.ProcutID)
+		if !ok {
+			continue
+		}
+
+		if groups[top] == nil {
+			groups[top] = &models.CategorySales{}
+		}
+		groups[top].Quantity += sp.Quantity
+		groups[top].Amount += sp.TotalPrice
+
+		for _, l := range r.s.db.saleReturnLines {
+			if l.SaleProductID == sp.Id {
+				groups[top].ReturnedQuantity += l.Quantity
+				groups[top].ReturnedAmount += l.TotalPrice
+			}
+		}
+	}
+
+	children, parent, err := r.s.db.reportRows(req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp = models.CategorySalesResponse{ParentID: req.ParentID}
+	for _, child := range children {
+		row, ok := groups[child.Id]
+		if !ok {
+			row = &models.CategorySales{}
+		}
+		row.CategoryID, row.Name = child.Id, child.Name
+		resp.Add(row)
+	}
+
+	if row, ok := groups[""]; ok {
+		row.CategoryID, row.Name = parent.Id, parent.Name
+		resp.Add(row)
+	}
+
+	return &resp, nil
+}
+
+func (r *categoryRepo) Stock(ctx context.Context, req *models.CategoryReportRequest) (*models.CategoryStockResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		tops   = r.s.db.categoryTops(req.ParentID)
+		groups = map[string]*models.CategoryStock{}
+	)
+
+	for _, rm := range r.s.db.remainders {
+		if len(req.BranchID) > 0 && rm.BranchID != req.BranchID {
+			continue
+		}
+
+		top, ok := r.s.db.categoryGroup(tops, req.ParentID, rm.ProductID)
+		if !ok {
+			continue
+		}
+
+		if groups[top] == nil {
+			groups[top] = &models.CategoryStock{}
+		}
+		groups[top].Quantity += rm.Quantity
+		groups[top].Cost += float64(rm.Quantity) * rm.ComingPrice
+		groups[top].Value += float64(rm.Quantity) * rm.SalePrice
+	}
+
+	children, parent, err := r.s.db.reportRows(req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp = models.CategoryStockResponse{ParentID: req.ParentID}
+	for _, child := range children {
+		row, ok := groups[child.Id]
+		if !ok {
+			row = &models.CategoryStock{}
+		}
+		row.CategoryID, row.Name = child.Id, child.Name
+		resp.Add(row)
+	}
+
+	if row, ok := groups[""]; ok {
+		row.CategoryID, row.Name = parent.Id, parent.Name
+		resp.Add(row)
+	}
+
+	return &resp, nil
+}
+
+// categoryTops maps every descendant of the children of parentId, the roots
+// when it is empty, to the child it is under. The caller holds the lock.
+func (d *database) categoryTops(parentId string) map[string]string {
+
+	var (
+		tops  = map[string]string{}
+		queue []string
+	)
+
+	for _, c := range d.categories {
+		if c.ParentID == parentId {
+			tops[c.Id] = c.Id
+			queue = append(queue, c.Id)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, c := range d.categories {
+			if _, seen := tops[c.Id]; !seen && c.ParentID == id {
+				tops[c.Id] = tops[id]
+				queue = append(queue, c.Id)
+			}
+		}
+	}
+
+	return tops
+}
+
+// categoryGroup is the child of parentId a product is counted under, empty
+// when the product is directly in the parent. False when it is elsewhere.
+func (d *database) categoryGroup(tops map[string]string, parentId, productId string) (string, bool) {
+
+	i := indexOf(d.products, func(p models.Product) bool { return p.Id == productId })
+	if i < 0 {
+		return "", false
+	}
+
+	categoryId := d.products[i].CategoryID
+	if top, ok := tops[categoryId]; ok && len(categoryId) > 0 {
+		return top, true
+	}
+
+	return "", categoryId == parentId
+}
+
+// reportRows lists the children of parentId by name and the parent itself,
+// the caller holds the lock.
+func (d *database) reportRows(parentId string) ([]*models.Category, *models.Category, error) {
+
+	var (
+		children []*models.Category
+		parent   = &models.Category{Id: parentId}
+	)
+
+	if len(parentId) > 0 {
+		i := indexOf(d.categories, func(c models.Category) bool { return c.Id == parentId })
+		if i < 0 {
+			return nil, nil, pgx.ErrNoRows
+		}
+		parent.Name = d.categories[i].Name
+	}
+
+	for _, c := range d.categories {
+		if c.ParentID == parentId {
+			children = append(children, &models.Category{Id: c.Id, Name: c.Name})
+		}
+	}
+
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+
+	return children, parent, nil
+}
+
+func categoryRow(c models.Category) row {
+	return row{
+		"name":       c.Name,
+		"parent_id":  c.ParentID,
+		"created_at": c.CreatedAt,
+	}
+}
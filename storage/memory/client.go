@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type clientRepo struct {
+	s *Store
+}
+
+func (r *clientRepo) Create(ctx context.Context, req *models.CreateClient) (*models.Client, error) {
+
+	birthday, err := time.Parse("2006-01-02", req.Birthday)
+	if err != nil {
+		return nil, err
+	}
+
+	client := models.Client{
+		Id:         uuid.New().String(),
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		FatherName: req.FatherName,
+		Phone:      req.Phone,
+		Birthday:   birthday.Format(time.RFC3339),
+		Gender:     req.Gender,
+		BranchID:   req.BranchID,
+		Active:     req.Active,
+		Group:      req.Group,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.clients = append(r.s.db.clients, client)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.ClientPrimaryKey{Id: client.Id})
+}
+
+func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey) (*models.Client, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.clients, func(c models.Client) bool { return c.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	client := r.s.db.clients[i]
+	return &client, nil
+}
+
+func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientRequest) (*models.GetListClientResponse, error) {
+
+	if err := req.Filter.Validate(models.ClientFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListClientResponse
+		found = newest(r.s.db.clients, func(c models.Client) bool {
+			return match(models.ClientFilterSpec, req.Search, req.Filter, clientRow(c))
+		})
+	)
+
+	for _, c := range page(found, req.Offset, req.Limit) {
+		client := c
+		resp.Count = len(found)
+		resp.Clients = append(resp.Clients, &client)
+	}
+
+	return &resp, nil
+}
+
+func (r *clientRepo) Update(ctx context.Context, req *models.UpdateClient) (int64, error) {
+
+	birthday, err := time.Parse("2006-01-02", req.Birthday)
+	if err != nil {
+		return 0, err
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.clients, func(c models.Client) bool { return c.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	client := &r.s.db.clients[i]
+	client.FirstName = req.FirstName
+	client.LastName = req.LastName
+	client.FatherName = req.FatherName
+	client.Phone = req.Phone
+	client.Birthday = birthday.Format(time.RFC3339)
+	client.Gender = req.Gender
+	client.BranchID = req.BranchID
+	client.Group = req.Group
+	client.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *clientRepo) Delete(ctx context.Context, req *models.ClientPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.clients = remove(r.s.db.clients, func(c models.Client) bool { return c.Id == req.Id })
+
+	return nil
+}
+
+func clientRow(c models.Client) row {
+	return row{
+		"first_name":  c.FirstName,
+		"last_name":   c.LastName,
+		"father_name": c.FatherName,
+		"phone":       c.Phone,
+		"gender":      c.Gender,
+		"branch_id":   c.BranchID,
+		"active":      c.Active,
+		"group":       c.Group,
+		"created_at":  c.CreatedAt,
+	}
+}
+
+func (r *clientRepo) Statement(ctx context.Context, req *models.ClientStatementRequest) (*models.ClientStatement, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		entries []*models.ClientStatementEntry
+		sales   = map[string]models.Sale{}
+	)
+
+	for _, s := range r.s.db.sales {
+		if s.ClientID != req.ClientID {
+			continue
+		}
+		sales[s.Id] = s
+		entries = append(entries, &models.ClientStatementEntry{
+			Type:        "sale",
+			Id:          s.Id,
+			SaleID:      s.Id,
+			IncrementID: s.IncrementID,
+			Debit:       s.TotalPrice,
+			CreatedAt:   s.CreatedAt,
+		})
+	}
+
+	for _, p := range r.s.db.payments {
+		s, ok := sales[p.SaleID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, &models.ClientStatementEntry{
+			Type:        "payment",
+			Id:          p.Id,
+			SaleID:      p.SaleID,
+			IncrementID: s.IncrementID,
+			Credit:      p.Amount,
+			CreatedAt:   p.CreatedAt,
+		})
+	}
+
+	for _, sr := range r.s.db.saleReturns {
+		if sr.ClientID != req.ClientID {
+			continue
+		}
+		entries = append(entries, &models.ClientStatementEntry{
+			Type:        "return",
+			Id:          sr.Id,
+			SaleID:      sr.SaleID,
+			IncrementID: sr.IncrementID,
+			Credit:      sr.TotalPrice,
+			CreatedAt:   sr.CreatedAt,
+		})
+	}
+
+	var times = make(map[*models.ClientStatementEntry]time.Time, len(entries))
+	for _, e := range entries {
+		createdAt, err := time.Parse(time.RFC3339Nano, e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		times[e] = createdAt
+	}
+
+	// a sale comes before the returns and payments made at the same moment,
+	// a return before the refund it issued
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !times[entries[i]].Equal(times[entries[j]]) {
+			return times[entries[i]].Before(times[entries[j]])
+		}
+		return entries[i].Type > entries[j].Type
+	})
+
+	var resp = models.ClientStatement{ClientID: req.ClientID}
+
+	for _, e := range entries {
+		switch createdAt := times[e]; {
+		case req.From != nil && createdAt.Before(*req.From):
+			resp.OpeningBalance += e.Debit - e.Credit
+			resp.ClosingBalance = resp.OpeningBalance
+		case req.To != nil && !createdAt.Before(*req.To):
+		default:
+			resp.AddEntry(e)
+		}
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type comingRepo struct {
+	s *Store
+}
+
+func (r *comingRepo) Create(ctx context.Context, req *models.CreateComing) (*models.Coming, error) {
+
+	coming := models.Coming{
+		Id:              uuid.New().String(),
+		IncrementID:     req.IncrementID,
+		BranchID:        req.BranchID,
+		SupplierID:      req.SupplierID,
+		PurchaseOrderID: req.PurchaseOrderID,
+		Status:          models.ComingDraft,
+		CreatedAt:       now(),
+		UpdatedAt:       now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.comings = append(r.s.db.comings, coming)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.ComingPrimaryKey{Id: coming.Id})
+}
+
+func (r *comingRepo) GetByID(ctx context.Context, req *models.ComingPrimaryKey) (*models.Coming, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.comings, func(c models.Coming) bool { return c.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	coming := r.s.db.comings[i]
+	coming.TotalPrice = r.s.db.comingTotal(coming.Id)
+	return &coming, nil
+}
+
+func (r *comingRepo) GetList(ctx context.Context, req *models.GetListComingRequest) (*models.GetListComingResponse, error) {
+
+	if err := req.Filter.Validate(models.ComingFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListComingResponse
+		found = newest(r.s.db.comings, func(c models.Coming) bool {
+			return match(models.ComingFilterSpec, req.Search, req.Filter, comingRow(c))
+		})
+	)
+
+	for _, c := range page(found, req.Offset, req.Limit) {
+		coming := c
+		coming.TotalPrice = r.s.db.comingTotal(coming.Id)
+		resp.Count = len(found)
+		resp.Cominges = append(resp.Cominges, &coming)
+	}
+
+	return &resp, nil
+}
+
+func (r *comingRepo) Update(ctx context.Context, req *models.UpdateComing) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.comings, func(c models.Coming) bool { return c.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	coming := &r.s.db.comings[i]
+	coming.BranchID = req.BranchID
+	coming.SupplierID = req.SupplierID
+	coming.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *comingRepo) Delete(ctx context.Context, req *models.ComingPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.comings = remove(r.s.db.comings, func(c models.Coming) bool { return c.Id == req.Id })
+
+	return nil
+}
+
+func (r *comingRepo) SetStatus(ctx context.Context, req *models.ComingStatus) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.comings, func(c models.Coming) bool { return c.Id == req.Id && c.Status == req.From })
+	if i < 0 {
+		return 0, nil
+	}
+
+	r.s.db.comings[i].Status = req.To
+	r.s.db.comings[i].UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *comingRepo) CreateEvent(ctx context.Context, req *models.CreateComingEvent) (*models.ComingEvent, error) {
+
+	event := models.ComingEvent{
+		Id:        uuid.New().String(),
+		ComingID:  req.ComingID,
+		Action:    req.Action,
+		UserID:    req.UserID,
+		Reason:    req.Reason,
+		CreatedAt: now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.comingEvents = append(r.s.db.comingEvents, event)
+	r.s.mu.Unlock()
+
+	return &event, nil
+}
+
+func (r *comingRepo) GetEvents(ctx context.Context, req *models.ComingPrimaryKey) ([]*models.ComingEvent, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var resp []*models.ComingEvent
+	for _, e := range r.s.db.comingEvents {
+		if e.ComingID == req.Id {
+			event := e
+			resp = append(resp, &event)
+		}
+	}
+
+	return resp, nil
+}
+
+// comingTotal sums the picking lists of a coming, the caller holds the lock.
+func (d *database) comingTotal(comingId string) float64 {
+
+	var total float64
+	for _, p := range d.pickingLists {
+		if p.ComingID == comingId {
+			total += p.Total_price
+		}
+	}
+
+	return total
+}
+
+// comingBranch is the branch of a coming, the caller holds the lock.
+func (d *database) comingBranch(comingId string) string {
+
+	if i := indexOf(d.comings, func(c models.Coming) bool { return c.Id == comingId }); i >= 0 {
+		return d.comings[i].BranchID
+	}
+
+	return ""
+}
+
+func comingRow(c models.Coming) row {
+	return row{
+		"increment_id":      c.IncrementID,
+		"branch_id":         c.BranchID,
+		"supplier_id":       c.SupplierID,
+		"purchase_order_id": c.PurchaseOrderID,
+		"status":            c.Status,
+		"created_at":        c.CreatedAt,
+	}
+}
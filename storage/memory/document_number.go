@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"market_system/models"
+	"market_system/pkg/helpers"
+)
+
+type counterKey struct {
+	document string
+	scope    string
+	year     int
+}
+
+type documentNumberRepo struct {
+	s *Store
+}
+
+func (r *documentNumberRepo) Next(ctx context.Context, req *models.NextDocumentNumber) (string, error) {
+
+	key := counterKey{document: req.Document}
+
+	if req.PerBranch {
+		key.scope = req.BranchID
+	}
+
+	if req.YearlyReset {
+		key.year = time.Now().Year()
+	}
+
+	r.s.mu.Lock()
+	r.s.db.counters[key]++
+	value := r.s.db.counters[key]
+	r.s.mu.Unlock()
+
+	return helpers.FormatDocumentNumber(req.Prefix, key.year, value, req.Width), nil
+}
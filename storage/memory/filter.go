@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"strings"
+	"time"
+
+	"market_system/models"
+
+	"github.com/spf13/cast"
+)
+
+// row is a record as column -> value, the way the postgres repos see it.
+type row map[string]interface{}
+
+// match reports whether r passes search and filter, following the same
+// rules as the WHERE clause built by the postgres repos.
+func match(spec models.FilterSpec, search string, filter models.Filter, r row) bool {
+
+	for column, values := range filter.Fields {
+		if len(values) > 0 && !contains(values, cast.ToString(r[column])) {
+			return false
+		}
+	}
+
+	for column, numberRange := range filter.Ranges {
+		value := cast.ToFloat64(r[column])
+		if numberRange.Min != nil && value < *numberRange.Min {
+			return false
+		}
+		if numberRange.Max != nil && value > *numberRange.Max {
+			return false
+		}
+	}
+
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, cast.ToString(r["created_at"]))
+		if err != nil {
+			return false
+		}
+		if filter.CreatedFrom != nil && createdAt.Before(*filter.CreatedFrom) {
+			return false
+		}
+		if filter.CreatedTo != nil && !createdAt.Before(*filter.CreatedTo) {
+			return false
+		}
+	}
+
+	if len(search) > 0 && len(spec.Search) > 0 {
+		for _, column := range spec.Search {
+			if strings.Contains(strings.ToLower(cast.ToString(r[column])), strings.ToLower(search)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"market_system/models"
+	"market_system/storage"
+)
+
+// database holds every table in insertion order, so iterating a slice
+// backwards gives the same "ORDER BY created_at DESC" as postgres.
+type database struct {
+	branches           []models.Branch
+	clients            []models.Client
+	products           []models.Product
+	productBarcodes    []productBarcode
+	productUnits       []productUnit
+	branchProducts     []branchProduct
+	priceChanges       []models.PriceChange
+	repricings         []models.Repricing
+	promotions         []models.Promotion
+	categories         []models.Category
+	comings            []models.Coming
+	comingEvents       []models.ComingEvent
+	pickingLists       []models.PickingList
+	remainders         []models.Remainder
+	stockMovements     []models.StockMovement
+	transfers          []models.Transfer
+	transferLines      []models.TransferLine
+	stockTakes         []models.StockTake
+	stockTakeLines     []models.StockTakeLine
+	sales              []models.Sale
+	saleProducts       []models.SaleProduct
+	saleReturns        []models.SaleReturn
+	saleReturnLines    []models.SaleReturnLine
+	payments           []models.Payment
+	users              []models.User
+	suppliers          []models.Supplier
+	supplierPayments   []models.SupplierPayment
+	purchaseOrders     []models.PurchaseOrder
+	purchaseOrderLines []models.PurchaseOrderLine
+	stockLevels        []models.StockLevel
+	counters           map[counterKey]int64
+}
+
+func (d *database) clone() *database {
+	return &database{
+		branches:           append([]models.Branch(nil), d.branches...),
+		clients:            append([]models.Client(nil), d.clients...),
+		products:           append([]models.Product(nil), d.products...),
+		productBarcodes:    append([]productBarcode(nil), d.productBarcodes...),
+		productUnits:       append([]productUnit(nil), d.productUnits...),
+		branchProducts:     append([]branchProduct(nil), d.branchProducts...),
+		priceChanges:       append([]models.PriceChange(nil), d.priceChanges...),
+		repricings:         append([]models.Repricing(nil), d.repricings...),
+		promotions:         append([]models.Promotion(nil), d.promotions...),
+		categories:         append([]models.Category(nil), d.categories...),
+		comings:            append([]models.Coming(nil), d.comings...),
+		comingEvents:       append([]models.ComingEvent(nil), d.comingEvents...),
+		pickingLists:       append([]models.PickingList(nil), d.pickingLists...),
+		remainders:         append([]models.Remainder(nil), d.remainders...),
+		stockMovements:     append([]models.StockMovement(nil), d.stockMovements...),
+		transfers:          append([]models.Transfer(nil), d.transfers...),
+		transferLines:      append([]models.TransferLine(nil), d.transferLines...),
+		stockTakes:         append([]models.StockTake(nil), d.stockTakes...),
+		stockTakeLines:     append([]models.StockTakeLine(nil), d.stockTakeLines...),
+		sales:              append([]models.Sale(nil), d.sales...),
+		saleProducts:       append([]models.SaleProduct(nil), d.saleProducts...),
+		saleReturns:        append([]models.SaleReturn(nil), d.saleReturns...),
+		saleReturnLines:    append([]models.SaleReturnLine(nil), d.saleReturnLines...),
+		payments:           append([]models.Payment(nil), d.payments...),
+		users:              append([]models.User(nil), d.users...),
+		suppliers:          append([]models.Supplier(nil), d.suppliers...),
+		supplierPayments:   append([]models.SupplierPayment(nil), d.supplierPayments...),
+		purchaseOrders:     append([]models.PurchaseOrder(nil), d.purchaseOrders...),
+		purchaseOrderLines: append([]models.PurchaseOrderLine(nil), d.purchaseOrderLines...),
+		stockLevels:        append([]models.StockLevel(nil), d.stockLevels...),
+		counters:           cloneMap(d.counters),
+	}
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	resp := make(map[K]V, len(m))
+	for k, v := range m {
+		resp[k] = v
+	}
+	return resp
+}
+
+type Store struct {
+	mu *sync.RWMutex
+	db *database
+}
+
+// NewStore returns an empty in-memory storage.StorageI.
+func NewStore() storage.StorageI {
+	return &Store{
+		mu: &sync.RWMutex{},
+		db: &database{counters: map[counterKey]int64{}},
+	}
+}
+
+// WithTx runs fn against a copy of the data and swaps it in when fn
+// returns nil. Other callers are blocked until the transaction ends.
+func (s *Store) WithTx(ctx context.Context, fn func(tx storage.StorageI) error) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &Store{
+		mu: &sync.RWMutex{},
+		db: s.db.clone(),
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	s.db = tx.db
+
+	return nil
+}
+
+func (s *Store) Branch() storage.BranchRepoI {
+	return &branchRepo{s: s}
+}
+
+func (s *Store) Client() storage.ClientRepoI {
+	return &clientRepo{s: s}
+}
+
+func (s *Store) Product() storage.ProductRepoI {
+	return &productRepo{s: s}
+}
+
+func (s *Store) Coming() storage.ComingRepoI {
+	return &comingRepo{s: s}
+}
+
+func (s *Store) PickingList() storage.PickingListRepoI {
+	return &pickingListRepo{s: s}
+}
+
+func (s *Store) Remainder() storage.RemainderRepoI {
+	return &remainderRepo{s: s}
+}
+
+func (s *Store) StockMovement() storage.StockMovementRepoI {
+	return &stockMovementRepo{s: s}
+}
+
+func (s *Store) Transfer() storage.TransferRepoI {
+	return &transferRepo{s: s}
+}
+
+func (s *Store) StockTake() storage.StockTakeRepoI {
+	return &stockTakeRepo{s: s}
+}
+
+func (s *Store) Sale() storage.SaleRepoI {
+	return &saleRepo{s: s}
+}
+
+func (s *Store) SaleReturn() storage.SaleReturnRepoI {
+	return &saleReturnRepo{s: s}
+}
+
+func (s *Store) SaleProduct() storage.SaleProductRepoI {
+	return &saleProductRepo{s: s}
+}
+
+func (s *Store) Payment() storage.PaymentRepoI {
+	return &paymentRepo{s: s}
+}
+
+func (s *Store) User() storage.UserRepoI {
+	return &userRepo{s: s}
+}
+
+func (s *Store) Supplier() storage.SupplierRepoI {
+	return &supplierRepo{s: s}
+}
+
+func (s *Store) SupplierPayment() storage.SupplierPaymentRepoI {
+	return &supplierPaymentRepo{s: s}
+}
+
+func (s *Store) PurchaseOrder() storage.PurchaseOrderRepoI {
+	return &purchaseOrderRepo{s: s}
+}
+
+func (s *Store) StockLevel() storage.StockLevelRepoI {
+	return &stockLevelRepo{s: s}
+}
+
+func (s *Store) Category() storage.CategoryRepoI {
+	return &categoryRepo{s: s}
+}
+
+func (s *Store) BranchProduct() storage.BranchProductRepoI {
+	return &branchProductRepo{s: s}
+}
+
+func (s *Store) PriceChange() storage.PriceChangeRepoI {
+	return &priceChangeRepo{s: s}
+}
+
+func (s *Store) Repricing() storage.RepricingRepoI {
+	return &repricingRepo{s: s}
+}
+
+func (s *Store) Promotion() storage.PromotionRepoI {
+	return &promotionRepo{s: s}
+}
+
+func (s *Store) DocumentNumber() storage.DocumentNumberRepoI {
+	return &documentNumberRepo{s: s}
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// today is the date lots expire against, comparable with their expiry_date.
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// page applies OFFSET/LIMIT with the same defaults as the postgres repos.
+func page[T any](items []T, offset, limit int64) []T {
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= int64(len(items)) {
+		return nil
+	}
+
+	end := offset + limit
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+
+	return items[offset:end]
+}
+
+// newest returns the items matching keep, newest first.
+func newest[T any](items []T, keep func(T) bool) []T {
+	var resp []T
+	for i := len(items) - 1; i >= 0; i-- {
+		if keep(items[i]) {
+			resp = append(resp, items[i])
+		}
+	}
+	return resp
+}
+
+func indexOf[T any](items []T, match func(T) bool) int {
+	for i := range items {
+		if match(items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func remove[T any](items []T, match func(T) bool) []T {
+	var resp []T
+	for _, item := range items {
+		if !match(item) {
+			resp = append(resp, item)
+		}
+	}
+	return resp
+}
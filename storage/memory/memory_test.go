@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"market_system/storage/memory"
+	"market_system/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, memory.NewStore())
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type paymentRepo struct {
+	s *Store
+}
+
+func (r *paymentRepo) Create(ctx context.Context, req *models.CreatePayment) (*models.Payment, error) {
+
+	r.s.mu.Lock()
+
+	db := r.s.db
+
+	s := indexOf(db.sales, func(s models.Sale) bool { return s.Id == req.SaleID })
+	if s < 0 {
+		r.s.mu.Unlock()
+		return nil, pgx.ErrNoRows
+	}
+
+	paid := req.Amount
+	for _, p := range db.payments {
+		if p.SaleID == req.SaleID {
+			paid += p.Amount
+		}
+	}
+
+	if paid > db.sales[s].TotalPrice-db.sales[s].Returned {
+		r.s.mu.Unlock()
+		return nil, storage.ErrOverpayment
+	}
+
+	if paid < 0 {
+		r.s.mu.Unlock()
+		return nil, storage.ErrOverRefund
+	}
+
+	payment := models.Payment{
+		Id:        uuid.New().String(),
+		SaleID:    req.SaleID,
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Cashier:   req.Cashier,
+		CreatedAt: now(),
+	}
+
+	db.payments = append(db.payments, payment)
+	db.sales[s].Paid = paid
+	db.sales[s].Debd = db.sales[s].TotalPrice - db.sales[s].Returned - paid
+	db.sales[s].UpdatedAt = now()
+
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.PaymentPrimaryKey{Id: payment.Id})
+}
+
+func (r *paymentRepo) GetByID(ctx context.Context, req *models.PaymentPrimaryKey) (*models.Payment, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.payments, func(p models.Payment) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	payment := r.s.db.payments[i]
+	return &payment, nil
+}
+
+func (r *paymentRepo) GetList(ctx context.Context, req *models.GetListPaymentRequest) (*models.GetListPaymentResponse, error) {
+
+	if err := req.Filter.Validate(models.PaymentFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListPaymentResponse
+		found = newest(r.s.db.payments, func(p models.Payment) bool {
+			// a payment is of the branch of its sale
+			fields := paymentRow(p)
+			fields["branch_id"] = r.s.db.saleBranch(p.SaleID)
+			return match(models.PaymentFilterSpec, req.Search, req.Filter, fields)
+		})
+	)
+
+	for _, p := range page(found, req.Offset, req.Limit) {
+		payment := p
+		resp.Count = len(found)
+		resp.Payments = append(resp.Payments, &payment)
+	}
+
+	return &resp, nil
+}
+
+func paymentRow(p models.Payment) row {
+	return row{
+		"sale_id":    p.SaleID,
+		"method":     p.Method,
+		"cashier":    p.Cashier,
+		"amount":     p.Amount,
+		"created_at": p.CreatedAt,
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type pickingListRepo struct {
+	s *Store
+}
+
+func (r *pickingListRepo) Create(ctx context.Context, req *models.PickingList) (*models.PickingList, error) {
+
+	pickingList := models.PickingList{
+		ID:                uuid.New().String(),
+		Product_ID:        req.Product_ID,
+		Price:             req.Price,
+		Quantity:          req.Quantity,
+		Total_price:       req.Price * float64(req.Quantity),
+		Unit:              req.Unit,
+		UnitQuantity:      unitQuantity(req),
+		ComingID:          req.ComingID,
+		ComingIncrementID: req.ComingIncrementID,
+		LotNumber:         req.LotNumber,
+		ExpiryDate:        req.ExpiryDate,
+		CreatedAt:         now(),
+		UpdatedAt:         now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.pickingLists = append(r.s.db.pickingLists, pickingList)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.PickingListPrimaryKey{Id: pickingList.ID})
+}
+
+func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPrimaryKey) (*models.PickingList, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.pickingLists, func(p models.PickingList) bool { return p.ID == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	pickingList := r.s.db.pickingLists[i]
+	return &pickingList, nil
+}
+
+func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickingListRequest) (*models.GetListPickingListResponse, error) {
+
+	if err := req.Filter.Validate(models.PickingListFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListPickingListResponse
+		found = newest(r.s.db.pickingLists, func(p models.PickingList) bool {
+			// a line is of the branch of its coming
+			fields := pickingListRow(p)
+			fields["branch_id"] = r.s.db.comingBranch(p.ComingID)
+			return match(models.PickingListFilterSpec, req.Search, req.Filter, fields)
+		})
+	)
+
+	for _, p := range page(found, req.Offset, req.Limit) {
+		pickingList := p
+		resp.Count = len(found)
+		resp.Pickinges = append(resp.Pickinges, &pickingList)
+	}
+
+	return &resp, nil
+}
+
+func (r *pickingListRepo) Update(ctx context.Context, req *models.PickingList) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.pickingLists, func(p models.PickingList) bool { return p.ID == req.ID })
+	if i < 0 {
+		return 0, nil
+	}
+
+	pickingList := &r.s.db.pickingLists[i]
+	pickingList.Product_ID = req.Product_ID
+	pickingList.Quantity = req.Quantity
+	pickingList.Price = req.Price
+	pickingList.Total_price = req.Price * float64(req.Quantity)
+	pickingList.Unit = req.Unit
+	pickingList.UnitQuantity = unitQuantity(req)
+	pickingList.ComingID = req.ComingID
+	pickingList.ComingIncrementID = req.ComingIncrementID
+	pickingList.LotNumber = req.LotNumber
+	pickingList.ExpiryDate = req.ExpiryDate
+	pickingList.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *pickingListRepo) Delete(ctx context.Context, req *models.PickingListPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.pickingLists = remove(r.s.db.pickingLists, func(p models.PickingList) bool { return p.ID == req.Id })
+
+	return nil
+}
+
+// unitQuantity is the quantity entered for the line, the base quantity
+// unless it was entered in a unit.
+func unitQuantity(p *models.PickingList) int {
+	if p.UnitQuantity == 0 {
+		return p.Quantity
+	}
+	return p.UnitQuantity
+}
+
+func pickingListRow(p models.PickingList) row {
+	return row{
+		"product_id":          p.Product_ID,
+		"coming_id":           p.ComingID,
+		"coming_increment_id": p.ComingIncrementID,
+		"lot_number":          p.LotNumber,
+		"price":               p.Price,
+		"quantity":            p.Quantity,
+		"total_price":         p.Total_price,
+		"created_at":          p.CreatedAt,
+	}
+}
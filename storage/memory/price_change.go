@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type priceChangeRepo struct {
+	s *Store
+}
+
+// effectiveFrom is when c takes effect, comparable across rows.
+func effectiveFrom(c models.PriceChange) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, c.EffectiveFrom)
+	return t
+}
+
+// sameTarget reports whether c changes the price of the product in the
+// branch, or in the catalog when branchId is empty.
+func sameTarget(c models.PriceChange, productId, branchId string) bool {
+	return c.ProductID == productId && c.BranchID == branchId
+}
+
+// priceAt is the latest change of the product in the branch, or the
+// catalog, effective by at. Pending changes count unless appliedOnly. The
+// caller holds the lock.
+func (d *database) priceAt(productId, branchId string, at time.Time, appliedOnly bool) (models.PriceChange, bool) {
+
+	var (
+		resp  models.PriceChange
+		found bool
+	)
+
+	// rows are in created_at order, so a later one wins a tie
+	for _, c := range d.priceChanges {
+		if !sameTarget(c, productId, branchId) || effectiveFrom(c).After(at) || (appliedOnly && len(c.AppliedAt) == 0) {
+			continue
+		}
+		if !found || !effectiveFrom(c).Before(effectiveFrom(resp)) {
+			resp, found = c, true
+		}
+	}
+
+	return resp, found
+}
+
+// recordPrice adds price to the history of the product as a change applied
+// now, unless it is the price the last applied change set. The caller holds
+// the lock.
+func (d *database) recordPrice(productId, branchId string, price *float64) {
+
+	last, ok := d.priceAt(productId, branchId, time.Now().UTC(), true)
+	if ok && samePrice(last.Price, price) || !ok && price == nil {
+		return
+	}
+
+	if price != nil {
+		p := *price
+		price = &p
+	}
+
+	d.priceChanges = append(d.priceChanges, models.PriceChange{
+		Id:            uuid.New().String(),
+		ProductID:     productId,
+		BranchID:      branchId,
+		Price:         price,
+		EffectiveFrom: now(),
+		AppliedAt:     now(),
+		CreatedAt:     now(),
+	})
+}
+
+func samePrice(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// addPriceChange appends a pending change, applyDue applies it when due. The
+// caller holds the lock.
+func (d *database) addPriceChange(req *models.CreatePriceChange, at time.Time) string {
+
+	var price *float64
+	if req.Price != nil {
+		p := *req.Price
+		price = &p
+	}
+
+	change := models.PriceChange{
+		Id:            uuid.New().String(),
+		ProductID:     req.ProductID,
+		BranchID:      req.BranchID,
+		Price:         price,
+		EffectiveFrom: at.UTC().Format(time.RFC3339Nano),
+		DocumentID:    req.DocumentID,
+		CreatedAt:     now(),
+	}
+	d.priceChanges = append(d.priceChanges, change)
+
+	return change.Id
+}
+
+// applyDue applies the pending changes due by at, oldest first. A change an
+// applied later one already overrides is only marked applied. The caller
+// holds the lock.
+func (d *database) applyDue(at time.Time) int64 {
+
+	var due []int
+	for i, c := range d.priceChanges {
+		if len(c.AppliedAt) == 0 && !effectiveFrom(c).After(at) {
+			due = append(due, i)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return effectiveFrom(d.priceChanges[due[i]]).Before(effectiveFrom(d.priceChanges[due[j]]))
+	})
+
+	for _, i := range due {
+		c := d.priceChanges[i]
+
+		superseded := indexOf(d.priceChanges, func(n models.PriceChange) bool {
+			return sameTarget(n, c.ProductID, c.BranchID) && len(n.AppliedAt) > 0 && effectiveFrom(n).After(effectiveFrom(c))
+		}) >= 0
+
+		// applied first, so setBranchProduct finds it the last price
+		d.priceChanges[i].AppliedAt = now()
+
+		switch {
+		case superseded:
+		case len(c.BranchID) > 0:
+			d.setBranchProduct(c.BranchID, c.ProductID, c.Price)
+		default:
+			if p := indexOf(d.products, func(p models.Product) bool { return p.Id == c.ProductID }); p >= 0 && c.Price != nil {
+				d.products[p].Price = *c.Price
+				d.products[p].UpdatedAt = now()
+				d.repriceStock("", c.ProductID)
+			}
+		}
+	}
+
+	return int64(len(due))
+}
+
+func (r *priceChangeRepo) Create(ctx context.Context, req *models.CreatePriceChange) (*models.PriceChange, error) {
+
+	var at = time.Now()
+	if req.EffectiveFrom != nil {
+		at = *req.EffectiveFrom
+	}
+
+	r.s.mu.Lock()
+	id := r.s.db.addPriceChange(req, at)
+	r.s.db.applyDue(time.Now())
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.PriceChangePrimaryKey{Id: id})
+}
+
+func (r *priceChangeRepo) GetByID(ctx context.Context, req *models.PriceChangePrimaryKey) (*models.PriceChange, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.priceChanges, func(c models.PriceChange) bool { return c.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	resp := r.s.db.priceChanges[i]
+	return &resp, nil
+}
+
+func (r *priceChangeRepo) GetList(ctx context.Context, req *models.GetListPriceChangeRequest) (*models.GetListPriceChangeResponse, error) {
+
+	if err := req.Filter.Validate(models.PriceChangeFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListPriceChangeResponse
+		found []*models.PriceChange
+	)
+
+	for _, c := range newest(r.s.db.priceChanges, func(c models.PriceChange) bool { return !req.Pending || len(c.AppliedAt) == 0 }) {
+		c := c
+		if match(models.PriceChangeFilterSpec, req.Search, req.Filter, r.s.db.priceChangeRow(c)) {
+			found = append(found, &c)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return effectiveFrom(*found[i]).After(effectiveFrom(*found[j]))
+	})
+
+	resp.Count = len(found)
+	resp.PriceChanges = page(found, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func (r *priceChangeRepo) Cancel(ctx context.Context, req *models.PriceChangePrimaryKey) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var count = len(r.s.db.priceChanges)
+	r.s.db.priceChanges = remove(r.s.db.priceChanges, func(c models.PriceChange) bool {
+		return c.Id == req.Id && len(c.AppliedAt) == 0
+	})
+
+	return int64(count - len(r.s.db.priceChanges)), nil
+}
+
+func (r *priceChangeRepo) ApplyDue(ctx context.Context, at time.Time) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.db.applyDue(at), nil
+}
+
+func (r *priceChangeRepo) AsOf(ctx context.Context, req *models.PriceAsOfRequest) (*models.PriceAsOf, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// the override of the branch, then the catalog
+	var branchIds = []string{""}
+	if len(req.BranchID) > 0 {
+		branchIds = []string{req.BranchID, ""}
+	}
+
+	for _, branchId := range branchIds {
+		c, ok := r.s.db.priceAt(req.ProductID, branchId, req.At, false)
+		if ok && c.Price != nil {
+			return &models.PriceAsOf{
+				ProductID:     req.ProductID,
+				BranchID:      req.BranchID,
+				At:            req.At.Format(time.RFC3339Nano),
+				Price:         *c.Price,
+				ChangeID:      c.Id,
+				EffectiveFrom: c.EffectiveFrom,
+			}, nil
+		}
+	}
+
+	return nil, pgx.ErrNoRows
+}
+
+// priceChangeRow is c joined with its product, the caller holds the lock.
+func (d *database) priceChangeRow(c models.PriceChange) row {
+
+	var name string
+	if p := indexOf(d.products, func(p models.Product) bool { return p.Id == c.ProductID }); p >= 0 {
+		name = d.products[p].Name
+	}
+
+	var price interface{}
+	if c.Price != nil {
+		price = *c.Price
+	}
+
+	return row{
+		"product_id":   c.ProductID,
+		"branch_id":    c.BranchID,
+		"document_id":  c.DocumentID,
+		"price":        price,
+		"product.name": name,
+		"created_at":   c.CreatedAt,
+	}
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type productRepo struct {
+	s *Store
+}
+
+// productBarcode is a row of product_barcode.
+type productBarcode struct {
+	ProductID string
+	models.Barcode
+}
+
+// productUnit is a row of product_unit.
+type productUnit struct {
+	ProductID string
+	models.ProductUnit
+}
+
+// errBarcodeTaken is what postgres reports for a code already in use.
+func errBarcodeTaken(code string) error {
+	return &pgconn.PgError{
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "product_barcode_pkey"`,
+		Detail:         fmt.Sprintf("Key (code)=(%s) already exists.", code),
+		TableName:      "product_barcode",
+		ConstraintName: "product_barcode_pkey",
+	}
+}
+
+// setBarcodes replaces the barcodes of a product, the caller holds the lock.
+func (d *database) setBarcodes(productId string, barcodes []*models.Barcode) error {
+
+	var kept = remove(d.productBarcodes, func(b productBarcode) bool { return b.ProductID == productId })
+	for _, barcode := range barcodes {
+		if indexOf(kept, func(b productBarcode) bool { return b.Code == barcode.Code }) >= 0 {
+			return errBarcodeTaken(barcode.Code)
+		}
+		kept = append(kept, productBarcode{ProductID: productId, Barcode: *barcode})
+	}
+
+	d.productBarcodes = kept
+	return nil
+}
+
+// setUnits replaces the pack units of a product, the caller holds the lock.
+func (d *database) setUnits(productId string, units []*models.ProductUnit) {
+
+	d.productUnits = remove(d.productUnits, func(u productUnit) bool { return u.ProductID == productId })
+	for _, unit := range units {
+		d.productUnits = append(d.productUnits, productUnit{ProductID: productId, ProductUnit: *unit})
+	}
+}
+
+// withBarcodes returns a copy of p with its barcodes and pack units, the
+// caller holds the lock.
+func (d *database) withBarcodes(p models.Product) *models.Product {
+
+	p.Barcodes = nil
+	for _, b := range d.productBarcodes {
+		if b.ProductID == p.Id {
+			barcode := b.Barcode
+			p.Barcodes = append(p.Barcodes, &barcode)
+		}
+	}
+
+	p.Units = nil
+	for _, u := range d.productUnits {
+		if u.ProductID == p.Id {
+			unit := u.ProductUnit
+			p.Units = append(p.Units, &unit)
+		}
+	}
+
+	return &p
+}
+
+func (r *productRepo) Create(ctx context.Context, req *models.CreateProduct) (*models.Product, error) {
+
+	product := models.Product{
+		Id:         uuid.New().String(),
+		Name:       req.Name,
+		Price:      req.Price,
+		BranchID:   req.BranchID,
+		CategoryID: req.CategoryID,
+		Unit:       req.Unit,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+
+	if len(product.Unit) == 0 {
+		product.Unit = models.DefaultUnit
+	}
+
+	r.s.mu.Lock()
+	if err := r.s.db.setBarcodes(product.Id, req.Barcodes); err != nil {
+		r.s.mu.Unlock()
+		return nil, err
+	}
+	r.s.db.setUnits(product.Id, req.Units)
+	r.s.db.recordPrice(product.Id, "", &product.Price)
+	if len(product.BranchID) > 0 {
+		r.s.db.setBranchProduct(product.BranchID, product.Id, nil)
+	}
+	r.s.db.products = append(r.s.db.products, product)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.ProductPrimaryKey{Id: product.Id})
+}
+
+func (r *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey) (*models.Product, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.products, func(p models.Product) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	return r.s.db.withBarcodes(r.s.db.products[i]), nil
+}
+
+func (r *productRepo) GetList(ctx context.Context, req *models.GetListProductRequest) (*models.GetListProductResponse, error) {
+
+	if err := req.Filter.Validate(models.ProductFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	filter, branchIds := req.Filter.Without("branch_id")
+
+	var (
+		resp  models.GetListProductResponse
+		found = newest(r.s.db.products, func(p models.Product) bool {
+			// a branch lists the products it carries
+			if len(branchIds) > 0 && indexOf(r.s.db.branchProducts, func(b branchProduct) bool {
+				return b.ProductID == p.Id && contains(branchIds, b.BranchID)
+			}) < 0 {
+				return false
+			}
+
+			// products are left joined with their branch, catalog products have none
+			var branch models.Branch
+			if i := indexOf(r.s.db.branches, func(b models.Branch) bool { return b.Id == p.BranchID }); i >= 0 {
+				branch = r.s.db.branches[i]
+			}
+			return match(models.ProductFilterSpec, req.Search, filter, productRow(p, branch))
+		})
+	)
+
+	for _, p := range page(found, req.Offset, req.Limit) {
+		resp.Count = len(found)
+		resp.Products = append(resp.Products, r.s.db.withBarcodes(p))
+	}
+
+	return &resp, nil
+}
+
+func (r *productRepo) Update(ctx context.Context, req *models.UpdateProduct) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.products, func(p models.Product) bool { return p.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	if req.Barcodes != nil {
+		if err := r.s.db.setBarcodes(req.Id, req.Barcodes); err != nil {
+			return 0, err
+		}
+	}
+
+	if req.Units != nil {
+		r.s.db.setUnits(req.Id, req.Units)
+	}
+
+	product := &r.s.db.products[i]
+	if len(req.Unit) > 0 {
+		product.Unit = req.Unit
+	}
+	product.Name = req.Name
+	product.Price = req.Price
+	product.BranchID = req.BranchID
+	product.CategoryID = req.CategoryID
+	product.UpdatedAt = now()
+	r.s.db.recordPrice(product.Id, "", &product.Price)
+	r.s.db.repriceStock("", product.Id)
+
+	return 1, nil
+}
+
+func (r *productRepo) Delete(ctx context.Context, req *models.ProductPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.products = remove(r.s.db.products, func(p models.Product) bool { return p.Id == req.Id })
+	r.s.db.productBarcodes = remove(r.s.db.productBarcodes, func(b productBarcode) bool { return b.ProductID == req.Id })
+	r.s.db.productUnits = remove(r.s.db.productUnits, func(u productUnit) bool { return u.ProductID == req.Id })
+	r.s.db.branchProducts = remove(r.s.db.branchProducts, func(b branchProduct) bool { return b.ProductID == req.Id })
+	r.s.db.priceChanges = remove(r.s.db.priceChanges, func(c models.PriceChange) bool { return c.ProductID == req.Id })
+	r.s.db.promotions = remove(r.s.db.promotions, func(p models.Promotion) bool { return p.ProductID == req.Id })
+
+	return nil
+}
+
+func (r *productRepo) GetByBarcode(ctx context.Context, req *models.ProductByBarcodeRequest) (*models.ProductByBarcode, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	b := indexOf(r.s.db.productBarcodes, func(b productBarcode) bool { return b.Code == req.Code })
+	if b < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	barcode := r.s.db.productBarcodes[b]
+	i := indexOf(r.s.db.products, func(p models.Product) bool { return p.Id == barcode.ProductID })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	var (
+		resp = models.ProductByBarcode{
+			Product:  r.s.db.withBarcodes(r.s.db.products[i]),
+			Barcode:  barcode.Barcode,
+			BranchID: req.BranchID,
+		}
+		first *models.Remainder
+	)
+
+	for _, rm := range r.s.db.remainders {
+		if rm.ProductID != barcode.ProductID || len(req.BranchID) > 0 && rm.BranchID != req.BranchID || expired(rm) {
+			continue
+		}
+
+		resp.Quantity += rm.Quantity
+		if rm.Quantity > 0 && (first == nil || fefo(rm, *first)) {
+			lot := rm
+			first = &lot
+		}
+	}
+
+	resp.SalePrice = resp.Product.Price
+	if len(req.BranchID) > 0 {
+		resp.SalePrice, _ = r.s.db.salePrice(req.BranchID, resp.Product.Id)
+	}
+	if first != nil {
+		resp.SalePrice = first.SalePrice
+	}
+
+	return &resp, nil
+}
+
+func productRow(p models.Product, b models.Branch) row {
+	return row{
+		"product.name": p.Name,
+		"branch.name":  b.Name,
+		"branch_id":    p.BranchID,
+		"category_id":  p.CategoryID,
+		"price":        p.Price,
+		"created_at":   p.CreatedAt,
+	}
+}
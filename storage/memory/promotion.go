@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type promotionRepo struct {
+	s *Store
+}
+
+// promotionWindow formats the window of a promotion, it starts now when
+// startsAt is nil and never ends when endsAt is nil.
+func promotionWindow(startsAt, endsAt *time.Time) (string, string) {
+
+	var starts, ends = now(), ""
+	if startsAt != nil {
+		starts = startsAt.UTC().Format(time.RFC3339Nano)
+	}
+	if endsAt != nil {
+		ends = endsAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return starts, ends
+}
+
+func (r *promotionRepo) Create(ctx context.Context, req *models.CreatePromotion) (*models.Promotion, error) {
+
+	startsAt, endsAt := promotionWindow(req.StartsAt, req.EndsAt)
+
+	promotion := models.Promotion{
+		Id:           uuid.New().String(),
+		Name:         req.Name,
+		Type:         req.Type,
+		Value:        req.Value,
+		BuyQuantity:  req.BuyQuantity,
+		FreeQuantity: req.FreeQuantity,
+		BranchID:     req.BranchID,
+		ProductID:    req.ProductID,
+		CategoryID:   req.CategoryID,
+		ClientGroup:  req.ClientGroup,
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.promotions = append(r.s.db.promotions, promotion)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.PromotionPrimaryKey{Id: promotion.Id})
+}
+
+func (r *promotionRepo) GetByID(ctx context.Context, req *models.PromotionPrimaryKey) (*models.Promotion, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.promotions, func(p models.Promotion) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	promotion := r.s.db.promotions[i]
+	return &promotion, nil
+}
+
+func (r *promotionRepo) GetList(ctx context.Context, req *models.GetListPromotionRequest) (*models.GetListPromotionResponse, error) {
+
+	if err := req.Filter.Validate(models.PromotionFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListPromotionResponse
+		found = newest(r.s.db.promotions, func(p models.Promotion) bool {
+			return match(models.PromotionFilterSpec, req.Search, req.Filter, promotionRow(p))
+		})
+	)
+
+	resp.Count = len(found)
+	for _, p := range page(found, req.Offset, req.Limit) {
+		promotion := p
+		resp.Promotions = append(resp.Promotions, &promotion)
+	}
+
+	return &resp, nil
+}
+
+func (r *promotionRepo) Update(ctx context.Context, req *models.UpdatePromotion) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.promotions, func(p models.Promotion) bool { return p.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	promotion := &r.s.db.promotions[i]
+	promotion.Name = req.Name
+	promotion.Type = req.Type
+	promotion.Value = req.Value
+	promotion.BuyQuantity = req.BuyQuantity
+	promotion.FreeQuantity = req.FreeQuantity
+	promotion.BranchID = req.BranchID
+	promotion.ProductID = req.ProductID
+	promotion.CategoryID = req.CategoryID
+	promotion.ClientGroup = req.ClientGroup
+	promotion.StartsAt, promotion.EndsAt = promotionWindow(req.StartsAt, req.EndsAt)
+	promotion.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *promotionRepo) Delete(ctx context.Context, req *models.PromotionPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.promotions, func(p models.Promotion) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil
+	}
+
+	promotion := &r.s.db.promotions[i]
+
+	startsAt, err := time.Parse(time.RFC3339Nano, promotion.StartsAt)
+	if err != nil {
+		return err
+	}
+
+	// the promotion ends, or never starts, and its sales keep it
+	var endsAt = time.Now()
+	if len(promotion.EndsAt) > 0 {
+		ends, err := time.Parse(time.RFC3339Nano, promotion.EndsAt)
+		if err != nil {
+			return err
+		}
+		if ends.Before(endsAt) {
+			endsAt = ends
+		}
+	}
+	if endsAt.Before(startsAt) {
+		endsAt = startsAt
+	}
+
+	_, promotion.EndsAt = promotionWindow(nil, &endsAt)
+	promotion.UpdatedAt = now()
+
+	return nil
+}
+
+func (r *promotionRepo) Active(ctx context.Context, branchId string, at time.Time) ([]*models.Promotion, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var resp []*models.Promotion
+	for _, p := range r.s.db.promotions {
+		startsAt, err := time.Parse(time.RFC3339Nano, p.StartsAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if startsAt.After(at) || len(p.BranchID) > 0 && p.BranchID != branchId {
+			continue
+		}
+
+		if len(p.EndsAt) > 0 {
+			endsAt, err := time.Parse(time.RFC3339Nano, p.EndsAt)
+			if err != nil {
+				return nil, err
+			}
+			if !endsAt.After(at) {
+				continue
+			}
+		}
+
+		promotion := p
+		resp = append(resp, &promotion)
+	}
+
+	return resp, nil
+}
+
+func (r *promotionRepo) Report(ctx context.Context, req *models.PromotionReportRequest) (*models.PromotionReportResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		groups = map[string]*models.PromotionCost{}
+		sales  = map[string]map[string]bool{}
+	)
+
+	for _, sp := range r.s.db.saleProducts {
+		p := indexOf(r.s.db.promotions, func(p models.Promotion) bool { return p.Id == sp.PromotionID })
+		if len(sp.PromotionID) == 0 || p < 0 {
+			continue
+		}
+
+		i := indexOf(r.s.db.sales, func(s models.Sale) bool { return s.Id == sp.SaleID })
+		if i < 0 || len(req.BranchID) > 0 && r.s.db.sales[i].BranchID != req.BranchID {
+			continue
+		}
+
+		// returned units give their share of the discount back
+		returned := r.s.db.returned(sp.SaleID)[sp.Id]
+		if returned >= sp.Quantity {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339Nano, sp.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if req.From != nil && createdAt.Before(*req.From) || req.To != nil && !createdAt.Before(*req.To) {
+			continue
+		}
+
+		row, ok := groups[sp.PromotionID]
+		if !ok {
+			promotion := r.s.db.promotions[p]
+			row = &models.PromotionCost{PromotionID: promotion.Id, Name: promotion.Name, Type: promotion.Type}
+			groups[sp.PromotionID] = row
+			sales[sp.PromotionID] = map[string]bool{}
+		}
+
+		sales[sp.PromotionID][sp.SaleID] = true
+		row.Sales = len(sales[sp.PromotionID])
+		row.Lines++
+		row.Quantity += sp.Quantity - returned
+		row.Discount += math.Round(sp.Discount*float64(sp.Quantity-returned)/float64(sp.Quantity)*100) / 100
+	}
+
+	var rows []*models.PromotionCost
+	for _, row := range groups {
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Discount != rows[j].Discount {
+			return rows[i].Discount > rows[j].Discount
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	var resp models.PromotionReportResponse
+	for _, row := range rows {
+		resp.Add(row)
+	}
+
+	return &resp, nil
+}
+
+func promotionRow(p models.Promotion) row {
+	return row{
+		"type":         p.Type,
+		"branch_id":    p.BranchID,
+		"product_id":   p.ProductID,
+		"category_id":  p.CategoryID,
+		"client_group": p.ClientGroup,
+		"value":        p.Value,
+		"name":         p.Name,
+		"created_at":   p.CreatedAt,
+	}
+}
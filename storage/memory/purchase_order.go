@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type purchaseOrderRepo struct {
+	s *Store
+}
+
+func (r *purchaseOrderRepo) Create(ctx context.Context, req *models.CreatePurchaseOrder) (*models.PurchaseOrder, error) {
+
+	purchaseOrder := models.PurchaseOrder{
+		Id:          uuid.New().String(),
+		IncrementID: req.IncrementID,
+		SupplierID:  req.SupplierID,
+		BranchID:    req.BranchID,
+		Status:      models.PurchaseOrderOpen,
+		UserID:      req.UserID,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.purchaseOrders = append(r.s.db.purchaseOrders, purchaseOrder)
+	r.s.db.addPurchaseOrderLines(purchaseOrder.Id, req.Lines)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: purchaseOrder.Id})
+}
+
+func (d *database) addPurchaseOrderLines(purchaseOrderId string, lines []*models.CreatePurchaseOrderLine) {
+	for _, line := range lines {
+		d.purchaseOrderLines = append(d.purchaseOrderLines, models.PurchaseOrderLine{
+			Id:              uuid.New().String(),
+			PurchaseOrderID: purchaseOrderId,
+			ProductID:       line.ProductID,
+			Quantity:        line.Quantity,
+			Price:           line.Price,
+			CreatedAt:       now(),
+		})
+	}
+}
+
+func (r *purchaseOrderRepo) GetByID(ctx context.Context, req *models.PurchaseOrderPrimaryKey) (*models.PurchaseOrder, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	db := r.s.db
+
+	i := indexOf(db.purchaseOrders, func(po models.PurchaseOrder) bool { return po.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	purchaseOrder := db.purchaseOrders[i]
+	for _, l := range db.purchaseOrderLines {
+		if l.PurchaseOrderID == purchaseOrder.Id {
+			line := l
+			purchaseOrder.Lines = append(purchaseOrder.Lines, &line)
+		}
+	}
+
+	var (
+		quantity = map[string]int{}
+		amount   = map[string]float64{}
+		finished = map[string]bool{}
+	)
+
+	for _, c := range db.comings {
+		if c.PurchaseOrderID == purchaseOrder.Id && c.Status == models.ComingFinished {
+			finished[c.Id] = true
+		}
+	}
+
+	for _, pl := range db.pickingLists {
+		if finished[pl.ComingID] {
+			quantity[pl.Product_ID] += pl.Quantity
+			amount[pl.Product_ID] += pl.Total_price
+		}
+	}
+
+	purchaseOrder.Receive(quantity, amount)
+
+	return &purchaseOrder, nil
+}
+
+func (r *purchaseOrderRepo) GetList(ctx context.Context, req *models.GetListPurchaseOrderRequest) (*models.GetListPurchaseOrderResponse, error) {
+
+	if err := req.Filter.Validate(models.PurchaseOrderFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListPurchaseOrderResponse
+		found = newest(r.s.db.purchaseOrders, func(po models.PurchaseOrder) bool {
+			return match(models.PurchaseOrderFilterSpec, req.Search, req.Filter, purchaseOrderRow(po))
+		})
+	)
+
+	for _, po := range page(found, req.Offset, req.Limit) {
+		purchaseOrder := po
+		resp.Count = len(found)
+		resp.PurchaseOrders = append(resp.PurchaseOrders, &purchaseOrder)
+	}
+
+	return &resp, nil
+}
+
+func (r *purchaseOrderRepo) Update(ctx context.Context, req *models.UpdatePurchaseOrder) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.purchaseOrders, func(po models.PurchaseOrder) bool { return po.Id == req.Id })
+	if i < 0 || indexOf(r.s.db.comings, func(c models.Coming) bool { return c.PurchaseOrderID == req.Id }) >= 0 {
+		return 0, nil
+	}
+
+	purchaseOrder := &r.s.db.purchaseOrders[i]
+	purchaseOrder.SupplierID = req.SupplierID
+	purchaseOrder.UpdatedAt = now()
+
+	if req.Lines != nil {
+		r.s.db.purchaseOrderLines = remove(r.s.db.purchaseOrderLines, func(l models.PurchaseOrderLine) bool { return l.PurchaseOrderID == req.Id })
+		r.s.db.addPurchaseOrderLines(req.Id, req.Lines)
+	}
+
+	return 1, nil
+}
+
+func (r *purchaseOrderRepo) Delete(ctx context.Context, req *models.PurchaseOrderPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.purchaseOrders = remove(r.s.db.purchaseOrders, func(po models.PurchaseOrder) bool { return po.Id == req.Id })
+	r.s.db.purchaseOrderLines = remove(r.s.db.purchaseOrderLines, func(l models.PurchaseOrderLine) bool { return l.PurchaseOrderID == req.Id })
+
+	return nil
+}
+
+func (r *purchaseOrderRepo) SetStatus(ctx context.Context, req *models.PurchaseOrderStatus) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.purchaseOrders, func(po models.PurchaseOrder) bool {
+		return po.Id == req.Id && po.Status == req.From
+	})
+	if i < 0 {
+		return 0, nil
+	}
+
+	purchaseOrder := &r.s.db.purchaseOrders[i]
+	purchaseOrder.Status = req.To
+	purchaseOrder.ClosedAt = ""
+	if req.To == models.PurchaseOrderClosed {
+		purchaseOrder.ClosedAt = now()
+	}
+	purchaseOrder.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func purchaseOrderRow(po models.PurchaseOrder) row {
+	return row{
+		"increment_id": po.IncrementID,
+		"supplier_id":  po.SupplierID,
+		"branch_id":    po.BranchID,
+		"status":       po.Status,
+		"user_id":      po.UserID,
+		"created_at":   po.CreatedAt,
+	}
+}
@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type remainderRepo struct {
+	s *Store
+}
+
+func (r *remainderRepo) Create(ctx context.Context, req *models.Remainder) (*models.Remainder, error) {
+
+	remainder := models.Remainder{
+		Id:          uuid.New().String(),
+		ProductID:   req.ProductID,
+		Name:        req.Name,
+		Quantity:    req.Quantity,
+		ComingPrice: req.ComingPrice,
+		SalePrice:   req.SalePrice,
+		BranchID:    req.BranchID,
+		LotNumber:   req.LotNumber,
+		ExpiryDate:  req.ExpiryDate,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.remainders = append(r.s.db.remainders, remainder)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.RemainderPrimaryKey{Id: remainder.Id})
+}
+
+func (r *remainderRepo) GetByID(ctx context.Context, req *models.RemainderPrimaryKey) (*models.Remainder, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.remainders, func(rm models.Remainder) bool { return rm.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	remainder := r.s.db.remainders[i]
+	return &remainder, nil
+}
+
+// GetList is not paginated, same as the postgres repo.
+func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainderRequest) (*models.GetListRemainderResponse, error) {
+
+	if err := req.Filter.Validate(models.RemainderFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// the rows are kept in the order they were created, oldest first
+	var (
+		resp  models.GetListRemainderResponse
+		found = r.s.db.remainders
+	)
+
+	for _, rm := range found {
+		if !match(models.RemainderFilterSpec, req.Search, req.Filter, remainderRow(rm)) {
+			continue
+		}
+		remainder := rm
+		resp.Remainders = append(resp.Remainders, &remainder)
+	}
+	resp.Count = len(resp.Remainders)
+
+	if req.Limit > 0 {
+		resp.Remainders = page(resp.Remainders, req.Offset, req.Limit)
+	}
+
+	return &resp, nil
+}
+
+func (r *remainderRepo) Update(ctx context.Context, req *models.Remainder) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.remainders, func(rm models.Remainder) bool { return rm.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	remainder := &r.s.db.remainders[i]
+	remainder.ProductID = req.ProductID
+	remainder.Quantity = req.Quantity
+	remainder.ComingPrice = req.ComingPrice
+	remainder.SalePrice = req.SalePrice
+	remainder.BranchID = req.BranchID
+	remainder.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *remainderRepo) Delete(ctx context.Context, req *models.RemainderPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.remainders = remove(r.s.db.remainders, func(rm models.Remainder) bool { return rm.Id == req.Id })
+
+	return nil
+}
+
+func remainderRow(rm models.Remainder) row {
+	return row{
+		"product_id":   rm.ProductID,
+		"branch_id":    rm.BranchID,
+		"name":         rm.Name,
+		"quantity":     rm.Quantity,
+		"coming_price": rm.ComingPrice,
+		"sale_price":   rm.SalePrice,
+		"lot_number":   rm.LotNumber,
+		"created_at":   rm.CreatedAt,
+	}
+}
+
+// addQuantity is the projection side of the stock journal, the caller
+// holds the lock. It changes the lot req.LotNumber, the oldest remainder
+// without a lot when that is empty.
+func (d *database) addQuantity(req *models.AddRemainder) error {
+
+	i := indexOf(d.remainders, func(rm models.Remainder) bool {
+		return rm.BranchID == req.BranchID && rm.ProductID == req.ProductID && rm.LotNumber == req.LotNumber
+	})
+
+	if i < 0 && req.Quantity >= 0 {
+		remainder := models.Remainder{
+			Id:          uuid.New().String(),
+			ProductID:   req.ProductID,
+			Name:        req.Name,
+			Quantity:    req.Quantity,
+			ComingPrice: req.ComingPrice,
+			SalePrice:   req.SalePrice,
+			BranchID:    req.BranchID,
+			LotNumber:   req.LotNumber,
+			ExpiryDate:  req.ExpiryDate,
+			CreatedAt:   now(),
+			UpdatedAt:   now(),
+		}
+
+		// a new lot of a product already in stock keeps its name and prices
+		if first := indexOf(d.remainders, func(rm models.Remainder) bool {
+			return rm.BranchID == req.BranchID && rm.ProductID == req.ProductID
+		}); first >= 0 {
+			if len(remainder.Name) == 0 {
+				remainder.Name = d.remainders[first].Name
+			}
+			if remainder.ComingPrice == 0 {
+				remainder.ComingPrice = d.remainders[first].ComingPrice
+			}
+			if remainder.SalePrice == 0 {
+				remainder.SalePrice = d.remainders[first].SalePrice
+			}
+		}
+
+		d.remainders = append(d.remainders, remainder)
+		return nil
+	}
+
+	if i < 0 || d.remainders[i].Quantity+req.Quantity < 0 || req.Sellable && expired(d.remainders[i]) {
+		return fmt.Errorf("%w: product %s", storage.ErrNotEnoughQuantity, req.ProductID)
+	}
+
+	remainder := &d.remainders[i]
+	remainder.Quantity += req.Quantity
+	if req.ComingPrice != 0 {
+		remainder.ComingPrice = req.ComingPrice
+	}
+	if req.SalePrice != 0 {
+		remainder.SalePrice = req.SalePrice
+	}
+	if len(req.ExpiryDate) > 0 {
+		remainder.ExpiryDate = req.ExpiryDate
+	}
+	remainder.UpdatedAt = now()
+
+	return nil
+}
+
+// allocate takes -req.Quantity of a product out of its lots in the branch,
+// the earliest expiry first and lots without expiry last, and returns the
+// lots with the quantity taken from each. Expired lots are left alone when
+// req.Sellable. The caller holds the lock.
+func (d *database) allocate(req *models.AddRemainder) ([]*models.Remainder, error) {
+
+	var (
+		lots  []int
+		taken []*models.Remainder
+		left  = -req.Quantity
+	)
+
+	for i, rm := range d.remainders {
+		if rm.BranchID != req.BranchID || rm.ProductID != req.ProductID || rm.Quantity <= 0 {
+			continue
+		}
+		if req.Sellable && expired(rm) {
+			continue
+		}
+		lots = append(lots, i)
+	}
+
+	// remainders are kept in the order they were created
+	sort.SliceStable(lots, func(i, j int) bool { return fefo(d.remainders[lots[i]], d.remainders[lots[j]]) })
+
+	for _, i := range lots {
+		if left == 0 {
+			break
+		}
+
+		lot := d.remainders[i]
+		if lot.Quantity > left {
+			lot.Quantity = left
+		}
+		left -= lot.Quantity
+		taken = append(taken, &lot)
+	}
+
+	if left > 0 {
+		return nil, fmt.Errorf("%w: product %s", storage.ErrNotEnoughQuantity, req.ProductID)
+	}
+
+	for _, lot := range taken {
+		i := indexOf(d.remainders, func(rm models.Remainder) bool { return rm.Id == lot.Id })
+		d.remainders[i].Quantity -= lot.Quantity
+		d.remainders[i].UpdatedAt = now()
+	}
+
+	return taken, nil
+}
+
+// fefo reports whether lot a is sold before b: the earliest expiry first,
+// lots without expiry last.
+func fefo(a, b models.Remainder) bool {
+	if len(a.ExpiryDate) == 0 || len(b.ExpiryDate) == 0 {
+		return len(a.ExpiryDate) > 0 && len(b.ExpiryDate) == 0
+	}
+	return a.ExpiryDate < b.ExpiryDate
+}
+
+func expired(rm models.Remainder) bool {
+	return len(rm.ExpiryDate) > 0 && rm.ExpiryDate < today()
+}
+
+func (r *remainderRepo) Expiring(ctx context.Context, req *models.ExpiringRequest) (*models.ExpiringResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp    = models.ExpiringResponse{Days: req.Days}
+		date, _ = time.Parse("2006-01-02", today())
+		until   = date.AddDate(0, 0, req.Days).Format("2006-01-02")
+		lots    []*models.ExpiringLot
+	)
+
+	for _, rm := range r.s.db.remainders {
+		if rm.Quantity <= 0 || len(rm.ExpiryDate) == 0 || rm.ExpiryDate > until {
+			continue
+		}
+
+		if len(req.BranchID) > 0 && rm.BranchID != req.BranchID {
+			continue
+		}
+
+		expiry, _ := time.Parse("2006-01-02", rm.ExpiryDate)
+		lots = append(lots, &models.ExpiringLot{
+			RemainderID: rm.Id,
+			BranchID:    rm.BranchID,
+			ProductID:   rm.ProductID,
+			Name:        rm.Name,
+			LotNumber:   rm.LotNumber,
+			ExpiryDate:  rm.ExpiryDate,
+			DaysLeft:    int(expiry.Sub(date).Hours() / 24),
+			Quantity:    rm.Quantity,
+			ComingPrice: rm.ComingPrice,
+		})
+	}
+
+	sort.SliceStable(lots, func(i, j int) bool {
+		if lots[i].ExpiryDate != lots[j].ExpiryDate {
+			return lots[i].ExpiryDate < lots[j].ExpiryDate
+		}
+		return lots[i].Name < lots[j].Name
+	})
+
+	for _, lot := range lots {
+		resp.Add(lot)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type repricingRepo struct {
+	s *Store
+}
+
+func (r *repricingRepo) Create(ctx context.Context, req *models.CreateRepricing) (*models.Repricing, error) {
+
+	var at = time.Now()
+	if req.EffectiveFrom != nil {
+		at = *req.EffectiveFrom
+	}
+
+	repricing := models.Repricing{
+		Id:            uuid.New().String(),
+		IncrementID:   req.IncrementID,
+		BranchID:      req.BranchID,
+		CategoryID:    req.CategoryID,
+		Percent:       req.Percent,
+		Price:         req.Price,
+		EffectiveFrom: at.UTC().Format(time.RFC3339Nano),
+		Comment:       req.Comment,
+		UserID:        req.UserID,
+		CreatedAt:     now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.repricings = append(r.s.db.repricings, repricing)
+	for _, line := range req.Lines {
+		line.DocumentID = repricing.Id
+		r.s.db.addPriceChange(line, at)
+	}
+	r.s.db.applyDue(time.Now())
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.RepricingPrimaryKey{Id: repricing.Id})
+}
+
+func (r *repricingRepo) GetByID(ctx context.Context, req *models.RepricingPrimaryKey) (*models.Repricing, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.repricings, func(p models.Repricing) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	resp := r.s.db.repricings[i]
+	for _, c := range r.s.db.priceChanges {
+		if c.DocumentID == req.Id {
+			c := c
+			resp.Lines = append(resp.Lines, &c)
+		}
+	}
+
+	name := func(productId string) string {
+		if p := indexOf(r.s.db.products, func(p models.Product) bool { return p.Id == productId }); p >= 0 {
+			return r.s.db.products[p].Name
+		}
+		return ""
+	}
+
+	sort.SliceStable(resp.Lines, func(i, j int) bool {
+		return name(resp.Lines[i].ProductID) < name(resp.Lines[j].ProductID)
+	})
+
+	return &resp, nil
+}
+
+func (r *repricingRepo) GetList(ctx context.Context, req *models.GetListRepricingRequest) (*models.GetListRepricingResponse, error) {
+
+	if err := req.Filter.Validate(models.RepricingFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListRepricingResponse
+		found []*models.Repricing
+	)
+
+	for _, p := range newest(r.s.db.repricings, func(models.Repricing) bool { return true }) {
+		p := p
+		if match(models.RepricingFilterSpec, req.Search, req.Filter, repricingRow(p)) {
+			found = append(found, &p)
+		}
+	}
+
+	resp.Count = len(found)
+	resp.Repricings = page(found, req.Offset, req.Limit)
+
+	return &resp, nil
+}
+
+func repricingRow(p models.Repricing) row {
+	return row{
+		"branch_id":    p.BranchID,
+		"category_id":  p.CategoryID,
+		"increment_id": p.IncrementID,
+		"user_id":      p.UserID,
+		"comment":      p.Comment,
+		"created_at":   p.CreatedAt,
+	}
+}
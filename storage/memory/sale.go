@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type saleRepo struct {
+	s *Store
+}
+
+func (r *saleRepo) Create(ctx context.Context, req *models.CreateSale) (*models.Sale, error) {
+
+	sale := models.Sale{
+		Id:          uuid.New().String(),
+		ClientID:    req.ClientID,
+		BranchID:    req.BranchID,
+		IncrementID: req.IncrementID,
+		TotalPrice:  req.TotalPrice,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.sales = append(r.s.db.sales, sale)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.SalePrimaryKey{Id: sale.Id})
+}
+
+func (r *saleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*models.Sale, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.sales, func(s models.Sale) bool { return s.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	sale := r.s.db.sales[i]
+	return &sale, nil
+}
+
+func (r *saleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest) (*models.GetListSaleResponse, error) {
+
+	if err := req.Filter.Validate(models.SaleFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListSaleResponse
+		found = newest(r.s.db.sales, func(s models.Sale) bool {
+			return match(models.SaleFilterSpec, req.Search, req.Filter, saleRow(s))
+		})
+	)
+
+	for _, s := range page(found, req.Offset, req.Limit) {
+		sale := s
+		resp.Count = len(found)
+		resp.Sales = append(resp.Sales, &sale)
+	}
+
+	return &resp, nil
+}
+
+func (r *saleRepo) Update(ctx context.Context, req *models.UpdateSale) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.sales, func(s models.Sale) bool { return s.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	sale := &r.s.db.sales[i]
+	sale.BranchID = req.BranchID
+	sale.ClientID = req.ClientID
+	sale.IncrementID = req.IncrementID
+	sale.TotalPrice = req.TotalPrice
+	sale.Debd = sale.TotalPrice - sale.Returned - sale.Paid
+	sale.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *saleRepo) Delete(ctx context.Context, req *models.SalePrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.sales = remove(r.s.db.sales, func(s models.Sale) bool { return s.Id == req.Id })
+
+	return nil
+}
+
+func (r *saleRepo) Checkout(ctx context.Context, req *models.CreateCheckout) (*models.Checkout, error) {
+
+	var resp models.Checkout
+
+	err := r.s.WithTx(ctx, func(tx storage.StorageI) error {
+
+		var (
+			db   = tx.(*Store).db
+			sale = models.Sale{
+				Id:          uuid.New().String(),
+				ClientID:    req.ClientID,
+				BranchID:    req.BranchID,
+				IncrementID: req.IncrementID,
+				CreatedAt:   now(),
+				UpdatedAt:   now(),
+			}
+		)
+
+		for _, product := range req.Products {
+
+			// taken from the lots expiring first, expired ones are not sold
+			movements, err := db.postStockMovement(&models.CreateStockMovement{
+				BranchID:       req.BranchID,
+				ProductID:      product.ProductID,
+				Quantity:       -product.BaseQuantity(),
+				Type:           models.MovementSale,
+				DocumentID:     sale.Id,
+				DocumentNumber: req.IncrementID,
+				UserID:         req.UserID,
+			})
+			if err != nil {
+				return err
+			}
+
+			i := indexOf(db.remainders, func(rm models.Remainder) bool {
+				return rm.BranchID == req.BranchID && rm.ProductID == product.ProductID && rm.LotNumber == movements[0].LotNumber
+			})
+
+			price, unitPrice := product.Prices(db.remainders[i].SalePrice)
+			promotion, discount := product.Discount(unitPrice)
+
+			saleProduct := models.SaleProduct{
+				Id:              uuid.New().String(),
+				ProcutID:        product.ProductID,
+				SaleID:          sale.Id,
+				SaleIncrementID: req.IncrementID,
+				Quantity:        product.BaseQuantity(),
+				Price:           price,
+				TotalPrice:      unitPrice*float64(product.Quantity) - discount,
+				Unit:            product.Unit,
+				UnitQuantity:    product.Quantity,
+				UnitPrice:       unitPrice,
+				Discount:        discount,
+				CreatedAt:       now(),
+				UpdatedAt:       now(),
+			}
+			if promotion != nil {
+				saleProduct.PromotionID = promotion.Id
+			}
+			sale.TotalPrice += saleProduct.TotalPrice
+
+			db.saleProducts = append(db.saleProducts, saleProduct)
+			resp.SaleProducts = append(resp.SaleProducts, &saleProduct)
+		}
+
+		sale.Debd = sale.TotalPrice
+		db.sales = append(db.sales, sale)
+		resp.Sale = &sale
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// saleBranch is the branch of a sale, the caller holds the lock.
+func (d *database) saleBranch(saleId string) string {
+
+	if i := indexOf(d.sales, func(s models.Sale) bool { return s.Id == saleId }); i >= 0 {
+		return d.sales[i].BranchID
+	}
+
+	return ""
+}
+
+func saleRow(s models.Sale) row {
+	return row{
+		"branch_id":    s.BranchID,
+		"client_id":    s.ClientID,
+		"increment_id": s.IncrementID,
+		"total_price":  s.TotalPrice,
+		"returned":     s.Returned,
+		"paid":         s.Paid,
+		"debt":         s.Debd,
+		"created_at":   s.CreatedAt,
+	}
+}
+
+func (r *saleRepo) DebtAging(ctx context.Context, req *models.DebtAgingRequest) (*models.DebtAgingResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp    models.DebtAgingResponse
+		clients = map[string]*models.DebtAging{}
+		at      = time.Now().UTC()
+	)
+
+	if req.At != nil {
+		at = *req.At
+	}
+
+	for _, s := range r.s.db.sales {
+		if s.Debd <= 0 || (len(req.BranchID) > 0 && s.BranchID != req.BranchID) {
+			continue
+		}
+
+		c := indexOf(r.s.db.clients, func(c models.Client) bool { return c.Id == s.ClientID })
+		if c < 0 {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339Nano, s.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		client, ok := clients[s.ClientID]
+		if !ok {
+			client = &models.DebtAging{
+				ClientID:  s.ClientID,
+				FirstName: r.s.db.clients[c].FirstName,
+				LastName:  r.s.db.clients[c].LastName,
+				Phone:     r.s.db.clients[c].Phone,
+			}
+			clients[s.ClientID] = client
+			resp.Clients = append(resp.Clients, client)
+		}
+
+		days := int(at.Sub(createdAt).Hours() / 24)
+		client.Add(days, s.Debd)
+		resp.Total.Add(days, s.Debd)
+	}
+
+	sort.SliceStable(resp.Clients, func(i, j int) bool { return resp.Clients[i].Total > resp.Clients[j].Total })
+
+	return &resp, nil
+}
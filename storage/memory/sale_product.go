@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+	"market_system/pkg/apperror"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type saleProductRepo struct {
+	s *Store
+}
+
+// Create mirrors the postgres repo: the line is priced from the product,
+// added to the sale total and taken from the branch remainder.
+func (r *saleProductRepo) Create(ctx context.Context, req *models.CreateSaleProduct) (*models.SaleProduct, error) {
+
+	r.s.mu.Lock()
+
+	db := r.s.db
+
+	s := indexOf(db.sales, func(s models.Sale) bool { return s.Id == req.SaleID })
+	if s < 0 {
+		r.s.mu.Unlock()
+		return nil, apperror.New(apperror.Validation, "no such sale").WithField("sale_id", "does not exist")
+	}
+
+	p := indexOf(db.products, func(p models.Product) bool { return p.Id == req.ProcutID })
+	if p < 0 {
+		r.s.mu.Unlock()
+		return nil, pgx.ErrNoRows
+	}
+
+	saleProduct := models.SaleProduct{
+		Id:              uuid.New().String(),
+		ProcutID:        req.ProcutID,
+		SaleID:          req.SaleID,
+		SaleIncrementID: req.SaleIncrementID,
+		Quantity:        req.Quantity,
+		Price:           db.products[p].Price,
+		TotalPrice:      db.products[p].Price * float64(req.Quantity),
+		UnitQuantity:    req.Quantity,
+		UnitPrice:       db.products[p].Price,
+		CreatedAt:       now(),
+	}
+
+	db.saleProducts = append(db.saleProducts, saleProduct)
+	db.sales[s].TotalPrice += saleProduct.TotalPrice
+
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.SaleProductPrimaryKey{Id: saleProduct.Id})
+}
+
+func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPrimaryKey) (*models.SaleProduct, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.saleProducts, func(sp models.SaleProduct) bool { return sp.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	saleProduct := r.s.db.saleProducts[i]
+	return &saleProduct, nil
+}
+
+func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSaleProductRequest) (*models.GetListSaleProductResponse, error) {
+
+	if err := req.Filter.Validate(models.SaleProductFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListSaleProductResponse
+		found = newest(r.s.db.saleProducts, func(sp models.SaleProduct) bool {
+			// a line is of the branch of its sale
+			fields := saleProductRow(sp)
+			fields["branch_id"] = r.s.db.saleBranch(sp.SaleID)
+			return match(models.SaleProductFilterSpec, req.Search, req.Filter, fields)
+		})
+	)
+
+	for _, sp := range page(found, req.Offset, req.Limit) {
+		saleProduct := sp
+		resp.Count = len(found)
+		resp.SaleProducts = append(resp.SaleProducts, &saleProduct)
+	}
+
+	return &resp, nil
+}
+
+func (r *saleProductRepo) Update(ctx context.Context, req *models.UpdateSaleProduct) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.saleProducts, func(sp models.SaleProduct) bool { return sp.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	saleProduct := &r.s.db.saleProducts[i]
+	saleProduct.ProcutID = req.ProcutID
+	saleProduct.SaleID = req.SaleID
+	saleProduct.SaleIncrementID = req.SaleIncrementID
+	saleProduct.Quantity = req.Quantity
+	saleProduct.Price = req.Price
+	saleProduct.TotalPrice = float64(req.Quantity) * req.Price
+	saleProduct.Unit = ""
+	saleProduct.UnitQuantity = req.Quantity
+	saleProduct.UnitPrice = req.Price
+	saleProduct.Discount = 0
+	saleProduct.PromotionID = ""
+	saleProduct.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *saleProductRepo) Delete(ctx context.Context, req *models.SaleProductPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.saleProducts = remove(r.s.db.saleProducts, func(sp models.SaleProduct) bool { return sp.Id == req.Id })
+
+	return nil
+}
+
+func saleProductRow(sp models.SaleProduct) row {
+	return row{
+		"product_id":        sp.ProcutID,
+		"sale_id":           sp.SaleID,
+		"sale_increment_id": sp.SaleIncrementID,
+		"quantity":          sp.Quantity,
+		"price":             sp.Price,
+		"total_price":       sp.TotalPrice,
+		"created_at":        sp.CreatedAt,
+	}
+}
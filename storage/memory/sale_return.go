@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type saleReturnRepo struct {
+	s *Store
+}
+
+func (r *saleReturnRepo) Create(ctx context.Context, req *models.CreateSaleReturn) (*models.SaleReturn, error) {
+
+	var saleReturnId = uuid.New().String()
+
+	err := r.s.WithTx(ctx, func(tx storage.StorageI) error {
+
+		db := tx.(*Store).db
+
+		s := indexOf(db.sales, func(s models.Sale) bool { return s.Id == req.SaleID })
+		if s < 0 {
+			return pgx.ErrNoRows
+		}
+
+		var (
+			sale       = &db.sales[s]
+			saleReturn = models.SaleReturn{
+				Id:          saleReturnId,
+				IncrementID: req.IncrementID,
+				SaleID:      sale.Id,
+				BranchID:    sale.BranchID,
+				ClientID:    sale.ClientID,
+				Reason:      req.Reason,
+				UserID:      req.UserID,
+				CreatedAt:   now(),
+			}
+			returned = db.returned(sale.Id)
+		)
+
+		for _, line := range req.Lines {
+
+			i := indexOf(db.saleProducts, func(sp models.SaleProduct) bool {
+				return sp.Id == line.SaleProductID && sp.SaleID == sale.Id
+			})
+			if i < 0 {
+				return pgx.ErrNoRows
+			}
+
+			saleProduct := db.saleProducts[i]
+			if returned[saleProduct.Id]+line.Quantity > saleProduct.Quantity {
+				return fmt.Errorf("%w: line %s", storage.ErrOverReturn, line.SaleProductID)
+			}
+			returned[saleProduct.Id] += line.Quantity
+
+			returnLine := models.SaleReturnLine{
+				Id:            uuid.New().String(),
+				SaleReturnID:  saleReturn.Id,
+				SaleProductID: saleProduct.Id,
+				ProductID:     saleProduct.ProcutID,
+				Quantity:      line.Quantity,
+				Price:         saleProduct.NetPrice(),
+				TotalPrice:    saleProduct.NetPrice() * float64(line.Quantity),
+				CreatedAt:     now(),
+			}
+			saleReturn.Quantity += returnLine.Quantity
+			saleReturn.TotalPrice += returnLine.TotalPrice
+
+			db.saleReturnLines = append(db.saleReturnLines, returnLine)
+		}
+
+		saleReturn.DebtReduced = math.Min(saleReturn.TotalPrice, math.Max(sale.Debd, 0))
+		saleReturn.Refund = saleReturn.TotalPrice - saleReturn.DebtReduced
+
+		if saleReturn.Refund > 0 {
+			payment := models.Payment{
+				Id:        uuid.New().String(),
+				SaleID:    sale.Id,
+				Amount:    -saleReturn.Refund,
+				Method:    req.Method,
+				CreatedAt: now(),
+			}
+			db.payments = append(db.payments, payment)
+			saleReturn.RefundPaymentID = payment.Id
+		}
+
+		sale.Returned += saleReturn.TotalPrice
+		sale.Paid -= saleReturn.Refund
+		sale.Debd -= saleReturn.DebtReduced
+		sale.UpdatedAt = now()
+
+		db.saleReturns = append(db.saleReturns, saleReturn)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.SaleReturnPrimaryKey{Id: saleReturnId})
+}
+
+// returned sums the returned quantity per sale_product of a sale.
+func (d *database) returned(saleId string) map[string]int {
+
+	var returns = map[string]bool{}
+	for _, sr := range d.saleReturns {
+		if sr.SaleID == saleId {
+			returns[sr.Id] = true
+		}
+	}
+
+	var resp = map[string]int{}
+	for _, l := range d.saleReturnLines {
+		if returns[l.SaleReturnID] {
+			resp[l.SaleProductID] += l.Quantity
+		}
+	}
+
+	return resp
+}
+
+func (r *saleReturnRepo) GetByID(ctx context.Context, req *models.SaleReturnPrimaryKey) (*models.SaleReturn, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.saleReturns, func(sr models.SaleReturn) bool { return sr.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	saleReturn := r.s.db.saleReturns[i]
+	for _, l := range r.s.db.saleReturnLines {
+		if l.SaleReturnID == saleReturn.Id {
+			line := l
+			saleReturn.Lines = append(saleReturn.Lines, &line)
+		}
+	}
+
+	return &saleReturn, nil
+}
+
+func (r *saleReturnRepo) GetList(ctx context.Context, req *models.GetListSaleReturnRequest) (*models.GetListSaleReturnResponse, error) {
+
+	if err := req.Filter.Validate(models.SaleReturnFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListSaleReturnResponse
+		found = newest(r.s.db.saleReturns, func(sr models.SaleReturn) bool {
+			return match(models.SaleReturnFilterSpec, req.Search, req.Filter, saleReturnRow(sr))
+		})
+	)
+
+	for _, sr := range page(found, req.Offset, req.Limit) {
+		saleReturn := sr
+		resp.Count = len(found)
+		resp.SaleReturns = append(resp.SaleReturns, &saleReturn)
+	}
+
+	return &resp, nil
+}
+
+func (r *saleReturnRepo) Returned(ctx context.Context, req *models.SalePrimaryKey) (map[string]int, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.db.returned(req.Id), nil
+}
+
+func saleReturnRow(sr models.SaleReturn) row {
+	return row{
+		"sale_id":      sr.SaleID,
+		"branch_id":    sr.BranchID,
+		"client_id":    sr.ClientID,
+		"increment_id": sr.IncrementID,
+		"user_id":      sr.UserID,
+		"total_price":  sr.TotalPrice,
+		"refund":       sr.Refund,
+		"created_at":   sr.CreatedAt,
+	}
+}
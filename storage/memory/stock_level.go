@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type stockLevelRepo struct {
+	s *Store
+}
+
+func (r *stockLevelRepo) Set(ctx context.Context, req *models.SetStockLevel) (*models.StockLevel, error) {
+
+	r.s.mu.Lock()
+	i := indexOf(r.s.db.stockLevels, func(l models.StockLevel) bool {
+		return l.BranchID == req.BranchID && l.ProductID == req.ProductID
+	})
+	if i < 0 {
+		r.s.db.stockLevels = append(r.s.db.stockLevels, models.StockLevel{
+			BranchID:  req.BranchID,
+			ProductID: req.ProductID,
+			CreatedAt: now(),
+		})
+		i = len(r.s.db.stockLevels) - 1
+	}
+
+	level := &r.s.db.stockLevels[i]
+	level.MinQuantity = req.MinQuantity
+	level.MaxQuantity = req.MaxQuantity
+	level.UpdatedAt = now()
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.StockLevelPrimaryKey{BranchID: req.BranchID, ProductID: req.ProductID})
+}
+
+func (r *stockLevelRepo) GetByID(ctx context.Context, req *models.StockLevelPrimaryKey) (*models.StockLevel, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.stockLevels, func(l models.StockLevel) bool {
+		return l.BranchID == req.BranchID && l.ProductID == req.ProductID
+	})
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	level := r.s.db.stockLevels[i]
+	return &level, nil
+}
+
+func (r *stockLevelRepo) GetList(ctx context.Context, req *models.GetListStockLevelRequest) (*models.GetListStockLevelResponse, error) {
+
+	if err := req.Filter.Validate(models.StockLevelFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListStockLevelResponse
+		found = newest(r.s.db.stockLevels, func(l models.StockLevel) bool {
+			return match(models.StockLevelFilterSpec, req.Search, req.Filter, stockLevelRow(l))
+		})
+	)
+
+	resp.Count = len(found)
+	for _, l := range page(found, req.Offset, req.Limit) {
+		level := l
+		resp.StockLevels = append(resp.StockLevels, &level)
+	}
+
+	return &resp, nil
+}
+
+func (r *stockLevelRepo) Delete(ctx context.Context, req *models.StockLevelPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.stockLevels = remove(r.s.db.stockLevels, func(l models.StockLevel) bool {
+		return l.BranchID == req.BranchID && l.ProductID == req.ProductID
+	})
+
+	return nil
+}
+
+func (r *stockLevelRepo) Low(ctx context.Context, req *models.LowStockRequest) (*models.LowStockResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var resp models.LowStockResponse
+	for _, l := range r.s.db.stockLevels {
+		if len(req.BranchID) > 0 && l.BranchID != req.BranchID {
+			continue
+		}
+
+		quantity, _ := r.s.db.stock(l.BranchID, l.ProductID)
+		if quantity >= l.MinQuantity {
+			continue
+		}
+
+		resp.Products = append(resp.Products, &models.LowStock{
+			BranchID:    l.BranchID,
+			ProductID:   l.ProductID,
+			Name:        r.s.db.productName(l.ProductID),
+			Quantity:    quantity,
+			MinQuantity: l.MinQuantity,
+			MaxQuantity: l.MaxQuantity,
+			Shortage:    l.MinQuantity - quantity,
+		})
+	}
+
+	sort.SliceStable(resp.Products, func(i, j int) bool {
+		if resp.Products[i].Shortage != resp.Products[j].Shortage {
+			return resp.Products[i].Shortage > resp.Products[j].Shortage
+		}
+		return resp.Products[i].Name < resp.Products[j].Name
+	})
+
+	return &resp, nil
+}
+
+func (r *stockLevelRepo) Reorder(ctx context.Context, req *models.ReorderRequest) (*models.ReorderResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp = models.ReorderResponse{
+			BranchID:   req.BranchID,
+			WindowDays: req.WindowDays,
+			LeadDays:   req.LeadDays,
+		}
+		since    = time.Now().UTC().AddDate(0, 0, -req.WindowDays)
+		sold     = map[string]int{}
+		returned = map[string]int{}
+		lines    []*models.ReorderLine
+	)
+
+	for _, sp := range r.s.db.saleProducts {
+		created, _ := time.Parse(time.RFC3339Nano, sp.CreatedAt)
+		if created.Before(since) {
+			continue
+		}
+
+		i := indexOf(r.s.db.sales, func(s models.Sale) bool { return s.Id == sp.SaleID })
+		if i >= 0 && r.s.db.sales[i].BranchID == req.BranchID {
+			sold[sp.ProcutID] += sp.Quantity
+		}
+	}
+
+	// what came back in the window was not sold
+	for _, l := range r.s.db.saleReturnLines {
+		created, _ := time.Parse(time.RFC3339Nano, l.CreatedAt)
+		if created.Before(since) {
+			continue
+		}
+
+		i := indexOf(r.s.db.saleReturns, func(sr models.SaleReturn) bool { return sr.Id == l.SaleReturnID })
+		if i >= 0 && r.s.db.saleReturns[i].BranchID == req.BranchID {
+			returned[l.ProductID] += l.Quantity
+		}
+	}
+
+	for _, l := range r.s.db.stockLevels {
+		if l.BranchID != req.BranchID || len(req.ProductIDs) > 0 && !contains(req.ProductIDs, l.ProductID) {
+			continue
+		}
+
+		quantity, price := r.s.db.stock(l.BranchID, l.ProductID)
+
+		sales := sold[l.ProductID] - returned[l.ProductID]
+		if sales < 0 {
+			sales = 0
+		}
+
+		lines = append(lines, &models.ReorderLine{
+			ProductID:   l.ProductID,
+			Name:        r.s.db.productName(l.ProductID),
+			Quantity:    quantity,
+			MinQuantity: l.MinQuantity,
+			MaxQuantity: l.MaxQuantity,
+			Sold:        sales,
+			Price:       price,
+		})
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Name < lines[j].Name })
+	for _, line := range lines {
+		resp.Propose(line)
+	}
+
+	return &resp, nil
+}
+
+// stock sums the lots of a product in a branch and returns the coming price
+// of the newest one, the caller holds the lock.
+func (d *database) stock(branchId, productId string) (quantity int, price float64) {
+
+	for _, rm := range d.remainders {
+		if rm.BranchID == branchId && rm.ProductID == productId {
+			quantity += rm.Quantity
+			price = rm.ComingPrice
+		}
+	}
+
+	return quantity, price
+}
+
+func (d *database) productName(productId string) string {
+
+	if i := indexOf(d.products, func(p models.Product) bool { return p.Id == productId }); i >= 0 {
+		return d.products[i].Name
+	}
+
+	return ""
+}
+
+func stockLevelRow(l models.StockLevel) row {
+	return row{
+		"branch_id":    l.BranchID,
+		"product_id":   l.ProductID,
+		"min_quantity": l.MinQuantity,
+		"max_quantity": l.MaxQuantity,
+		"created_at":   l.CreatedAt,
+	}
+}
@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type stockMovementRepo struct {
+	s *Store
+}
+
+func (r *stockMovementRepo) Create(ctx context.Context, req *models.CreateStockMovement) (*models.StockMovement, error) {
+
+	r.s.mu.Lock()
+
+	movements, err := r.s.db.postStockMovement(req)
+	if err != nil {
+		r.s.mu.Unlock()
+		return nil, err
+	}
+
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.StockMovementPrimaryKey{Id: movements[0].Id})
+}
+
+// postStockMovement applies req to remainder and appends its journal lines,
+// an outgoing movement without a lot gets one line per lot it is taken from.
+// The caller holds the lock.
+func (d *database) postStockMovement(req *models.CreateStockMovement) ([]models.StockMovement, error) {
+
+	var add = &models.AddRemainder{
+		BranchID:    req.BranchID,
+		ProductID:   req.ProductID,
+		Name:        req.Name,
+		Quantity:    req.Quantity,
+		ComingPrice: req.ComingPrice,
+		SalePrice:   req.SalePrice,
+		LotNumber:   req.LotNumber,
+		ExpiryDate:  req.ExpiryDate,
+		Sellable:    req.Type == models.MovementSale,
+	}
+
+	if req.Quantity >= 0 || len(req.LotNumber) > 0 {
+		if err := d.addQuantity(add); err != nil {
+			return nil, err
+		}
+
+		movement := newStockMovement(req)
+		d.stockMovements = append(d.stockMovements, movement)
+
+		return []models.StockMovement{movement}, nil
+	}
+
+	lots, err := d.allocate(add)
+	if err != nil {
+		return nil, err
+	}
+
+	var movements []models.StockMovement
+	for _, lot := range lots {
+		line := *req
+		line.Quantity = -lot.Quantity
+		line.LotNumber = lot.LotNumber
+		line.ExpiryDate = lot.ExpiryDate
+
+		movement := newStockMovement(&line)
+		d.stockMovements = append(d.stockMovements, movement)
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+func newStockMovement(req *models.CreateStockMovement) models.StockMovement {
+	return models.StockMovement{
+		Id:             uuid.New().String(),
+		BranchID:       req.BranchID,
+		ProductID:      req.ProductID,
+		Quantity:       req.Quantity,
+		Type:           req.Type,
+		DocumentID:     req.DocumentID,
+		DocumentNumber: req.DocumentNumber,
+		UserID:         req.UserID,
+		Comment:        req.Comment,
+		LotNumber:      req.LotNumber,
+		ExpiryDate:     req.ExpiryDate,
+		CreatedAt:      now(),
+	}
+}
+
+func (r *stockMovementRepo) GetByID(ctx context.Context, req *models.StockMovementPrimaryKey) (*models.StockMovement, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.stockMovements, func(m models.StockMovement) bool { return m.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	movement := r.s.db.stockMovements[i]
+	return &movement, nil
+}
+
+func (r *stockMovementRepo) GetList(ctx context.Context, req *models.GetListStockMovementRequest) (*models.GetListStockMovementResponse, error) {
+
+	if err := req.Filter.Validate(models.StockMovementFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListStockMovementResponse
+		found = newest(r.s.db.stockMovements, func(m models.StockMovement) bool {
+			return match(models.StockMovementFilterSpec, req.Search, req.Filter, stockMovementRow(m))
+		})
+	)
+
+	for _, m := range page(found, req.Offset, req.Limit) {
+		movement := m
+		resp.Count = len(found)
+		resp.StockMovements = append(resp.StockMovements, &movement)
+	}
+
+	return &resp, nil
+}
+
+func (r *stockMovementRepo) RebuildRemainder(ctx context.Context, req *models.RebuildRemainderRequest) (*models.RebuildRemainderResponse, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	type key struct{ branchID, productID, lotNumber string }
+
+	var (
+		resp    = models.RebuildRemainderResponse{DryRun: req.DryRun}
+		journal = map[key]int{}
+		stock   = map[key]int{}
+		keys    []key
+	)
+
+	add := func(sums map[key]int, k key, quantity int) {
+		if len(req.BranchID) > 0 && k.branchID != req.BranchID {
+			return
+		}
+		if _, ok := journal[k]; !ok {
+			if _, ok = stock[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sums[k] += quantity
+	}
+
+	for _, m := range r.s.db.stockMovements {
+		add(journal, key{m.BranchID, m.ProductID, m.LotNumber}, m.Quantity)
+	}
+
+	for _, rm := range r.s.db.remainders {
+		add(stock, key{rm.BranchID, rm.ProductID, rm.LotNumber}, rm.Quantity)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].branchID != keys[j].branchID {
+			return keys[i].branchID < keys[j].branchID
+		}
+		if keys[i].productID != keys[j].productID {
+			return keys[i].productID < keys[j].productID
+		}
+		return keys[i].lotNumber < keys[j].lotNumber
+	})
+
+	for _, k := range keys {
+		if journal[k] == stock[k] {
+			continue
+		}
+
+		resp.Discrepancies = append(resp.Discrepancies, &models.StockDiscrepancy{
+			BranchID:   k.branchID,
+			ProductID:  k.productID,
+			LotNumber:  k.lotNumber,
+			Remainder:  stock[k],
+			Journal:    journal[k],
+			Difference: journal[k] - stock[k],
+		})
+
+		if req.DryRun {
+			continue
+		}
+
+		var first = true
+		for i := range r.s.db.remainders {
+			rm := &r.s.db.remainders[i]
+			if rm.BranchID != k.branchID || rm.ProductID != k.productID || rm.LotNumber != k.lotNumber {
+				continue
+			}
+
+			rm.Quantity = 0
+			if first {
+				rm.Quantity = journal[k]
+				first = false
+			}
+			rm.UpdatedAt = now()
+		}
+
+		if !first {
+			continue
+		}
+
+		remainder := models.Remainder{
+			Id:        uuid.New().String(),
+			ProductID: k.productID,
+			Quantity:  journal[k],
+			BranchID:  k.branchID,
+			LotNumber: k.lotNumber,
+			CreatedAt: now(),
+			UpdatedAt: now(),
+		}
+
+		for _, m := range r.s.db.stockMovements {
+			if m.BranchID == k.branchID && m.ProductID == k.productID && m.LotNumber == k.lotNumber && m.ExpiryDate > remainder.ExpiryDate {
+				remainder.ExpiryDate = m.ExpiryDate
+			}
+		}
+
+		if p := indexOf(r.s.db.products, func(p models.Product) bool { return p.Id == k.productID }); p >= 0 {
+			remainder.Name = r.s.db.products[p].Name
+			remainder.SalePrice, _ = r.s.db.salePrice(k.branchID, k.productID)
+		}
+
+		r.s.db.remainders = append(r.s.db.remainders, remainder)
+	}
+
+	return &resp, nil
+}
+
+func stockMovementRow(m models.StockMovement) row {
+	return row{
+		"branch_id":       m.BranchID,
+		"product_id":      m.ProductID,
+		"type":            m.Type,
+		"document_id":     m.DocumentID,
+		"user_id":         m.UserID,
+		"quantity":        m.Quantity,
+		"document_number": m.DocumentNumber,
+		"comment":         m.Comment,
+		"lot_number":      m.LotNumber,
+		"created_at":      m.CreatedAt,
+	}
+}
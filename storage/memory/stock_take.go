@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type stockTakeRepo struct {
+	s *Store
+}
+
+func (r *stockTakeRepo) Create(ctx context.Context, req *models.CreateStockTake) (*models.StockTake, error) {
+
+	stockTake := models.StockTake{
+		Id:          uuid.New().String(),
+		IncrementID: req.IncrementID,
+		BranchID:    req.BranchID,
+		Status:      models.StockTakeOpen,
+		UserID:      req.UserID,
+		CreatedAt:   now(),
+		UpdatedAt:   now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.stockTakes = append(r.s.db.stockTakes, stockTake)
+	for _, line := range req.Lines {
+		r.s.db.stockTakeLines = append(r.s.db.stockTakeLines, models.StockTakeLine{
+			Id:          uuid.New().String(),
+			StockTakeID: stockTake.Id,
+			ProductID:   line.ProductID,
+			Expected:    line.Expected,
+			ComingPrice: line.ComingPrice,
+			CreatedAt:   now(),
+		})
+	}
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.StockTakePrimaryKey{Id: stockTake.Id})
+}
+
+func (r *stockTakeRepo) GetByID(ctx context.Context, req *models.StockTakePrimaryKey) (*models.StockTake, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.stockTakes, func(t models.StockTake) bool { return t.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	stockTake := r.s.db.stockTakes[i]
+	for _, l := range r.s.db.stockTakeLines {
+		if l.StockTakeID == stockTake.Id {
+			line := l
+			if l.Counted != nil {
+				counted := *l.Counted
+				line.Counted = &counted
+			}
+			stockTake.AddLine(&line)
+		}
+	}
+
+	return &stockTake, nil
+}
+
+func (r *stockTakeRepo) GetList(ctx context.Context, req *models.GetListStockTakeRequest) (*models.GetListStockTakeResponse, error) {
+
+	if err := req.Filter.Validate(models.StockTakeFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListStockTakeResponse
+		found = newest(r.s.db.stockTakes, func(t models.StockTake) bool {
+			return match(models.StockTakeFilterSpec, req.Search, req.Filter, stockTakeRow(t))
+		})
+	)
+
+	for _, t := range page(found, req.Offset, req.Limit) {
+		stockTake := t
+		resp.Count = len(found)
+		resp.StockTakes = append(resp.StockTakes, &stockTake)
+	}
+
+	return &resp, nil
+}
+
+func (r *stockTakeRepo) Delete(ctx context.Context, req *models.StockTakePrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.stockTakes = remove(r.s.db.stockTakes, func(t models.StockTake) bool { return t.Id == req.Id })
+	r.s.db.stockTakeLines = remove(r.s.db.stockTakeLines, func(l models.StockTakeLine) bool { return l.StockTakeID == req.Id })
+
+	return nil
+}
+
+func (r *stockTakeRepo) SetCounts(ctx context.Context, req *models.SetStockTakeCounts) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.stockTakes, func(t models.StockTake) bool {
+		return t.Id == req.Id && t.Status == models.StockTakeOpen
+	})
+	if i < 0 {
+		return 0, nil
+	}
+
+	var rowsAffected int64
+	for _, count := range req.Counts {
+		j := indexOf(r.s.db.stockTakeLines, func(l models.StockTakeLine) bool {
+			return l.StockTakeID == req.Id && l.ProductID == count.ProductID
+		})
+		if j < 0 {
+			continue
+		}
+
+		counted := count.Counted
+		r.s.db.stockTakeLines[j].Counted = &counted
+		rowsAffected++
+	}
+
+	r.s.db.stockTakes[i].UpdatedAt = now()
+
+	return rowsAffected, nil
+}
+
+func (r *stockTakeRepo) SetExpected(ctx context.Context, req *models.SetStockTakeExpected) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.stockTakes, func(t models.StockTake) bool {
+		return t.Id == req.Id && t.Status == models.StockTakeOpen
+	})
+	if i < 0 {
+		return 0, nil
+	}
+
+	var rowsAffected int64
+	for _, expected := range req.Expected {
+		j := indexOf(r.s.db.stockTakeLines, func(l models.StockTakeLine) bool {
+			return l.StockTakeID == req.Id && l.ProductID == expected.ProductID
+		})
+		if j < 0 {
+			continue
+		}
+
+		r.s.db.stockTakeLines[j].Expected = expected.Expected
+		rowsAffected++
+	}
+
+	r.s.db.stockTakes[i].UpdatedAt = now()
+
+	return rowsAffected, nil
+}
+
+func (r *stockTakeRepo) SetStatus(ctx context.Context, req *models.StockTakeStatus) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.stockTakes, func(t models.StockTake) bool { return t.Id == req.Id && t.Status == req.From })
+	if i < 0 {
+		return 0, nil
+	}
+
+	stockTake := &r.s.db.stockTakes[i]
+	stockTake.Status = req.To
+	stockTake.UpdatedAt = now()
+
+	if req.To == models.StockTakeApproved {
+		stockTake.ApprovedAt = now()
+	}
+
+	return 1, nil
+}
+
+func stockTakeRow(t models.StockTake) row {
+	return row{
+		"increment_id": t.IncrementID,
+		"branch_id":    t.BranchID,
+		"status":       t.Status,
+		"user_id":      t.UserID,
+		"created_at":   t.CreatedAt,
+	}
+}
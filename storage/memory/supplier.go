@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type supplierRepo struct {
+	s *Store
+}
+
+// errTINTaken is what postgres reports for a second supplier with the same TIN.
+func errTINTaken(tin string) error {
+	return &pgconn.PgError{
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "supplier_tin_idx"`,
+		Detail:         fmt.Sprintf("Key (tin)=(%s) already exists.", tin),
+		TableName:      "supplier",
+		ConstraintName: "supplier_tin_idx",
+	}
+}
+
+func (d *database) tinTaken(id, tin string) bool {
+	return tin != "" && indexOf(d.suppliers, func(s models.Supplier) bool { return s.TIN == tin && s.Id != id }) >= 0
+}
+
+func (r *supplierRepo) Create(ctx context.Context, req *models.CreateSupplier) (*models.Supplier, error) {
+
+	supplier := models.Supplier{
+		Id:        uuid.New().String(),
+		Name:      req.Name,
+		TIN:       req.TIN,
+		Phone:     req.Phone,
+		Address:   req.Address,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+
+	r.s.mu.Lock()
+	if r.s.db.tinTaken(supplier.Id, req.TIN) {
+		r.s.mu.Unlock()
+		return nil, errTINTaken(req.TIN)
+	}
+	r.s.db.suppliers = append(r.s.db.suppliers, supplier)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.SupplierPrimaryKey{Id: supplier.Id})
+}
+
+func (r *supplierRepo) GetByID(ctx context.Context, req *models.SupplierPrimaryKey) (*models.Supplier, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.suppliers, func(s models.Supplier) bool { return s.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	supplier := r.s.db.suppliers[i]
+	return &supplier, nil
+}
+
+func (r *supplierRepo) GetList(ctx context.Context, req *models.GetListSupplierRequest) (*models.GetListSupplierResponse, error) {
+
+	if err := req.Filter.Validate(models.SupplierFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListSupplierResponse
+		found = newest(r.s.db.suppliers, func(s models.Supplier) bool {
+			return match(models.SupplierFilterSpec, req.Search, req.Filter, supplierRow(s))
+		})
+	)
+
+	for _, s := range page(found, req.Offset, req.Limit) {
+		supplier := s
+		resp.Count = len(found)
+		resp.Suppliers = append(resp.Suppliers, &supplier)
+	}
+
+	return &resp, nil
+}
+
+func (r *supplierRepo) Update(ctx context.Context, req *models.UpdateSupplier) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.suppliers, func(s models.Supplier) bool { return s.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	if r.s.db.tinTaken(req.Id, req.TIN) {
+		return 0, errTINTaken(req.TIN)
+	}
+
+	s := &r.s.db.suppliers[i]
+	s.Name = req.Name
+	s.TIN = req.TIN
+	s.Phone = req.Phone
+	s.Address = req.Address
+	s.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *supplierRepo) Delete(ctx context.Context, req *models.SupplierPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.suppliers = remove(r.s.db.suppliers, func(s models.Supplier) bool { return s.Id == req.Id })
+
+	return nil
+}
+
+func (r *supplierRepo) Statement(ctx context.Context, req *models.SupplierStatementRequest) (*models.SupplierStatement, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		entries []*models.SupplierStatementEntry
+		comings = map[string]models.Coming{}
+	)
+
+	for _, c := range r.s.db.comings {
+		if c.SupplierID == req.SupplierID && (req.BranchID == "" || c.BranchID == req.BranchID) {
+			comings[c.Id] = c
+		}
+	}
+
+	for _, e := range r.s.db.comingEvents {
+		c, ok := comings[e.ComingID]
+		if !ok {
+			continue
+		}
+
+		var (
+			total = r.s.db.comingTotal(c.Id)
+			entry = &models.SupplierStatementEntry{
+				Id:          e.Id,
+				ComingID:    c.Id,
+				IncrementID: c.IncrementID,
+				BranchID:    c.BranchID,
+				CreatedAt:   e.CreatedAt,
+			}
+		)
+
+		switch e.Action {
+		case "finish":
+			entry.Type, entry.Credit = "coming", total
+		case "reverse":
+			entry.Type, entry.Debit = "reversal", total
+		default:
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	for _, p := range r.s.db.supplierPayments {
+		if p.SupplierID != req.SupplierID || (req.BranchID != "" && p.BranchID != req.BranchID) {
+			continue
+		}
+		entries = append(entries, &models.SupplierStatementEntry{
+			Type:      "payment",
+			Id:        p.Id,
+			BranchID:  p.BranchID,
+			Debit:     p.Amount,
+			CreatedAt: p.CreatedAt,
+		})
+	}
+
+	var times = make(map[*models.SupplierStatementEntry]time.Time, len(entries))
+	for _, e := range entries {
+		createdAt, err := time.Parse(time.RFC3339Nano, e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		times[e] = createdAt
+	}
+
+	// a coming comes before the payment made at the same moment
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !times[entries[i]].Equal(times[entries[j]]) {
+			return times[entries[i]].Before(times[entries[j]])
+		}
+		return entries[i].Type < entries[j].Type
+	})
+
+	var resp = models.SupplierStatement{SupplierID: req.SupplierID, BranchID: req.BranchID}
+
+	for _, e := range entries {
+		switch createdAt := times[e]; {
+		case req.From != nil && createdAt.Before(*req.From):
+			resp.OpeningBalance += e.Credit - e.Debit
+			resp.ClosingBalance = resp.OpeningBalance
+		case req.To != nil && !createdAt.Before(*req.To):
+		default:
+			resp.AddEntry(e)
+		}
+	}
+
+	return &resp, nil
+}
+
+// supplierBalance is what was received from the supplier in finished comings
+// and what was paid to it, in one branch or in all when branchId is empty.
+func (d *database) supplierBalance(supplierId, branchId string) (received, paid float64, ok bool) {
+
+	for _, c := range d.comings {
+		if c.SupplierID == supplierId && c.Status == "finished" && (branchId == "" || c.BranchID == branchId) {
+			received += d.comingTotal(c.Id)
+			ok = true
+		}
+	}
+
+	for _, p := range d.supplierPayments {
+		if p.SupplierID == supplierId && (branchId == "" || p.BranchID == branchId) {
+			paid += p.Amount
+			ok = true
+		}
+	}
+
+	return received, paid, ok
+}
+
+func (r *supplierRepo) Payables(ctx context.Context, req *models.PayablesRequest) (*models.PayablesResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var resp models.PayablesResponse
+
+	for _, s := range r.s.db.suppliers {
+		received, paid, ok := r.s.db.supplierBalance(s.Id, req.BranchID)
+		if !ok {
+			continue
+		}
+		resp.Add(&models.Payable{
+			SupplierID: s.Id,
+			Name:       s.Name,
+			TIN:        s.TIN,
+			Phone:      s.Phone,
+			Received:   received,
+			Paid:       paid,
+		})
+	}
+
+	sort.SliceStable(resp.Suppliers, func(i, j int) bool {
+		if resp.Suppliers[i].Balance != resp.Suppliers[j].Balance {
+			return resp.Suppliers[i].Balance > resp.Suppliers[j].Balance
+		}
+		return resp.Suppliers[i].Name < resp.Suppliers[j].Name
+	})
+
+	return &resp, nil
+}
+
+func supplierRow(s models.Supplier) row {
+	return row{
+		"name":       s.Name,
+		"tin":        s.TIN,
+		"phone":      s.Phone,
+		"address":    s.Address,
+		"created_at": s.CreatedAt,
+	}
+}
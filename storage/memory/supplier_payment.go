@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type supplierPaymentRepo struct {
+	s *Store
+}
+
+func (r *supplierPaymentRepo) Create(ctx context.Context, req *models.CreateSupplierPayment) (*models.SupplierPayment, error) {
+
+	r.s.mu.Lock()
+
+	db := r.s.db
+
+	if indexOf(db.suppliers, func(s models.Supplier) bool { return s.Id == req.SupplierID }) < 0 {
+		r.s.mu.Unlock()
+		return nil, pgx.ErrNoRows
+	}
+
+	received, paid, _ := db.supplierBalance(req.SupplierID, req.BranchID)
+	if req.Amount > received-paid {
+		r.s.mu.Unlock()
+		return nil, storage.ErrOverpaySupplier
+	}
+
+	payment := models.SupplierPayment{
+		Id:         uuid.New().String(),
+		SupplierID: req.SupplierID,
+		BranchID:   req.BranchID,
+		Amount:     req.Amount,
+		Method:     req.Method,
+		Comment:    req.Comment,
+		UserID:     req.UserID,
+		CreatedAt:  now(),
+	}
+
+	db.supplierPayments = append(db.supplierPayments, payment)
+
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.SupplierPaymentPrimaryKey{Id: payment.Id})
+}
+
+func (r *supplierPaymentRepo) GetByID(ctx context.Context, req *models.SupplierPaymentPrimaryKey) (*models.SupplierPayment, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.supplierPayments, func(p models.SupplierPayment) bool { return p.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	payment := r.s.db.supplierPayments[i]
+	return &payment, nil
+}
+
+func (r *supplierPaymentRepo) GetList(ctx context.Context, req *models.GetListSupplierPaymentRequest) (*models.GetListSupplierPaymentResponse, error) {
+
+	if err := req.Filter.Validate(models.SupplierPaymentFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListSupplierPaymentResponse
+		found = newest(r.s.db.supplierPayments, func(p models.SupplierPayment) bool {
+			return match(models.SupplierPaymentFilterSpec, req.Search, req.Filter, supplierPaymentRow(p))
+		})
+	)
+
+	for _, p := range page(found, req.Offset, req.Limit) {
+		payment := p
+		resp.Count = len(found)
+		resp.SupplierPayments = append(resp.SupplierPayments, &payment)
+	}
+
+	return &resp, nil
+}
+
+func supplierPaymentRow(p models.SupplierPayment) row {
+	return row{
+		"supplier_id": p.SupplierID,
+		"branch_id":   p.BranchID,
+		"method":      p.Method,
+		"user_id":     p.UserID,
+		"amount":      p.Amount,
+		"comment":     p.Comment,
+		"created_at":  p.CreatedAt,
+	}
+}
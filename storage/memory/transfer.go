@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type transferRepo struct {
+	s *Store
+}
+
+func (r *transferRepo) Create(ctx context.Context, req *models.CreateTransfer) (*models.Transfer, error) {
+
+	transfer := models.Transfer{
+		Id:           uuid.New().String(),
+		IncrementID:  req.IncrementID,
+		FromBranchID: req.FromBranchID,
+		ToBranchID:   req.ToBranchID,
+		Status:       models.TransferDraft,
+		UserID:       req.UserID,
+		CreatedAt:    now(),
+		UpdatedAt:    now(),
+	}
+
+	r.s.mu.Lock()
+	r.s.db.transfers = append(r.s.db.transfers, transfer)
+	r.s.db.addTransferLines(transfer.Id, req.Lines)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.TransferPrimaryKey{Id: transfer.Id})
+}
+
+func (d *database) addTransferLines(transferId string, lines []*models.CreateTransferLine) {
+	for _, line := range lines {
+		d.transferLines = append(d.transferLines, models.TransferLine{
+			Id:         uuid.New().String(),
+			TransferID: transferId,
+			ProductID:  line.ProductID,
+			Quantity:   line.Quantity,
+			CreatedAt:  now(),
+		})
+	}
+}
+
+func (r *transferRepo) GetByID(ctx context.Context, req *models.TransferPrimaryKey) (*models.Transfer, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.transfers, func(t models.Transfer) bool { return t.Id == req.Id })
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	transfer := r.s.db.transfers[i]
+	for _, l := range r.s.db.transferLines {
+		if l.TransferID == transfer.Id {
+			line := l
+			if line.ReceivedQuantity != nil {
+				line.Shortage = line.Quantity - *line.ReceivedQuantity
+			}
+			transfer.Lines = append(transfer.Lines, &line)
+		}
+	}
+
+	return &transfer, nil
+}
+
+func (r *transferRepo) GetList(ctx context.Context, req *models.GetListTransferRequest) (*models.GetListTransferResponse, error) {
+
+	if err := req.Filter.Validate(models.TransferFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListTransferResponse
+		found = newest(r.s.db.transfers, func(t models.Transfer) bool {
+			if len(req.BranchID) > 0 && t.FromBranchID != req.BranchID && t.ToBranchID != req.BranchID {
+				return false
+			}
+			return match(models.TransferFilterSpec, req.Search, req.Filter, transferRow(t))
+		})
+	)
+
+	for _, t := range page(found, req.Offset, req.Limit) {
+		transfer := t
+		resp.Count = len(found)
+		resp.Transfers = append(resp.Transfers, &transfer)
+	}
+
+	return &resp, nil
+}
+
+func (r *transferRepo) Update(ctx context.Context, req *models.UpdateTransfer) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.transfers, func(t models.Transfer) bool {
+		return t.Id == req.Id && t.Status == models.TransferDraft
+	})
+	if i < 0 {
+		return 0, nil
+	}
+
+	transfer := &r.s.db.transfers[i]
+	transfer.ToBranchID = req.ToBranchID
+	transfer.UpdatedAt = now()
+
+	if req.Lines != nil {
+		r.s.db.transferLines = remove(r.s.db.transferLines, func(l models.TransferLine) bool { return l.TransferID == req.Id })
+		r.s.db.addTransferLines(req.Id, req.Lines)
+	}
+
+	return 1, nil
+}
+
+func (r *transferRepo) Delete(ctx context.Context, req *models.TransferPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.transfers = remove(r.s.db.transfers, func(t models.Transfer) bool { return t.Id == req.Id })
+	r.s.db.transferLines = remove(r.s.db.transferLines, func(l models.TransferLine) bool { return l.TransferID == req.Id })
+
+	return nil
+}
+
+func (r *transferRepo) SetStatus(ctx context.Context, req *models.TransferStatus) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.transfers, func(t models.Transfer) bool { return t.Id == req.Id && t.Status == req.From })
+	if i < 0 {
+		return 0, nil
+	}
+
+	transfer := &r.s.db.transfers[i]
+	transfer.Status = req.To
+	transfer.UpdatedAt = now()
+
+	switch req.To {
+	case models.TransferSent:
+		transfer.SentAt = now()
+	case models.TransferReceived:
+		transfer.ReceivedAt = now()
+	}
+
+	return 1, nil
+}
+
+func (r *transferRepo) UpdateLine(ctx context.Context, req *models.UpdateTransferLine) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.transferLines, func(l models.TransferLine) bool { return l.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	line := &r.s.db.transferLines[i]
+	line.ComingPrice = req.ComingPrice
+	line.ReceivedQuantity = nil
+	if req.ReceivedQuantity != nil {
+		received := *req.ReceivedQuantity
+		line.ReceivedQuantity = &received
+	}
+
+	return 1, nil
+}
+
+func (r *transferRepo) InTransit(ctx context.Context, req *models.InTransitRequest) (*models.InTransitResponse, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var resp models.InTransitResponse
+
+	for _, t := range r.s.db.transfers {
+		if t.Status != models.TransferSent {
+			continue
+		}
+
+		if len(req.BranchID) > 0 && t.FromBranchID != req.BranchID && t.ToBranchID != req.BranchID {
+			continue
+		}
+
+		for _, l := range r.s.db.transferLines {
+			if l.TransferID != t.Id {
+				continue
+			}
+
+			resp.Lines = append(resp.Lines, &models.InTransit{
+				TransferID:   t.Id,
+				IncrementID:  t.IncrementID,
+				FromBranchID: t.FromBranchID,
+				ToBranchID:   t.ToBranchID,
+				ProductID:    l.ProductID,
+				Quantity:     l.Quantity,
+				ComingPrice:  l.ComingPrice,
+				SentAt:       t.SentAt,
+			})
+		}
+	}
+
+	sort.SliceStable(resp.Lines, func(i, j int) bool {
+		sentI, _ := time.Parse(time.RFC3339Nano, resp.Lines[i].SentAt)
+		sentJ, _ := time.Parse(time.RFC3339Nano, resp.Lines[j].SentAt)
+		return sentI.Before(sentJ)
+	})
+
+	return &resp, nil
+}
+
+func transferRow(t models.Transfer) row {
+	return row{
+		"from_branch_id": t.FromBranchID,
+		"to_branch_id":   t.ToBranchID,
+		"status":         t.Status,
+		"increment_id":   t.IncrementID,
+		"user_id":        t.UserID,
+		"created_at":     t.CreatedAt,
+	}
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+// errLoginTaken is what postgres reports for a second user with the same login.
+func errLoginTaken(login string) error {
+	return &pgconn.PgError{
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "user_login_idx"`,
+		Detail:         fmt.Sprintf("Key (login)=(%s) already exists.", login),
+		TableName:      "user",
+		ConstraintName: "user_login_idx",
+	}
+}
+
+func (r *userRepo) Create(ctx context.Context, req *models.CreateUser) (*models.User, error) {
+
+	user := models.User{
+		Id:         uuid.New().String(),
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Login:      req.Login,
+		Password:   req.Password,
+		ClientType: req.ClientType,
+		BranchID:   req.BranchID,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+
+	r.s.mu.Lock()
+	if indexOf(r.s.db.users, func(u models.User) bool { return u.Login == req.Login }) >= 0 {
+		r.s.mu.Unlock()
+		return nil, errLoginTaken(req.Login)
+	}
+	r.s.db.users = append(r.s.db.users, user)
+	r.s.mu.Unlock()
+
+	return r.GetByID(ctx, &models.UserPrimaryKey{Id: user.Id})
+}
+
+func (r *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*models.User, error) {
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	i := indexOf(r.s.db.users, func(u models.User) bool {
+		if len(req.Id) == 0 {
+			return u.Login == req.Login
+		}
+		return u.Id == req.Id
+	})
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	user := r.s.db.users[i]
+	return &user, nil
+}
+
+func (r *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest) (*models.GetListUserResponse, error) {
+
+	if err := req.Filter.Validate(models.UserFilterSpec); err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		resp  models.GetListUserResponse
+		found = newest(r.s.db.users, func(u models.User) bool {
+			return match(models.UserFilterSpec, req.Search, req.Filter, userRow(u))
+		})
+	)
+
+	for _, u := range page(found, req.Offset, req.Limit) {
+		user := u
+		user.Password = ""
+		resp.Count = len(found)
+		resp.Users = append(resp.Users, &user)
+	}
+
+	return &resp, nil
+}
+
+func (r *userRepo) Update(ctx context.Context, req *models.UpdateUser) (int64, error) {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := indexOf(r.s.db.users, func(u models.User) bool { return u.Id == req.Id })
+	if i < 0 {
+		return 0, nil
+	}
+
+	if indexOf(r.s.db.users, func(u models.User) bool { return u.Login == req.Login && u.Id != req.Id }) >= 0 {
+		return 0, errLoginTaken(req.Login)
+	}
+
+	user := &r.s.db.users[i]
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.Login = req.Login
+	if len(req.Password) > 0 {
+		user.Password = req.Password
+	}
+	user.ClientType = req.ClientType
+	user.BranchID = req.BranchID
+	user.UpdatedAt = now()
+
+	return 1, nil
+}
+
+func (r *userRepo) Delete(ctx context.Context, req *models.UserPrimaryKey) error {
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.db.users = remove(r.s.db.users, func(u models.User) bool { return u.Id == req.Id })
+
+	return nil
+}
+
+func userRow(u models.User) row {
+	return row{
+		"client_type": u.ClientType,
+		"branch_id":   u.BranchID,
+		"login":       u.Login,
+		"first_name":  u.FirstName,
+		"last_name":   u.LastName,
+		"created_at":  u.CreatedAt,
+	}
+}
@@ -7,14 +7,13 @@ import (
 	"market_system/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type branchRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewBranchRepo(db *pgxpool.Pool) *branchRepo {
+func NewBranchRepo(db DB) *branchRepo {
 	return &branchRepo{
 		db: db,
 	}
@@ -58,8 +57,8 @@ func (r *branchRepo) GetByID(ctx context.Context, req *models.BranchPrimaryKey)
 					"name",
 					"address",
 					"phone",
-					"updated_at",
-					"created_at"	
+					"created_at",
+					"updated_at"
 			FROM "branch"
 			WHERE "id" = $1
 		`
@@ -99,12 +98,16 @@ func (r *branchRepo) GetByID(ctx context.Context, req *models.BranchPrimaryKey)
 func (r *branchRepo) GetList(ctx context.Context, req *models.GetListBranchRequest) (*models.GetListBranchResponse, error) {
 	var (
 		resp   models.GetListBranchResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	where, args, err := whereClause("branch", models.BranchFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -113,10 +116,6 @@ func (r *branchRepo) GetList(ctx context.Context, req *models.GetListBranchReque
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND title ILIKE" + " '%" + req.Search + "%'" + "OR phone ILIKE " + "'%" + req.Search + "%'"
-	}
-
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
@@ -124,13 +123,13 @@ func (r *branchRepo) GetList(ctx context.Context, req *models.GetListBranchReque
 			"name",
 			"address",
 			"phone",
-			"updated_at",
-			"created_at"
+			"created_at",
+			"updated_at"
 		FROM "branch"
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
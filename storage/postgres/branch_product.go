@@ -0,0 +1,311 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+)
+
+type branchProductRepo struct {
+	db DB
+}
+
+func NewBranchProductRepo(db DB) *branchProductRepo {
+	return &branchProductRepo{
+		db: db,
+	}
+}
+
+// branchProductColumns are read by scanBranchProduct, from branch_product
+// joined with its product.
+const branchProductColumns = `
+	"branch_product"."branch_id",
+	"branch_product"."product_id",
+	"product"."name",
+	"product"."price",
+	"branch_product"."price",
+	COALESCE("branch_product"."price", "product"."price", 0),
+	"branch_product"."created_at",
+	"branch_product"."updated_at"`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBranchProduct(row scanner, dest ...interface{}) (*models.BranchProduct, error) {
+
+	var (
+		BranchID     sql.NullString
+		ProductID    sql.NullString
+		Name         sql.NullString
+		CatalogPrice sql.NullFloat64
+		Price        sql.NullFloat64
+		SalePrice    sql.NullFloat64
+		CreatedAt    sql.NullString
+		UpdatedAt    sql.NullString
+	)
+
+	err := row.Scan(append(dest,
+		&BranchID,
+		&ProductID,
+		&Name,
+		&CatalogPrice,
+		&Price,
+		&SalePrice,
+		&CreatedAt,
+		&UpdatedAt,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.BranchProduct{
+		BranchID:     BranchID.String,
+		ProductID:    ProductID.String,
+		Name:         Name.String,
+		CatalogPrice: CatalogPrice.Float64,
+		SalePrice:    SalePrice.Float64,
+		CreatedAt:    CreatedAt.String,
+		UpdatedAt:    UpdatedAt.String,
+	}
+	if Price.Valid {
+		resp.Price = &Price.Float64
+	}
+
+	return resp, nil
+}
+
+func (r *branchProductRepo) Set(ctx context.Context, req *models.SetBranchProduct) (*models.BranchProduct, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "branch_product"(
+			"branch_id",
+			"product_id",
+			"price",
+			"updated_at"
+		) VALUES ($1, $2, $3, NOW())
+		ON CONFLICT ("branch_id", "product_id") DO UPDATE
+			SET
+				"price" = EXCLUDED."price",
+				"updated_at" = NOW()`,
+		req.BranchID,
+		req.ProductID,
+		req.Price,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = recordPrice(ctx, tx, req.ProductID, req.BranchID, req.Price); err != nil {
+		return nil, err
+	}
+
+	if err = repriceStock(ctx, tx, req.BranchID, []string{req.ProductID}); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: req.BranchID, ProductID: req.ProductID})
+}
+
+func (r *branchProductRepo) GetByID(ctx context.Context, req *models.BranchProductPrimaryKey) (*models.BranchProduct, error) {
+
+	return scanBranchProduct(r.db.QueryRow(ctx, `
+		SELECT`+branchProductColumns+`
+		FROM "branch_product"
+		JOIN "product" ON "product"."id" = "branch_product"."product_id"
+		WHERE "branch_product"."branch_id" = $1 AND "branch_product"."product_id" = $2`,
+		req.BranchID,
+		req.ProductID,
+	))
+}
+
+func (r *branchProductRepo) GetList(ctx context.Context, req *models.GetListBranchProductRequest) (*models.GetListBranchProductResponse, error) {
+	var (
+		resp   models.GetListBranchProductResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = ` ORDER BY "product"."name", "branch_product"."branch_id"`
+	)
+
+	where, args, err := whereClause("branch_product", models.BranchProductFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),` + branchProductColumns + `
+		FROM "branch_product"
+		JOIN "product" ON "product"."id" = "branch_product"."product_id"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		branchProduct, err := scanBranchProduct(rows, &resp.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.BranchProducts = append(resp.BranchProducts, branchProduct)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Delete drops the product from the assortment of the branch, the stock it
+// still holds goes back to the catalog price.
+func (r *branchProductRepo) Delete(ctx context.Context, req *models.BranchProductPrimaryKey) error {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM "branch_product" WHERE "branch_id" = $1 AND "product_id" = $2`,
+		req.BranchID,
+		req.ProductID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = repriceStock(ctx, tx, req.BranchID, []string{req.ProductID}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SalePrice is also answered for a product the branch no longer carries,
+// for the stock it still holds, at the catalog price.
+func (r *branchProductRepo) SalePrice(ctx context.Context, req *models.BranchProductPrimaryKey) (float64, error) {
+
+	var price sql.NullFloat64
+
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE("branch_product"."price", "product"."price", 0)
+		FROM "product"
+		LEFT JOIN "branch_product" ON "branch_product"."product_id" = "product"."id" AND "branch_product"."branch_id" = $1
+		WHERE "product"."id" = $2`,
+		req.BranchID,
+		req.ProductID,
+	).Scan(&price)
+	if err != nil {
+		return 0, err
+	}
+
+	return price.Float64, nil
+}
+
+func (r *branchProductRepo) Copy(ctx context.Context, req *models.CopyCatalog) (*models.CopyCatalogResponse, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the overrides the target gets are changes of its prices
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "price_history"(
+			"id",
+			"product_id",
+			"branch_id",
+			"price",
+			"effective_from",
+			"applied_at"
+		)
+		SELECT gen_random_uuid(), "from"."product_id", $2, "from"."price", NOW(), NOW()
+		FROM "branch_product" AS "from"
+		LEFT JOIN "branch_product" AS "to" ON "to"."branch_id" = $2 AND "to"."product_id" = "from"."product_id"
+		WHERE "from"."branch_id" = $1 AND "from"."price" IS DISTINCT FROM "to"."price"`,
+		req.FromBranchID,
+		req.ToBranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO "branch_product"(
+			"branch_id",
+			"product_id",
+			"price",
+			"updated_at"
+		)
+		SELECT $2, "product_id", "price", NOW()
+		FROM "branch_product"
+		WHERE "branch_id" = $1
+		ON CONFLICT ("branch_id", "product_id") DO UPDATE
+			SET
+				"price" = EXCLUDED."price",
+				"updated_at" = NOW()
+		RETURNING "product_id"::TEXT`,
+		req.FromBranchID,
+		req.ToBranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var productIds []string
+	for rows.Next() {
+		var productId string
+		if err = rows.Scan(&productId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		productIds = append(productIds, productId)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = repriceStock(ctx, tx, req.ToBranchID, productIds); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &models.CopyCatalogResponse{
+		FromBranchID: req.FromBranchID,
+		ToBranchID:   req.ToBranchID,
+		Copied:       len(productIds),
+	}, nil
+}
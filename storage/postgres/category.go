@@ -0,0 +1,491 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type categoryRepo struct {
+	db DB
+}
+
+func NewCategoryRepo(db DB) *categoryRepo {
+	return &categoryRepo{
+		db: db,
+	}
+}
+
+// categoryTree maps every descendant of the children of $1, the roots when
+// it is empty, to the child it is under.
+const categoryTree = `
+	WITH RECURSIVE "tree" AS (
+		SELECT "id", "id" AS "top" FROM "category"
+		WHERE "parent_id" IS NOT DISTINCT FROM NULLIF($1, '')::UUID
+		UNION ALL
+		SELECT "category"."id", "tree"."top" FROM "category"
+		JOIN "tree" ON "category"."parent_id" = "tree"."id"
+	)`
+
+func (r *categoryRepo) Create(ctx context.Context, req *models.CreateCategory) (*models.Category, error) {
+
+	var categoryId = uuid.New().String()
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "category"(
+			"id",
+			"name",
+			"parent_id",
+			"updated_at"
+		) VALUES ($1, $2, NULLIF($3, '')::UUID, NOW())`,
+		categoryId,
+		req.Name,
+		req.ParentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.CategoryPrimaryKey{Id: categoryId})
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, req *models.CategoryPrimaryKey) (*models.Category, error) {
+
+	var (
+		Id        sql.NullString
+		Name      sql.NullString
+		ParentID  sql.NullString
+		CreatedAt sql.NullString
+		UpdatedAt sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			"id",
+			"name",
+			"parent_id",
+			"created_at",
+			"updated_at"
+		FROM "category"
+		WHERE "id" = $1`,
+		req.Id,
+	).Scan(
+		&Id,
+		&Name,
+		&ParentID,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Category{
+		Id:        Id.String,
+		Name:      Name.String,
+		ParentID:  ParentID.String,
+		CreatedAt: CreatedAt.String,
+		UpdatedAt: UpdatedAt.String,
+	}, nil
+}
+
+func (r *categoryRepo) GetList(ctx context.Context, req *models.GetListCategoryRequest) (*models.GetListCategoryResponse, error) {
+	var (
+		resp   models.GetListCategoryResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("category", models.CategoryFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"name",
+			"parent_id",
+			"created_at",
+			"updated_at"
+		FROM "category"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id        sql.NullString
+			Name      sql.NullString
+			ParentID  sql.NullString
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&Name,
+			&ParentID,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Categories = append(resp.Categories, &models.Category{
+			Id:        Id.String,
+			Name:      Name.String,
+			ParentID:  ParentID.String,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Update renames the category and moves it under ParentID unless that is the
+// category or one of its descendants. The category and the ancestors of its
+// new parent are locked on the way up, so concurrent moves can not close a
+// loop between them.
+func (r *categoryRepo) Update(ctx context.Context, req *models.UpdateCategory) (int64, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var categoryId string
+	err = tx.QueryRow(ctx, `SELECT "id" FROM "category" WHERE "id" = $1 FOR UPDATE`, req.Id).Scan(&categoryId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for parentId, seen := req.ParentID, map[string]bool{}; len(parentId) > 0 && !seen[parentId]; {
+		if parentId == req.Id {
+			return 0, storage.ErrCategoryCycle
+		}
+		seen[parentId] = true
+
+		var ParentID sql.NullString
+		err = tx.QueryRow(ctx, `SELECT "parent_id" FROM "category" WHERE "id" = $1 FOR UPDATE`, parentId).Scan(&ParentID)
+		if err != nil {
+			return 0, err
+		}
+		parentId = ParentID.String
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE "category"
+			SET
+				"name" = $2,
+				"parent_id" = NULLIF($3, '')::UUID,
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.Id,
+		req.Name,
+		req.ParentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), tx.Commit(ctx)
+}
+
+func (r *categoryRepo) Delete(ctx context.Context, req *models.CategoryPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "category" WHERE "id" = $1`, req.Id)
+	return err
+}
+
+func (r *categoryRepo) GetTree(ctx context.Context) ([]*models.CategoryNode, error) {
+
+	rows, err := r.db.Query(ctx, `SELECT "id", "name", "parent_id", "created_at", "updated_at" FROM "category"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*models.Category
+	for rows.Next() {
+		var (
+			Id        sql.NullString
+			Name      sql.NullString
+			ParentID  sql.NullString
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		if err = rows.Scan(&Id, &Name, &ParentID, &CreatedAt, &UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, &models.Category{
+			Id:        Id.String,
+			Name:      Name.String,
+			ParentID:  ParentID.String,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return models.CategoryTree(categories), nil
+}
+
+func (r *categoryRepo) Subtree(ctx context.Context, req *models.CategoryPrimaryKey) ([]string, error) {
+
+	rows, err := r.db.Query(ctx, `
+		WITH RECURSIVE "tree" AS (
+			SELECT "id" FROM "category" WHERE "id" = $1
+			UNION ALL
+			SELECT "category"."id" FROM "category"
+			JOIN "tree" ON "category"."parent_id" = "tree"."id"
+		)
+		SELECT "id" FROM "tree"`,
+		req.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp []string
+	for rows.Next() {
+		var Id sql.NullString
+		if err = rows.Scan(&Id); err != nil {
+			return nil, err
+		}
+		resp = append(resp, Id.String)
+	}
+
+	return resp, rows.Err()
+}
+
+// reportRows lists the children of parentId and the parent itself, which
+// has no name at the root level.
+func (r *categoryRepo) reportRows(ctx context.Context, parentId string) ([]*models.Category, *models.Category, error) {
+
+	var parent = &models.Category{Id: parentId}
+	if len(parentId) > 0 {
+		var err error
+		if parent, err = r.GetByID(ctx, &models.CategoryPrimaryKey{Id: parentId}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT "id", "name" FROM "category"
+		WHERE "parent_id" IS NOT DISTINCT FROM NULLIF($1, '')::UUID
+		ORDER BY "name"`,
+		parentId,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var children []*models.Category
+	for rows.Next() {
+		var (
+			Id   sql.NullString
+			Name sql.NullString
+		)
+		if err = rows.Scan(&Id, &Name); err != nil {
+			return nil, nil, err
+		}
+		children = append(children, &models.Category{Id: Id.String, Name: Name.String})
+	}
+
+	return children, parent, rows.Err()
+}
+
+// Sales sums sale_product by the child of req.ParentID the product is
+// under, an empty group for products directly in the parent.
+func (r *categoryRepo) Sales(ctx context.Context, req *models.CategoryReportRequest) (*models.CategorySalesResponse, error) {
+
+	var (
+		resp  = models.CategorySalesResponse{ParentID: req.ParentID}
+		args  = []interface{}{req.ParentID, req.BranchID}
+		query = categoryTree + `
+			SELECT
+				COALESCE("tree"."top"::TEXT, ''),
+				SUM("sp"."quantity"),
+				SUM("sp"."total_price"),
+				SUM(COALESCE("returned"."quantity", 0)),
+				SUM(COALESCE("returned"."amount", 0))
+			FROM "sale_product" AS "sp"
+			JOIN "sale" ON "sale"."id" = "sp"."sale_id"
+			JOIN "product" ON "product"."id" = "sp"."product_id"
+			LEFT JOIN "tree" ON "tree"."id" = "product"."category_id"
+			LEFT JOIN (
+				SELECT "sale_product_id", SUM("quantity") AS "quantity", SUM("total_price") AS "amount"
+				FROM "sale_return_line"
+				GROUP BY "sale_product_id"
+			) AS "returned" ON "returned"."sale_product_id" = "sp"."id"
+			WHERE ("tree"."top" IS NOT NULL OR "product"."category_id" IS NOT DISTINCT FROM NULLIF($1, '')::UUID)
+			AND ($2 = '' OR "sale"."branch_id"::TEXT = $2)
+		`
+	)
+
+	if req.From != nil {
+		args = append(args, *req.From)
+		query += fmt.Sprintf(` AND "sp"."created_at" >= $%d`, len(args))
+	}
+
+	if req.To != nil {
+		args = append(args, *req.To)
+		query += fmt.Sprintf(` AND "sp"."created_at" < $%d`, len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query+` GROUP BY 1`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups = map[string]*models.CategorySales{}
+	for rows.Next() {
+		var (
+			Top              sql.NullString
+			Quantity         sql.NullInt64
+			Amount           sql.NullFloat64
+			ReturnedQuantity sql.NullInt64
+			ReturnedAmount   sql.NullFloat64
+		)
+		if err = rows.Scan(&Top, &Quantity, &Amount, &ReturnedQuantity, &ReturnedAmount); err != nil {
+			return nil, err
+		}
+		groups[Top.String] = &models.CategorySales{
+			Quantity:         int(Quantity.Int64),
+			Amount:           Amount.Float64,
+			ReturnedQuantity: int(ReturnedQuantity.Int64),
+			ReturnedAmount:   ReturnedAmount.Float64,
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	children, parent, err := r.reportRows(ctx, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		row, ok := groups[child.Id]
+		if !ok {
+			row = &models.CategorySales{}
+		}
+		row.CategoryID, row.Name = child.Id, child.Name
+		resp.Add(row)
+	}
+
+	if row, ok := groups[""]; ok {
+		row.CategoryID, row.Name = parent.Id, parent.Name
+		resp.Add(row)
+	}
+
+	return &resp, nil
+}
+
+// Stock sums the lots in remainder the same way Sales sums sale_product.
+func (r *categoryRepo) Stock(ctx context.Context, req *models.CategoryReportRequest) (*models.CategoryStockResponse, error) {
+
+	rows, err := r.db.Query(ctx, categoryTree+`
+		SELECT
+			COALESCE("tree"."top"::TEXT, ''),
+			SUM("remainder"."quantity"),
+			SUM("remainder"."quantity" * "remainder"."coming_price"),
+			SUM("remainder"."quantity" * "remainder"."sale_price")
+		FROM "remainder"
+		JOIN "product" ON "product"."id" = "remainder"."product_id"
+		LEFT JOIN "tree" ON "tree"."id" = "product"."category_id"
+		WHERE ("tree"."top" IS NOT NULL OR "product"."category_id" IS NOT DISTINCT FROM NULLIF($1, '')::UUID)
+		AND ($2 = '' OR "remainder"."branch_id"::TEXT = $2)
+		GROUP BY 1`,
+		req.ParentID,
+		req.BranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups = map[string]*models.CategoryStock{}
+	for rows.Next() {
+		var (
+			Top      sql.NullString
+			Quantity sql.NullInt64
+			Cost     sql.NullFloat64
+			Value    sql.NullFloat64
+		)
+		if err = rows.Scan(&Top, &Quantity, &Cost, &Value); err != nil {
+			return nil, err
+		}
+		groups[Top.String] = &models.CategoryStock{Quantity: int(Quantity.Int64), Cost: Cost.Float64, Value: Value.Float64}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	children, parent, err := r.reportRows(ctx, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp = models.CategoryStockResponse{ParentID: req.ParentID}
+	for _, child := range children {
+		row, ok := groups[child.Id]
+		if !ok {
+			row = &models.CategoryStock{}
+		}
+		row.CategoryID, row.Name = child.Id, child.Name
+		resp.Add(row)
+	}
+
+	if row, ok := groups[""]; ok {
+		row.CategoryID, row.Name = parent.Id, parent.Name
+		resp.Add(row)
+	}
+
+	return &resp, nil
+}
@@ -8,14 +8,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type clientRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewClientRepo(db *pgxpool.Pool) *clientRepo {
+func NewClientRepo(db DB) *clientRepo {
 	return &clientRepo{
 		db: db,
 	}
@@ -36,8 +35,9 @@ func (r *clientRepo) Create(ctx context.Context, req *models.CreateClient) (*mod
 				"gender",
 				"branch_id",
 				"active",
+				"group",
 				"updated_at"
-			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9, NOW())`
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10, NOW())`
 		birthday, err = time.Parse("2006-01-02", req.Birthday)
 	)
 	if err != nil {
@@ -55,6 +55,7 @@ func (r *clientRepo) Create(ctx context.Context, req *models.CreateClient) (*mod
 		req.Gender,
 		req.BranchID,
 		req.Active,
+		req.Group,
 	)
 	if err != nil {
 		fmt.Println(
@@ -88,6 +89,7 @@ func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey)
 				"gender",
 				"branch_id",
 				"active",
+				"group",
 				"created_at",
 				"updated_at"
 			FROM "client"
@@ -105,6 +107,7 @@ func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey)
 		Gender     sql.NullString
 		BranchID   sql.NullString
 		Active     sql.NullString
+		Group      sql.NullString
 		CreatedAt  sql.NullString
 		UpdatedAt  sql.NullString
 	)
@@ -119,6 +122,7 @@ func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey)
 		&Gender,
 		&BranchID,
 		&Active,
+		&Group,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -136,6 +140,7 @@ func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey)
 		Gender:     Gender.String,
 		BranchID:   BranchID.String,
 		Active:     Active.String,
+		Group:      Group.String,
 		CreatedAt:  CreatedAt.String,
 		UpdatedAt:  UpdatedAt.String,
 	}, nil
@@ -144,12 +149,16 @@ func (r *clientRepo) GetByID(ctx context.Context, req *models.ClientPrimaryKey)
 func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientRequest) (*models.GetListClientResponse, error) {
 	var (
 		resp   models.GetListClientResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	where, args, err := whereClause("client", models.ClientFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -158,10 +167,6 @@ func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientReque
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND client.first_name ILIKE " + "'%" + req.Search + "%'" + "OR client.last_name ILIKE " + "'%" + req.Search + "%' " + "OR client.father_name ILIKE " + "'%" + req.Search + "%'" + "OR client.phone ILIKE " + "'%" + req.Search + "%'" + "OR branch.name ILIKE " + "'%" + req.Search + "%'"
-	}
-	fmt.Println(where)
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
@@ -174,14 +179,15 @@ func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientReque
 			"gender",
 			"branch_id",
 			"active",
-			"updated_at",
-			"created_at"
+			"group",
+			"created_at",
+			"updated_at"
 		FROM "client"
 	`
 
 	query += where + sort + offset + limit
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -197,6 +203,7 @@ func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientReque
 			Gender     sql.NullString
 			BranchID   sql.NullString
 			Active     sql.NullString
+			Group      sql.NullString
 			CreatedAt  sql.NullString
 			UpdatedAt  sql.NullString
 		)
@@ -212,6 +219,7 @@ func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientReque
 			&Gender,
 			&BranchID,
 			&Active,
+			&Group,
 			&CreatedAt,
 			&UpdatedAt,
 		)
@@ -229,6 +237,7 @@ func (r *clientRepo) GetList(ctx context.Context, req *models.GetListClientReque
 			Gender:     Gender.String,
 			BranchID:   BranchID.String,
 			Active:     Active.String,
+			Group:      Group.String,
 			CreatedAt:  CreatedAt.String,
 			UpdatedAt:  UpdatedAt.String,
 		})
@@ -248,6 +257,7 @@ func (r *clientRepo) Update(ctx context.Context, req *models.UpdateClient) (int6
 				birthday = $6,
 				gender = $7,
 				branch_id = $8,
+				"group" = $9,
 				updated_at = NOW()
 		WHERE id = $1
 	`
@@ -262,6 +272,7 @@ func (r *clientRepo) Update(ctx context.Context, req *models.UpdateClient) (int6
 		req.Birthday,
 		req.Gender,
 		req.BranchID,
+		req.Group,
 	)
 
 	if err != nil {
@@ -279,3 +290,136 @@ func (r *clientRepo) Delete(ctx context.Context, req *models.ClientPrimaryKey) e
 	_, err := r.db.Exec(ctx, "DELETE FROM client WHERE id = $1", req.Id)
 	return err
 }
+
+// Statement lists sales, returns and payments of the client in [From, To) with a
+// running balance, starting from the balance of everything before From.
+func (r *clientRepo) Statement(ctx context.Context, req *models.ClientStatementRequest) (*models.ClientStatement, error) {
+
+	var resp = models.ClientStatement{ClientID: req.ClientID}
+
+	if req.From != nil {
+		err := r.db.QueryRow(ctx, `
+			SELECT
+				COALESCE((SELECT SUM("total_price") FROM "sale" WHERE "client_id" = $1 AND "created_at" < $2), 0) -
+				COALESCE((SELECT SUM(sr."total_price") FROM "sale_return" AS sr WHERE sr."client_id" = $1 AND sr."created_at" < $2), 0) -
+				COALESCE((
+					SELECT SUM(p."amount") FROM "payment" AS p
+					JOIN "sale" AS s ON s."id" = p."sale_id"
+					WHERE s."client_id" = $1 AND p."created_at" < $2
+				), 0)`,
+			req.ClientID,
+			*req.From,
+		).Scan(&resp.OpeningBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.ClosingBalance = resp.OpeningBalance
+
+	var (
+		args  = []interface{}{req.ClientID}
+		query = `
+			SELECT
+				"type",
+				"id",
+				"sale_id",
+				"increment_id",
+				"debit",
+				"credit",
+				"created_at"
+			FROM (
+				SELECT
+					'sale' AS "type",
+					"id",
+					"id" AS "sale_id",
+					"increment_id",
+					COALESCE("total_price", 0) AS "debit",
+					0 AS "credit",
+					"created_at"
+				FROM "sale"
+				WHERE "client_id" = $1
+				UNION ALL
+				SELECT
+					'payment',
+					p."id",
+					p."sale_id",
+					s."increment_id",
+					0,
+					p."amount",
+					p."created_at"
+				FROM "payment" AS p
+				JOIN "sale" AS s ON s."id" = p."sale_id"
+				WHERE s."client_id" = $1
+				UNION ALL
+				SELECT
+					'return',
+					sr."id",
+					sr."sale_id",
+					sr."increment_id",
+					0,
+					sr."total_price",
+					sr."created_at"
+				FROM "sale_return" AS sr
+				WHERE sr."client_id" = $1
+			) AS "entry"
+			WHERE TRUE
+		`
+	)
+
+	if req.From != nil {
+		args = append(args, *req.From)
+		query += fmt.Sprintf(` AND "created_at" >= $%d`, len(args))
+	}
+
+	if req.To != nil {
+		args = append(args, *req.To)
+		query += fmt.Sprintf(` AND "created_at" < $%d`, len(args))
+	}
+
+	// a sale comes before the returns and payments made at the same moment,
+	// a return before the refund it issued
+	query += ` ORDER BY "created_at", "type" DESC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Type        sql.NullString
+			Id          sql.NullString
+			SaleID      sql.NullString
+			IncrementID sql.NullString
+			Debit       sql.NullFloat64
+			Credit      sql.NullFloat64
+			CreatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&Type,
+			&Id,
+			&SaleID,
+			&IncrementID,
+			&Debit,
+			&Credit,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.AddEntry(&models.ClientStatementEntry{
+			Type:        Type.String,
+			Id:          Id.String,
+			SaleID:      SaleID.String,
+			IncrementID: IncrementID.String,
+			Debit:       Debit.Float64,
+			Credit:      Credit.Float64,
+			CreatedAt:   CreatedAt.String,
+		})
+	}
+
+	return &resp, rows.Err()
+}
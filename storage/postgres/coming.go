@@ -8,14 +8,13 @@ import (
 	"market_system/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type ComingRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewComingRepo(db *pgxpool.Pool) *ComingRepo {
+func NewComingRepo(db DB) *ComingRepo {
 	return &ComingRepo{
 		db: db,
 	}
@@ -30,8 +29,10 @@ func (r *ComingRepo) Create(ctx context.Context, req *models.CreateComing) (*mod
 				"id",
 				"increment_id",
 				"branch_id",
+				"supplier_id",
+				"purchase_order_id",
 				"updated_at"
-			) VALUES ($1, $2, $3, NOW())`
+			) VALUES ($1, $2, $3, NULLIF($4, '')::UUID, NULLIF($5, '')::UUID, NOW())`
 	)
 
 	_, err := r.db.Exec(ctx,
@@ -39,6 +40,8 @@ func (r *ComingRepo) Create(ctx context.Context, req *models.CreateComing) (*mod
 		comingId,
 		req.IncrementID,
 		req.BranchID,
+		req.SupplierID,
+		req.PurchaseOrderID,
 	)
 
 	if err != nil {
@@ -56,6 +59,10 @@ func (r *ComingRepo) GetByID(ctx context.Context, req *models.ComingPrimaryKey)
 				 "id",
 				 "increment_id",
 				 "branch_id",
+				 "supplier_id",
+				 "purchase_order_id",
+				 (SELECT SUM("total_price") FROM "picking_list" WHERE "coming_id" = "coming"."id"),
+				 "status",
 				 "created_at",
 				 "updated_at"
 			FROM "coming"
@@ -64,17 +71,25 @@ func (r *ComingRepo) GetByID(ctx context.Context, req *models.ComingPrimaryKey)
 	)
 
 	var (
-		Id          sql.NullString
-		IncrementID sql.NullString
-		BranchID    sql.NullString
-		CreatedAt   sql.NullString
-		UpdatedAt   sql.NullString
+		Id              sql.NullString
+		IncrementID     sql.NullString
+		BranchID        sql.NullString
+		SupplierID      sql.NullString
+		PurchaseOrderID sql.NullString
+		TotalPrice      sql.NullFloat64
+		Status          sql.NullString
+		CreatedAt       sql.NullString
+		UpdatedAt       sql.NullString
 	)
 
 	err := r.db.QueryRow(ctx, query, req.Id).Scan(
 		&Id,
-		&BranchID,
 		&IncrementID,
+		&BranchID,
+		&SupplierID,
+		&PurchaseOrderID,
+		&TotalPrice,
+		&Status,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -84,23 +99,31 @@ func (r *ComingRepo) GetByID(ctx context.Context, req *models.ComingPrimaryKey)
 	}
 
 	return &models.Coming{
-		Id:          Id.String,
-		IncrementID: IncrementID.String,
-		BranchID:    BranchID.String,
-		CreatedAt:   CreatedAt.String,
-		UpdatedAt:   UpdatedAt.String,
+		Id:              Id.String,
+		IncrementID:     IncrementID.String,
+		BranchID:        BranchID.String,
+		SupplierID:      SupplierID.String,
+		PurchaseOrderID: PurchaseOrderID.String,
+		TotalPrice:      TotalPrice.Float64,
+		Status:          Status.String,
+		CreatedAt:       CreatedAt.String,
+		UpdatedAt:       UpdatedAt.String,
 	}, nil
 }
 
 func (r *ComingRepo) GetList(ctx context.Context, req *models.GetListComingRequest) (*models.GetListComingResponse, error) {
 	var (
 		resp   models.GetListComingResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	where, args, err := whereClause("coming", models.ComingFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -109,35 +132,38 @@ func (r *ComingRepo) GetList(ctx context.Context, req *models.GetListComingReque
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-
-	if len(req.Query) > 0 {
-		where += req.Query
-	}
-
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
 			"id",
 			"increment_id",
 			"branch_id",
+			"supplier_id",
+			"purchase_order_id",
+			(SELECT SUM("total_price") FROM "picking_list" WHERE "coming_id" = "coming"."id"),
+			"status",
 			"created_at",
 			"updated_at"
 		FROM "coming"
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	for rows.Next() {
 		var (
-			Id          sql.NullString
-			IncrementID sql.NullString
-			BranchID    sql.NullString
-			CreatedAt   sql.NullString
-			UpdatedAt   sql.NullString
+			Id              sql.NullString
+			IncrementID     sql.NullString
+			BranchID        sql.NullString
+			SupplierID      sql.NullString
+			PurchaseOrderID sql.NullString
+			TotalPrice      sql.NullFloat64
+			Status          sql.NullString
+			CreatedAt       sql.NullString
+			UpdatedAt       sql.NullString
 		)
 
 		err = rows.Scan(
@@ -145,6 +171,10 @@ func (r *ComingRepo) GetList(ctx context.Context, req *models.GetListComingReque
 			&Id,
 			&IncrementID,
 			&BranchID,
+			&SupplierID,
+			&PurchaseOrderID,
+			&TotalPrice,
+			&Status,
 			&CreatedAt,
 			&UpdatedAt,
 		)
@@ -152,11 +182,15 @@ func (r *ComingRepo) GetList(ctx context.Context, req *models.GetListComingReque
 			return nil, err
 		}
 		resp.Cominges = append(resp.Cominges, &models.Coming{
-			Id:          Id.String,
-			IncrementID: IncrementID.String,
-			BranchID:    BranchID.String,
-			CreatedAt:   CreatedAt.String,
-			UpdatedAt:   UpdatedAt.String,
+			Id:              Id.String,
+			IncrementID:     IncrementID.String,
+			BranchID:        BranchID.String,
+			SupplierID:      SupplierID.String,
+			PurchaseOrderID: PurchaseOrderID.String,
+			TotalPrice:      TotalPrice.Float64,
+			Status:          Status.String,
+			CreatedAt:       CreatedAt.String,
+			UpdatedAt:       UpdatedAt.String,
 		})
 	}
 
@@ -169,6 +203,7 @@ func (r *ComingRepo) Update(ctx context.Context, req *models.UpdateComing) (int6
 		UPDATE "coming"
 			SET
 				"branch_id" = $2,
+				"supplier_id" = NULLIF($3, '')::UUID,
 				"updated_at" = NOW()
 		WHERE "id" = $1
 	`
@@ -176,6 +211,7 @@ func (r *ComingRepo) Update(ctx context.Context, req *models.UpdateComing) (int6
 		query,
 		req.Id,
 		req.BranchID,
+		req.SupplierID,
 	)
 	if err != nil {
 		return 0, err
@@ -189,3 +225,116 @@ func (r *ComingRepo) Delete(ctx context.Context, req *models.ComingPrimaryKey) e
 	_, err := r.db.Exec(ctx, query, req.Id)
 	return err
 }
+
+// SetStatus changes the status only when it is still req.From, so of two
+// concurrent calls one gets zero rows affected.
+func (r *ComingRepo) SetStatus(ctx context.Context, req *models.ComingStatus) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "coming"
+			SET
+				"status" = $3,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND "status" = $2`,
+		req.Id,
+		req.From,
+		req.To,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *ComingRepo) CreateEvent(ctx context.Context, req *models.CreateComingEvent) (*models.ComingEvent, error) {
+
+	var (
+		event = models.ComingEvent{
+			Id:       uuid.New().String(),
+			ComingID: req.ComingID,
+			Action:   req.Action,
+			UserID:   req.UserID,
+			Reason:   req.Reason,
+		}
+		CreatedAt sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO "coming_event"(
+			"id",
+			"coming_id",
+			"action",
+			"user_id",
+			"reason"
+		) VALUES ($1, $2, $3, NULLIF($4, '')::UUID, $5)
+		RETURNING "created_at"`,
+		event.Id,
+		event.ComingID,
+		event.Action,
+		event.UserID,
+		event.Reason,
+	).Scan(&CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	event.CreatedAt = CreatedAt.String
+	return &event, nil
+}
+
+func (r *ComingRepo) GetEvents(ctx context.Context, req *models.ComingPrimaryKey) ([]*models.ComingEvent, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"coming_id",
+			"action",
+			"user_id",
+			"reason",
+			"created_at"
+		FROM "coming_event"
+		WHERE "coming_id" = $1
+		ORDER BY "created_at"`,
+		req.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp []*models.ComingEvent
+	for rows.Next() {
+		var (
+			Id        sql.NullString
+			ComingID  sql.NullString
+			Action    sql.NullString
+			UserID    sql.NullString
+			Reason    sql.NullString
+			CreatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&ComingID,
+			&Action,
+			&UserID,
+			&Reason,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, &models.ComingEvent{
+			Id:        Id.String,
+			ComingID:  ComingID.String,
+			Action:    Action.String,
+			UserID:    UserID.String,
+			Reason:    Reason.String,
+			CreatedAt: CreatedAt.String,
+		})
+	}
+
+	return resp, rows.Err()
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"market_system/models"
+	"market_system/pkg/helpers"
+)
+
+type documentNumberRepo struct {
+	db DB
+}
+
+func NewDocumentNumberRepo(db DB) *documentNumberRepo {
+	return &documentNumberRepo{
+		db: db,
+	}
+}
+
+// Next increments the counter with a single upsert, so the row lock taken
+// by postgres serializes concurrent callers and every number is handed out once.
+func (r *documentNumberRepo) Next(ctx context.Context, req *models.NextDocumentNumber) (string, error) {
+
+	var (
+		scope string
+		year  int
+		value int64
+		query = `
+			INSERT INTO "document_counter"(
+				"document",
+				"scope",
+				"year",
+				"value",
+				"updated_at"
+			) VALUES ($1, $2, $3, 1, NOW())
+			ON CONFLICT ("document", "scope", "year") DO UPDATE
+				SET
+					"value" = "document_counter"."value" + 1,
+					"updated_at" = NOW()
+			RETURNING "value"`
+	)
+
+	if req.PerBranch {
+		scope = req.BranchID
+	}
+
+	if req.YearlyReset {
+		year = time.Now().Year()
+	}
+
+	err := r.db.QueryRow(ctx, query, req.Document, scope, year).Scan(&value)
+	if err != nil {
+		return "", err
+	}
+
+	return helpers.FormatDocumentNumber(req.Prefix, year, value, req.Width), nil
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+
+	"market_system/pkg/apperror"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// errorDB translates the errors of db with apperror.Translate, so every
+// repo reports missing rows and constraint violations the same way.
+type errorDB struct {
+	db DB
+}
+
+func (d errorDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Translate(err)
+	}
+	return errorTx{Tx: tx}, nil
+}
+
+func (d errorDB) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := d.db.Exec(ctx, sql, arguments...)
+	return tag, apperror.Translate(err)
+}
+
+func (d errorDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := d.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.Translate(err)
+	}
+	return errorRows{Rows: rows}, nil
+}
+
+func (d errorDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return errorRow{row: d.db.QueryRow(ctx, sql, args...)}
+}
+
+// errorTx is a pgx.Tx whose statements and commit go through errorDB.
+type errorTx struct {
+	pgx.Tx
+}
+
+func (t errorTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return errorDB{db: t.Tx}.Begin(ctx)
+}
+
+func (t errorTx) Commit(ctx context.Context) error {
+	return apperror.Translate(t.Tx.Commit(ctx))
+}
+
+func (t errorTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return errorDB{db: t.Tx}.Exec(ctx, sql, arguments...)
+}
+
+func (t errorTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return errorDB{db: t.Tx}.Query(ctx, sql, args...)
+}
+
+func (t errorTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return errorDB{db: t.Tx}.QueryRow(ctx, sql, args...)
+}
+
+type errorRow struct {
+	row pgx.Row
+}
+
+func (r errorRow) Scan(dest ...interface{}) error {
+	return apperror.Translate(r.row.Scan(dest...))
+}
+
+type errorRows struct {
+	pgx.Rows
+}
+
+func (r errorRows) Scan(dest ...interface{}) error {
+	return apperror.Translate(r.Rows.Scan(dest...))
+}
+
+func (r errorRows) Err() error {
+	return apperror.Translate(r.Rows.Err())
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"market_system/models"
+)
+
+// whereClause compiles search and filter into a WHERE clause with
+// positional placeholders. Only columns declared in spec are used, and
+// unqualified columns are prefixed with table.
+func whereClause(table string, spec models.FilterSpec, search string, filter models.Filter) (string, []interface{}, error) {
+
+	if err := filter.Validate(spec); err != nil {
+		return "", nil, err
+	}
+
+	var (
+		where = " WHERE TRUE"
+		args  []interface{}
+	)
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, column := range sortedKeys(filter.Fields) {
+		values := filter.Fields[column]
+		switch len(values) {
+		case 0:
+		case 1:
+			where += fmt.Sprintf(" AND %s = %s", qualify(table, column), arg(values[0]))
+		default:
+			where += fmt.Sprintf(" AND %s = ANY(%s)", qualify(table, column), arg(values))
+		}
+	}
+
+	for _, column := range sortedKeys(filter.Ranges) {
+		numberRange := filter.Ranges[column]
+		if numberRange.Min != nil {
+			where += fmt.Sprintf(" AND %s >= %s", qualify(table, column), arg(*numberRange.Min))
+		}
+		if numberRange.Max != nil {
+			where += fmt.Sprintf(" AND %s <= %s", qualify(table, column), arg(*numberRange.Max))
+		}
+	}
+
+	if filter.CreatedFrom != nil {
+		where += fmt.Sprintf(" AND %s >= %s", qualify(table, "created_at"), arg(*filter.CreatedFrom))
+	}
+
+	if filter.CreatedTo != nil {
+		where += fmt.Sprintf(" AND %s < %s", qualify(table, "created_at"), arg(*filter.CreatedTo))
+	}
+
+	if len(search) > 0 && len(spec.Search) > 0 {
+		var (
+			placeholder = arg("%" + escapeLike(search) + "%")
+			conditions  []string
+		)
+		for _, column := range spec.Search {
+			conditions = append(conditions, qualify(table, column)+" ILIKE "+placeholder)
+		}
+		where += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+
+	return where, args, nil
+}
+
+// inBranches narrows where to the rows of table whose parent, referenced
+// by column, is of one of branchIds. Lines and payments are of the branch of
+// their sale or coming.
+func inBranches(where string, args []interface{}, table, column, parent string, branchIds []string) (string, []interface{}) {
+
+	if len(branchIds) == 0 {
+		return where, args
+	}
+
+	args = append(args, branchIds)
+	where += fmt.Sprintf(` AND %s IN (SELECT "id" FROM %q WHERE "branch_id"::TEXT = ANY($%d))`, qualify(table, column), parent, len(args))
+
+	return where, args
+}
+
+func qualify(table, column string) string {
+
+	if strings.Contains(column, ".") {
+		parts := strings.SplitN(column, ".", 2)
+		return fmt.Sprintf("%q.%q", parts[0], parts[1])
+	}
+
+	return fmt.Sprintf("%q.%q", table, column)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"market_system/models"
+)
+
+func TestWhereClause(t *testing.T) {
+
+	min := 10.0
+
+	where, args, err := whereClause("product", models.ProductFilterSpec, "50%", models.Filter{
+		Fields: map[string][]string{"branch_id": {"a", "b"}},
+		Ranges: map[string]models.NumberRange{"price": {Min: &min}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantWhere := ` WHERE TRUE AND "product"."branch_id" = ANY($1) AND "product"."price" >= $2` +
+		` AND ("product"."name" ILIKE $3 OR "branch"."name" ILIKE $3)`
+	if where != wantWhere {
+		t.Fatalf("where =\n%s\nwant\n%s", where, wantWhere)
+	}
+
+	wantArgs := []interface{}{[]string{"a", "b"}, 10.0, `%50\%%`}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args = %#v, want %#v", args, wantArgs)
+	}
+
+	_, _, err = whereClause("product", models.ProductFilterSpec, "", models.Filter{
+		Fields: map[string][]string{"barcode": {"1"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for undeclared column")
+	}
+}
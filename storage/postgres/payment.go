@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+)
+
+type paymentRepo struct {
+	db DB
+}
+
+func NewPaymentRepo(db DB) *paymentRepo {
+	return &paymentRepo{
+		db: db,
+	}
+}
+
+// Create records the payment and recomputes paid and debt of the sale from
+// all of its payments. The sale row is locked first, so concurrent payments
+// of one sale are applied one after another.
+func (r *paymentRepo) Create(ctx context.Context, req *models.CreatePayment) (*models.Payment, error) {
+
+	var (
+		paymentId  = uuid.New().String()
+		totalPrice sql.NullFloat64
+		returned   float64
+		paid       float64
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `SELECT "total_price", "returned" FROM "sale" WHERE "id" = $1 FOR UPDATE`, req.SaleID).Scan(&totalPrice, &returned)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "payment"(
+			"id",
+			"sale_id",
+			"amount",
+			"method",
+			"cashier"
+		) VALUES ($1, $2, $3, $4, $5)`,
+		paymentId,
+		req.SaleID,
+		req.Amount,
+		req.Method,
+		req.Cashier,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM("amount"), 0) FROM "payment" WHERE "sale_id" = $1`, req.SaleID).Scan(&paid)
+	if err != nil {
+		return nil, err
+	}
+
+	if paid > totalPrice.Float64-returned {
+		return nil, storage.ErrOverpayment
+	}
+
+	if paid < 0 {
+		return nil, storage.ErrOverRefund
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "sale"
+			SET
+				"paid" = $2,
+				"debt" = $3,
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.SaleID,
+		paid,
+		totalPrice.Float64-returned-paid,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.PaymentPrimaryKey{Id: paymentId})
+}
+
+func (r *paymentRepo) GetByID(ctx context.Context, req *models.PaymentPrimaryKey) (*models.Payment, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"sale_id",
+				"amount",
+				"method",
+				"cashier",
+				"created_at"
+			FROM "payment"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id        sql.NullString
+		SaleID    sql.NullString
+		Amount    sql.NullFloat64
+		Method    sql.NullString
+		Cashier   sql.NullString
+		CreatedAt sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&SaleID,
+		&Amount,
+		&Method,
+		&Cashier,
+		&CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Payment{
+		Id:        Id.String,
+		SaleID:    SaleID.String,
+		Amount:    Amount.Float64,
+		Method:    Method.String,
+		Cashier:   Cashier.String,
+		CreatedAt: CreatedAt.String,
+	}, nil
+}
+
+func (r *paymentRepo) GetList(ctx context.Context, req *models.GetListPaymentRequest) (*models.GetListPaymentResponse, error) {
+	var (
+		resp   models.GetListPaymentResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	filter, branchIds := req.Filter.Without("branch_id")
+
+	where, args, err := whereClause("payment", models.PaymentFilterSpec, req.Search, filter)
+	if err != nil {
+		return nil, err
+	}
+	where, args = inBranches(where, args, "payment", "sale_id", "sale", branchIds)
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"sale_id",
+			"amount",
+			"method",
+			"cashier",
+			"created_at"
+		FROM "payment"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var (
+			Id        sql.NullString
+			SaleID    sql.NullString
+			Amount    sql.NullFloat64
+			Method    sql.NullString
+			Cashier   sql.NullString
+			CreatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&SaleID,
+			&Amount,
+			&Method,
+			&Cashier,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Payments = append(resp.Payments, &models.Payment{
+			Id:        Id.String,
+			SaleID:    SaleID.String,
+			Amount:    Amount.Float64,
+			Method:    Method.String,
+			Cashier:   Cashier.String,
+			CreatedAt: CreatedAt.String,
+		})
+	}
+
+	return &resp, nil
+}
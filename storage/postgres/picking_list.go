@@ -8,14 +8,13 @@ import (
 	"market_system/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type pickingListRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewPickingListRepo(db *pgxpool.Pool) *pickingListRepo {
+func NewPickingListRepo(db DB) *pickingListRepo {
 	return &pickingListRepo{
 		db: db,
 	}
@@ -34,8 +33,12 @@ func (r *pickingListRepo) Create(ctx context.Context, req *models.PickingList) (
 				"price",
 				"total_price",
 				"coming_increment_id",
+				"lot_number",
+				"expiry_date",
+				"unit",
+				"unit_quantity",
 				"updated_at"
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, '')::DATE, NULLIF($10, ''), NULLIF($11, 0), NOW())`
 	)
 
 	_, err := r.db.Exec(ctx,
@@ -47,6 +50,10 @@ func (r *pickingListRepo) Create(ctx context.Context, req *models.PickingList) (
 		req.Price,
 		req.Price*float64(req.Quantity),
 		req.ComingIncrementID,
+		req.LotNumber,
+		req.ExpiryDate,
+		req.Unit,
+		req.UnitQuantity,
 	)
 
 	if err != nil {
@@ -66,8 +73,12 @@ func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPr
 				"price",
 				"quantity",
 				"total_price",
+				COALESCE("unit", ''),
+				COALESCE("unit_quantity", "quantity"),
 				"coming_id",
 				"coming_increment_id",
+				"lot_number",
+				TO_CHAR("expiry_date", 'YYYY-MM-DD'),
 				"created_at",
 				"updated_at"
 			FROM "picking_list"
@@ -81,8 +92,12 @@ func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPr
 		Price             sql.NullFloat64
 		Quantity          sql.NullInt64
 		Total_price       sql.NullFloat64
+		Unit              sql.NullString
+		UnitQuantity      sql.NullInt64
 		ComingID          sql.NullString
 		ComingIncrementID sql.NullString
+		LotNumber         sql.NullString
+		ExpiryDate        sql.NullString
 		CreatedAt         sql.NullString
 		UpdatedAt         sql.NullString
 	)
@@ -93,8 +108,12 @@ func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPr
 		&Price,
 		&Quantity,
 		&Total_price,
+		&Unit,
+		&UnitQuantity,
 		&ComingID,
 		&ComingIncrementID,
+		&LotNumber,
+		&ExpiryDate,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -109,8 +128,12 @@ func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPr
 		Price:             Price.Float64,
 		Quantity:          int(Quantity.Int64),
 		Total_price:       Total_price.Float64,
+		Unit:              Unit.String,
+		UnitQuantity:      int(UnitQuantity.Int64),
 		ComingID:          ComingID.String,
 		ComingIncrementID: ComingIncrementID.String,
+		LotNumber:         LotNumber.String,
+		ExpiryDate:        ExpiryDate.String,
 		CreatedAt:         CreatedAt.String,
 		UpdatedAt:         UpdatedAt.String,
 	}, nil
@@ -119,12 +142,19 @@ func (r *pickingListRepo) GetByID(ctx context.Context, req *models.PickingListPr
 func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickingListRequest) (*models.GetListPickingListResponse, error) {
 	var (
 		resp   models.GetListPickingListResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	filter, branchIds := req.Filter.Without("branch_id")
+
+	where, args, err := whereClause("picking_list", models.PickingListFilterSpec, req.Search, filter)
+	if err != nil {
+		return nil, err
+	}
+	where, args = inBranches(where, args, "picking_list", "coming_id", "coming", branchIds)
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -133,14 +163,6 @@ func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickin
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND (PickingList_id ILIKE '%" + req.Search + "%' OR PickingList_id ILIKE '%" + req.Search + "%' OR barcode ILIKE '%" + req.Search + "%' OR PickingList_id ILIKE '%" + req.Search + "%')"
-	}
-
-	if len(req.Query) > 0 {
-		where += req.Query
-	}
-
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
@@ -149,15 +171,19 @@ func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickin
 				"price",
 				"quantity",
 				"total_price",
+				COALESCE("unit", ''),
+				COALESCE("unit_quantity", "quantity"),
 				"coming_id",
 				"coming_increment_id",
+				"lot_number",
+				TO_CHAR("expiry_date", 'YYYY-MM-DD'),
 				"created_at",
 				"updated_at"
 		FROM "picking_list"
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -169,8 +195,12 @@ func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickin
 			Price             sql.NullFloat64
 			Quantity          sql.NullInt64
 			Total_price       sql.NullFloat64
+			Unit              sql.NullString
+			UnitQuantity      sql.NullInt64
 			ComingID          sql.NullString
 			ComingIncrementID sql.NullString
+			LotNumber         sql.NullString
+			ExpiryDate        sql.NullString
 			CreatedAt         sql.NullString
 			UpdatedAt         sql.NullString
 		)
@@ -182,8 +212,12 @@ func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickin
 			&Price,
 			&Quantity,
 			&Total_price,
+			&Unit,
+			&UnitQuantity,
 			&ComingID,
 			&ComingIncrementID,
+			&LotNumber,
+			&ExpiryDate,
 			&CreatedAt,
 			&UpdatedAt,
 		)
@@ -196,8 +230,12 @@ func (r *pickingListRepo) GetList(ctx context.Context, req *models.GetListPickin
 			Price:             Price.Float64,
 			Quantity:          int(Quantity.Int64),
 			Total_price:       Total_price.Float64,
+			Unit:              Unit.String,
+			UnitQuantity:      int(UnitQuantity.Int64),
 			ComingID:          ComingID.String,
 			ComingIncrementID: ComingIncrementID.String,
+			LotNumber:         LotNumber.String,
+			ExpiryDate:        ExpiryDate.String,
 			CreatedAt:         CreatedAt.String,
 			UpdatedAt:         UpdatedAt.String,
 		})
@@ -214,8 +252,13 @@ func (r *pickingListRepo) Update(ctx context.Context, req *models.PickingList) (
 				"product_id" = $2,
 				"quantity" = $3,
 				"price" = $4,
+				"total_price" = $4 * $3,
 				"coming_id" = $5,
 				"coming_increment_id" = $6,
+				"lot_number" = NULLIF($7, ''),
+				"expiry_date" = NULLIF($8, '')::DATE,
+				"unit" = NULLIF($9, ''),
+				"unit_quantity" = NULLIF($10, 0),
 				"updated_at" = NOW()
 		WHERE "id" = $1
 	`
@@ -227,6 +270,10 @@ func (r *pickingListRepo) Update(ctx context.Context, req *models.PickingList) (
 		req.Price,
 		req.ComingID,
 		req.ComingIncrementID,
+		req.LotNumber,
+		req.ExpiryDate,
+		req.Unit,
+		req.UnitQuantity,
 	)
 	if err != nil {
 		return 0, err
@@ -5,14 +5,24 @@ import (
 	"fmt"
 
 	"market_system/config"
-	"market_system/pkg/helpers"
 	"market_system/storage"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// DB is implemented by both *pgxpool.Pool and pgx.Tx, so every repo
+// can run either on the pool or inside a transaction.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 type Store struct {
-	db          *pgxpool.Pool
+	db          DB
 	coming      storage.ComingRepoI
 	branch      storage.BranchRepoI
 	client      storage.ClientRepoI
@@ -20,8 +30,23 @@ type Store struct {
 	sale        storage.SaleRepoI
 	saleProduct storage.SaleProductRepoI
 	remainder   storage.RemainderRepoI
+	stockMovement  storage.StockMovementRepoI
+	transfer       storage.TransferRepoI
+	stockTake      storage.StockTakeRepoI
+	saleReturn     storage.SaleReturnRepoI
 	pickingList storage.PickingListRepoI
-	getIncrementId storage.IncrementIDRepoI
+	documentNumber storage.DocumentNumberRepoI
+	payment        storage.PaymentRepoI
+	user           storage.UserRepoI
+	supplier        storage.SupplierRepoI
+	supplierPayment storage.SupplierPaymentRepoI
+	purchaseOrder   storage.PurchaseOrderRepoI
+	stockLevel      storage.StockLevelRepoI
+	category        storage.CategoryRepoI
+	branchProduct   storage.BranchProductRepoI
+	priceChange     storage.PriceChangeRepoI
+	repricing       storage.RepricingRepoI
+	promotion       storage.PromotionRepoI
 }
 
 func NewConnectionPostgres(cfg *config.Config) (storage.StorageI, error) {
@@ -51,10 +76,37 @@ func NewConnectionPostgres(cfg *config.Config) (storage.StorageI, error) {
 	}
 
 	return &Store{
-		db: pgxpool,
+		db: errorDB{db: pgxpool},
 	}, nil
 }
 
+// WithTx runs fn against a Store whose repos share one pgx.Tx.
+// The transaction is committed when fn returns nil and rolled back
+// when it returns an error or panics.
+func (s *Store) WithTx(ctx context.Context, fn func(tx storage.StorageI) error) (err error) {
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+	}()
+
+	return fn(&Store{db: tx})
+}
+
 func (s *Store) Remainder() storage.RemainderRepoI {
 
 	if s.remainder == nil {
@@ -63,6 +115,42 @@ func (s *Store) Remainder() storage.RemainderRepoI {
 
 	return s.remainder
 }
+
+func (s *Store) StockMovement() storage.StockMovementRepoI {
+
+	if s.stockMovement == nil {
+		s.stockMovement = NewStockMovementRepo(s.db)
+	}
+
+	return s.stockMovement
+}
+
+func (s *Store) Transfer() storage.TransferRepoI {
+
+	if s.transfer == nil {
+		s.transfer = NewTransferRepo(s.db)
+	}
+
+	return s.transfer
+}
+
+func (s *Store) SaleReturn() storage.SaleReturnRepoI {
+
+	if s.saleReturn == nil {
+		s.saleReturn = NewSaleReturnRepo(s.db)
+	}
+
+	return s.saleReturn
+}
+
+func (s *Store) StockTake() storage.StockTakeRepoI {
+
+	if s.stockTake == nil {
+		s.stockTake = NewStockTakeRepo(s.db)
+	}
+
+	return s.stockTake
+}
 func (s *Store) Client() storage.ClientRepoI {
 
 	if s.client == nil {
@@ -122,11 +210,110 @@ func (s *Store) Branch() storage.BranchRepoI {
 	return s.branch
 }
 
-func (s *Store) IncrementID() storage.IncrementIDRepoI {
+func (s *Store) DocumentNumber() storage.DocumentNumberRepoI {
+
+	if s.documentNumber == nil {
+		s.documentNumber = NewDocumentNumberRepo(s.db)
+	}
+
+	return s.documentNumber
+}
+
+func (s *Store) User() storage.UserRepoI {
+
+	if s.user == nil {
+		s.user = NewUserRepo(s.db)
+	}
+
+	return s.user
+}
+
+func (s *Store) Payment() storage.PaymentRepoI {
+
+	if s.payment == nil {
+		s.payment = NewPaymentRepo(s.db)
+	}
+
+	return s.payment
+}
+
+func (s *Store) Supplier() storage.SupplierRepoI {
+
+	if s.supplier == nil {
+		s.supplier = NewSupplierRepo(s.db)
+	}
+
+	return s.supplier
+}
+
+func (s *Store) SupplierPayment() storage.SupplierPaymentRepoI {
+
+	if s.supplierPayment == nil {
+		s.supplierPayment = NewSupplierPaymentRepo(s.db)
+	}
+
+	return s.supplierPayment
+}
+
+func (s *Store) PurchaseOrder() storage.PurchaseOrderRepoI {
+
+	if s.purchaseOrder == nil {
+		s.purchaseOrder = NewPurchaseOrderRepo(s.db)
+	}
+
+	return s.purchaseOrder
+}
+
+func (s *Store) StockLevel() storage.StockLevelRepoI {
+
+	if s.stockLevel == nil {
+		s.stockLevel = NewStockLevelRepo(s.db)
+	}
+
+	return s.stockLevel
+}
+
+func (s *Store) Category() storage.CategoryRepoI {
+
+	if s.category == nil {
+		s.category = NewCategoryRepo(s.db)
+	}
+
+	return s.category
+}
+
+func (s *Store) BranchProduct() storage.BranchProductRepoI {
+
+	if s.branchProduct == nil {
+		s.branchProduct = NewBranchProductRepo(s.db)
+	}
+
+	return s.branchProduct
+}
+
+func (s *Store) PriceChange() storage.PriceChangeRepoI {
+
+	if s.priceChange == nil {
+		s.priceChange = NewPriceChangeRepo(s.db)
+	}
+
+	return s.priceChange
+}
+
+func (s *Store) Repricing() storage.RepricingRepoI {
+
+	if s.repricing == nil {
+		s.repricing = NewRepricingRepo(s.db)
+	}
+
+	return s.repricing
+}
+
+func (s *Store) Promotion() storage.PromotionRepoI {
 
-	if s.getIncrementId == nil {
-		s.getIncrementId = helpers.NewIncrementRepo(s.db)
+	if s.promotion == nil {
+		s.promotion = NewPromotionRepo(s.db)
 	}
 
-	return s.getIncrementId
+	return s.promotion
 }
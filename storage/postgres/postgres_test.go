@@ -0,0 +1,28 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	"market_system/config"
+	"market_system/storage/postgres"
+	"market_system/storage/storagetest"
+)
+
+// TestConformance runs against the database described by the usual
+// POSTGRES_* variables and is skipped when POSTGRES_HOST is not set.
+func TestConformance(t *testing.T) {
+
+	if _, ok := os.LookupEnv("POSTGRES_HOST"); !ok {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+
+	cfg := config.Load()
+
+	strg, err := postgres.NewConnectionPostgres(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storagetest.Run(t, strg)
+}
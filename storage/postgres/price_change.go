@@ -0,0 +1,445 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type priceChangeRepo struct {
+	db DB
+}
+
+func NewPriceChangeRepo(db DB) *priceChangeRepo {
+	return &priceChangeRepo{
+		db: db,
+	}
+}
+
+// priceChangeColumns are read by scanPriceChange.
+const priceChangeColumns = `
+	"price_history"."id",
+	"price_history"."product_id",
+	"price_history"."branch_id",
+	"price_history"."price",
+	"price_history"."effective_from",
+	"price_history"."document_id",
+	"price_history"."applied_at",
+	"price_history"."created_at"`
+
+func scanPriceChange(row scanner, dest ...interface{}) (*models.PriceChange, error) {
+
+	var (
+		Id            sql.NullString
+		ProductID     sql.NullString
+		BranchID      sql.NullString
+		Price         sql.NullFloat64
+		EffectiveFrom sql.NullString
+		DocumentID    sql.NullString
+		AppliedAt     sql.NullString
+		CreatedAt     sql.NullString
+	)
+
+	err := row.Scan(append(dest,
+		&Id,
+		&ProductID,
+		&BranchID,
+		&Price,
+		&EffectiveFrom,
+		&DocumentID,
+		&AppliedAt,
+		&CreatedAt,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.PriceChange{
+		Id:            Id.String,
+		ProductID:     ProductID.String,
+		BranchID:      BranchID.String,
+		EffectiveFrom: EffectiveFrom.String,
+		DocumentID:    DocumentID.String,
+		AppliedAt:     AppliedAt.String,
+		CreatedAt:     CreatedAt.String,
+	}
+	if Price.Valid {
+		resp.Price = &Price.Float64
+	}
+
+	return resp, nil
+}
+
+// insertPriceChange adds a pending change, applyDue applies it when due.
+func insertPriceChange(ctx context.Context, db DB, id string, req *models.CreatePriceChange, effectiveFrom time.Time) error {
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO "price_history"(
+			"id",
+			"product_id",
+			"branch_id",
+			"price",
+			"effective_from",
+			"document_id",
+			"created_at"
+		) VALUES ($1, $2, NULLIF($3, '')::UUID, $4, $5::TIMESTAMPTZ, NULLIF($6, '')::UUID, CLOCK_TIMESTAMP())`,
+		id,
+		req.ProductID,
+		req.BranchID,
+		req.Price,
+		effectiveFrom,
+		req.DocumentID,
+	)
+
+	return err
+}
+
+// recordPrice adds price to the history of the product, in the branch or
+// the catalog when branchId is empty, as a change applied now unless it is
+// the price the last applied change set.
+func recordPrice(ctx context.Context, db DB, productId, branchId string, price *float64) error {
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO "price_history"(
+			"id",
+			"product_id",
+			"branch_id",
+			"price",
+			"effective_from",
+			"applied_at",
+			"created_at"
+		)
+		SELECT $1, $2, NULLIF($3, '')::UUID, $4::NUMERIC, NOW(), NOW(), CLOCK_TIMESTAMP()
+		WHERE $4::NUMERIC IS DISTINCT FROM (
+			SELECT "price"
+			FROM "price_history"
+			WHERE "product_id" = $2
+				AND "branch_id" IS NOT DISTINCT FROM NULLIF($3, '')::UUID
+				AND "applied_at" IS NOT NULL
+			ORDER BY "effective_from" DESC, "created_at" DESC
+			LIMIT 1
+		)`,
+		uuid.New().String(),
+		productId,
+		branchId,
+		price,
+	)
+
+	return err
+}
+
+// repriceStock sets the sale price of the lots of the products in the
+// branch, or in every branch when branchId is empty, to the price the branch
+// sells them at, so the checkout charges a changed price for stock already
+// on hand.
+func repriceStock(ctx context.Context, db DB, branchId string, productIds []string) error {
+
+	_, err := db.Exec(ctx, `
+		UPDATE "remainder"
+			SET
+				"sale_price" = COALESCE((
+					SELECT "branch_product"."price"
+					FROM "branch_product"
+					WHERE "branch_product"."branch_id" = "remainder"."branch_id"
+						AND "branch_product"."product_id" = "remainder"."product_id"
+				), "product"."price"),
+				"updated_at" = NOW()
+		FROM "product"
+		WHERE "product"."id" = "remainder"."product_id"
+			AND ($1 = '' OR "remainder"."branch_id"::TEXT = $1)
+			AND "remainder"."product_id"::TEXT = ANY($2::TEXT[])`,
+		branchId,
+		productIds,
+	)
+
+	return err
+}
+
+// applyDue applies the pending changes due by at, oldest first. A change
+// an applied later one already overrides is only marked applied.
+func applyDue(ctx context.Context, db DB, at time.Time) (int64, error) {
+
+	type due struct {
+		Id         string
+		ProductID  string
+		BranchID   sql.NullString
+		Price      sql.NullFloat64
+		Superseded bool
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT
+			"price_history"."id",
+			"price_history"."product_id",
+			"price_history"."branch_id",
+			"price_history"."price",
+			EXISTS (
+				SELECT 1
+				FROM "price_history" AS "newer"
+				WHERE "newer"."product_id" = "price_history"."product_id"
+					AND "newer"."branch_id" IS NOT DISTINCT FROM "price_history"."branch_id"
+					AND "newer"."applied_at" IS NOT NULL
+					AND "newer"."effective_from" > "price_history"."effective_from"
+			)
+		FROM "price_history"
+		WHERE "price_history"."applied_at" IS NULL AND "price_history"."effective_from" <= $1::TIMESTAMPTZ
+		ORDER BY "price_history"."effective_from", "price_history"."created_at"
+		FOR UPDATE SKIP LOCKED`,
+		at,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var changes []due
+	for rows.Next() {
+		var change due
+		if err = rows.Scan(&change.Id, &change.ProductID, &change.BranchID, &change.Price, &change.Superseded); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.Superseded:
+		case change.BranchID.Valid:
+			_, err = db.Exec(ctx, `
+				INSERT INTO "branch_product"(
+					"branch_id",
+					"product_id",
+					"price",
+					"updated_at"
+				) VALUES ($1, $2, $3, NOW())
+				ON CONFLICT ("branch_id", "product_id") DO UPDATE
+					SET
+						"price" = EXCLUDED."price",
+						"updated_at" = NOW()`,
+				change.BranchID.String,
+				change.ProductID,
+				change.Price,
+			)
+		default:
+			_, err = db.Exec(ctx,
+				`UPDATE "product" SET "price" = $2, "updated_at" = NOW() WHERE "id" = $1`,
+				change.ProductID,
+				change.Price.Float64,
+			)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if !change.Superseded {
+			if err = repriceStock(ctx, db, change.BranchID.String, []string{change.ProductID}); err != nil {
+				return 0, err
+			}
+		}
+
+		_, err = db.Exec(ctx, `UPDATE "price_history" SET "applied_at" = NOW() WHERE "id" = $1`, change.Id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(changes)), nil
+}
+
+func (r *priceChangeRepo) Create(ctx context.Context, req *models.CreatePriceChange) (*models.PriceChange, error) {
+
+	var (
+		priceChangeId = uuid.New().String()
+		effectiveFrom = time.Now()
+	)
+
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = insertPriceChange(ctx, tx, priceChangeId, req, effectiveFrom); err != nil {
+		return nil, err
+	}
+
+	if _, err = applyDue(ctx, tx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.PriceChangePrimaryKey{Id: priceChangeId})
+}
+
+func (r *priceChangeRepo) GetByID(ctx context.Context, req *models.PriceChangePrimaryKey) (*models.PriceChange, error) {
+
+	return scanPriceChange(r.db.QueryRow(ctx, `
+		SELECT`+priceChangeColumns+`
+		FROM "price_history"
+		WHERE "price_history"."id" = $1`,
+		req.Id,
+	))
+}
+
+func (r *priceChangeRepo) GetList(ctx context.Context, req *models.GetListPriceChangeRequest) (*models.GetListPriceChangeResponse, error) {
+	var (
+		resp   models.GetListPriceChangeResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = ` ORDER BY "price_history"."effective_from" DESC, "price_history"."created_at" DESC`
+	)
+
+	where, args, err := whereClause("price_history", models.PriceChangeFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Pending {
+		where += ` AND "price_history"."applied_at" IS NULL`
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),` + priceChangeColumns + `
+		FROM "price_history"
+		JOIN "product" ON "product"."id" = "price_history"."product_id"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		priceChange, err := scanPriceChange(rows, &resp.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.PriceChanges = append(resp.PriceChanges, priceChange)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Cancel deletes a change that is still pending, an applied one stays in
+// the history.
+func (r *priceChangeRepo) Cancel(ctx context.Context, req *models.PriceChangePrimaryKey) (int64, error) {
+
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM "price_history" WHERE "id" = $1 AND "applied_at" IS NULL`,
+		req.Id,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *priceChangeRepo) ApplyDue(ctx context.Context, at time.Time) (int64, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	applied, err := applyDue(ctx, tx, at)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return applied, nil
+}
+
+func (r *priceChangeRepo) AsOf(ctx context.Context, req *models.PriceAsOfRequest) (*models.PriceAsOf, error) {
+
+	var query = `
+		SELECT
+			"id",
+			"price",
+			"effective_from"
+		FROM "price_history"
+		WHERE "product_id" = $1
+			AND "branch_id" IS NOT DISTINCT FROM NULLIF($2, '')::UUID
+			AND "effective_from" <= $3::TIMESTAMPTZ
+		ORDER BY "effective_from" DESC, "created_at" DESC
+		LIMIT 1
+	`
+
+	var resp = models.PriceAsOf{
+		ProductID: req.ProductID,
+		BranchID:  req.BranchID,
+		At:        req.At.Format(time.RFC3339Nano),
+	}
+
+	// the override of the branch, then the catalog
+	var branchIds = []string{""}
+	if len(req.BranchID) > 0 {
+		branchIds = []string{req.BranchID, ""}
+	}
+
+	for _, branchId := range branchIds {
+		var (
+			Id            sql.NullString
+			Price         sql.NullFloat64
+			EffectiveFrom sql.NullString
+		)
+
+		err := r.db.QueryRow(ctx, query, req.ProductID, branchId, req.At).Scan(&Id, &Price, &EffectiveFrom)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if Price.Valid {
+			resp.Price = Price.Float64
+			resp.ChangeID = Id.String
+			resp.EffectiveFrom = EffectiveFrom.String
+			return &resp, nil
+		}
+	}
+
+	return nil, pgx.ErrNoRows
+}
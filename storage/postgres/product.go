@@ -8,14 +8,13 @@ import (
 	"market_system/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type productRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewProductRepo(db *pgxpool.Pool) *productRepo {
+func NewProductRepo(db DB) *productRepo {
 	return &productRepo{
 		db: db,
 	}
@@ -31,21 +30,55 @@ func (r *productRepo) Create(ctx context.Context, req *models.CreateProduct) (*m
 				"name",
 				"price",
 				"branch_id",
+				"category_id",
+				"unit",
 				"updated_at"
-			) VALUES ($1, $2, $3,$4, NOW())`
+			) VALUES ($1, $2, $3, NULLIF($4, '')::UUID, NULLIF($5, '')::UUID, COALESCE(NULLIF($6, ''), 'pcs'), NOW())`
 	)
-	_, err := r.db.Exec(ctx,
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
 		query,
 		productId,
 		req.Name,
 		req.Price,
 		req.BranchID,
+		req.CategoryID,
+		req.Unit,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if err = insertBarcodes(ctx, tx, productId, req.Barcodes); err != nil {
+		return nil, err
+	}
+
+	if err = insertUnits(ctx, tx, productId, req.Units); err != nil {
+		return nil, err
+	}
+
+	if err = recordPrice(ctx, tx, productId, "", &req.Price); err != nil {
+		return nil, err
+	}
+
+	// a product made for a branch is in its assortment
+	if len(req.BranchID) > 0 {
+		if _, err = NewBranchProductRepo(tx).Set(ctx, &models.SetBranchProduct{BranchID: req.BranchID, ProductID: productId}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return r.GetByID(ctx, &models.ProductPrimaryKey{Id: productId})
 }
 
@@ -58,6 +91,8 @@ func (r *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 				"name",
 				"price",
 				"branch_id",
+				"category_id",
+				"unit",
 				"created_at",
 				"updated_at"
 			FROM "product"
@@ -66,12 +101,14 @@ func (r *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 	)
 
 	var (
-		Id        sql.NullString
-		Name      sql.NullString
-		Price     sql.NullFloat64
-		BranchID  sql.NullString
-		CreatedAt sql.NullString
-		UpdatedAt sql.NullString
+		Id         sql.NullString
+		Name       sql.NullString
+		Price      sql.NullFloat64
+		BranchID   sql.NullString
+		CategoryID sql.NullString
+		Unit       sql.NullString
+		CreatedAt  sql.NullString
+		UpdatedAt  sql.NullString
 	)
 
 	err := r.db.QueryRow(ctx, query, req.Id).Scan(
@@ -79,6 +116,8 @@ func (r *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 		&Name,
 		&Price,
 		&BranchID,
+		&CategoryID,
+		&Unit,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -87,39 +126,61 @@ func (r *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 		return nil, err
 	}
 
+	barcodes, err := productBarcodes(ctx, r.db, Id.String)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := productUnits(ctx, r.db, Id.String)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.Product{
-		Id:        Id.String,
-		Name:      Name.String,
-		Price:     Price.Float64,
-		BranchID:  BranchID.String,
-		CreatedAt: CreatedAt.String,
-		UpdatedAt: UpdatedAt.String,
+		Id:         Id.String,
+		Name:       Name.String,
+		Price:      Price.Float64,
+		BranchID:   BranchID.String,
+		CategoryID: CategoryID.String,
+		Unit:       Unit.String,
+		Units:      units[Id.String],
+		Barcodes:   barcodes[Id.String],
+		CreatedAt:  CreatedAt.String,
+		UpdatedAt:  UpdatedAt.String,
 	}, nil
 }
 
 func (r *productRepo) GetList(ctx context.Context, req *models.GetListProductRequest) (*models.GetListProductResponse, error) {
 	var (
 		resp   models.GetListProductResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
-		sort   = " ORDER BY created_at DESC"
+		sort   = " ORDER BY product.created_at DESC"
 	)
 
-	if req.Offset > 0 {
-		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	filter, branchIds := req.Filter.Without("branch_id")
+
+	where, args, err := whereClause("product", models.ProductFilterSpec, req.Search, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Limit > 0 {
-		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	// a branch lists the products it carries
+	if len(branchIds) > 0 {
+		args = append(args, branchIds)
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1
+			FROM "branch_product"
+			WHERE "branch_product"."product_id" = product."id" AND "branch_product"."branch_id"::TEXT = ANY($%d)
+		)`, len(args))
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND (product.name ILIKE '%" + req.Search + "%' OR branch.name ILIKE '%" + req.Search + "%')"
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
 
-	if len(req.Query) > 0 {
-		where += req.Query
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
 	var query = `
@@ -129,25 +190,31 @@ func (r *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 			product."name",
 			product."price",
 			product."branch_id",
+			product."category_id",
+			product."unit",
 			product."created_at",
 			product."updated_at",
 			branch."name"
 		FROM "product"
-		JOIN branch ON product.branch_id = branch.id
+		LEFT JOIN branch ON product.branch_id = branch.id
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
+	var ids []string
 	for rows.Next() {
 		var (
 			Id        sql.NullString
 			Name      sql.NullString
 			Price     sql.NullFloat64
 			BranchID  sql.NullString
+			CategoryID sql.NullString
+			Unit      sql.NullString
 			CreatedAt sql.NullString
 			UpdatedAt sql.NullString
 			BranchName sql.NullString
@@ -160,6 +227,8 @@ func (r *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 			&Name,
 			&Price,
 			&BranchID,
+			&CategoryID,
+			&Unit,
 			&CreatedAt,
 			&UpdatedAt,
 			&BranchName,
@@ -172,10 +241,32 @@ func (r *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 			Name:      Name.String,
 			Price:     Price.Float64,
 			BranchID:  BranchID.String,
+			CategoryID: CategoryID.String,
+			Unit:      Unit.String,
 			CreatedAt: CreatedAt.String,
 			UpdatedAt: UpdatedAt.String,
 		
 		})
+		ids = append(ids, Id.String)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	barcodes, err := productBarcodes(ctx, r.db, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := productUnits(ctx, r.db, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range resp.Products {
+		product.Barcodes = barcodes[product.Id]
+		product.Units = units[product.Id]
 	}
 
 	return &resp, nil
@@ -188,21 +279,65 @@ func (r *productRepo) Update(ctx context.Context, req *models.UpdateProduct) (in
 			SET
 				"name" = $2,
 				"price" = $3,
-				"branch_id" = $4,
+				"branch_id" = NULLIF($4, '')::UUID,
+				"category_id" = NULLIF($5, '')::UUID,
+				"unit" = COALESCE(NULLIF($6, ''), "unit"),
 				"updated_at" = NOW()
 		WHERE "id" = $1
 	`
-	rowsAffected, err := r.db.Exec(ctx,
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rowsAffected, err := tx.Exec(ctx,
 		query,
 		req.Id,
 		req.Name,
 		req.Price,
 		req.BranchID,
+		req.CategoryID,
+		req.Unit,
 	)
 	if err != nil {
 		return 0, err
 	}
 
+	if rowsAffected.RowsAffected() > 0 {
+		if err = recordPrice(ctx, tx, req.Id, "", &req.Price); err != nil {
+			return 0, err
+		}
+		if err = repriceStock(ctx, tx, "", []string{req.Id}); err != nil {
+			return 0, err
+		}
+	}
+
+	if rowsAffected.RowsAffected() > 0 && req.Barcodes != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM "product_barcode" WHERE "product_id" = $1`, req.Id); err != nil {
+			return 0, err
+		}
+
+		if err = insertBarcodes(ctx, tx, req.Id, req.Barcodes); err != nil {
+			return 0, err
+		}
+	}
+
+	if rowsAffected.RowsAffected() > 0 && req.Units != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM "product_unit" WHERE "product_id" = $1`, req.Id); err != nil {
+			return 0, err
+		}
+
+		if err = insertUnits(ctx, tx, req.Id, req.Units); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
 	return rowsAffected.RowsAffected(), nil
 }
 
@@ -210,3 +345,195 @@ func (r *productRepo) Delete(ctx context.Context, req *models.ProductPrimaryKey)
 	_, err := r.db.Exec(ctx, "DELETE FROM product WHERE id = $1", req.Id)
 	return err
 }
+
+func insertBarcodes(ctx context.Context, db DB, productId string, barcodes []*models.Barcode) error {
+
+	for _, barcode := range barcodes {
+		// clock_timestamp keeps the barcodes in the order they were given
+		_, err := db.Exec(ctx, `
+			INSERT INTO "product_barcode"(
+				"code",
+				"product_id",
+				"type",
+				"created_at"
+			) VALUES ($1, $2, $3, CLOCK_TIMESTAMP())`,
+			barcode.Code,
+			productId,
+			barcode.Type,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// productBarcodes reads the barcodes of the products, by product id.
+func productBarcodes(ctx context.Context, db DB, productIds ...string) (map[string][]*models.Barcode, error) {
+
+	var resp = map[string][]*models.Barcode{}
+	if len(productIds) == 0 {
+		return resp, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT
+			"product_id",
+			"code",
+			"type"
+		FROM "product_barcode"
+		WHERE "product_id" = ANY($1)
+		ORDER BY "created_at"`,
+		productIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ProductID sql.NullString
+			Code      sql.NullString
+			Type      sql.NullString
+		)
+
+		if err = rows.Scan(&ProductID, &Code, &Type); err != nil {
+			return nil, err
+		}
+
+		resp[ProductID.String] = append(resp[ProductID.String], &models.Barcode{Code: Code.String, Type: Type.String})
+	}
+
+	return resp, rows.Err()
+}
+
+func insertUnits(ctx context.Context, db DB, productId string, units []*models.ProductUnit) error {
+
+	for _, unit := range units {
+		_, err := db.Exec(ctx, `
+			INSERT INTO "product_unit"(
+				"product_id",
+				"name",
+				"factor",
+				"price",
+				"created_at"
+			) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP())`,
+			productId,
+			unit.Name,
+			unit.Factor,
+			unit.Price,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// productUnits reads the pack units of the products, by product id.
+func productUnits(ctx context.Context, db DB, productIds ...string) (map[string][]*models.ProductUnit, error) {
+
+	var resp = map[string][]*models.ProductUnit{}
+	if len(productIds) == 0 {
+		return resp, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT
+			"product_id",
+			"name",
+			"factor",
+			"price"
+		FROM "product_unit"
+		WHERE "product_id" = ANY($1)
+		ORDER BY "created_at"`,
+		productIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ProductID sql.NullString
+			Name      sql.NullString
+			Factor    sql.NullInt64
+			Price     sql.NullFloat64
+		)
+
+		if err = rows.Scan(&ProductID, &Name, &Factor, &Price); err != nil {
+			return nil, err
+		}
+
+		resp[ProductID.String] = append(resp[ProductID.String], &models.ProductUnit{Name: Name.String, Factor: int(Factor.Int64), Price: Price.Float64})
+	}
+
+	return resp, rows.Err()
+}
+
+// GetByBarcode finds the product of a code with the sale price of the lot
+// a checkout takes first and the sellable stock of the branch.
+func (r *productRepo) GetByBarcode(ctx context.Context, req *models.ProductByBarcodeRequest) (*models.ProductByBarcode, error) {
+
+	var (
+		resp = models.ProductByBarcode{BranchID: req.BranchID}
+
+		ProductID sql.NullString
+		Type      sql.NullString
+		SalePrice sql.NullFloat64
+		Quantity  sql.NullInt64
+	)
+
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			"b"."product_id",
+			"b"."type",
+			(
+				SELECT "sale_price" FROM "remainder"
+				WHERE "product_id" = "b"."product_id" AND ($2 = '' OR "branch_id"::TEXT = $2) AND "quantity" > 0
+				AND ("expiry_date" IS NULL OR "expiry_date" >= CURRENT_DATE)
+				ORDER BY "expiry_date" NULLS LAST, "created_at"
+				LIMIT 1
+			),
+			(
+				SELECT SUM("quantity") FROM "remainder"
+				WHERE "product_id" = "b"."product_id" AND ($2 = '' OR "branch_id"::TEXT = $2)
+				AND ("expiry_date" IS NULL OR "expiry_date" >= CURRENT_DATE)
+			)
+		FROM "product_barcode" AS "b"
+		WHERE "b"."code" = $1`,
+		req.Code,
+		req.BranchID,
+	).Scan(
+		&ProductID,
+		&Type,
+		&SalePrice,
+		&Quantity,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Product, err = r.GetByID(ctx, &models.ProductPrimaryKey{Id: ProductID.String})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Barcode = models.Barcode{Code: req.Code, Type: Type.String}
+	resp.SalePrice = resp.Product.Price
+	if SalePrice.Valid {
+		resp.SalePrice = SalePrice.Float64
+	} else if len(req.BranchID) > 0 {
+		resp.SalePrice, err = NewBranchProductRepo(r.db).SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: req.BranchID, ProductID: resp.Product.Id})
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.Quantity = int(Quantity.Int64)
+
+	return &resp, nil
+}
@@ -0,0 +1,389 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type promotionRepo struct {
+	db DB
+}
+
+func NewPromotionRepo(db DB) *promotionRepo {
+	return &promotionRepo{
+		db: db,
+	}
+}
+
+// promotionColumns are read by scanPromotion.
+const promotionColumns = `
+	"promotion"."id",
+	"promotion"."name",
+	"promotion"."type",
+	"promotion"."value",
+	"promotion"."buy_quantity",
+	"promotion"."free_quantity",
+	"promotion"."branch_id",
+	"promotion"."product_id",
+	"promotion"."category_id",
+	"promotion"."client_group",
+	"promotion"."starts_at",
+	"promotion"."ends_at",
+	"promotion"."created_at",
+	"promotion"."updated_at"`
+
+func scanPromotion(row scanner, dest ...interface{}) (*models.Promotion, error) {
+
+	var (
+		Id           sql.NullString
+		Name         sql.NullString
+		Type         sql.NullString
+		Value        sql.NullFloat64
+		BuyQuantity  sql.NullInt64
+		FreeQuantity sql.NullInt64
+		BranchID     sql.NullString
+		ProductID    sql.NullString
+		CategoryID   sql.NullString
+		ClientGroup  sql.NullString
+		StartsAt     sql.NullString
+		EndsAt       sql.NullString
+		CreatedAt    sql.NullString
+		UpdatedAt    sql.NullString
+	)
+
+	err := row.Scan(append(dest,
+		&Id,
+		&Name,
+		&Type,
+		&Value,
+		&BuyQuantity,
+		&FreeQuantity,
+		&BranchID,
+		&ProductID,
+		&CategoryID,
+		&ClientGroup,
+		&StartsAt,
+		&EndsAt,
+		&CreatedAt,
+		&UpdatedAt,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Promotion{
+		Id:           Id.String,
+		Name:         Name.String,
+		Type:         Type.String,
+		Value:        Value.Float64,
+		BuyQuantity:  int(BuyQuantity.Int64),
+		FreeQuantity: int(FreeQuantity.Int64),
+		BranchID:     BranchID.String,
+		ProductID:    ProductID.String,
+		CategoryID:   CategoryID.String,
+		ClientGroup:  ClientGroup.String,
+		StartsAt:     StartsAt.String,
+		EndsAt:       EndsAt.String,
+		CreatedAt:    CreatedAt.String,
+		UpdatedAt:    UpdatedAt.String,
+	}, nil
+}
+
+func (r *promotionRepo) Create(ctx context.Context, req *models.CreatePromotion) (*models.Promotion, error) {
+
+	var (
+		promotionId = uuid.New().String()
+		startsAt    = time.Now()
+	)
+
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "promotion"(
+			"id",
+			"name",
+			"type",
+			"value",
+			"buy_quantity",
+			"free_quantity",
+			"branch_id",
+			"product_id",
+			"category_id",
+			"client_group",
+			"starts_at",
+			"ends_at",
+			"updated_at"
+		) VALUES (
+			$1, $2, $3, $4, $5, $6,
+			NULLIF($7, '')::UUID,
+			NULLIF($8, '')::UUID,
+			NULLIF($9, '')::UUID,
+			NULLIF($10, ''),
+			$11::TIMESTAMPTZ,
+			$12::TIMESTAMPTZ,
+			NOW()
+		)`,
+		promotionId,
+		req.Name,
+		req.Type,
+		req.Value,
+		req.BuyQuantity,
+		req.FreeQuantity,
+		req.BranchID,
+		req.ProductID,
+		req.CategoryID,
+		req.ClientGroup,
+		startsAt,
+		req.EndsAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.PromotionPrimaryKey{Id: promotionId})
+}
+
+func (r *promotionRepo) GetByID(ctx context.Context, req *models.PromotionPrimaryKey) (*models.Promotion, error) {
+
+	return scanPromotion(r.db.QueryRow(ctx, `
+		SELECT`+promotionColumns+`
+		FROM "promotion"
+		WHERE "promotion"."id" = $1`,
+		req.Id,
+	))
+}
+
+func (r *promotionRepo) GetList(ctx context.Context, req *models.GetListPromotionRequest) (*models.GetListPromotionResponse, error) {
+	var (
+		resp   models.GetListPromotionResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = ` ORDER BY "promotion"."created_at" DESC`
+	)
+
+	where, args, err := whereClause("promotion", models.PromotionFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),` + promotionColumns + `
+		FROM "promotion"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		promotion, err := scanPromotion(rows, &resp.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Promotions = append(resp.Promotions, promotion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *promotionRepo) Update(ctx context.Context, req *models.UpdatePromotion) (int64, error) {
+
+	var startsAt = time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "promotion"
+			SET
+				"name" = $2,
+				"type" = $3,
+				"value" = $4,
+				"buy_quantity" = $5,
+				"free_quantity" = $6,
+				"branch_id" = NULLIF($7, '')::UUID,
+				"product_id" = NULLIF($8, '')::UUID,
+				"category_id" = NULLIF($9, '')::UUID,
+				"client_group" = NULLIF($10, ''),
+				"starts_at" = $11::TIMESTAMPTZ,
+				"ends_at" = $12::TIMESTAMPTZ,
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.Id,
+		req.Name,
+		req.Type,
+		req.Value,
+		req.BuyQuantity,
+		req.FreeQuantity,
+		req.BranchID,
+		req.ProductID,
+		req.CategoryID,
+		req.ClientGroup,
+		startsAt,
+		req.EndsAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// Delete ends the promotion now, or before it starts when it has not, so the
+// sales it discounted keep it.
+func (r *promotionRepo) Delete(ctx context.Context, req *models.PromotionPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "promotion"
+			SET
+				"ends_at" = GREATEST("starts_at", LEAST(COALESCE("ends_at", NOW()), NOW())),
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.Id,
+	)
+	return err
+}
+
+func (r *promotionRepo) Active(ctx context.Context, branchId string, at time.Time) ([]*models.Promotion, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT`+promotionColumns+`
+		FROM "promotion"
+		WHERE "promotion"."starts_at" <= $2::TIMESTAMPTZ
+		AND ("promotion"."ends_at" IS NULL OR "promotion"."ends_at" > $2::TIMESTAMPTZ)
+		AND ("promotion"."branch_id" IS NULL OR "promotion"."branch_id"::TEXT = $1)
+		ORDER BY "promotion"."created_at", "promotion"."id"`,
+		branchId,
+		at,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp []*models.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, promotion)
+	}
+
+	return resp, rows.Err()
+}
+
+// Report sums the discounts of the sale lines by the promotion that gave
+// them, the costliest first. The returned units of a line are taken off with
+// their share of its discount, a line returned in full is not counted.
+func (r *promotionRepo) Report(ctx context.Context, req *models.PromotionReportRequest) (*models.PromotionReportResponse, error) {
+
+	var (
+		resp  models.PromotionReportResponse
+		args  = []interface{}{req.BranchID}
+		query = `
+			SELECT
+				"promotion"."id",
+				"promotion"."name",
+				"promotion"."type",
+				COUNT(DISTINCT "sp"."sale_id"),
+				COUNT(*),
+				SUM("sp"."quantity" - COALESCE("returned"."quantity", 0)),
+				SUM(ROUND("sp"."discount" * ("sp"."quantity" - COALESCE("returned"."quantity", 0)) / "sp"."quantity", 2))
+			FROM "sale_product" AS "sp"
+			JOIN "promotion" ON "promotion"."id" = "sp"."promotion_id"
+			JOIN "sale" ON "sale"."id" = "sp"."sale_id"
+			LEFT JOIN (
+				SELECT "sale_product_id", SUM("quantity") AS "quantity"
+				FROM "sale_return_line"
+				GROUP BY "sale_product_id"
+			) AS "returned" ON "returned"."sale_product_id" = "sp"."id"
+			WHERE ($1 = '' OR "sale"."branch_id"::TEXT = $1)
+			AND "sp"."quantity" > COALESCE("returned"."quantity", 0)
+		`
+	)
+
+	if req.From != nil {
+		args = append(args, *req.From)
+		query += fmt.Sprintf(` AND "sp"."created_at" >= $%d`, len(args))
+	}
+
+	if req.To != nil {
+		args = append(args, *req.To)
+		query += fmt.Sprintf(` AND "sp"."created_at" < $%d`, len(args))
+	}
+
+	query += ` GROUP BY "promotion"."id" ORDER BY 7 DESC, "promotion"."name"`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			PromotionID sql.NullString
+			Name        sql.NullString
+			Type        sql.NullString
+			Sales       sql.NullInt64
+			Lines       sql.NullInt64
+			Quantity    sql.NullInt64
+			Discount    sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&PromotionID,
+			&Name,
+			&Type,
+			&Sales,
+			&Lines,
+			&Quantity,
+			&Discount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Add(&models.PromotionCost{
+			PromotionID: PromotionID.String,
+			Name:        Name.String,
+			Type:        Type.String,
+			Sales:       int(Sales.Int64),
+			Lines:       int(Lines.Int64),
+			Quantity:    int(Quantity.Int64),
+			Discount:    Discount.Float64,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,424 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type purchaseOrderRepo struct {
+	db DB
+}
+
+func NewPurchaseOrderRepo(db DB) *purchaseOrderRepo {
+	return &purchaseOrderRepo{
+		db: db,
+	}
+}
+
+func (r *purchaseOrderRepo) Create(ctx context.Context, req *models.CreatePurchaseOrder) (*models.PurchaseOrder, error) {
+
+	var purchaseOrderId = uuid.New().String()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "purchase_order"(
+			"id",
+			"increment_id",
+			"supplier_id",
+			"branch_id",
+			"user_id",
+			"updated_at"
+		) VALUES ($1, $2, $3, $4, NULLIF($5, '')::UUID, NOW())`,
+		purchaseOrderId,
+		req.IncrementID,
+		req.SupplierID,
+		req.BranchID,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = insertPurchaseOrderLines(ctx, tx, purchaseOrderId, req.Lines); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: purchaseOrderId})
+}
+
+func insertPurchaseOrderLines(ctx context.Context, db DB, purchaseOrderId string, lines []*models.CreatePurchaseOrderLine) error {
+
+	for _, line := range lines {
+		// clock_timestamp keeps the lines in the order they were given
+		_, err := db.Exec(ctx, `
+			INSERT INTO "purchase_order_line"(
+				"id",
+				"purchase_order_id",
+				"product_id",
+				"quantity",
+				"price",
+				"created_at"
+			) VALUES ($1, $2, $3, $4, $5, CLOCK_TIMESTAMP())`,
+			uuid.New().String(),
+			purchaseOrderId,
+			line.ProductID,
+			line.Quantity,
+			line.Price,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *purchaseOrderRepo) GetByID(ctx context.Context, req *models.PurchaseOrderPrimaryKey) (*models.PurchaseOrder, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"increment_id",
+				"supplier_id",
+				"branch_id",
+				"status",
+				"user_id",
+				"closed_at",
+				"created_at",
+				"updated_at"
+			FROM "purchase_order"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id          sql.NullString
+		IncrementID sql.NullString
+		SupplierID  sql.NullString
+		BranchID    sql.NullString
+		Status      sql.NullString
+		UserID      sql.NullString
+		ClosedAt    sql.NullString
+		CreatedAt   sql.NullString
+		UpdatedAt   sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&IncrementID,
+		&SupplierID,
+		&BranchID,
+		&Status,
+		&UserID,
+		&ClosedAt,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrder := &models.PurchaseOrder{
+		Id:          Id.String,
+		IncrementID: IncrementID.String,
+		SupplierID:  SupplierID.String,
+		BranchID:    BranchID.String,
+		Status:      Status.String,
+		UserID:      UserID.String,
+		ClosedAt:    ClosedAt.String,
+		CreatedAt:   CreatedAt.String,
+		UpdatedAt:   UpdatedAt.String,
+	}
+
+	purchaseOrder.Lines, err = r.lines(ctx, purchaseOrder.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, amount, err := r.received(ctx, purchaseOrder.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrder.Receive(quantity, amount)
+
+	return purchaseOrder, nil
+}
+
+func (r *purchaseOrderRepo) lines(ctx context.Context, purchaseOrderId string) ([]*models.PurchaseOrderLine, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"purchase_order_id",
+			"product_id",
+			"quantity",
+			"price",
+			"created_at"
+		FROM "purchase_order_line"
+		WHERE "purchase_order_id" = $1
+		ORDER BY "created_at"`,
+		purchaseOrderId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp []*models.PurchaseOrderLine
+
+	for rows.Next() {
+		var (
+			Id              sql.NullString
+			PurchaseOrderID sql.NullString
+			ProductID       sql.NullString
+			Quantity        sql.NullInt64
+			Price           sql.NullFloat64
+			CreatedAt       sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&PurchaseOrderID,
+			&ProductID,
+			&Quantity,
+			&Price,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, &models.PurchaseOrderLine{
+			Id:              Id.String,
+			PurchaseOrderID: PurchaseOrderID.String,
+			ProductID:       ProductID.String,
+			Quantity:        int(Quantity.Int64),
+			Price:           Price.Float64,
+			CreatedAt:       CreatedAt.String,
+		})
+	}
+
+	return resp, rows.Err()
+}
+
+// received sums the picking lists of the finished comings of the order by
+// product.
+func (r *purchaseOrderRepo) received(ctx context.Context, purchaseOrderId string) (map[string]int, map[string]float64, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			pl."product_id",
+			SUM(pl."quantity"),
+			SUM(pl."total_price")
+		FROM "coming" AS c
+		JOIN "picking_list" AS pl ON pl."coming_id" = c."id"
+		WHERE c."purchase_order_id" = $1 AND c."status" = 'finished'
+		GROUP BY pl."product_id"`,
+		purchaseOrderId,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var (
+		quantity = map[string]int{}
+		amount   = map[string]float64{}
+	)
+
+	for rows.Next() {
+		var (
+			ProductID sql.NullString
+			Quantity  sql.NullInt64
+			Amount    sql.NullFloat64
+		)
+
+		if err = rows.Scan(&ProductID, &Quantity, &Amount); err != nil {
+			return nil, nil, err
+		}
+
+		quantity[ProductID.String] = int(Quantity.Int64)
+		amount[ProductID.String] = Amount.Float64
+	}
+
+	return quantity, amount, rows.Err()
+}
+
+func (r *purchaseOrderRepo) GetList(ctx context.Context, req *models.GetListPurchaseOrderRequest) (*models.GetListPurchaseOrderResponse, error) {
+	var (
+		resp   models.GetListPurchaseOrderResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("purchase_order", models.PurchaseOrderFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"increment_id",
+			"supplier_id",
+			"branch_id",
+			"status",
+			"user_id",
+			"closed_at",
+			"created_at",
+			"updated_at"
+		FROM "purchase_order"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id          sql.NullString
+			IncrementID sql.NullString
+			SupplierID  sql.NullString
+			BranchID    sql.NullString
+			Status      sql.NullString
+			UserID      sql.NullString
+			ClosedAt    sql.NullString
+			CreatedAt   sql.NullString
+			UpdatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&IncrementID,
+			&SupplierID,
+			&BranchID,
+			&Status,
+			&UserID,
+			&ClosedAt,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.PurchaseOrders = append(resp.PurchaseOrders, &models.PurchaseOrder{
+			Id:          Id.String,
+			IncrementID: IncrementID.String,
+			SupplierID:  SupplierID.String,
+			BranchID:    BranchID.String,
+			Status:      Status.String,
+			UserID:      UserID.String,
+			ClosedAt:    ClosedAt.String,
+			CreatedAt:   CreatedAt.String,
+			UpdatedAt:   UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Update changes an order without comings only. Nil lines keep the current
+// ones.
+func (r *purchaseOrderRepo) Update(ctx context.Context, req *models.UpdatePurchaseOrder) (int64, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE "purchase_order"
+			SET
+				"supplier_id" = $2,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND NOT EXISTS (SELECT 1 FROM "coming" WHERE "purchase_order_id" = $1)`,
+		req.Id,
+		req.SupplierID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, nil
+	}
+
+	if req.Lines != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM "purchase_order_line" WHERE "purchase_order_id" = $1`, req.Id); err != nil {
+			return 0, err
+		}
+
+		if err = insertPurchaseOrderLines(ctx, tx, req.Id, req.Lines); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *purchaseOrderRepo) Delete(ctx context.Context, req *models.PurchaseOrderPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "purchase_order" WHERE "id" = $1`, req.Id)
+	return err
+}
+
+// SetStatus changes the status only when it is still req.From, so of two
+// concurrent calls one gets zero rows affected.
+func (r *purchaseOrderRepo) SetStatus(ctx context.Context, req *models.PurchaseOrderStatus) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "purchase_order"
+			SET
+				"status" = $3,
+				"closed_at" = CASE WHEN $3 = 'closed' THEN NOW() END,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND "status" = $2`,
+		req.Id,
+		req.From,
+		req.To,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
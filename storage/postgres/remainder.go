@@ -3,18 +3,19 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"market_system/models"
+	"market_system/storage"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type remainderRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewRemainderRepo(db *pgxpool.Pool) *remainderRepo {
+func NewRemainderRepo(db DB) *remainderRepo {
 	return &remainderRepo{
 		db: db,
 	}
@@ -33,8 +34,10 @@ func (r *remainderRepo) Create(ctx context.Context, req *models.Remainder) (*mod
 		  "quantity",
 		  "sale_price",
 		  "coming_price",
+		  "lot_number",
+		  "expiry_date",
 		  "updated_at"
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, '')::DATE, NOW())`
 	)
 
 	_, err := r.db.Exec(ctx,
@@ -46,6 +49,8 @@ func (r *remainderRepo) Create(ctx context.Context, req *models.Remainder) (*mod
 		req.Quantity,
 		req.SalePrice,
 		req.ComingPrice,
+		req.LotNumber,
+		req.ExpiryDate,
 	)
 
 	if err != nil {
@@ -62,8 +67,13 @@ func (r *remainderRepo) GetByID(ctx context.Context, req *models.RemainderPrimar
 			SELECT
 				 "id",
 				 "product_id",
-				 "branch_id",
 				 "name",
+				 "quantity",
+				 "coming_price",
+				 "sale_price",
+				 "branch_id",
+				 "lot_number",
+				 TO_CHAR("expiry_date", 'YYYY-MM-DD'),
 				 "created_at",
 				 "updated_at"
 			FROM "remainder"
@@ -75,16 +85,26 @@ func (r *remainderRepo) GetByID(ctx context.Context, req *models.RemainderPrimar
 		Id          sql.NullString
 		ProductID   sql.NullString
 		ProductName sql.NullString
+		Quantity    sql.NullInt64
+		ComingPrice sql.NullFloat64
+		SalePrice   sql.NullFloat64
 		BranchID    sql.NullString
+		LotNumber   sql.NullString
+		ExpiryDate  sql.NullString
 		CreatedAt   sql.NullString
 		UpdatedAt   sql.NullString
 	)
 
 	err := r.db.QueryRow(ctx, query, req.Id).Scan(
 		&Id,
-		&BranchID,
 		&ProductID,
 		&ProductName,
+		&Quantity,
+		&ComingPrice,
+		&SalePrice,
+		&BranchID,
+		&LotNumber,
+		&ExpiryDate,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -94,27 +114,28 @@ func (r *remainderRepo) GetByID(ctx context.Context, req *models.RemainderPrimar
 	}
 
 	return &models.Remainder{
-		Id:        Id.String,
-		ProductID: ProductID.String,
-		BranchID:  BranchID.String,
-		Name:      ProductName.String,
-		CreatedAt: CreatedAt.String,
-		UpdatedAt: UpdatedAt.String,
+		Id:          Id.String,
+		ProductID:   ProductID.String,
+		Name:        ProductName.String,
+		Quantity:    int(Quantity.Int64),
+		ComingPrice: ComingPrice.Float64,
+		SalePrice:   SalePrice.Float64,
+		BranchID:    BranchID.String,
+		LotNumber:   LotNumber.String,
+		ExpiryDate:  ExpiryDate.String,
+		CreatedAt:   CreatedAt.String,
+		UpdatedAt:   UpdatedAt.String,
 	}, nil
 }
 
 func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainderRequest) (*models.GetListRemainderResponse, error) {
 	var (
-		resp  models.GetListRemainderResponse
-		where = " WHERE TRUE"
+		resp models.GetListRemainderResponse
 	)
 
-	if len(req.Search) > 0 {
-		where += " AND (title ILIKE '%" + req.Search + "%' OR branch_id ILIKE '%" + req.Search + "%')"
-	}
-
-	if len(req.Query) > 0 {
-		where += req.Query
+	where, args, err := whereClause("remainder", models.RemainderFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
 	}
 
 	var query = `
@@ -126,13 +147,24 @@ func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainde
 			"coming_price",
 			"sale_price",
 			"branch_id",
+			"lot_number",
+			TO_CHAR("expiry_date", 'YYYY-MM-DD'),
 			"created_at",
 			"updated_at"
 	  FROM "remainder"
 	`
 
-	query += where
-	rows, err := r.db.Query(ctx, query)
+	query += where + ` ORDER BY "created_at", "id"`
+
+	if req.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +179,8 @@ func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainde
 			PriceIncome sql.NullFloat64
 			PriceSales  sql.NullFloat64
 			BranchID    sql.NullString
+			LotNumber   sql.NullString
+			ExpiryDate  sql.NullString
 			CreatedAt   sql.NullString
 			UpdatedAt   sql.NullString
 		)
@@ -160,6 +194,8 @@ func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainde
 			&PriceIncome,
 			&PriceSales,
 			&BranchID,
+			&LotNumber,
+			&ExpiryDate,
 			&CreatedAt,
 			&UpdatedAt,
 		)
@@ -175,6 +211,8 @@ func (r *remainderRepo) GetList(ctx context.Context, req *models.GetListRemainde
 			Quantity:    int(Quantity.Int64),
 			ComingPrice: PriceIncome.Float64,
 			SalePrice:   PriceSales.Float64,
+			LotNumber:   LotNumber.String,
+			ExpiryDate:  ExpiryDate.String,
 			CreatedAt:   CreatedAt.String,
 			UpdatedAt:   UpdatedAt.String,
 		})
@@ -216,3 +254,253 @@ func (r *remainderRepo) Delete(ctx context.Context, req *models.RemainderPrimary
 	_, err := r.db.Exec(ctx, "DELETE FROM remainder WHERE id = $1", req.Id)
 	return err
 }
+
+// addQuantity is the projection side of the stock journal, see
+// stockMovementRepo.Create. It changes the lot req.LotNumber, the oldest
+// remainder without a lot when that is empty.
+func (r *remainderRepo) addQuantity(ctx context.Context, req *models.AddRemainder) error {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "remainder"
+			SET
+				"quantity" = "quantity" + $3,
+				"coming_price" = COALESCE(NULLIF($4::NUMERIC, 0), "coming_price"),
+				"sale_price" = COALESCE(NULLIF($5::NUMERIC, 0), "sale_price"),
+				"expiry_date" = COALESCE(NULLIF($7, '')::DATE, "expiry_date"),
+				"updated_at" = NOW()
+		WHERE "id" = (
+			SELECT "id" FROM "remainder"
+			WHERE "branch_id" = $1 AND "product_id" = $2 AND COALESCE("lot_number", '') = $6
+			ORDER BY "created_at"
+			LIMIT 1
+			FOR UPDATE
+		) AND "quantity" + $3 >= 0
+		AND (NOT $8 OR "expiry_date" IS NULL OR "expiry_date" >= CURRENT_DATE)`,
+		req.BranchID,
+		req.ProductID,
+		req.Quantity,
+		req.ComingPrice,
+		req.SalePrice,
+		req.LotNumber,
+		req.ExpiryDate,
+		req.Sellable,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	if req.Quantity < 0 {
+		return fmt.Errorf("%w: product %s", storage.ErrNotEnoughQuantity, req.ProductID)
+	}
+
+	// a new lot of a product already in stock keeps its name and prices
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO "remainder"(
+			"id",
+			"product_id",
+			"name",
+			"branch_id",
+			"quantity",
+			"sale_price",
+			"coming_price",
+			"lot_number",
+			"expiry_date",
+			"updated_at"
+		)
+		SELECT $1, $2, COALESCE(NULLIF($3, ''), r."name", ''), $4, $5,
+			COALESCE(NULLIF($6::NUMERIC, 0), r."sale_price", 0),
+			COALESCE(NULLIF($7::NUMERIC, 0), r."coming_price", 0),
+			NULLIF($8, ''), NULLIF($9, '')::DATE, NOW()
+		FROM (SELECT 1) AS one
+		LEFT JOIN (
+			SELECT "name", "sale_price", "coming_price" FROM "remainder"
+			WHERE "branch_id" = $4 AND "product_id" = $2
+			ORDER BY "created_at"
+			LIMIT 1
+		) AS r ON TRUE`,
+		uuid.New().String(),
+		req.ProductID,
+		req.Name,
+		req.BranchID,
+		req.Quantity,
+		req.SalePrice,
+		req.ComingPrice,
+		req.LotNumber,
+		req.ExpiryDate,
+	)
+	return err
+}
+
+// allocate takes -req.Quantity of a product out of its lots in the branch,
+// the earliest expiry first and lots without expiry last, and returns the
+// lots with the quantity taken from each. Expired lots are left alone when
+// req.Sellable.
+func (r *remainderRepo) allocate(ctx context.Context, req *models.AddRemainder) ([]*models.Remainder, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"quantity",
+			"sale_price",
+			"lot_number",
+			TO_CHAR("expiry_date", 'YYYY-MM-DD')
+		FROM "remainder"
+		WHERE "branch_id" = $1 AND "product_id" = $2 AND "quantity" > 0
+		AND (NOT $3 OR "expiry_date" IS NULL OR "expiry_date" >= CURRENT_DATE)
+		ORDER BY "expiry_date" NULLS LAST, "created_at"
+		FOR UPDATE`,
+		req.BranchID,
+		req.ProductID,
+		req.Sellable,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lots []*models.Remainder
+		left = -req.Quantity
+	)
+
+	for rows.Next() && left > 0 {
+		var (
+			Id         sql.NullString
+			Quantity   sql.NullInt64
+			SalePrice  sql.NullFloat64
+			LotNumber  sql.NullString
+			ExpiryDate sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&Quantity,
+			&SalePrice,
+			&LotNumber,
+			&ExpiryDate,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		taken := int(Quantity.Int64)
+		if taken > left {
+			taken = left
+		}
+		left -= taken
+
+		lots = append(lots, &models.Remainder{
+			Id:         Id.String,
+			BranchID:   req.BranchID,
+			ProductID:  req.ProductID,
+			Quantity:   taken,
+			SalePrice:  SalePrice.Float64,
+			LotNumber:  LotNumber.String,
+			ExpiryDate: ExpiryDate.String,
+		})
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if left > 0 {
+		return nil, fmt.Errorf("%w: product %s", storage.ErrNotEnoughQuantity, req.ProductID)
+	}
+
+	for _, lot := range lots {
+		_, err = r.db.Exec(ctx, `UPDATE "remainder" SET "quantity" = "quantity" - $2, "updated_at" = NOW() WHERE "id" = $1`, lot.Id, lot.Quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lots, nil
+}
+
+// Expiring lists the lots in stock expiring within req.Days, the earliest
+// expiry first.
+func (r *remainderRepo) Expiring(ctx context.Context, req *models.ExpiringRequest) (*models.ExpiringResponse, error) {
+
+	var (
+		resp  = models.ExpiringResponse{Days: req.Days}
+		args  = []interface{}{req.Days}
+		query = `
+			SELECT
+				"id",
+				"branch_id",
+				"product_id",
+				"name",
+				"lot_number",
+				TO_CHAR("expiry_date", 'YYYY-MM-DD'),
+				"expiry_date" - CURRENT_DATE,
+				"quantity",
+				"coming_price"
+			FROM "remainder"
+			WHERE "quantity" > 0 AND "expiry_date" <= CURRENT_DATE + $1::INT
+		`
+	)
+
+	if len(req.BranchID) > 0 {
+		args = append(args, req.BranchID)
+		query += fmt.Sprintf(` AND "branch_id" = $%d`, len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query+` ORDER BY "expiry_date", "name"`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id          sql.NullString
+			BranchID    sql.NullString
+			ProductID   sql.NullString
+			Name        sql.NullString
+			LotNumber   sql.NullString
+			ExpiryDate  sql.NullString
+			DaysLeft    sql.NullInt64
+			Quantity    sql.NullInt64
+			ComingPrice sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&Id,
+			&BranchID,
+			&ProductID,
+			&Name,
+			&LotNumber,
+			&ExpiryDate,
+			&DaysLeft,
+			&Quantity,
+			&ComingPrice,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Add(&models.ExpiringLot{
+			RemainderID: Id.String,
+			BranchID:    BranchID.String,
+			ProductID:   ProductID.String,
+			Name:        Name.String,
+			LotNumber:   LotNumber.String,
+			ExpiryDate:  ExpiryDate.String,
+			DaysLeft:    int(DaysLeft.Int64),
+			Quantity:    int(Quantity.Int64),
+			ComingPrice: ComingPrice.Float64,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type repricingRepo struct {
+	db DB
+}
+
+func NewRepricingRepo(db DB) *repricingRepo {
+	return &repricingRepo{
+		db: db,
+	}
+}
+
+// repricingColumns are read by scanRepricing.
+const repricingColumns = `
+	"repricing"."id",
+	"repricing"."increment_id",
+	"repricing"."branch_id",
+	"repricing"."category_id",
+	"repricing"."percent",
+	"repricing"."price",
+	"repricing"."effective_from",
+	"repricing"."comment",
+	"repricing"."user_id",
+	"repricing"."created_at"`
+
+func scanRepricing(row scanner, dest ...interface{}) (*models.Repricing, error) {
+
+	var (
+		Id            sql.NullString
+		IncrementID   sql.NullString
+		BranchID      sql.NullString
+		CategoryID    sql.NullString
+		Percent       sql.NullFloat64
+		Price         sql.NullFloat64
+		EffectiveFrom sql.NullString
+		Comment       sql.NullString
+		UserID        sql.NullString
+		CreatedAt     sql.NullString
+	)
+
+	err := row.Scan(append(dest,
+		&Id,
+		&IncrementID,
+		&BranchID,
+		&CategoryID,
+		&Percent,
+		&Price,
+		&EffectiveFrom,
+		&Comment,
+		&UserID,
+		&CreatedAt,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.Repricing{
+		Id:            Id.String,
+		IncrementID:   IncrementID.String,
+		BranchID:      BranchID.String,
+		CategoryID:    CategoryID.String,
+		EffectiveFrom: EffectiveFrom.String,
+		Comment:       Comment.String,
+		UserID:        UserID.String,
+		CreatedAt:     CreatedAt.String,
+	}
+	if Percent.Valid {
+		resp.Percent = &Percent.Float64
+	}
+	if Price.Valid {
+		resp.Price = &Price.Float64
+	}
+
+	return resp, nil
+}
+
+func (r *repricingRepo) Create(ctx context.Context, req *models.CreateRepricing) (*models.Repricing, error) {
+
+	var (
+		repricingId   = uuid.New().String()
+		effectiveFrom = time.Now()
+	)
+
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "repricing"(
+			"id",
+			"increment_id",
+			"branch_id",
+			"category_id",
+			"percent",
+			"price",
+			"effective_from",
+			"comment",
+			"user_id"
+		) VALUES ($1, $2, NULLIF($3, '')::UUID, NULLIF($4, '')::UUID, $5, $6, $7::TIMESTAMPTZ, $8, NULLIF($9, '')::UUID)`,
+		repricingId,
+		req.IncrementID,
+		req.BranchID,
+		req.CategoryID,
+		req.Percent,
+		req.Price,
+		effectiveFrom,
+		req.Comment,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+		line.DocumentID = repricingId
+		if err = insertPriceChange(ctx, tx, uuid.New().String(), line, effectiveFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = applyDue(ctx, tx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.RepricingPrimaryKey{Id: repricingId})
+}
+
+func (r *repricingRepo) GetByID(ctx context.Context, req *models.RepricingPrimaryKey) (*models.Repricing, error) {
+
+	resp, err := scanRepricing(r.db.QueryRow(ctx, `
+		SELECT`+repricingColumns+`
+		FROM "repricing"
+		WHERE "repricing"."id" = $1`,
+		req.Id,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT`+priceChangeColumns+`
+		FROM "price_history"
+		JOIN "product" ON "product"."id" = "price_history"."product_id"
+		WHERE "price_history"."document_id" = $1
+		ORDER BY "product"."name", "price_history"."product_id"`,
+		req.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Lines = append(resp.Lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *repricingRepo) GetList(ctx context.Context, req *models.GetListRepricingRequest) (*models.GetListRepricingResponse, error) {
+	var (
+		resp   models.GetListRepricingResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = ` ORDER BY "repricing"."created_at" DESC`
+	)
+
+	where, args, err := whereClause("repricing", models.RepricingFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),` + repricingColumns + `
+		FROM "repricing"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		repricing, err := scanRepricing(rows, &resp.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Repricings = append(resp.Repricings, repricing)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
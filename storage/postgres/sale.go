@@ -4,18 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 
 	"market_system/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type SaleRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewSaleRepo(db *pgxpool.Pool) *SaleRepo {
+func NewSaleRepo(db DB) *SaleRepo {
 	return &SaleRepo{
 		db: db,
 	}
@@ -63,6 +63,7 @@ func (r *SaleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*mo
 				 "client_id",
 				 "increment_id",
 				 "total_price",
+				 "returned",
 				 "paid",
 				 "debt",
 				 "created_at",
@@ -78,6 +79,7 @@ func (r *SaleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*mo
 		ClientID    sql.NullString
 		IncrementID sql.NullString
 		TotalPrice  sql.NullFloat64
+		Returned    sql.NullFloat64
 		Paid        sql.NullFloat64
 		Debd        sql.NullFloat64
 		CreatedAt   sql.NullString
@@ -90,6 +92,7 @@ func (r *SaleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*mo
 		&ClientID,
 		&IncrementID,
 		&TotalPrice,
+		&Returned,
 		&Paid,
 		&Debd,
 		&CreatedAt,
@@ -106,8 +109,9 @@ func (r *SaleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*mo
 		ClientID:    ClientID.String,
 		IncrementID: IncrementID.String,
 		TotalPrice:  TotalPrice.Float64,
+		Returned:    Returned.Float64,
 		Paid:        Paid.Float64,
-		Debd:        Paid.Float64,
+		Debd:        Debd.Float64,
 		CreatedAt:   CreatedAt.String,
 		UpdatedAt:   UpdatedAt.String,
 	}, nil
@@ -116,12 +120,16 @@ func (r *SaleRepo) GetByID(ctx context.Context, req *models.SalePrimaryKey) (*mo
 func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest) (*models.GetListSaleResponse, error) {
 	var (
 		resp   models.GetListSaleResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	where, args, err := whereClause("sale", models.SaleFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -130,14 +138,6 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND (branch_id ILIKE '%" + req.Search + "%' OR category_id ILIKE '%" + req.Search + "%' OR barcode ILIKE '%" + req.Search + "%' OR Sale_id ILIKE '%" + req.Search + "%')"
-	}
-
-	if len(req.Query) > 0 {
-		where += req.Query
-	}
-
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
@@ -146,6 +146,7 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 			"client_id",
 			"increment_id",
 			"total_price",
+			"returned",
 			"paid",
 			"debt",
 			"created_at",
@@ -154,7 +155,7 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -166,6 +167,7 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 			ClientID    sql.NullString
 			IncrementID sql.NullString
 			TotalPrice  sql.NullFloat64
+			Returned    sql.NullFloat64
 			Paid        sql.NullFloat64
 			Debd        sql.NullFloat64
 			CreatedAt   sql.NullString
@@ -179,6 +181,7 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 			&ClientID,
 			&IncrementID,
 			&TotalPrice,
+			&Returned,
 			&Paid,
 			&Debd,
 			&CreatedAt,
@@ -193,8 +196,9 @@ func (r *SaleRepo) GetList(ctx context.Context, req *models.GetListSaleRequest)
 			ClientID:    ClientID.String,
 			IncrementID: IncrementID.String,
 			TotalPrice:  TotalPrice.Float64,
+			Returned:    Returned.Float64,
 			Paid:        Paid.Float64,
-			Debd:        Paid.Float64,
+			Debd:        Debd.Float64,
 			CreatedAt:   CreatedAt.String,
 			UpdatedAt:   UpdatedAt.String,
 		})
@@ -212,19 +216,10 @@ func (r *SaleRepo) Update(ctx context.Context, req *models.UpdateSale) (int64, e
 				"client_id" = $3,
 				"increment_id" = $4,
 				"total_price" = $5,
-				"paid" = $6,
-				"debt" = $7,
+				"debt" = $5 - "returned" - "paid",
 				"updated_at" = NOW()
 		WHERE "id" = $1
 	`
-	// fmt.Println(req.Id,
-	// 	req.BranchID,
-	// 	req.ClientID,
-	// 	req.IncrementID,
-	// 	req.TotalPrice,
-	// 	req.Paid,
-	// 	req.Debd,
-	// 	req.TotalPrice,)
 	rowsAffected, err := r.db.Exec(ctx,
 		query,
 		req.Id,
@@ -232,8 +227,6 @@ func (r *SaleRepo) Update(ctx context.Context, req *models.UpdateSale) (int64, e
 		req.ClientID,
 		req.IncrementID,
 		req.TotalPrice,
-		req.Paid,
-		req.Debd,
 	)
 	if err != nil {
 		return 0, err
@@ -246,3 +239,222 @@ func (r *SaleRepo) Delete(ctx context.Context, req *models.SalePrimaryKey) error
 	_, err := r.db.Exec(ctx, "DELETE FROM sale WHERE id = $1", req.Id)
 	return err
 }
+
+func (r *SaleRepo) Checkout(ctx context.Context, req *models.CreateCheckout) (*models.Checkout, error) {
+
+	var (
+		saleId         = uuid.New().String()
+		totalPrice     float64
+		saleProductIds []string
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "sale"(
+			"id",
+			"branch_id",
+			"client_id",
+			"increment_id",
+			"total_price",
+			"paid",
+			"debt",
+			"updated_at"
+		) VALUES ($1, $2, $3, $4, 0, 0, 0, NOW())`,
+		saleId,
+		req.BranchID,
+		req.ClientID,
+		req.IncrementID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range req.Products {
+
+		var saleProductId = uuid.New().String()
+
+		// take the stock from the lots expiring first, expired ones are not sold
+		lots, err := NewRemainderRepo(tx).allocate(ctx, &models.AddRemainder{
+			BranchID:  req.BranchID,
+			ProductID: product.ProductID,
+			Quantity:  -product.BaseQuantity(),
+			Sellable:  true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		movement := &models.CreateStockMovement{
+			BranchID:       req.BranchID,
+			ProductID:      product.ProductID,
+			Quantity:       -product.BaseQuantity(),
+			Type:           models.MovementSale,
+			DocumentID:     saleId,
+			DocumentNumber: req.IncrementID,
+			UserID:         req.UserID,
+		}
+		if _, err = insertAllocated(ctx, tx, movement, lots); err != nil {
+			return nil, err
+		}
+
+		salePrice, unitPrice := product.Prices(lots[0].SalePrice)
+
+		var (
+			promotion, discount = product.Discount(unitPrice)
+			promotionId         string
+		)
+		if promotion != nil {
+			promotionId = promotion.Id
+		}
+
+		lineTotal := unitPrice*float64(product.Quantity) - discount
+		totalPrice += lineTotal
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "sale_product"(
+				"id",
+				"product_id",
+				"sale_id",
+				"sale_increment_id",
+				"quantity",
+				"price",
+				"total_price",
+				"unit",
+				"unit_quantity",
+				"unit_price",
+				"discount",
+				"promotion_id",
+				"updated_at"
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, NULLIF($12, '')::UUID, NOW())`,
+			saleProductId,
+			product.ProductID,
+			saleId,
+			req.IncrementID,
+			product.BaseQuantity(),
+			salePrice,
+			lineTotal,
+			product.Unit,
+			product.Quantity,
+			unitPrice,
+			discount,
+			promotionId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		saleProductIds = append(saleProductIds, saleProductId)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE "sale" SET "total_price" = $2, "debt" = $2 WHERE "id" = $1`, saleId, totalPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	sale, err := r.GetByID(ctx, &models.SalePrimaryKey{Id: saleId})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.Checkout{Sale: sale}
+	saleProductRepo := NewSaleProductRepo(r.db)
+	for _, id := range saleProductIds {
+		saleProduct, err := saleProductRepo.GetByID(ctx, &models.SaleProductPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		resp.SaleProducts = append(resp.SaleProducts, saleProduct)
+	}
+
+	return resp, nil
+}
+
+// DebtAging buckets the outstanding debt of every client by the age of the
+// sale it comes from, the largest debtors first.
+func (r *SaleRepo) DebtAging(ctx context.Context, req *models.DebtAgingRequest) (*models.DebtAgingResponse, error) {
+
+	var (
+		resp  models.DebtAgingResponse
+		args  = []interface{}{req.At}
+		query = `
+			SELECT
+				c."id",
+				c."first_name",
+				c."last_name",
+				c."phone",
+				DATE_PART('day', COALESCE($1::TIMESTAMP, LOCALTIMESTAMP) - s."created_at"),
+				s."debt"
+			FROM "sale" AS s
+			JOIN "client" AS c ON c."id" = s."client_id"
+			WHERE s."debt" > 0
+		`
+	)
+
+	if len(req.BranchID) > 0 {
+		args = append(args, req.BranchID)
+		query += fmt.Sprintf(` AND s."branch_id" = $%d`, len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients = map[string]*models.DebtAging{}
+
+	for rows.Next() {
+		var (
+			ClientID  sql.NullString
+			FirstName sql.NullString
+			LastName  sql.NullString
+			Phone     sql.NullString
+			Days      sql.NullFloat64
+			Debt      sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&ClientID,
+			&FirstName,
+			&LastName,
+			&Phone,
+			&Days,
+			&Debt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		client, ok := clients[ClientID.String]
+		if !ok {
+			client = &models.DebtAging{
+				ClientID:  ClientID.String,
+				FirstName: FirstName.String,
+				LastName:  LastName.String,
+				Phone:     Phone.String,
+			}
+			clients[ClientID.String] = client
+			resp.Clients = append(resp.Clients, client)
+		}
+
+		client.Add(int(Days.Float64), Debt.Float64)
+		resp.Total.Add(int(Days.Float64), Debt.Float64)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(resp.Clients, func(i, j int) bool { return resp.Clients[i].Total > resp.Clients[j].Total })
+
+	return &resp, nil
+}
@@ -7,18 +7,19 @@ import (
 	"fmt"
 
 	"market_system/models"
+	"market_system/pkg/apperror"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4"
 )
 
 
 
 type saleProductRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewSaleProductRepo(db *pgxpool.Pool) *saleProductRepo {
+func NewSaleProductRepo(db DB) *saleProductRepo {
 	return &saleProductRepo{
 		db: db,
 	}
@@ -39,7 +40,6 @@ func (r *saleProductRepo) Create(ctx context.Context, req *models.CreateSaleProd
 				"total_price"
 			) VALUES ($1,$2,$3,$4,$5,$6,$7)`
 
-		query1 = `SELECT quantity FROM remainder WHERE product_id = $1 AND branch_id = $2`
 		query2 = `SELECT price FROM product WHERE id = $1`
 		query3 = `UPDATE sale 
 				  SET total_price = sale.total_price + $1
@@ -47,27 +47,17 @@ func (r *saleProductRepo) Create(ctx context.Context, req *models.CreateSaleProd
 		`
 		query4 =`SELECT branch_id from sale where id = $1`
 		branchId sql.NullString
-		remaining sql.NullInt64
 		price sql.NullFloat64
 	)
 	
 	err := r.db.QueryRow(ctx,query4,req.SaleID).Scan(&branchId,)
-	if err == sql.ErrNoRows {
-        return nil, errors.New("no such product")
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.New(apperror.Validation, "no such sale").WithField("sale_id", "does not exist")
 
 	}
 
 	
 
-	err = r.db.QueryRow(ctx,query1,req.ProcutID,branchId.String).Scan(&remaining,)
-	if err!=nil{
-		return nil,errors.New("no such product")
-	}
-	
-	if remaining.Int64 < int64(req.Quantity){
-		return nil, errors.New("not enough quantity")
-	}
-	
 	err = r.db.QueryRow(ctx,query2,req.ProcutID).Scan(&price,)
 	if err!=nil{
 		return nil,err
@@ -95,12 +85,6 @@ func (r *saleProductRepo) Create(ctx context.Context, req *models.CreateSaleProd
 		return nil,err
 	}
 	fmt.Println("ok3")	
-	if remaining.Int64 > 0 || remaining.Int64 > int64(req.Quantity){
-	_,err = r.db.Exec(ctx,`UPDATE remainder SET quantity = quantity - $1 where product_id = $2 AND branch_id = $3`,req.Quantity,req.ProcutID,branchId)
-	if err!=nil{
-		return nil,err
-	}
-}
 	return r.GetByID(ctx, &models.SaleProductPrimaryKey{Id: saleProductId})
 }
 
@@ -116,6 +100,11 @@ func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPr
 				"quantity",
 				"price",
 				"total_price",
+				COALESCE("unit", ''),
+				COALESCE("unit_quantity", "quantity"),
+				COALESCE("unit_price", "price"),
+				"discount",
+				COALESCE("promotion_id"::TEXT, ''),
 				"created_at",
 				"updated_at"
 			FROM "sale_product"
@@ -131,6 +120,11 @@ func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPr
 		Quantity        sql.NullInt64
 		Price           sql.NullFloat64
 		TotalPrice      sql.NullFloat64
+		Unit            sql.NullString
+		UnitQuantity    sql.NullInt64
+		UnitPrice       sql.NullFloat64
+		Discount        sql.NullFloat64
+		PromotionID     sql.NullString
 		CreatedAt       sql.NullString
 		UpdatedAt       sql.NullString
 	)
@@ -143,6 +137,11 @@ func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPr
 		&Quantity,
 		&Price,
 		&TotalPrice,
+		&Unit,
+		&UnitQuantity,
+		&UnitPrice,
+		&Discount,
+		&PromotionID,
 		&CreatedAt,
 		&UpdatedAt,
 	)
@@ -159,6 +158,11 @@ func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPr
 		Quantity:        int(Quantity.Int64),
 		Price:           Price.Float64,
 		TotalPrice:      TotalPrice.Float64,
+		Unit:            Unit.String,
+		UnitQuantity:    int(UnitQuantity.Int64),
+		UnitPrice:       UnitPrice.Float64,
+		Discount:        Discount.Float64,
+		PromotionID:     PromotionID.String,
 		CreatedAt:       CreatedAt.String,
 		UpdatedAt:       UpdatedAt.String,
 	}, nil
@@ -167,12 +171,19 @@ func (r *saleProductRepo) GetByID(ctx context.Context, req *models.SaleProductPr
 func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSaleProductRequest) (*models.GetListSaleProductResponse, error) {
 	var (
 		resp   models.GetListSaleProductResponse
-		where  = " WHERE TRUE"
 		offset = " OFFSET 0"
 		limit  = " LIMIT 10"
 		sort   = " ORDER BY created_at DESC"
 	)
 
+	filter, branchIds := req.Filter.Without("branch_id")
+
+	where, args, err := whereClause("sale_product", models.SaleProductFilterSpec, req.Search, filter)
+	if err != nil {
+		return nil, err
+	}
+	where, args = inBranches(where, args, "sale_product", "sale_id", "sale", branchIds)
+
 	if req.Offset > 0 {
 		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
 	}
@@ -181,14 +192,6 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
 
-	if len(req.Search) > 0 {
-		where += " AND (branch_id ILIKE '%" + req.Search + "%' OR category_id ILIKE '%" + req.Search + "%' OR barcode ILIKE '%" + req.Search + "%' OR Sale_id ILIKE '%" + req.Search + "%')"
-	}
-
-	if len(req.Query) > 0 {
-		where += req.Query
-	}
-
 	var query = `
 		SELECT
 			COUNT(*) OVER(),
@@ -199,13 +202,18 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 			"quantity",
 			"price",
 			"total_price",
+			COALESCE("unit", ''),
+			COALESCE("unit_quantity", "quantity"),
+			COALESCE("unit_price", "price"),
+			"discount",
+			COALESCE("promotion_id"::TEXT, ''),
 			"created_at",
 			"updated_at"
 		FROM "sale_product"
 	`
 
 	query += where + sort + offset + limit
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -219,11 +227,17 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 			Quantity        sql.NullInt64
 			Price           sql.NullFloat64
 			TotalPrice      sql.NullFloat64
+			Unit            sql.NullString
+			UnitQuantity    sql.NullInt64
+			UnitPrice       sql.NullFloat64
+			Discount        sql.NullFloat64
+			PromotionID     sql.NullString
 			CreatedAt       sql.NullString
 			UpdatedAt       sql.NullString
 		)
 
 		err = rows.Scan(
+			&resp.Count,
 			&Id,
 			&ProcutID,
 			&SaleID,
@@ -231,6 +245,11 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 			&Quantity,
 			&Price,
 			&TotalPrice,
+			&Unit,
+			&UnitQuantity,
+			&UnitPrice,
+			&Discount,
+			&PromotionID,
 			&CreatedAt,
 			&UpdatedAt,
 		)
@@ -245,6 +264,11 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 			Quantity:        int(Quantity.Int64),
 			Price:           Price.Float64,
 			TotalPrice:      TotalPrice.Float64,
+			Unit:            Unit.String,
+			UnitQuantity:    int(UnitQuantity.Int64),
+			UnitPrice:       UnitPrice.Float64,
+			Discount:        Discount.Float64,
+			PromotionID:     PromotionID.String,
 			CreatedAt:       CreatedAt.String,
 			UpdatedAt:       UpdatedAt.String,
 		})
@@ -256,7 +280,7 @@ func (r *saleProductRepo) GetList(ctx context.Context, req *models.GetListSalePr
 func (r *saleProductRepo) Update(ctx context.Context, req *models.UpdateSaleProduct) (int64, error) {
 
 	query := `
-		UPDATE "sale"
+		UPDATE "sale_product"
 			SET
 				"product_id" = $2,
 				"sale_id" = $3,
@@ -264,6 +288,11 @@ func (r *saleProductRepo) Update(ctx context.Context, req *models.UpdateSaleProd
 				"quantity" = $5,
 				"price" = $6,
 				"total_price" = $7,
+				"unit" = NULL,
+				"unit_quantity" = NULL,
+				"unit_price" = NULL,
+				"discount" = 0,
+				"promotion_id" = NULL,
 				"updated_at" = NOW()
 		WHERE "id" = $1
 	`
@@ -276,7 +305,6 @@ func (r *saleProductRepo) Update(ctx context.Context, req *models.UpdateSaleProd
 		req.Quantity,
 		req.Price,
 		float64(req.Quantity)*req.Price,
-		req.TotalPrice,
 	)
 	if err != nil {
 		return 0, err
@@ -0,0 +1,473 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+)
+
+type saleReturnRepo struct {
+	db DB
+}
+
+func NewSaleReturnRepo(db DB) *saleReturnRepo {
+	return &saleReturnRepo{
+		db: db,
+	}
+}
+
+func (r *saleReturnRepo) Create(ctx context.Context, req *models.CreateSaleReturn) (*models.SaleReturn, error) {
+
+	var (
+		saleReturnId = uuid.New().String()
+		BranchID     sql.NullString
+		ClientID     sql.NullString
+		Debt         sql.NullFloat64
+		totalPrice   float64
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// returns and payments of one sale are applied one after another
+	err = tx.QueryRow(ctx, `SELECT "branch_id", "client_id", "debt" FROM "sale" WHERE "id" = $1 FOR UPDATE`, req.SaleID).Scan(&BranchID, &ClientID, &Debt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "sale_return"(
+			"id",
+			"increment_id",
+			"sale_id",
+			"branch_id",
+			"client_id",
+			"total_price",
+			"reason",
+			"user_id"
+		) VALUES ($1, $2, $3, $4, $5, 0, $6, NULLIF($7, '')::UUID)`,
+		saleReturnId,
+		req.IncrementID,
+		req.SaleID,
+		BranchID,
+		ClientID,
+		req.Reason,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+
+		var (
+			ProductID sql.NullString
+			Sold      sql.NullInt64
+			Price     sql.NullFloat64
+			returned  int
+		)
+
+		err = tx.QueryRow(ctx, `
+			SELECT "product_id", "quantity", "price" - COALESCE("discount" / NULLIF("quantity", 0), 0)
+			FROM "sale_product"
+			WHERE "id" = $1 AND "sale_id" = $2`,
+			line.SaleProductID,
+			req.SaleID,
+		).Scan(&ProductID, &Sold, &Price)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.QueryRow(ctx, `SELECT COALESCE(SUM("quantity"), 0) FROM "sale_return_line" WHERE "sale_product_id" = $1`, line.SaleProductID).Scan(&returned)
+		if err != nil {
+			return nil, err
+		}
+
+		if returned+line.Quantity > int(Sold.Int64) {
+			return nil, fmt.Errorf("%w: line %s", storage.ErrOverReturn, line.SaleProductID)
+		}
+
+		lineTotal := Price.Float64 * float64(line.Quantity)
+		totalPrice += lineTotal
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "sale_return_line"(
+				"id",
+				"sale_return_id",
+				"sale_product_id",
+				"product_id",
+				"quantity",
+				"price",
+				"total_price",
+				"created_at"
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, CLOCK_TIMESTAMP())`,
+			uuid.New().String(),
+			saleReturnId,
+			line.SaleProductID,
+			ProductID,
+			line.Quantity,
+			Price.Float64,
+			lineTotal,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		debtReduced     = math.Min(totalPrice, math.Max(Debt.Float64, 0))
+		refund          = totalPrice - debtReduced
+		refundPaymentId sql.NullString
+	)
+
+	if refund > 0 {
+		refundPaymentId = sql.NullString{String: uuid.New().String(), Valid: true}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "payment"(
+				"id",
+				"sale_id",
+				"amount",
+				"method",
+				"cashier"
+			) VALUES ($1, $2, $3, $4, '')`,
+			refundPaymentId,
+			req.SaleID,
+			-refund,
+			req.Method,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "sale_return"
+			SET
+				"total_price" = $2,
+				"debt_reduced" = $3,
+				"refund" = $4,
+				"refund_payment_id" = $5
+		WHERE "id" = $1`,
+		saleReturnId,
+		totalPrice,
+		debtReduced,
+		refund,
+		refundPaymentId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "sale"
+			SET
+				"returned" = "returned" + $2,
+				"paid" = "paid" - $3,
+				"debt" = "debt" - $4,
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.SaleID,
+		totalPrice,
+		refund,
+		debtReduced,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.SaleReturnPrimaryKey{Id: saleReturnId})
+}
+
+func (r *saleReturnRepo) GetByID(ctx context.Context, req *models.SaleReturnPrimaryKey) (*models.SaleReturn, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"increment_id",
+				"sale_id",
+				"branch_id",
+				"client_id",
+				(SELECT COALESCE(SUM(l."quantity"), 0) FROM "sale_return_line" AS l WHERE l."sale_return_id" = "sale_return"."id"),
+				"total_price",
+				"debt_reduced",
+				"refund",
+				"refund_payment_id",
+				"reason",
+				"user_id",
+				"created_at"
+			FROM "sale_return"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id              sql.NullString
+		IncrementID     sql.NullString
+		SaleID          sql.NullString
+		BranchID        sql.NullString
+		ClientID        sql.NullString
+		Quantity        sql.NullInt64
+		TotalPrice      sql.NullFloat64
+		DebtReduced     sql.NullFloat64
+		Refund          sql.NullFloat64
+		RefundPaymentID sql.NullString
+		Reason          sql.NullString
+		UserID          sql.NullString
+		CreatedAt       sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&IncrementID,
+		&SaleID,
+		&BranchID,
+		&ClientID,
+		&Quantity,
+		&TotalPrice,
+		&DebtReduced,
+		&Refund,
+		&RefundPaymentID,
+		&Reason,
+		&UserID,
+		&CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	saleReturn := &models.SaleReturn{
+		Id:              Id.String,
+		IncrementID:     IncrementID.String,
+		SaleID:          SaleID.String,
+		BranchID:        BranchID.String,
+		ClientID:        ClientID.String,
+		Quantity:        int(Quantity.Int64),
+		TotalPrice:      TotalPrice.Float64,
+		DebtReduced:     DebtReduced.Float64,
+		Refund:          Refund.Float64,
+		RefundPaymentID: RefundPaymentID.String,
+		Reason:          Reason.String,
+		UserID:          UserID.String,
+		CreatedAt:       CreatedAt.String,
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"sale_return_id",
+			"sale_product_id",
+			"product_id",
+			"quantity",
+			"price",
+			"total_price",
+			"created_at"
+		FROM "sale_return_line"
+		WHERE "sale_return_id" = $1
+		ORDER BY "created_at"`,
+		saleReturn.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id            sql.NullString
+			SaleReturnID  sql.NullString
+			SaleProductID sql.NullString
+			ProductID     sql.NullString
+			Quantity      sql.NullInt64
+			Price         sql.NullFloat64
+			TotalPrice    sql.NullFloat64
+			CreatedAt     sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&SaleReturnID,
+			&SaleProductID,
+			&ProductID,
+			&Quantity,
+			&Price,
+			&TotalPrice,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		saleReturn.Lines = append(saleReturn.Lines, &models.SaleReturnLine{
+			Id:            Id.String,
+			SaleReturnID:  SaleReturnID.String,
+			SaleProductID: SaleProductID.String,
+			ProductID:     ProductID.String,
+			Quantity:      int(Quantity.Int64),
+			Price:         Price.Float64,
+			TotalPrice:    TotalPrice.Float64,
+			CreatedAt:     CreatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return saleReturn, nil
+}
+
+func (r *saleReturnRepo) GetList(ctx context.Context, req *models.GetListSaleReturnRequest) (*models.GetListSaleReturnResponse, error) {
+	var (
+		resp   models.GetListSaleReturnResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("sale_return", models.SaleReturnFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"increment_id",
+			"sale_id",
+			"branch_id",
+			"client_id",
+			(SELECT COALESCE(SUM(l."quantity"), 0) FROM "sale_return_line" AS l WHERE l."sale_return_id" = "sale_return"."id"),
+			"total_price",
+			"debt_reduced",
+			"refund",
+			"refund_payment_id",
+			"reason",
+			"user_id",
+			"created_at"
+		FROM "sale_return"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id              sql.NullString
+			IncrementID     sql.NullString
+			SaleID          sql.NullString
+			BranchID        sql.NullString
+			ClientID        sql.NullString
+			Quantity        sql.NullInt64
+			TotalPrice      sql.NullFloat64
+			DebtReduced     sql.NullFloat64
+			Refund          sql.NullFloat64
+			RefundPaymentID sql.NullString
+			Reason          sql.NullString
+			UserID          sql.NullString
+			CreatedAt       sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&IncrementID,
+			&SaleID,
+			&BranchID,
+			&ClientID,
+			&Quantity,
+			&TotalPrice,
+			&DebtReduced,
+			&Refund,
+			&RefundPaymentID,
+			&Reason,
+			&UserID,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.SaleReturns = append(resp.SaleReturns, &models.SaleReturn{
+			Id:              Id.String,
+			IncrementID:     IncrementID.String,
+			SaleID:          SaleID.String,
+			BranchID:        BranchID.String,
+			ClientID:        ClientID.String,
+			Quantity:        int(Quantity.Int64),
+			TotalPrice:      TotalPrice.Float64,
+			DebtReduced:     DebtReduced.Float64,
+			Refund:          Refund.Float64,
+			RefundPaymentID: RefundPaymentID.String,
+			Reason:          Reason.String,
+			UserID:          UserID.String,
+			CreatedAt:       CreatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *saleReturnRepo) Returned(ctx context.Context, req *models.SalePrimaryKey) (map[string]int, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT l."sale_product_id", SUM(l."quantity")
+		FROM "sale_return_line" AS l
+		JOIN "sale_return" AS sr ON sr."id" = l."sale_return_id"
+		WHERE sr."sale_id" = $1
+		GROUP BY l."sale_product_id"`,
+		req.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp = map[string]int{}
+	for rows.Next() {
+		var (
+			SaleProductID sql.NullString
+			Quantity      sql.NullInt64
+		)
+
+		if err = rows.Scan(&SaleProductID, &Quantity); err != nil {
+			return nil, err
+		}
+
+		resp[SaleProductID.String] = int(Quantity.Int64)
+	}
+
+	return resp, rows.Err()
+}
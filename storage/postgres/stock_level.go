@@ -0,0 +1,361 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+)
+
+type stockLevelRepo struct {
+	db DB
+}
+
+func NewStockLevelRepo(db DB) *stockLevelRepo {
+	return &stockLevelRepo{
+		db: db,
+	}
+}
+
+// stockQuantity sums the lots of every product in every branch.
+const stockQuantity = `
+	SELECT "branch_id", "product_id", SUM("quantity") AS "quantity"
+	FROM "remainder"
+	GROUP BY "branch_id", "product_id"`
+
+func (r *stockLevelRepo) Set(ctx context.Context, req *models.SetStockLevel) (*models.StockLevel, error) {
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "stock_level"(
+			"branch_id",
+			"product_id",
+			"min_quantity",
+			"max_quantity",
+			"updated_at"
+		) VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT ("branch_id", "product_id") DO UPDATE
+			SET
+				"min_quantity" = EXCLUDED."min_quantity",
+				"max_quantity" = EXCLUDED."max_quantity",
+				"updated_at" = NOW()`,
+		req.BranchID,
+		req.ProductID,
+		req.MinQuantity,
+		req.MaxQuantity,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.StockLevelPrimaryKey{BranchID: req.BranchID, ProductID: req.ProductID})
+}
+
+func (r *stockLevelRepo) GetByID(ctx context.Context, req *models.StockLevelPrimaryKey) (*models.StockLevel, error) {
+
+	var (
+		BranchID    sql.NullString
+		ProductID   sql.NullString
+		MinQuantity sql.NullInt64
+		MaxQuantity sql.NullInt64
+		CreatedAt   sql.NullString
+		UpdatedAt   sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			"branch_id",
+			"product_id",
+			"min_quantity",
+			"max_quantity",
+			"created_at",
+			"updated_at"
+		FROM "stock_level"
+		WHERE "branch_id" = $1 AND "product_id" = $2`,
+		req.BranchID,
+		req.ProductID,
+	).Scan(
+		&BranchID,
+		&ProductID,
+		&MinQuantity,
+		&MaxQuantity,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StockLevel{
+		BranchID:    BranchID.String,
+		ProductID:   ProductID.String,
+		MinQuantity: int(MinQuantity.Int64),
+		MaxQuantity: int(MaxQuantity.Int64),
+		CreatedAt:   CreatedAt.String,
+		UpdatedAt:   UpdatedAt.String,
+	}, nil
+}
+
+func (r *stockLevelRepo) GetList(ctx context.Context, req *models.GetListStockLevelRequest) (*models.GetListStockLevelResponse, error) {
+	var (
+		resp   models.GetListStockLevelResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("stock_level", models.StockLevelFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"branch_id",
+			"product_id",
+			"min_quantity",
+			"max_quantity",
+			"created_at",
+			"updated_at"
+		FROM "stock_level"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			BranchID    sql.NullString
+			ProductID   sql.NullString
+			MinQuantity sql.NullInt64
+			MaxQuantity sql.NullInt64
+			CreatedAt   sql.NullString
+			UpdatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&BranchID,
+			&ProductID,
+			&MinQuantity,
+			&MaxQuantity,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.StockLevels = append(resp.StockLevels, &models.StockLevel{
+			BranchID:    BranchID.String,
+			ProductID:   ProductID.String,
+			MinQuantity: int(MinQuantity.Int64),
+			MaxQuantity: int(MaxQuantity.Int64),
+			CreatedAt:   CreatedAt.String,
+			UpdatedAt:   UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *stockLevelRepo) Delete(ctx context.Context, req *models.StockLevelPrimaryKey) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM "stock_level" WHERE "branch_id" = $1 AND "product_id" = $2`,
+		req.BranchID,
+		req.ProductID,
+	)
+	return err
+}
+
+// Low lists the products whose lots together are below their minimum, the
+// largest shortage first.
+func (r *stockLevelRepo) Low(ctx context.Context, req *models.LowStockRequest) (*models.LowStockResponse, error) {
+
+	var (
+		resp  models.LowStockResponse
+		args  []interface{}
+		query = `
+			SELECT
+				"l"."branch_id",
+				"l"."product_id",
+				"p"."name",
+				COALESCE("s"."quantity", 0),
+				"l"."min_quantity",
+				"l"."max_quantity"
+			FROM "stock_level" AS "l"
+			JOIN "product" AS "p" ON "p"."id" = "l"."product_id"
+			LEFT JOIN (` + stockQuantity + `) AS "s"
+				ON "s"."branch_id" = "l"."branch_id" AND "s"."product_id" = "l"."product_id"
+			WHERE COALESCE("s"."quantity", 0) < "l"."min_quantity"
+		`
+	)
+
+	if len(req.BranchID) > 0 {
+		args = append(args, req.BranchID)
+		query += fmt.Sprintf(` AND "l"."branch_id" = $%d`, len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query+` ORDER BY "l"."min_quantity" - COALESCE("s"."quantity", 0) DESC, "p"."name"`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			BranchID    sql.NullString
+			ProductID   sql.NullString
+			Name        sql.NullString
+			Quantity    sql.NullInt64
+			MinQuantity sql.NullInt64
+			MaxQuantity sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&BranchID,
+			&ProductID,
+			&Name,
+			&Quantity,
+			&MinQuantity,
+			&MaxQuantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Products = append(resp.Products, &models.LowStock{
+			BranchID:    BranchID.String,
+			ProductID:   ProductID.String,
+			Name:        Name.String,
+			Quantity:    int(Quantity.Int64),
+			MinQuantity: int(MinQuantity.Int64),
+			MaxQuantity: int(MaxQuantity.Int64),
+			Shortage:    int(MinQuantity.Int64 - Quantity.Int64),
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Reorder reads the stock, the sales of the last req.WindowDays and the
+// coming price of the newest lot of every product with a stock level in
+// the branch, and keeps those ReorderResponse.Propose finds short.
+func (r *stockLevelRepo) Reorder(ctx context.Context, req *models.ReorderRequest) (*models.ReorderResponse, error) {
+
+	var (
+		resp = models.ReorderResponse{
+			BranchID:   req.BranchID,
+			WindowDays: req.WindowDays,
+			LeadDays:   req.LeadDays,
+		}
+		args  = []interface{}{req.BranchID, req.WindowDays}
+		query = `
+			SELECT
+				"l"."product_id",
+				"p"."name",
+				COALESCE("s"."quantity", 0),
+				"l"."min_quantity",
+				"l"."max_quantity",
+				GREATEST(COALESCE("sold"."quantity", 0) - COALESCE("returned"."quantity", 0), 0),
+				COALESCE((
+					SELECT "coming_price" FROM "remainder"
+					WHERE "branch_id" = "l"."branch_id" AND "product_id" = "l"."product_id"
+					ORDER BY "created_at" DESC
+					LIMIT 1
+				), 0)
+			FROM "stock_level" AS "l"
+			JOIN "product" AS "p" ON "p"."id" = "l"."product_id"
+			LEFT JOIN (` + stockQuantity + `) AS "s"
+				ON "s"."branch_id" = "l"."branch_id" AND "s"."product_id" = "l"."product_id"
+			LEFT JOIN (
+				SELECT "sp"."product_id", SUM("sp"."quantity") AS "quantity"
+				FROM "sale_product" AS "sp"
+				JOIN "sale" ON "sale"."id" = "sp"."sale_id"
+				WHERE "sale"."branch_id" = $1 AND "sp"."created_at" >= NOW() - MAKE_INTERVAL(days => $2::INT)
+				GROUP BY "sp"."product_id"
+			) AS "sold" ON "sold"."product_id" = "l"."product_id"
+			LEFT JOIN (
+				SELECT "rl"."product_id", SUM("rl"."quantity") AS "quantity"
+				FROM "sale_return_line" AS "rl"
+				JOIN "sale_return" ON "sale_return"."id" = "rl"."sale_return_id"
+				WHERE "sale_return"."branch_id" = $1 AND "rl"."created_at" >= NOW() - MAKE_INTERVAL(days => $2::INT)
+				GROUP BY "rl"."product_id"
+			) AS "returned" ON "returned"."product_id" = "l"."product_id"
+			WHERE "l"."branch_id" = $1
+		`
+	)
+
+	if len(req.ProductIDs) > 0 {
+		args = append(args, req.ProductIDs)
+		query += fmt.Sprintf(` AND "l"."product_id" = ANY($%d)`, len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query+` ORDER BY "p"."name"`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ProductID   sql.NullString
+			Name        sql.NullString
+			Quantity    sql.NullInt64
+			MinQuantity sql.NullInt64
+			MaxQuantity sql.NullInt64
+			Sold        sql.NullInt64
+			Price       sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&ProductID,
+			&Name,
+			&Quantity,
+			&MinQuantity,
+			&MaxQuantity,
+			&Sold,
+			&Price,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Propose(&models.ReorderLine{
+			ProductID:   ProductID.String,
+			Name:        Name.String,
+			Quantity:    int(Quantity.Int64),
+			MinQuantity: int(MinQuantity.Int64),
+			MaxQuantity: int(MaxQuantity.Int64),
+			Sold:        int(Sold.Int64),
+			Price:       Price.Float64,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
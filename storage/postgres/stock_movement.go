@@ -0,0 +1,461 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type stockMovementRepo struct {
+	db DB
+}
+
+func NewStockMovementRepo(db DB) *stockMovementRepo {
+	return &stockMovementRepo{
+		db: db,
+	}
+}
+
+// Create writes the movement and applies it to remainder in one transaction.
+// An outgoing movement without a lot is split per lot, the first line is
+// returned.
+func (r *stockMovementRepo) Create(ctx context.Context, req *models.CreateStockMovement) (*models.StockMovement, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	movementIds, err := postStockMovement(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.StockMovementPrimaryKey{Id: movementIds[0]})
+}
+
+// postStockMovement applies req to remainder and writes its journal lines.
+func postStockMovement(ctx context.Context, db DB, req *models.CreateStockMovement) ([]string, error) {
+
+	var add = &models.AddRemainder{
+		BranchID:    req.BranchID,
+		ProductID:   req.ProductID,
+		Name:        req.Name,
+		Quantity:    req.Quantity,
+		ComingPrice: req.ComingPrice,
+		SalePrice:   req.SalePrice,
+		LotNumber:   req.LotNumber,
+		ExpiryDate:  req.ExpiryDate,
+		Sellable:    req.Type == models.MovementSale,
+	}
+
+	if req.Quantity < 0 && len(req.LotNumber) == 0 {
+		lots, err := NewRemainderRepo(db).allocate(ctx, add)
+		if err != nil {
+			return nil, err
+		}
+
+		return insertAllocated(ctx, db, req, lots)
+	}
+
+	if err := NewRemainderRepo(db).addQuantity(ctx, add); err != nil {
+		return nil, err
+	}
+
+	var movementId = uuid.New().String()
+	if err := insertStockMovement(ctx, db, movementId, req); err != nil {
+		return nil, err
+	}
+
+	return []string{movementId}, nil
+}
+
+// insertAllocated writes a journal line of req for each lot it was taken from.
+func insertAllocated(ctx context.Context, db DB, req *models.CreateStockMovement, lots []*models.Remainder) ([]string, error) {
+
+	var movementIds []string
+
+	for _, lot := range lots {
+		var (
+			movementId = uuid.New().String()
+			line       = *req
+		)
+
+		line.Quantity = -lot.Quantity
+		line.LotNumber = lot.LotNumber
+		line.ExpiryDate = lot.ExpiryDate
+
+		if err := insertStockMovement(ctx, db, movementId, &line); err != nil {
+			return nil, err
+		}
+		movementIds = append(movementIds, movementId)
+	}
+
+	return movementIds, nil
+}
+
+// insertStockMovement only writes the journal line, the caller has already
+// changed remainder.
+func insertStockMovement(ctx context.Context, db DB, id string, req *models.CreateStockMovement) error {
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO "stock_movement"(
+			"id",
+			"branch_id",
+			"product_id",
+			"quantity",
+			"type",
+			"document_id",
+			"document_number",
+			"user_id",
+			"comment",
+			"lot_number",
+			"expiry_date"
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::UUID, $7, NULLIF($8, '')::UUID, $9, NULLIF($10, ''), NULLIF($11, '')::DATE)`,
+		id,
+		req.BranchID,
+		req.ProductID,
+		req.Quantity,
+		req.Type,
+		req.DocumentID,
+		req.DocumentNumber,
+		req.UserID,
+		req.Comment,
+		req.LotNumber,
+		req.ExpiryDate,
+	)
+	return err
+}
+
+func (r *stockMovementRepo) GetByID(ctx context.Context, req *models.StockMovementPrimaryKey) (*models.StockMovement, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"branch_id",
+				"product_id",
+				"quantity",
+				"type",
+				"document_id",
+				"document_number",
+				"user_id",
+				"comment",
+				"lot_number",
+				TO_CHAR("expiry_date", 'YYYY-MM-DD'),
+				"created_at"
+			FROM "stock_movement"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id             sql.NullString
+		BranchID       sql.NullString
+		ProductID      sql.NullString
+		Quantity       sql.NullInt64
+		Type           sql.NullString
+		DocumentID     sql.NullString
+		DocumentNumber sql.NullString
+		UserID         sql.NullString
+		Comment        sql.NullString
+		LotNumber      sql.NullString
+		ExpiryDate     sql.NullString
+		CreatedAt      sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&BranchID,
+		&ProductID,
+		&Quantity,
+		&Type,
+		&DocumentID,
+		&DocumentNumber,
+		&UserID,
+		&Comment,
+		&LotNumber,
+		&ExpiryDate,
+		&CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StockMovement{
+		Id:             Id.String,
+		BranchID:       BranchID.String,
+		ProductID:      ProductID.String,
+		Quantity:       int(Quantity.Int64),
+		Type:           Type.String,
+		DocumentID:     DocumentID.String,
+		DocumentNumber: DocumentNumber.String,
+		UserID:         UserID.String,
+		Comment:        Comment.String,
+		LotNumber:      LotNumber.String,
+		ExpiryDate:     ExpiryDate.String,
+		CreatedAt:      CreatedAt.String,
+	}, nil
+}
+
+func (r *stockMovementRepo) GetList(ctx context.Context, req *models.GetListStockMovementRequest) (*models.GetListStockMovementResponse, error) {
+	var (
+		resp   models.GetListStockMovementResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("stock_movement", models.StockMovementFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"branch_id",
+			"product_id",
+			"quantity",
+			"type",
+			"document_id",
+			"document_number",
+			"user_id",
+			"comment",
+			"lot_number",
+			TO_CHAR("expiry_date", 'YYYY-MM-DD'),
+			"created_at"
+		FROM "stock_movement"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id             sql.NullString
+			BranchID       sql.NullString
+			ProductID      sql.NullString
+			Quantity       sql.NullInt64
+			Type           sql.NullString
+			DocumentID     sql.NullString
+			DocumentNumber sql.NullString
+			UserID         sql.NullString
+			Comment        sql.NullString
+			LotNumber      sql.NullString
+			ExpiryDate     sql.NullString
+			CreatedAt      sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&BranchID,
+			&ProductID,
+			&Quantity,
+			&Type,
+			&DocumentID,
+			&DocumentNumber,
+			&UserID,
+			&Comment,
+			&LotNumber,
+			&ExpiryDate,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.StockMovements = append(resp.StockMovements, &models.StockMovement{
+			Id:             Id.String,
+			BranchID:       BranchID.String,
+			ProductID:      ProductID.String,
+			Quantity:       int(Quantity.Int64),
+			Type:           Type.String,
+			DocumentID:     DocumentID.String,
+			DocumentNumber: DocumentNumber.String,
+			UserID:         UserID.String,
+			Comment:        Comment.String,
+			LotNumber:      LotNumber.String,
+			ExpiryDate:     ExpiryDate.String,
+			CreatedAt:      CreatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// RebuildRemainder compares remainder with the sum of the journal for every
+// lot of every product of the branch, or of all branches, and unless DryRun
+// sets the oldest remainder row of the lot to the journal sum and empties the
+// others.
+func (r *stockMovementRepo) RebuildRemainder(ctx context.Context, req *models.RebuildRemainderRequest) (*models.RebuildRemainderResponse, error) {
+
+	var resp = models.RebuildRemainderResponse{DryRun: req.DryRun}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH "journal" AS (
+			SELECT "branch_id", "product_id", COALESCE("lot_number", '') AS "lot_number", SUM("quantity") AS "quantity"
+			FROM "stock_movement"
+			WHERE $1 = '' OR "branch_id" = NULLIF($1, '')::UUID
+			GROUP BY 1, 2, 3
+		), "stock" AS (
+			SELECT "branch_id", "product_id", COALESCE("lot_number", '') AS "lot_number", SUM(COALESCE("quantity", 0)) AS "quantity"
+			FROM "remainder"
+			WHERE $1 = '' OR "branch_id" = NULLIF($1, '')::UUID
+			GROUP BY 1, 2, 3
+		)
+		SELECT
+			COALESCE(j."branch_id", s."branch_id"),
+			COALESCE(j."product_id", s."product_id"),
+			COALESCE(j."lot_number", s."lot_number"),
+			COALESCE(s."quantity", 0),
+			COALESCE(j."quantity", 0)
+		FROM "journal" AS j
+		FULL JOIN "stock" AS s
+			ON s."branch_id" = j."branch_id" AND s."product_id" = j."product_id" AND s."lot_number" = j."lot_number"
+		WHERE COALESCE(s."quantity", 0) <> COALESCE(j."quantity", 0)
+		ORDER BY 1, 2, 3`,
+		req.BranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var (
+			BranchID  sql.NullString
+			ProductID sql.NullString
+			LotNumber sql.NullString
+			Remainder sql.NullInt64
+			Journal   sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&BranchID,
+			&ProductID,
+			&LotNumber,
+			&Remainder,
+			&Journal,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		resp.Discrepancies = append(resp.Discrepancies, &models.StockDiscrepancy{
+			BranchID:   BranchID.String,
+			ProductID:  ProductID.String,
+			LotNumber:  LotNumber.String,
+			Remainder:  int(Remainder.Int64),
+			Journal:    int(Journal.Int64),
+			Difference: int(Journal.Int64 - Remainder.Int64),
+		})
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return &resp, nil
+	}
+
+	for _, d := range resp.Discrepancies {
+
+		result, err := tx.Exec(ctx, `
+			UPDATE "remainder" AS r
+				SET
+					"quantity" = CASE WHEN r."id" = f."id" THEN $3 ELSE 0 END,
+					"updated_at" = NOW()
+			FROM (
+				SELECT "id" FROM "remainder"
+				WHERE "branch_id" = $1 AND "product_id" = $2 AND COALESCE("lot_number", '') = $4
+				ORDER BY "created_at"
+				LIMIT 1
+			) AS f
+			WHERE r."branch_id" = $1 AND r."product_id" = $2 AND COALESCE(r."lot_number", '') = $4`,
+			d.BranchID,
+			d.ProductID,
+			d.Journal,
+			d.LotNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.RowsAffected() > 0 {
+			continue
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "remainder"(
+				"id",
+				"product_id",
+				"name",
+				"quantity",
+				"coming_price",
+				"sale_price",
+				"branch_id",
+				"lot_number",
+				"expiry_date",
+				"updated_at"
+			)
+			SELECT $1, p."id", p."name", $3, 0, COALESCE(bp."price", p."price"), $2, NULLIF($5, ''), (
+				SELECT MAX("expiry_date") FROM "stock_movement"
+				WHERE "branch_id" = $2 AND "product_id" = $4 AND "lot_number" = $5
+			), NOW()
+			FROM "product" AS p
+			LEFT JOIN "branch_product" AS bp ON bp."product_id" = p."id" AND bp."branch_id" = $2
+			WHERE p."id" = $4`,
+			uuid.New().String(),
+			d.BranchID,
+			d.Journal,
+			d.ProductID,
+			d.LotNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
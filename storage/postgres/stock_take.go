@@ -0,0 +1,413 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type stockTakeRepo struct {
+	db DB
+}
+
+func NewStockTakeRepo(db DB) *stockTakeRepo {
+	return &stockTakeRepo{
+		db: db,
+	}
+}
+
+func (r *stockTakeRepo) Create(ctx context.Context, req *models.CreateStockTake) (*models.StockTake, error) {
+
+	var stockTakeId = uuid.New().String()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "stock_take"(
+			"id",
+			"increment_id",
+			"branch_id",
+			"user_id",
+			"updated_at"
+		) VALUES ($1, $2, $3, NULLIF($4, '')::UUID, NOW())`,
+		stockTakeId,
+		req.IncrementID,
+		req.BranchID,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "stock_take_line"(
+				"id",
+				"stock_take_id",
+				"product_id",
+				"expected",
+				"coming_price",
+				"created_at"
+			) VALUES ($1, $2, $3, $4, $5, CLOCK_TIMESTAMP())`,
+			uuid.New().String(),
+			stockTakeId,
+			line.ProductID,
+			line.Expected,
+			line.ComingPrice,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.StockTakePrimaryKey{Id: stockTakeId})
+}
+
+func (r *stockTakeRepo) GetByID(ctx context.Context, req *models.StockTakePrimaryKey) (*models.StockTake, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"increment_id",
+				"branch_id",
+				"status",
+				"user_id",
+				"approved_at",
+				"created_at",
+				"updated_at"
+			FROM "stock_take"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id          sql.NullString
+		IncrementID sql.NullString
+		BranchID    sql.NullString
+		Status      sql.NullString
+		UserID      sql.NullString
+		ApprovedAt  sql.NullString
+		CreatedAt   sql.NullString
+		UpdatedAt   sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&IncrementID,
+		&BranchID,
+		&Status,
+		&UserID,
+		&ApprovedAt,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stockTake := &models.StockTake{
+		Id:          Id.String,
+		IncrementID: IncrementID.String,
+		BranchID:    BranchID.String,
+		Status:      Status.String,
+		UserID:      UserID.String,
+		ApprovedAt:  ApprovedAt.String,
+		CreatedAt:   CreatedAt.String,
+		UpdatedAt:   UpdatedAt.String,
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"stock_take_id",
+			"product_id",
+			"expected",
+			"counted",
+			"coming_price",
+			"created_at"
+		FROM "stock_take_line"
+		WHERE "stock_take_id" = $1
+		ORDER BY "created_at"`,
+		stockTake.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id          sql.NullString
+			StockTakeID sql.NullString
+			ProductID   sql.NullString
+			Expected    sql.NullInt64
+			Counted     sql.NullInt64
+			ComingPrice sql.NullFloat64
+			CreatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&StockTakeID,
+			&ProductID,
+			&Expected,
+			&Counted,
+			&ComingPrice,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line := &models.StockTakeLine{
+			Id:          Id.String,
+			StockTakeID: StockTakeID.String,
+			ProductID:   ProductID.String,
+			Expected:    int(Expected.Int64),
+			ComingPrice: ComingPrice.Float64,
+			CreatedAt:   CreatedAt.String,
+		}
+
+		if Counted.Valid {
+			counted := int(Counted.Int64)
+			line.Counted = &counted
+		}
+
+		stockTake.AddLine(line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+func (r *stockTakeRepo) GetList(ctx context.Context, req *models.GetListStockTakeRequest) (*models.GetListStockTakeResponse, error) {
+	var (
+		resp   models.GetListStockTakeResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("stock_take", models.StockTakeFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"increment_id",
+			"branch_id",
+			"status",
+			"user_id",
+			"approved_at",
+			"created_at",
+			"updated_at"
+		FROM "stock_take"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id          sql.NullString
+			IncrementID sql.NullString
+			BranchID    sql.NullString
+			Status      sql.NullString
+			UserID      sql.NullString
+			ApprovedAt  sql.NullString
+			CreatedAt   sql.NullString
+			UpdatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&IncrementID,
+			&BranchID,
+			&Status,
+			&UserID,
+			&ApprovedAt,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.StockTakes = append(resp.StockTakes, &models.StockTake{
+			Id:          Id.String,
+			IncrementID: IncrementID.String,
+			BranchID:    BranchID.String,
+			Status:      Status.String,
+			UserID:      UserID.String,
+			ApprovedAt:  ApprovedAt.String,
+			CreatedAt:   CreatedAt.String,
+			UpdatedAt:   UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *stockTakeRepo) Delete(ctx context.Context, req *models.StockTakePrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "stock_take" WHERE "id" = $1`, req.Id)
+	return err
+}
+
+// SetCounts updates the lines of an open stock-take only and returns how
+// many lines it set.
+func (r *stockTakeRepo) SetCounts(ctx context.Context, req *models.SetStockTakeCounts) (int64, error) {
+
+	var rowsAffected int64
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id sql.NullString
+	err = tx.QueryRow(ctx, `SELECT "id" FROM "stock_take" WHERE "id" = $1 AND "status" = 'open' FOR UPDATE`, req.Id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, count := range req.Counts {
+		result, err := tx.Exec(ctx, `
+			UPDATE "stock_take_line"
+				SET "counted" = $3
+			WHERE "stock_take_id" = $1 AND "product_id" = $2`,
+			req.Id,
+			count.ProductID,
+			count.Counted,
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		rowsAffected += result.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE "stock_take" SET "updated_at" = NOW() WHERE "id" = $1`, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+func (r *stockTakeRepo) SetExpected(ctx context.Context, req *models.SetStockTakeExpected) (int64, error) {
+
+	var rowsAffected int64
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id sql.NullString
+	err = tx.QueryRow(ctx, `SELECT "id" FROM "stock_take" WHERE "id" = $1 AND "status" = 'open' FOR UPDATE`, req.Id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, expected := range req.Expected {
+		result, err := tx.Exec(ctx, `
+			UPDATE "stock_take_line"
+				SET "expected" = $3
+			WHERE "stock_take_id" = $1 AND "product_id" = $2`,
+			req.Id,
+			expected.ProductID,
+			expected.Expected,
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		rowsAffected += result.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE "stock_take" SET "updated_at" = NOW() WHERE "id" = $1`, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// SetStatus changes the status only when it is still req.From, so of two
+// concurrent calls one gets zero rows affected.
+func (r *stockTakeRepo) SetStatus(ctx context.Context, req *models.StockTakeStatus) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "stock_take"
+			SET
+				"status" = $3,
+				"approved_at" = CASE WHEN $3 = 'approved' THEN NOW() ELSE "approved_at" END,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND "status" = $2`,
+		req.Id,
+		req.From,
+		req.To,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,407 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type supplierRepo struct {
+	db DB
+}
+
+func NewSupplierRepo(db DB) *supplierRepo {
+	return &supplierRepo{
+		db: db,
+	}
+}
+
+func (r *supplierRepo) Create(ctx context.Context, req *models.CreateSupplier) (*models.Supplier, error) {
+
+	var (
+		supplierId = uuid.New().String()
+		query      = `
+			INSERT INTO "supplier"(
+				"id",
+				"name",
+				"tin",
+				"phone",
+				"address",
+				"updated_at"
+			) VALUES ($1, $2, NULLIF($3, ''), $4, $5, NOW())`
+	)
+
+	_, err := r.db.Exec(ctx,
+		query,
+		supplierId,
+		req.Name,
+		req.TIN,
+		req.Phone,
+		req.Address,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.SupplierPrimaryKey{Id: supplierId})
+}
+
+func (r *supplierRepo) GetByID(ctx context.Context, req *models.SupplierPrimaryKey) (*models.Supplier, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"name",
+				"tin",
+				"phone",
+				"address",
+				"created_at",
+				"updated_at"
+			FROM "supplier"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id        sql.NullString
+		Name      sql.NullString
+		TIN       sql.NullString
+		Phone     sql.NullString
+		Address   sql.NullString
+		CreatedAt sql.NullString
+		UpdatedAt sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&Name,
+		&TIN,
+		&Phone,
+		&Address,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Supplier{
+		Id:        Id.String,
+		Name:      Name.String,
+		TIN:       TIN.String,
+		Phone:     Phone.String,
+		Address:   Address.String,
+		CreatedAt: CreatedAt.String,
+		UpdatedAt: UpdatedAt.String,
+	}, nil
+}
+
+func (r *supplierRepo) GetList(ctx context.Context, req *models.GetListSupplierRequest) (*models.GetListSupplierResponse, error) {
+	var (
+		resp   models.GetListSupplierResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("supplier", models.SupplierFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"name",
+			"tin",
+			"phone",
+			"address",
+			"created_at",
+			"updated_at"
+		FROM "supplier"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id        sql.NullString
+			Name      sql.NullString
+			TIN       sql.NullString
+			Phone     sql.NullString
+			Address   sql.NullString
+			CreatedAt sql.NullString
+			UpdatedAt sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&Name,
+			&TIN,
+			&Phone,
+			&Address,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Suppliers = append(resp.Suppliers, &models.Supplier{
+			Id:        Id.String,
+			Name:      Name.String,
+			TIN:       TIN.String,
+			Phone:     Phone.String,
+			Address:   Address.String,
+			CreatedAt: CreatedAt.String,
+			UpdatedAt: UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *supplierRepo) Update(ctx context.Context, req *models.UpdateSupplier) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "supplier"
+			SET
+				"name" = $2,
+				"tin" = NULLIF($3, ''),
+				"phone" = $4,
+				"address" = $5,
+				"updated_at" = NOW()
+		WHERE "id" = $1`,
+		req.Id,
+		req.Name,
+		req.TIN,
+		req.Phone,
+		req.Address,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *supplierRepo) Delete(ctx context.Context, req *models.SupplierPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "supplier" WHERE "id" = $1`, req.Id)
+	return err
+}
+
+// supplierEntries are the finished comings, their reversals and the payments
+// of supplier $1 in branch $2, of all branches when $2 is empty.
+const supplierEntries = `
+	SELECT * FROM (
+		SELECT
+			CASE e."action" WHEN 'finish' THEN 'coming' ELSE 'reversal' END AS "type",
+			e."id",
+			c."id" AS "coming_id",
+			c."increment_id",
+			c."branch_id",
+			CASE e."action" WHEN 'reverse' THEN t."total" ELSE 0 END AS "debit",
+			CASE e."action" WHEN 'finish' THEN t."total" ELSE 0 END AS "credit",
+			e."created_at"
+		FROM "coming_event" AS e
+		JOIN "coming" AS c ON c."id" = e."coming_id"
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM("total_price"), 0) AS "total" FROM "picking_list" WHERE "coming_id" = c."id"
+		) AS t
+		WHERE c."supplier_id" = $1
+		UNION ALL
+		SELECT
+			'payment',
+			p."id",
+			NULL,
+			NULL,
+			p."branch_id",
+			p."amount",
+			0,
+			p."created_at"
+		FROM "supplier_payment" AS p
+		WHERE p."supplier_id" = $1
+	) AS "entry"
+	WHERE ($2 = '' OR "branch_id" = NULLIF($2, '')::UUID)
+`
+
+// Statement lists what was received from and paid to a supplier with the
+// running balance we owe.
+func (r *supplierRepo) Statement(ctx context.Context, req *models.SupplierStatementRequest) (*models.SupplierStatement, error) {
+
+	var resp = models.SupplierStatement{SupplierID: req.SupplierID, BranchID: req.BranchID}
+
+	if req.From != nil {
+		err := r.db.QueryRow(ctx,
+			`SELECT COALESCE(SUM("credit" - "debit"), 0) FROM (`+supplierEntries+`) AS "before" WHERE "created_at" < $3`,
+			req.SupplierID,
+			req.BranchID,
+			*req.From,
+		).Scan(&resp.OpeningBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.ClosingBalance = resp.OpeningBalance
+
+	var (
+		args  = []interface{}{req.SupplierID, req.BranchID}
+		query = supplierEntries
+	)
+
+	if req.From != nil {
+		args = append(args, *req.From)
+		query += fmt.Sprintf(` AND "created_at" >= $%d`, len(args))
+	}
+
+	if req.To != nil {
+		args = append(args, *req.To)
+		query += fmt.Sprintf(` AND "created_at" < $%d`, len(args))
+	}
+
+	// a coming comes before the payment made at the same moment
+	query += ` ORDER BY "created_at", "type"`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Type        sql.NullString
+			Id          sql.NullString
+			ComingID    sql.NullString
+			IncrementID sql.NullString
+			BranchID    sql.NullString
+			Debit       sql.NullFloat64
+			Credit      sql.NullFloat64
+			CreatedAt   sql.NullString
+		)
+
+		err = rows.Scan(
+			&Type,
+			&Id,
+			&ComingID,
+			&IncrementID,
+			&BranchID,
+			&Debit,
+			&Credit,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.AddEntry(&models.SupplierStatementEntry{
+			Type:        Type.String,
+			Id:          Id.String,
+			ComingID:    ComingID.String,
+			IncrementID: IncrementID.String,
+			BranchID:    BranchID.String,
+			Debit:       Debit.Float64,
+			Credit:      Credit.Float64,
+			CreatedAt:   CreatedAt.String,
+		})
+	}
+
+	return &resp, rows.Err()
+}
+
+// Payables lists the suppliers a branch, or all branches, received goods
+// from or paid, the largest balance first.
+func (r *supplierRepo) Payables(ctx context.Context, req *models.PayablesRequest) (*models.PayablesResponse, error) {
+
+	rows, err := r.db.Query(ctx, `
+		WITH "received" AS (
+			SELECT c."supplier_id", SUM(pl."total_price") AS "amount"
+			FROM "coming" AS c
+			JOIN "picking_list" AS pl ON pl."coming_id" = c."id"
+			WHERE c."status" = 'finished' AND ($1 = '' OR c."branch_id" = NULLIF($1, '')::UUID)
+			GROUP BY c."supplier_id"
+		), "paid" AS (
+			SELECT "supplier_id", SUM("amount") AS "amount"
+			FROM "supplier_payment"
+			WHERE $1 = '' OR "branch_id" = NULLIF($1, '')::UUID
+			GROUP BY "supplier_id"
+		)
+		SELECT
+			s."id",
+			s."name",
+			s."tin",
+			s."phone",
+			COALESCE(r."amount", 0),
+			COALESCE(p."amount", 0)
+		FROM "supplier" AS s
+		LEFT JOIN "received" AS r ON r."supplier_id" = s."id"
+		LEFT JOIN "paid" AS p ON p."supplier_id" = s."id"
+		WHERE r."amount" IS NOT NULL OR p."amount" IS NOT NULL
+		ORDER BY COALESCE(r."amount", 0) - COALESCE(p."amount", 0) DESC, s."name"`,
+		req.BranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp models.PayablesResponse
+
+	for rows.Next() {
+		var (
+			SupplierID sql.NullString
+			Name       sql.NullString
+			TIN        sql.NullString
+			Phone      sql.NullString
+			Received   sql.NullFloat64
+			Paid       sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&SupplierID,
+			&Name,
+			&TIN,
+			&Phone,
+			&Received,
+			&Paid,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Add(&models.Payable{
+			SupplierID: SupplierID.String,
+			Name:       Name.String,
+			TIN:        TIN.String,
+			Phone:      Phone.String,
+			Received:   Received.Float64,
+			Paid:       Paid.Float64,
+		})
+	}
+
+	return &resp, rows.Err()
+}
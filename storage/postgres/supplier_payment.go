@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+)
+
+type supplierPaymentRepo struct {
+	db DB
+}
+
+func NewSupplierPaymentRepo(db DB) *supplierPaymentRepo {
+	return &supplierPaymentRepo{
+		db: db,
+	}
+}
+
+// Create records the payment if the branch owes the supplier at least the
+// amount. The supplier row is locked first, so concurrent payments to one
+// supplier are checked one after another.
+func (r *supplierPaymentRepo) Create(ctx context.Context, req *models.CreateSupplierPayment) (*models.SupplierPayment, error) {
+
+	var (
+		supplierPaymentId = uuid.New().String()
+		supplierId        string
+		received          float64
+		paid              float64
+	)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `SELECT "id" FROM "supplier" WHERE "id" = $1 FOR UPDATE`, req.SupplierID).Scan(&supplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(pl."total_price"), 0)
+		FROM "coming" AS c
+		JOIN "picking_list" AS pl ON pl."coming_id" = c."id"
+		WHERE c."supplier_id" = $1 AND c."branch_id" = $2 AND c."status" = 'finished'`,
+		req.SupplierID,
+		req.BranchID,
+	).Scan(&received)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM("amount"), 0) FROM "supplier_payment" WHERE "supplier_id" = $1 AND "branch_id" = $2`,
+		req.SupplierID,
+		req.BranchID,
+	).Scan(&paid)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount > received-paid {
+		return nil, storage.ErrOverpaySupplier
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "supplier_payment"(
+			"id",
+			"supplier_id",
+			"branch_id",
+			"amount",
+			"method",
+			"comment",
+			"user_id"
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::UUID)`,
+		supplierPaymentId,
+		req.SupplierID,
+		req.BranchID,
+		req.Amount,
+		req.Method,
+		req.Comment,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.SupplierPaymentPrimaryKey{Id: supplierPaymentId})
+}
+
+func (r *supplierPaymentRepo) GetByID(ctx context.Context, req *models.SupplierPaymentPrimaryKey) (*models.SupplierPayment, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"supplier_id",
+				"branch_id",
+				"amount",
+				"method",
+				"comment",
+				"user_id",
+				"created_at"
+			FROM "supplier_payment"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id         sql.NullString
+		SupplierID sql.NullString
+		BranchID   sql.NullString
+		Amount     sql.NullFloat64
+		Method     sql.NullString
+		Comment    sql.NullString
+		UserID     sql.NullString
+		CreatedAt  sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&SupplierID,
+		&BranchID,
+		&Amount,
+		&Method,
+		&Comment,
+		&UserID,
+		&CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SupplierPayment{
+		Id:         Id.String,
+		SupplierID: SupplierID.String,
+		BranchID:   BranchID.String,
+		Amount:     Amount.Float64,
+		Method:     Method.String,
+		Comment:    Comment.String,
+		UserID:     UserID.String,
+		CreatedAt:  CreatedAt.String,
+	}, nil
+}
+
+func (r *supplierPaymentRepo) GetList(ctx context.Context, req *models.GetListSupplierPaymentRequest) (*models.GetListSupplierPaymentResponse, error) {
+	var (
+		resp   models.GetListSupplierPaymentResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("supplier_payment", models.SupplierPaymentFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"supplier_id",
+			"branch_id",
+			"amount",
+			"method",
+			"comment",
+			"user_id",
+			"created_at"
+		FROM "supplier_payment"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id         sql.NullString
+			SupplierID sql.NullString
+			BranchID   sql.NullString
+			Amount     sql.NullFloat64
+			Method     sql.NullString
+			Comment    sql.NullString
+			UserID     sql.NullString
+			CreatedAt  sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&SupplierID,
+			&BranchID,
+			&Amount,
+			&Method,
+			&Comment,
+			&UserID,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.SupplierPayments = append(resp.SupplierPayments, &models.SupplierPayment{
+			Id:         Id.String,
+			SupplierID: SupplierID.String,
+			BranchID:   BranchID.String,
+			Amount:     Amount.Float64,
+			Method:     Method.String,
+			Comment:    Comment.String,
+			UserID:     UserID.String,
+			CreatedAt:  CreatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,496 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type transferRepo struct {
+	db DB
+}
+
+func NewTransferRepo(db DB) *transferRepo {
+	return &transferRepo{
+		db: db,
+	}
+}
+
+func (r *transferRepo) Create(ctx context.Context, req *models.CreateTransfer) (*models.Transfer, error) {
+
+	var transferId = uuid.New().String()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "transfer"(
+			"id",
+			"increment_id",
+			"from_branch_id",
+			"to_branch_id",
+			"user_id",
+			"updated_at"
+		) VALUES ($1, $2, $3, $4, NULLIF($5, '')::UUID, NOW())`,
+		transferId,
+		req.IncrementID,
+		req.FromBranchID,
+		req.ToBranchID,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = insertTransferLines(ctx, tx, transferId, req.Lines); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.TransferPrimaryKey{Id: transferId})
+}
+
+func insertTransferLines(ctx context.Context, db DB, transferId string, lines []*models.CreateTransferLine) error {
+
+	for _, line := range lines {
+		// clock_timestamp keeps the lines in the order they were given
+		_, err := db.Exec(ctx, `
+			INSERT INTO "transfer_line"(
+				"id",
+				"transfer_id",
+				"product_id",
+				"quantity",
+				"created_at"
+			) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP())`,
+			uuid.New().String(),
+			transferId,
+			line.ProductID,
+			line.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *transferRepo) GetByID(ctx context.Context, req *models.TransferPrimaryKey) (*models.Transfer, error) {
+
+	var (
+		query = `
+			SELECT
+				"id",
+				"increment_id",
+				"from_branch_id",
+				"to_branch_id",
+				"status",
+				"user_id",
+				"sent_at",
+				"received_at",
+				"created_at",
+				"updated_at"
+			FROM "transfer"
+			WHERE "id" = $1
+		`
+	)
+
+	var (
+		Id           sql.NullString
+		IncrementID  sql.NullString
+		FromBranchID sql.NullString
+		ToBranchID   sql.NullString
+		Status       sql.NullString
+		UserID       sql.NullString
+		SentAt       sql.NullString
+		ReceivedAt   sql.NullString
+		CreatedAt    sql.NullString
+		UpdatedAt    sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query, req.Id).Scan(
+		&Id,
+		&IncrementID,
+		&FromBranchID,
+		&ToBranchID,
+		&Status,
+		&UserID,
+		&SentAt,
+		&ReceivedAt,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		Id:           Id.String,
+		IncrementID:  IncrementID.String,
+		FromBranchID: FromBranchID.String,
+		ToBranchID:   ToBranchID.String,
+		Status:       Status.String,
+		UserID:       UserID.String,
+		SentAt:       SentAt.String,
+		ReceivedAt:   ReceivedAt.String,
+		CreatedAt:    CreatedAt.String,
+		UpdatedAt:    UpdatedAt.String,
+	}
+
+	transfer.Lines, err = r.lines(ctx, transfer.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (r *transferRepo) lines(ctx context.Context, transferId string) ([]*models.TransferLine, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			"id",
+			"transfer_id",
+			"product_id",
+			"quantity",
+			"coming_price",
+			"received_quantity",
+			"created_at"
+		FROM "transfer_line"
+		WHERE "transfer_id" = $1
+		ORDER BY "created_at"`,
+		transferId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resp []*models.TransferLine
+
+	for rows.Next() {
+		var (
+			Id               sql.NullString
+			TransferID       sql.NullString
+			ProductID        sql.NullString
+			Quantity         sql.NullInt64
+			ComingPrice      sql.NullFloat64
+			ReceivedQuantity sql.NullInt64
+			CreatedAt        sql.NullString
+		)
+
+		err = rows.Scan(
+			&Id,
+			&TransferID,
+			&ProductID,
+			&Quantity,
+			&ComingPrice,
+			&ReceivedQuantity,
+			&CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line := &models.TransferLine{
+			Id:          Id.String,
+			TransferID:  TransferID.String,
+			ProductID:   ProductID.String,
+			Quantity:    int(Quantity.Int64),
+			ComingPrice: ComingPrice.Float64,
+			CreatedAt:   CreatedAt.String,
+		}
+
+		if ReceivedQuantity.Valid {
+			received := int(ReceivedQuantity.Int64)
+			line.ReceivedQuantity = &received
+			line.Shortage = line.Quantity - received
+		}
+
+		resp = append(resp, line)
+	}
+
+	return resp, rows.Err()
+}
+
+func (r *transferRepo) GetList(ctx context.Context, req *models.GetListTransferRequest) (*models.GetListTransferResponse, error) {
+	var (
+		resp   models.GetListTransferResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("transfer", models.TransferFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.BranchID) > 0 {
+		args = append(args, req.BranchID)
+		where += fmt.Sprintf(` AND ("transfer"."from_branch_id" = $%d OR "transfer"."to_branch_id" = $%d)`, len(args), len(args))
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"increment_id",
+			"from_branch_id",
+			"to_branch_id",
+			"status",
+			"user_id",
+			"sent_at",
+			"received_at",
+			"created_at",
+			"updated_at"
+		FROM "transfer"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id           sql.NullString
+			IncrementID  sql.NullString
+			FromBranchID sql.NullString
+			ToBranchID   sql.NullString
+			Status       sql.NullString
+			UserID       sql.NullString
+			SentAt       sql.NullString
+			ReceivedAt   sql.NullString
+			CreatedAt    sql.NullString
+			UpdatedAt    sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&IncrementID,
+			&FromBranchID,
+			&ToBranchID,
+			&Status,
+			&UserID,
+			&SentAt,
+			&ReceivedAt,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Transfers = append(resp.Transfers, &models.Transfer{
+			Id:           Id.String,
+			IncrementID:  IncrementID.String,
+			FromBranchID: FromBranchID.String,
+			ToBranchID:   ToBranchID.String,
+			Status:       Status.String,
+			UserID:       UserID.String,
+			SentAt:       SentAt.String,
+			ReceivedAt:   ReceivedAt.String,
+			CreatedAt:    CreatedAt.String,
+			UpdatedAt:    UpdatedAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Update changes a draft only. Nil lines keep the current ones.
+func (r *transferRepo) Update(ctx context.Context, req *models.UpdateTransfer) (int64, error) {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE "transfer"
+			SET
+				"to_branch_id" = $2,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND "status" = 'draft'`,
+		req.Id,
+		req.ToBranchID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, nil
+	}
+
+	if req.Lines != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM "transfer_line" WHERE "transfer_id" = $1`, req.Id); err != nil {
+			return 0, err
+		}
+
+		if err = insertTransferLines(ctx, tx, req.Id, req.Lines); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *transferRepo) Delete(ctx context.Context, req *models.TransferPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "transfer" WHERE "id" = $1`, req.Id)
+	return err
+}
+
+// SetStatus changes the status only when it is still req.From, so of two
+// concurrent calls one gets zero rows affected.
+func (r *transferRepo) SetStatus(ctx context.Context, req *models.TransferStatus) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "transfer"
+			SET
+				"status" = $3,
+				"sent_at" = CASE WHEN $3 = 'sent' THEN NOW() ELSE "sent_at" END,
+				"received_at" = CASE WHEN $3 = 'received' THEN NOW() ELSE "received_at" END,
+				"updated_at" = NOW()
+		WHERE "id" = $1 AND "status" = $2`,
+		req.Id,
+		req.From,
+		req.To,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *transferRepo) UpdateLine(ctx context.Context, req *models.UpdateTransferLine) (int64, error) {
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE "transfer_line"
+			SET
+				"coming_price" = $2,
+				"received_quantity" = $3
+		WHERE "id" = $1`,
+		req.Id,
+		req.ComingPrice,
+		req.ReceivedQuantity,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// InTransit lists the lines of sent transfers, the oldest first.
+func (r *transferRepo) InTransit(ctx context.Context, req *models.InTransitRequest) (*models.InTransitResponse, error) {
+
+	var (
+		resp  models.InTransitResponse
+		args  []interface{}
+		query = `
+			SELECT
+				t."id",
+				t."increment_id",
+				t."from_branch_id",
+				t."to_branch_id",
+				l."product_id",
+				l."quantity",
+				l."coming_price",
+				t."sent_at"
+			FROM "transfer" AS t
+			JOIN "transfer_line" AS l ON l."transfer_id" = t."id"
+			WHERE t."status" = 'sent'
+		`
+	)
+
+	if len(req.BranchID) > 0 {
+		args = append(args, req.BranchID)
+		query += ` AND (t."from_branch_id" = $1 OR t."to_branch_id" = $1)`
+	}
+
+	query += ` ORDER BY t."sent_at", l."created_at"`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			TransferID   sql.NullString
+			IncrementID  sql.NullString
+			FromBranchID sql.NullString
+			ToBranchID   sql.NullString
+			ProductID    sql.NullString
+			Quantity     sql.NullInt64
+			ComingPrice  sql.NullFloat64
+			SentAt       sql.NullString
+		)
+
+		err = rows.Scan(
+			&TransferID,
+			&IncrementID,
+			&FromBranchID,
+			&ToBranchID,
+			&ProductID,
+			&Quantity,
+			&ComingPrice,
+			&SentAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Lines = append(resp.Lines, &models.InTransit{
+			TransferID:   TransferID.String,
+			IncrementID:  IncrementID.String,
+			FromBranchID: FromBranchID.String,
+			ToBranchID:   ToBranchID.String,
+			ProductID:    ProductID.String,
+			Quantity:     int(Quantity.Int64),
+			ComingPrice:  ComingPrice.Float64,
+			SentAt:       SentAt.String,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"market_system/models"
+
+	"github.com/google/uuid"
+)
+
+type userRepo struct {
+	db DB
+}
+
+func NewUserRepo(db DB) *userRepo {
+	return &userRepo{
+		db: db,
+	}
+}
+
+func (r *userRepo) Create(ctx context.Context, req *models.CreateUser) (*models.User, error) {
+
+	var (
+		userId = uuid.New().String()
+		query  = `
+			INSERT INTO "user"(
+				"id",
+				"first_name",
+				"last_name",
+				"login",
+				"password",
+				"client_type",
+				"branch_id",
+				"updated_at"
+			) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::UUID, NOW())`
+	)
+
+	_, err := r.db.Exec(ctx,
+		query,
+		userId,
+		req.FirstName,
+		req.LastName,
+		req.Login,
+		req.Password,
+		req.ClientType,
+		req.BranchID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, &models.UserPrimaryKey{Id: userId})
+}
+
+// GetByID finds the user by id, or by login when id is empty.
+func (r *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*models.User, error) {
+
+	var (
+		where = `"id" = $1`
+		key   = req.Id
+		query = `
+			SELECT
+				"id",
+				"first_name",
+				"last_name",
+				"login",
+				"password",
+				"client_type",
+				"branch_id",
+				"created_at",
+				"updated_at"
+			FROM "user"
+			WHERE `
+	)
+
+	if len(req.Id) == 0 {
+		where = `"login" = $1`
+		key = req.Login
+	}
+
+	var (
+		Id         sql.NullString
+		FirstName  sql.NullString
+		LastName   sql.NullString
+		Login      sql.NullString
+		Password   sql.NullString
+		ClientType sql.NullString
+		BranchID   sql.NullString
+		CreatedAt  sql.NullString
+		UpdatedAt  sql.NullString
+	)
+
+	err := r.db.QueryRow(ctx, query+where, key).Scan(
+		&Id,
+		&FirstName,
+		&LastName,
+		&Login,
+		&Password,
+		&ClientType,
+		&BranchID,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.User{
+		Id:         Id.String,
+		FirstName:  FirstName.String,
+		LastName:   LastName.String,
+		Login:      Login.String,
+		Password:   Password.String,
+		ClientType: ClientType.String,
+		BranchID:   BranchID.String,
+		CreatedAt:  CreatedAt.String,
+		UpdatedAt:  UpdatedAt.String,
+	}, nil
+}
+
+func (r *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest) (*models.GetListUserResponse, error) {
+	var (
+		resp   models.GetListUserResponse
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		sort   = " ORDER BY created_at DESC"
+	)
+
+	where, args, err := whereClause("user", models.UserFilterSpec, req.Search, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	var query = `
+		SELECT
+			COUNT(*) OVER(),
+			"id",
+			"first_name",
+			"last_name",
+			"login",
+			"client_type",
+			"branch_id",
+			"created_at",
+			"updated_at"
+		FROM "user"
+	`
+
+	query += where + sort + offset + limit
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Id         sql.NullString
+			FirstName  sql.NullString
+			LastName   sql.NullString
+			Login      sql.NullString
+			ClientType sql.NullString
+			BranchID   sql.NullString
+			CreatedAt  sql.NullString
+			UpdatedAt  sql.NullString
+		)
+
+		err = rows.Scan(
+			&resp.Count,
+			&Id,
+			&FirstName,
+			&LastName,
+			&Login,
+			&ClientType,
+			&BranchID,
+			&CreatedAt,
+			&UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Users = append(resp.Users, &models.User{
+			Id:         Id.String,
+			FirstName:  FirstName.String,
+			LastName:   LastName.String,
+			Login:      Login.String,
+			ClientType: ClientType.String,
+			BranchID:   BranchID.String,
+			CreatedAt:  CreatedAt.String,
+			UpdatedAt:  UpdatedAt.String,
+		})
+	}
+
+	return &resp, rows.Err()
+}
+
+// Update keeps the current password when req.Password is empty.
+func (r *userRepo) Update(ctx context.Context, req *models.UpdateUser) (int64, error) {
+
+	query := `
+		UPDATE "user"
+			SET
+				"first_name" = $2,
+				"last_name" = $3,
+				"login" = $4,
+				"password" = COALESCE(NULLIF($5, ''), "password"),
+				"client_type" = $6,
+				"branch_id" = NULLIF($7, '')::UUID,
+				"updated_at" = NOW()
+		WHERE "id" = $1
+	`
+	result, err := r.db.Exec(
+		ctx,
+		query,
+		req.Id,
+		req.FirstName,
+		req.LastName,
+		req.Login,
+		req.Password,
+		req.ClientType,
+		req.BranchID,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *userRepo) Delete(ctx context.Context, req *models.UserPrimaryKey) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "user" WHERE "id" = $1`, req.Id)
+	return err
+}
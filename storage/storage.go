@@ -2,20 +2,47 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"market_system/models"
 )
 
+var (
+	ErrNotEnoughQuantity = errors.New("not enough quantity")
+	ErrOverpayment       = errors.New("payment exceeds sale debt")
+	ErrOverRefund        = errors.New("refund exceeds paid amount")
+	ErrOverReturn        = errors.New("return exceeds sold quantity")
+	ErrOverpaySupplier   = errors.New("payment exceeds supplier payable")
+	ErrCategoryCycle     = errors.New("category can not be moved under itself")
+)
+
 type StorageI interface {
+	WithTx(ctx context.Context, fn func(tx StorageI) error) error
 	Coming() ComingRepoI
 	Branch() BranchRepoI
 	Client() ClientRepoI
 	Product() ProductRepoI
+	BranchProduct() BranchProductRepoI
+	PriceChange() PriceChangeRepoI
+	Repricing() RepricingRepoI
+	Promotion() PromotionRepoI
+	Category() CategoryRepoI
 	SaleProduct() SaleProductRepoI
 	Remainder() RemainderRepoI
+	StockMovement() StockMovementRepoI
+	Transfer() TransferRepoI
+	StockTake() StockTakeRepoI
 	Sale() SaleRepoI
+	SaleReturn() SaleReturnRepoI
 	PickingList() PickingListRepoI
-	IncrementID() IncrementIDRepoI
+	DocumentNumber() DocumentNumberRepoI
+	Payment() PaymentRepoI
+	Supplier() SupplierRepoI
+	SupplierPayment() SupplierPaymentRepoI
+	PurchaseOrder() PurchaseOrderRepoI
+	StockLevel() StockLevelRepoI
+	User() UserRepoI
 }
 
 type ComingRepoI interface {
@@ -24,6 +51,9 @@ type ComingRepoI interface {
 	GetList(ctx context.Context, req *models.GetListComingRequest) (*models.GetListComingResponse, error)
 	Update(ctx context.Context, req *models.UpdateComing) (int64, error)
 	Delete(ctx context.Context, req *models.ComingPrimaryKey) error
+	SetStatus(ctx context.Context, req *models.ComingStatus) (int64, error)
+	CreateEvent(ctx context.Context, req *models.CreateComingEvent) (*models.ComingEvent, error)
+	GetEvents(ctx context.Context, req *models.ComingPrimaryKey) ([]*models.ComingEvent, error)
 }
 
 type ProductRepoI interface {
@@ -32,6 +62,57 @@ type ProductRepoI interface {
 	GetList(ctx context.Context, req *models.GetListProductRequest) (*models.GetListProductResponse, error)
 	Update(ctx context.Context, req *models.UpdateProduct) (int64, error)
 	Delete(ctx context.Context, req *models.ProductPrimaryKey) error
+	GetByBarcode(ctx context.Context, req *models.ProductByBarcodeRequest) (*models.ProductByBarcode, error)
+}
+
+// PriceChangeRepoI keeps the price history of products. Create applies a
+// change that is already due and leaves a later one pending, ApplyDue applies
+// the pending changes due by at and returns how many. AsOf answers the price
+// of a product in a branch at a time, pending changes included, falling back
+// to the catalog when the branch has no override then.
+type PriceChangeRepoI interface {
+	Create(ctx context.Context, req *models.CreatePriceChange) (*models.PriceChange, error)
+	GetByID(ctx context.Context, req *models.PriceChangePrimaryKey) (*models.PriceChange, error)
+	GetList(ctx context.Context, req *models.GetListPriceChangeRequest) (*models.GetListPriceChangeResponse, error)
+	Cancel(ctx context.Context, req *models.PriceChangePrimaryKey) (int64, error)
+	ApplyDue(ctx context.Context, at time.Time) (int64, error)
+	AsOf(ctx context.Context, req *models.PriceAsOfRequest) (*models.PriceAsOf, error)
+}
+
+// RepricingRepoI keeps the repricing documents, Create schedules their lines
+// as price changes.
+type RepricingRepoI interface {
+	Create(ctx context.Context, req *models.CreateRepricing) (*models.Repricing, error)
+	GetByID(ctx context.Context, req *models.RepricingPrimaryKey) (*models.Repricing, error)
+	GetList(ctx context.Context, req *models.GetListRepricingRequest) (*models.GetListRepricingResponse, error)
+}
+
+// PromotionRepoI keeps the promotion rules. Active returns those running at
+// a time in a branch, Report sums the discounts sale lines got from each.
+type PromotionRepoI interface {
+	Create(ctx context.Context, req *models.CreatePromotion) (*models.Promotion, error)
+	GetByID(ctx context.Context, req *models.PromotionPrimaryKey) (*models.Promotion, error)
+	GetList(ctx context.Context, req *models.GetListPromotionRequest) (*models.GetListPromotionResponse, error)
+	Update(ctx context.Context, req *models.UpdatePromotion) (int64, error)
+	Delete(ctx context.Context, req *models.PromotionPrimaryKey) error
+	Active(ctx context.Context, branchId string, at time.Time) ([]*models.Promotion, error)
+	Report(ctx context.Context, req *models.PromotionReportRequest) (*models.PromotionReportResponse, error)
+}
+
+// CategoryRepoI keeps the category tree. Update returns ErrCategoryCycle
+// for a move under the category itself or one of its descendants. Subtree
+// returns the id of a category with those of all its descendants, Sales and
+// Stock roll up to the children of CategoryReportRequest.ParentID.
+type CategoryRepoI interface {
+	Create(ctx context.Context, req *models.CreateCategory) (*models.Category, error)
+	GetByID(ctx context.Context, req *models.CategoryPrimaryKey) (*models.Category, error)
+	GetList(ctx context.Context, req *models.GetListCategoryRequest) (*models.GetListCategoryResponse, error)
+	Update(ctx context.Context, req *models.UpdateCategory) (int64, error)
+	Delete(ctx context.Context, req *models.CategoryPrimaryKey) error
+	GetTree(ctx context.Context) ([]*models.CategoryNode, error)
+	Subtree(ctx context.Context, req *models.CategoryPrimaryKey) ([]string, error)
+	Sales(ctx context.Context, req *models.CategoryReportRequest) (*models.CategorySalesResponse, error)
+	Stock(ctx context.Context, req *models.CategoryReportRequest) (*models.CategoryStockResponse, error)
 }
 
 type SaleRepoI interface {
@@ -40,6 +121,8 @@ type SaleRepoI interface {
 	GetList(ctx context.Context, req *models.GetListSaleRequest) (*models.GetListSaleResponse, error)
 	Update(ctx context.Context, req *models.UpdateSale) (int64, error)
 	Delete(ctx context.Context, req *models.SalePrimaryKey) error
+	Checkout(ctx context.Context, req *models.CreateCheckout) (*models.Checkout, error)
+	DebtAging(ctx context.Context, req *models.DebtAgingRequest) (*models.DebtAgingResponse, error)
 }
 
 type SaleProductRepoI interface {
@@ -50,12 +133,95 @@ type SaleProductRepoI interface {
 	Delete(ctx context.Context, req *models.SaleProductPrimaryKey) error
 }
 
+// RemainderRepoI holds one row per lot of a product in a branch. GetList
+// lists the rows oldest first, all of them when Limit is zero.
 type RemainderRepoI interface {
 	Create(ctx context.Context, req *models.Remainder) (*models.Remainder, error)
 	GetByID(ctx context.Context, req *models.RemainderPrimaryKey) (*models.Remainder, error)
 	GetList(ctx context.Context, req *models.GetListRemainderRequest) (*models.GetListRemainderResponse, error)
 	Update(ctx context.Context, req *models.Remainder) (int64, error)
 	Delete(ctx context.Context, req *models.RemainderPrimaryKey) error
+	Expiring(ctx context.Context, req *models.ExpiringRequest) (*models.ExpiringResponse, error)
+}
+
+// StockMovementRepoI is the stock journal. Create keeps remainder in step
+// with the journal and fails with ErrNotEnoughQuantity instead of letting
+// a quantity go negative.
+type StockMovementRepoI interface {
+	Create(ctx context.Context, req *models.CreateStockMovement) (*models.StockMovement, error)
+	GetByID(ctx context.Context, req *models.StockMovementPrimaryKey) (*models.StockMovement, error)
+	GetList(ctx context.Context, req *models.GetListStockMovementRequest) (*models.GetListStockMovementResponse, error)
+	RebuildRemainder(ctx context.Context, req *models.RebuildRemainderRequest) (*models.RebuildRemainderResponse, error)
+}
+
+type TransferRepoI interface {
+	Create(ctx context.Context, req *models.CreateTransfer) (*models.Transfer, error)
+	GetByID(ctx context.Context, req *models.TransferPrimaryKey) (*models.Transfer, error)
+	GetList(ctx context.Context, req *models.GetListTransferRequest) (*models.GetListTransferResponse, error)
+	Update(ctx context.Context, req *models.UpdateTransfer) (int64, error)
+	Delete(ctx context.Context, req *models.TransferPrimaryKey) error
+	SetStatus(ctx context.Context, req *models.TransferStatus) (int64, error)
+	UpdateLine(ctx context.Context, req *models.UpdateTransferLine) (int64, error)
+	InTransit(ctx context.Context, req *models.InTransitRequest) (*models.InTransitResponse, error)
+}
+
+// BranchProductRepoI keeps the assortment of each branch with its price
+// overrides. SalePrice is what a branch sells a product at, the catalog
+// price when the branch does not override it, and Copy copies the
+// assortment of a branch to another.
+type BranchProductRepoI interface {
+	Set(ctx context.Context, req *models.SetBranchProduct) (*models.BranchProduct, error)
+	GetByID(ctx context.Context, req *models.BranchProductPrimaryKey) (*models.BranchProduct, error)
+	GetList(ctx context.Context, req *models.GetListBranchProductRequest) (*models.GetListBranchProductResponse, error)
+	Delete(ctx context.Context, req *models.BranchProductPrimaryKey) error
+	SalePrice(ctx context.Context, req *models.BranchProductPrimaryKey) (float64, error)
+	Copy(ctx context.Context, req *models.CopyCatalog) (*models.CopyCatalogResponse, error)
+}
+
+// StockLevelRepoI keeps the min and max stock of products per branch. Low
+// lists the products below their minimum, Reorder proposes the quantities
+// that bring them back to their maximum.
+type StockLevelRepoI interface {
+	Set(ctx context.Context, req *models.SetStockLevel) (*models.StockLevel, error)
+	GetByID(ctx context.Context, req *models.StockLevelPrimaryKey) (*models.StockLevel, error)
+	GetList(ctx context.Context, req *models.GetListStockLevelRequest) (*models.GetListStockLevelResponse, error)
+	Delete(ctx context.Context, req *models.StockLevelPrimaryKey) error
+	Low(ctx context.Context, req *models.LowStockRequest) (*models.LowStockResponse, error)
+	Reorder(ctx context.Context, req *models.ReorderRequest) (*models.ReorderResponse, error)
+}
+
+// PurchaseOrderRepoI keeps purchase orders. GetByID reconciles the lines
+// with the picking lists of the finished comings of the order, GetList
+// leaves the lines out.
+type PurchaseOrderRepoI interface {
+	Create(ctx context.Context, req *models.CreatePurchaseOrder) (*models.PurchaseOrder, error)
+	GetByID(ctx context.Context, req *models.PurchaseOrderPrimaryKey) (*models.PurchaseOrder, error)
+	GetList(ctx context.Context, req *models.GetListPurchaseOrderRequest) (*models.GetListPurchaseOrderResponse, error)
+	Update(ctx context.Context, req *models.UpdatePurchaseOrder) (int64, error)
+	Delete(ctx context.Context, req *models.PurchaseOrderPrimaryKey) error
+	SetStatus(ctx context.Context, req *models.PurchaseOrderStatus) (int64, error)
+}
+
+// SaleReturnRepoI records returns of sales. Create locks the sale, fails
+// with ErrOverReturn when a line would be returned beyond what was sold
+// and settles the money: the debt of the sale is reduced first, the rest
+// is refunded as a negative payment.
+type SaleReturnRepoI interface {
+	Create(ctx context.Context, req *models.CreateSaleReturn) (*models.SaleReturn, error)
+	GetByID(ctx context.Context, req *models.SaleReturnPrimaryKey) (*models.SaleReturn, error)
+	GetList(ctx context.Context, req *models.GetListSaleReturnRequest) (*models.GetListSaleReturnResponse, error)
+	// Returned is the quantity returned so far per sale_product of a sale.
+	Returned(ctx context.Context, req *models.SalePrimaryKey) (map[string]int, error)
+}
+
+type StockTakeRepoI interface {
+	Create(ctx context.Context, req *models.CreateStockTake) (*models.StockTake, error)
+	GetByID(ctx context.Context, req *models.StockTakePrimaryKey) (*models.StockTake, error)
+	GetList(ctx context.Context, req *models.GetListStockTakeRequest) (*models.GetListStockTakeResponse, error)
+	Delete(ctx context.Context, req *models.StockTakePrimaryKey) error
+	SetCounts(ctx context.Context, req *models.SetStockTakeCounts) (int64, error)
+	SetExpected(ctx context.Context, req *models.SetStockTakeExpected) (int64, error)
+	SetStatus(ctx context.Context, req *models.StockTakeStatus) (int64, error)
 }
 
 type BranchRepoI interface {
@@ -72,6 +238,7 @@ type ClientRepoI interface {
 	GetList(ctx context.Context, req *models.GetListClientRequest) (*models.GetListClientResponse, error)
 	Update(ctx context.Context, req *models.UpdateClient) (int64, error)
 	Delete(ctx context.Context, req *models.ClientPrimaryKey) error
+	Statement(ctx context.Context, req *models.ClientStatementRequest) (*models.ClientStatement, error)
 }
 
 type PickingListRepoI interface {
@@ -81,6 +248,41 @@ type PickingListRepoI interface {
 	Update(ctx context.Context, req *models.PickingList) (int64, error)
 	Delete(ctx context.Context, req *models.PickingListPrimaryKey) error
   }
-type IncrementIDRepoI interface {
-	GetLast(ctx context.Context, tableName string, columnName string) (string, error)
-  }
+type PaymentRepoI interface {
+	Create(ctx context.Context, req *models.CreatePayment) (*models.Payment, error)
+	GetByID(ctx context.Context, req *models.PaymentPrimaryKey) (*models.Payment, error)
+	GetList(ctx context.Context, req *models.GetListPaymentRequest) (*models.GetListPaymentResponse, error)
+}
+
+// SupplierRepoI holds the suppliers comings are received from. What a branch
+// owes a supplier is the total of its finished comings less its payments.
+type SupplierRepoI interface {
+	Create(ctx context.Context, req *models.CreateSupplier) (*models.Supplier, error)
+	GetByID(ctx context.Context, req *models.SupplierPrimaryKey) (*models.Supplier, error)
+	GetList(ctx context.Context, req *models.GetListSupplierRequest) (*models.GetListSupplierResponse, error)
+	Update(ctx context.Context, req *models.UpdateSupplier) (int64, error)
+	Delete(ctx context.Context, req *models.SupplierPrimaryKey) error
+	Statement(ctx context.Context, req *models.SupplierStatementRequest) (*models.SupplierStatement, error)
+	Payables(ctx context.Context, req *models.PayablesRequest) (*models.PayablesResponse, error)
+}
+
+// SupplierPaymentRepoI records payments to suppliers. Create locks the
+// supplier and fails with ErrOverpaySupplier when the payment exceeds what
+// the branch owes.
+type SupplierPaymentRepoI interface {
+	Create(ctx context.Context, req *models.CreateSupplierPayment) (*models.SupplierPayment, error)
+	GetByID(ctx context.Context, req *models.SupplierPaymentPrimaryKey) (*models.SupplierPayment, error)
+	GetList(ctx context.Context, req *models.GetListSupplierPaymentRequest) (*models.GetListSupplierPaymentResponse, error)
+}
+
+type UserRepoI interface {
+	Create(ctx context.Context, req *models.CreateUser) (*models.User, error)
+	GetByID(ctx context.Context, req *models.UserPrimaryKey) (*models.User, error)
+	GetList(ctx context.Context, req *models.GetListUserRequest) (*models.GetListUserResponse, error)
+	Update(ctx context.Context, req *models.UpdateUser) (int64, error)
+	Delete(ctx context.Context, req *models.UserPrimaryKey) error
+}
+
+type DocumentNumberRepoI interface {
+	Next(ctx context.Context, req *models.NextDocumentNumber) (string, error)
+}
@@ -0,0 +1,2135 @@
+// Package storagetest holds the conformance suite every storage.StorageI
+// implementation is expected to pass.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"market_system/models"
+	"market_system/pkg/apperror"
+	"market_system/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Run runs the conformance suite against strg. The suite only relies on
+// rows it creates itself, so it can run against a database that already
+// holds data.
+func Run(t *testing.T, strg storage.StorageI) {
+
+	t.Run("Branch", func(t *testing.T) { testBranch(t, strg) })
+	t.Run("ProductList", func(t *testing.T) { testProductList(t, strg) })
+	t.Run("Barcode", func(t *testing.T) { testBarcode(t, strg) })
+	t.Run("Category", func(t *testing.T) { testCategory(t, strg) })
+	t.Run("Unit", func(t *testing.T) { testUnit(t, strg) })
+	t.Run("BranchProduct", func(t *testing.T) { testBranchProduct(t, strg) })
+	t.Run("PriceHistory", func(t *testing.T) { testPriceHistory(t, strg) })
+	t.Run("Remainder", func(t *testing.T) { testRemainder(t, strg) })
+	t.Run("Filter", func(t *testing.T) { testFilter(t, strg) })
+	t.Run("Checkout", func(t *testing.T) { testCheckout(t, strg) })
+	t.Run("Payment", func(t *testing.T) { testPayment(t, strg) })
+	t.Run("ClientDebt", func(t *testing.T) { testClientDebt(t, strg) })
+	t.Run("SaleReturn", func(t *testing.T) { testSaleReturn(t, strg) })
+	t.Run("Promotion", func(t *testing.T) { testPromotion(t, strg) })
+	t.Run("ComingLifecycle", func(t *testing.T) { testComingLifecycle(t, strg) })
+	t.Run("StockMovement", func(t *testing.T) { testStockMovement(t, strg) })
+	t.Run("Transfer", func(t *testing.T) { testTransfer(t, strg) })
+	t.Run("StockTake", func(t *testing.T) { testStockTake(t, strg) })
+	t.Run("Lot", func(t *testing.T) { testLot(t, strg) })
+	t.Run("Supplier", func(t *testing.T) { testSupplier(t, strg) })
+	t.Run("PurchaseOrder", func(t *testing.T) { testPurchaseOrder(t, strg) })
+	t.Run("StockLevel", func(t *testing.T) { testStockLevel(t, strg) })
+	t.Run("User", func(t *testing.T) { testUser(t, strg) })
+	t.Run("WithTx", func(t *testing.T) { testWithTx(t, strg) })
+	t.Run("DocumentNumber", func(t *testing.T) { testDocumentNumber(t, strg) })
+}
+
+func testBranch(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor", Address: "Bunyodkor 1", Phone: "+998901234567"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: branch.Id})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Name != "Chilonzor" || got.Address != "Bunyodkor 1" || got.Phone != "+998901234567" {
+		t.Fatalf("unexpected branch %+v", got)
+	}
+
+	rows, err := strg.Branch().Update(ctx, &models.UpdateBranch{Id: branch.Id, Name: "Yunusobod"})
+	if err != nil || rows != 1 {
+		t.Fatalf("update: rows=%d err=%v", rows, err)
+	}
+
+	rows, err = strg.Branch().Update(ctx, &models.UpdateBranch{Id: uuid.New().String(), Name: "Yunusobod"})
+	if err != nil || rows != 0 {
+		t.Fatalf("update missing: rows=%d err=%v", rows, err)
+	}
+
+	got, err = strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: branch.Id})
+	if err != nil || got.Name != "Yunusobod" {
+		t.Fatalf("get after update: %+v %v", got, err)
+	}
+
+	if err = strg.Branch().Delete(ctx, &models.BranchPrimaryKey{Id: branch.Id}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err = strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: branch.Id}); err == nil {
+		t.Fatal("get after delete: expected error")
+	}
+}
+
+func testProductList(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	name := "conformance-" + uuid.New().String()[:8]
+	for i := 0; i < 3; i++ {
+		_, err = strg.Product().Create(ctx, &models.CreateProduct{Name: name, Price: 1000, BranchID: branch.Id})
+		if err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	first, err := strg.Product().GetList(ctx, &models.GetListProductRequest{Limit: 2, Search: name})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if first.Count != 3 || len(first.Products) != 2 {
+		t.Fatalf("first page: count=%d len=%d", first.Count, len(first.Products))
+	}
+
+	second, err := strg.Product().GetList(ctx, &models.GetListProductRequest{Offset: 2, Limit: 2, Search: name})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if second.Count != 3 || len(second.Products) != 1 {
+		t.Fatalf("second page: count=%d len=%d", second.Count, len(second.Products))
+	}
+
+	for _, p := range first.Products {
+		if p.Id == second.Products[0].Id {
+			t.Fatalf("product %s returned on both pages", p.Id)
+		}
+	}
+}
+
+func testBarcode(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Mirobod"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	// codes are unique over the database, so every run makes up new ones
+	var (
+		ean   = &models.Barcode{Code: fmt.Sprintf("%012d", time.Now().UnixNano()%1e12), Type: models.BarcodeEAN13}
+		extra = &models.Barcode{Code: "INT-" + uuid.New().String()[:8], Type: models.BarcodeInternal}
+	)
+	ean.Code += string(checkDigit(ean.Code))
+
+	product, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Paracetamol", Price: 5000, BranchID: branch.Id, Barcodes: []*models.Barcode{ean}})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if len(product.Barcodes) != 1 || *product.Barcodes[0] != *ean {
+		t.Fatalf("unexpected barcodes %+v", product.Barcodes)
+	}
+
+	_, err = strg.Product().Create(ctx, &models.CreateProduct{Name: "Copy", Price: 5000, BranchID: branch.Id, Barcodes: []*models.Barcode{ean}})
+	var appErr *apperror.Error
+	if !errors.As(apperror.Translate(err), &appErr) || appErr.Code != apperror.Conflict || len(appErr.Fields["code"]) == 0 {
+		t.Fatalf("expected a conflict on code, got %v", err)
+	}
+
+	// without stock the product price is shown
+	found, err := strg.Product().GetByBarcode(ctx, &models.ProductByBarcodeRequest{Code: ean.Code, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("get by barcode: %v", err)
+	}
+	if found.Product.Id != product.Id || found.SalePrice != 5000 || found.Quantity != 0 || found.Barcode != *ean {
+		t.Fatalf("unexpected scan %+v", found)
+	}
+
+	_, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 4, SalePrice: 5500, BranchID: branch.Id, LotNumber: "L1", ExpiryDate: time.Now().AddDate(1, 0, 0).Format("2006-01-02")})
+	if err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+	_, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 3, SalePrice: 6000, BranchID: branch.Id, LotNumber: "L2"})
+	if err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	// the lot expiring first is the one sold first
+	if found, _ = strg.Product().GetByBarcode(ctx, &models.ProductByBarcodeRequest{Code: ean.Code, BranchID: branch.Id}); found.SalePrice != 5500 || found.Quantity != 7 {
+		t.Fatalf("unexpected scan with stock %+v", found)
+	}
+
+	rows, err := strg.Product().Update(ctx, &models.UpdateProduct{Id: product.Id, Name: product.Name, Price: 5000, BranchID: branch.Id, Barcodes: []*models.Barcode{extra}})
+	if err != nil || rows != 1 {
+		t.Fatalf("update: rows=%d err=%v", rows, err)
+	}
+
+	if _, err = strg.Product().GetByBarcode(ctx, &models.ProductByBarcodeRequest{Code: ean.Code}); err == nil {
+		t.Fatal("replaced barcode is still found")
+	}
+
+	list, err := strg.Product().GetList(ctx, &models.GetListProductRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}}},
+	})
+	if err != nil || list.Count != 1 || len(list.Products[0].Barcodes) != 1 || *list.Products[0].Barcodes[0] != *extra {
+		t.Fatalf("unexpected product list %+v err=%v", list, err)
+	}
+}
+
+func testCategory(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Mirobod"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	// medicine > pain > tablets, medicine > vitamins
+	medicine, err := strg.Category().Create(ctx, &models.CreateCategory{Name: "Medicine " + uuid.New().String()[:8]})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	pain, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Pain", ParentID: medicine.Id})
+	tablets, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Tablets", ParentID: pain.Id})
+	vitamins, _ := strg.Category().Create(ctx, &models.CreateCategory{Name: "Vitamins", ParentID: medicine.Id})
+	if tablets.ParentID != pain.Id {
+		t.Fatalf("unexpected category %+v", tablets)
+	}
+
+	subtree, err := strg.Category().Subtree(ctx, &models.CategoryPrimaryKey{Id: pain.Id})
+	if err != nil || len(subtree) != 2 {
+		t.Fatalf("subtree of pain = %v err=%v", subtree, err)
+	}
+
+	// a category does not go under itself or its descendants
+	for _, parent := range []*models.Category{pain, tablets} {
+		if _, err = strg.Category().Update(ctx, &models.UpdateCategory{Id: pain.Id, Name: "Pain", ParentID: parent.Id}); !errors.Is(err, storage.ErrCategoryCycle) {
+			t.Fatalf("move pain under %s: %v", parent.Name, err)
+		}
+	}
+	if moved, err := strg.Category().Update(ctx, &models.UpdateCategory{Id: tablets.Id, Name: "Tablets", ParentID: pain.Id}); err != nil || moved != 1 {
+		t.Fatalf("update tablets: %d %v", moved, err)
+	}
+
+	tree, err := strg.Category().GetTree(ctx)
+	if err != nil {
+		t.Fatalf("tree: %v", err)
+	}
+	i := nodeIndex(tree, medicine.Id)
+	if i < 0 || len(tree[i].Children) != 2 || tree[i].Children[0].Id != pain.Id || tree[i].Children[0].Children[0].Id != tablets.Id {
+		t.Fatalf("unexpected tree under %s", medicine.Name)
+	}
+
+	var products = map[string]*models.Product{}
+	for _, category := range []*models.Category{medicine, tablets, vitamins} {
+		product, err := strg.Product().Create(ctx, &models.CreateProduct{Name: category.Name, Price: 1000, BranchID: branch.Id, CategoryID: category.Id})
+		if err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		products[category.Id] = product
+
+		if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 10, ComingPrice: 600, SalePrice: 1000, BranchID: branch.Id}); err != nil {
+			t.Fatalf("create remainder: %v", err)
+		}
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products: []*models.CheckoutProduct{
+			{ProductID: products[tablets.Id].Id, Quantity: 3},
+			{ProductID: products[vitamins.Id].Id, Quantity: 1},
+			{ProductID: products[medicine.Id].Id, Quantity: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	_, err = strg.SaleReturn().Create(ctx, &models.CreateSaleReturn{
+		IncrementID: "R-" + uuid.New().String()[:8],
+		SaleID:      checkout.Sale.Id,
+		Method:      "cash",
+		Lines:       []*models.CreateSaleReturnLine{{SaleProductID: checkout.SaleProducts[0].Id, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("return: %v", err)
+	}
+
+	list, err := strg.Product().GetList(ctx, &models.GetListProductRequest{
+		Filter: models.Filter{Fields: map[string][]string{"category_id": subtree}},
+	})
+	if err != nil || list.Count != 1 || list.Products[0].CategoryID != tablets.Id {
+		t.Fatalf("products under pain %+v err=%v", list, err)
+	}
+
+	// tablets roll up into pain, the medicine product is the parent's own row
+	sales, err := strg.Category().Sales(ctx, &models.CategoryReportRequest{ParentID: medicine.Id, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("sales: %v", err)
+	}
+	if len(sales.Categories) != 3 || sales.Quantity != 6 || sales.Amount != 6000 || sales.NetQuantity != 5 || sales.NetAmount != 5000 {
+		t.Fatalf("unexpected sales %+v", sales)
+	}
+	if row := sales.Categories[0]; row.CategoryID != pain.Id || row.Quantity != 3 || row.Amount != 3000 || row.ReturnedQuantity != 1 || row.ReturnedAmount != 1000 || row.NetAmount != 2000 {
+		t.Fatalf("unexpected pain row %+v", row)
+	}
+	if row := sales.Categories[2]; row.CategoryID != medicine.Id || row.Quantity != 2 {
+		t.Fatalf("unexpected own row %+v", row)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if sales, _ = strg.Category().Sales(ctx, &models.CategoryReportRequest{ParentID: medicine.Id, BranchID: branch.Id, From: &future}); sales.Quantity != 0 || len(sales.Categories) != 2 {
+		t.Fatalf("sales from the future %+v", sales)
+	}
+
+	stock, err := strg.Category().Stock(ctx, &models.CategoryReportRequest{ParentID: pain.Id, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("stock: %v", err)
+	}
+	if len(stock.Categories) != 1 || stock.Quantity != 7 || stock.Cost != 4200 || stock.Value != 7000 {
+		t.Fatalf("unexpected stock %+v", stock)
+	}
+
+	// the whole medicine tree is one row at the root level
+	stock, err = strg.Category().Stock(ctx, &models.CategoryReportRequest{BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("stock at the root: %v", err)
+	}
+	var root *models.CategoryStock
+	for _, row := range stock.Categories {
+		if row.CategoryID == medicine.Id {
+			root = row
+		}
+	}
+	if root == nil || root.Quantity != 24 {
+		t.Fatalf("unexpected root stock %+v", stock.Categories)
+	}
+}
+
+func testUnit(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yunusobod"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	product, err := strg.Product().Create(ctx, &models.CreateProduct{
+		Name:     "Citramon",
+		Price:    1000,
+		BranchID: branch.Id,
+		Unit:     "blister",
+		Units: []*models.ProductUnit{
+			{Name: "box", Factor: 10, Price: 9000},
+			{Name: "pack", Factor: 5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if product.Unit != "blister" || len(product.Units) != 2 || *product.Units[0] != (models.ProductUnit{Name: "box", Factor: 10, Price: 9000}) {
+		t.Fatalf("unexpected units %s %+v", product.Unit, product.Units)
+	}
+
+	// nil units and an empty unit keep what the product has
+	_, err = strg.Product().Update(ctx, &models.UpdateProduct{Id: product.Id, Name: "Citramon P", Price: 1000, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+	if product, _ = strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: product.Id}); product.Unit != "blister" || len(product.Units) != 2 {
+		t.Fatalf("units after update %s %+v", product.Unit, product.Units)
+	}
+
+	plain, _ := strg.Product().Create(ctx, &models.CreateProduct{Name: "Plain", Price: 1000, BranchID: branch.Id})
+	if plain.Unit != models.DefaultUnit || len(plain.Units) != 0 {
+		t.Fatalf("default unit %q %+v", plain.Unit, plain.Units)
+	}
+
+	if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 40, ComingPrice: 700, SalePrice: 1000, BranchID: branch.Id}); err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	box, _ := product.FindUnit("box")
+	pack, _ := product.FindUnit("PACK")
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products: []*models.CheckoutProduct{
+			{ProductID: product.Id, Quantity: 2, Unit: box.Name, Pack: box},
+			{ProductID: product.Id, Quantity: 1, Unit: pack.Name, Pack: pack},
+			{ProductID: product.Id, Quantity: 3},
+		},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	// a box has a price of its own, a pack is five blisters
+	if checkout.Sale.TotalPrice != 2*9000+5000+3000 {
+		t.Fatalf("unexpected total %v", checkout.Sale.TotalPrice)
+	}
+	if line := checkout.SaleProducts[0]; line.Quantity != 20 || line.Price != 900 || line.Unit != "box" || line.UnitQuantity != 2 || line.UnitPrice != 9000 || line.TotalPrice != 18000 {
+		t.Fatalf("unexpected box line %+v", line)
+	}
+	if line := checkout.SaleProducts[1]; line.Quantity != 5 || line.Price != 1000 || line.UnitPrice != 5000 {
+		t.Fatalf("unexpected pack line %+v", line)
+	}
+
+	remainders, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"product_id": {product.Id}}},
+	})
+	if err != nil || remainders.Count != 1 || remainders.Remainders[0].Quantity != 40-20-5-3 {
+		t.Fatalf("stock in blisters %+v err=%v", remainders, err)
+	}
+
+	_, err = strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 2, Unit: box.Name, Pack: box}},
+	})
+	if !errors.Is(err, storage.ErrNotEnoughQuantity) {
+		t.Fatalf("two boxes out of 12 blisters: %v", err)
+	}
+}
+
+func testBranchProduct(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	chilonzor, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Chilonzor"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	sergeli, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Sergeli"})
+	opened, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Bektemir"})
+
+	// made for a branch it is in its assortment, a catalog product is in none
+	aspirin, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 4000, BranchID: chilonzor.Id})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	ibuprofen, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Ibuprofen " + uuid.New().String()[:8], Price: 6000})
+	if err != nil {
+		t.Fatalf("create catalog product: %v", err)
+	}
+	if ibuprofen.BranchID != "" {
+		t.Fatalf("catalog product with a branch %+v", ibuprofen)
+	}
+
+	products, err := strg.Product().GetList(ctx, &models.GetListProductRequest{Search: ibuprofen.Name})
+	if err != nil || products.Count != 1 {
+		t.Fatalf("catalog product is listed %+v err=%v", products, err)
+	}
+
+	own, err := strg.BranchProduct().GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: chilonzor.Id, ProductID: aspirin.Id})
+	if err != nil || own.Price != nil || own.SalePrice != 4000 || own.Name != "Aspirin" {
+		t.Fatalf("assortment of the product's branch %+v err=%v", own, err)
+	}
+
+	price := 6500.0
+	if _, err = strg.BranchProduct().Set(ctx, &models.SetBranchProduct{BranchID: chilonzor.Id, ProductID: ibuprofen.Id, Price: &price}); err != nil {
+		t.Fatalf("set branch product: %v", err)
+	}
+
+	// a branch lists the products it carries, whichever branch they were made for
+	for branchID, want := range map[string]int{chilonzor.Id: 2, sergeli.Id: 0} {
+		carried, err := strg.Product().GetList(ctx, &models.GetListProductRequest{
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchID}}},
+		})
+		if err != nil || carried.Count != want {
+			t.Fatalf("products of branch %s %+v err=%v, want %d", branchID, carried, err, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		branchID, productID string
+		price               float64
+	}{
+		{chilonzor.Id, ibuprofen.Id, 6500},
+		{chilonzor.Id, aspirin.Id, 4000},
+		{sergeli.Id, ibuprofen.Id, 6000},
+	} {
+		got, err := strg.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: tt.branchID, ProductID: tt.productID})
+		if err != nil || got != tt.price {
+			t.Fatalf("sale price = %v err=%v, want %v", got, err, tt.price)
+		}
+	}
+
+	if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: ibuprofen.Id, Quantity: 5, ComingPrice: 4000, SalePrice: 6000, BranchID: opened.Id}); err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	copied, err := strg.BranchProduct().Copy(ctx, &models.CopyCatalog{FromBranchID: chilonzor.Id, ToBranchID: opened.Id})
+	if err != nil || copied.Copied != 2 {
+		t.Fatalf("copy catalog %+v err=%v", copied, err)
+	}
+
+	// the stock the target holds sells at the copied prices
+	onHand, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {opened.Id}}},
+	})
+	if err != nil || onHand.Count != 1 || onHand.Remainders[0].SalePrice != 6500 {
+		t.Fatalf("stock of the target %+v err=%v", onHand, err)
+	}
+
+	list, err := strg.BranchProduct().GetList(ctx, &models.GetListBranchProductRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {opened.Id}}},
+	})
+	if err != nil || list.Count != 2 || list.BranchProducts[0].ProductID != aspirin.Id || *list.BranchProducts[1].Price != 6500 {
+		t.Fatalf("copied assortment %+v err=%v", list, err)
+	}
+
+	// a remainder rebuilt from the journal is priced for the branch
+	movement, err := strg.StockMovement().Create(ctx, &models.CreateStockMovement{BranchID: opened.Id, ProductID: ibuprofen.Id, Quantity: 3, Type: models.MovementAdjustment})
+	if err != nil {
+		t.Fatalf("create movement: %v", err)
+	}
+	stock := func() *models.GetListRemainderResponse {
+		remainders, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {opened.Id}, "product_id": {ibuprofen.Id}}},
+		})
+		if err != nil {
+			t.Fatalf("remainders: %v", err)
+		}
+		return remainders
+	}
+	if err = strg.Remainder().Delete(ctx, &models.RemainderPrimaryKey{Id: stock().Remainders[0].Id}); err != nil {
+		t.Fatalf("delete remainder of %s: %v", movement.Id, err)
+	}
+	if _, err = strg.StockMovement().RebuildRemainder(ctx, &models.RebuildRemainderRequest{BranchID: opened.Id}); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if remainders := stock(); remainders.Count != 1 || remainders.Remainders[0].Quantity != 3 || remainders.Remainders[0].SalePrice != 6500 {
+		t.Fatalf("rebuilt remainder %+v", remainders)
+	}
+
+	if err = strg.BranchProduct().Delete(ctx, &models.BranchProductPrimaryKey{BranchID: opened.Id, ProductID: aspirin.Id}); err != nil {
+		t.Fatalf("delete branch product: %v", err)
+	}
+	if _, err = strg.BranchProduct().GetByID(ctx, &models.BranchProductPrimaryKey{BranchID: opened.Id, ProductID: aspirin.Id}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("deleted branch product: %v", err)
+	}
+}
+
+func testPriceHistory(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yunusobod"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	product, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Paracetamol", Price: 1000})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 10, ComingPrice: 600, SalePrice: 1000, BranchID: branch.Id}); err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	// charged is what the checkout of a unit in the branch charges
+	charged := func() float64 {
+		checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+			ClientID:    client.Id,
+			BranchID:    branch.Id,
+			IncrementID: "S-" + uuid.New().String()[:8],
+			Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+		return checkout.SaleProducts[0].Price
+	}
+
+	created := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	// an edit of the price is a change of the history, saving it unchanged is not
+	for _, price := range []float64{1200, 1200} {
+		if _, err = strg.Product().Update(ctx, &models.UpdateProduct{Id: product.Id, Name: product.Name, Price: price}); err != nil {
+			t.Fatalf("update product: %v", err)
+		}
+	}
+
+	history := func(pending bool) *models.GetListPriceChangeResponse {
+		list, err := strg.PriceChange().GetList(ctx, &models.GetListPriceChangeRequest{
+			Pending: pending,
+			Filter:  models.Filter{Fields: map[string][]string{"product_id": {product.Id}}},
+		})
+		if err != nil {
+			t.Fatalf("price history: %v", err)
+		}
+		return list
+	}
+	if list := history(false); list.Count != 2 || *list.PriceChanges[0].Price != 1200 || *list.PriceChanges[1].Price != 1000 {
+		t.Fatalf("price history %+v", list)
+	}
+	if got := charged(); got != 1200 {
+		t.Fatalf("checkout after the edit charged %v, want 1200", got)
+	}
+
+	asOf := func(branchID string, at time.Time) float64 {
+		price, err := strg.PriceChange().AsOf(ctx, &models.PriceAsOfRequest{ProductID: product.Id, BranchID: branchID, At: at})
+		if err != nil {
+			t.Fatalf("price as of %v: %v", at, err)
+		}
+		return price.Price
+	}
+	if got := asOf("", created); got != 1000 {
+		t.Fatalf("price when created = %v, want 1000", got)
+	}
+	if got := asOf(branch.Id, time.Now()); got != 1200 {
+		t.Fatalf("price in a branch without override = %v, want 1200", got)
+	}
+	if _, err = strg.PriceChange().AsOf(ctx, &models.PriceAsOfRequest{ProductID: product.Id, At: created.Add(-time.Hour)}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("price before the product existed: %v", err)
+	}
+
+	// a change in the future waits until it is due
+	var (
+		price    = 1500.0
+		tomorrow = time.Now().Add(24 * time.Hour)
+	)
+	scheduled, err := strg.PriceChange().Create(ctx, &models.CreatePriceChange{ProductID: product.Id, Price: &price, EffectiveFrom: &tomorrow})
+	if err != nil || scheduled.AppliedAt != "" {
+		t.Fatalf("schedule price change %+v err=%v", scheduled, err)
+	}
+	if list := history(true); list.Count != 1 || list.PriceChanges[0].Id != scheduled.Id {
+		t.Fatalf("pending changes %+v", list)
+	}
+	if got := asOf("", tomorrow.Add(time.Minute)); got != 1500 {
+		t.Fatalf("price tomorrow = %v, want 1500", got)
+	}
+	if applied, err := strg.PriceChange().ApplyDue(ctx, time.Now()); err != nil || applied != 0 {
+		t.Fatalf("applied %d err=%v before it is due", applied, err)
+	}
+	if got, _ := strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: product.Id}); got.Price != 1200 {
+		t.Fatalf("price before the change is due %v", got.Price)
+	}
+	if applied, err := strg.PriceChange().ApplyDue(ctx, tomorrow.Add(time.Minute)); err != nil || applied < 1 {
+		t.Fatalf("applied %d err=%v when due", applied, err)
+	}
+	if got, _ := strg.Product().GetByID(ctx, &models.ProductPrimaryKey{Id: product.Id}); got.Price != 1500 {
+		t.Fatalf("price after the change is due %v", got.Price)
+	}
+	if got := charged(); got != 1500 {
+		t.Fatalf("checkout after the change is due charged %v, want 1500", got)
+	}
+	if n, err := strg.PriceChange().Cancel(ctx, &models.PriceChangePrimaryKey{Id: scheduled.Id}); err != nil || n != 0 {
+		t.Fatalf("cancelled an applied change %d err=%v", n, err)
+	}
+
+	// the override of a branch, applied right away without effective_from
+	override := 1400.0
+	if _, err = strg.PriceChange().Create(ctx, &models.CreatePriceChange{ProductID: product.Id, BranchID: branch.Id, Price: &override}); err != nil {
+		t.Fatalf("branch price change: %v", err)
+	}
+	if got, err := strg.BranchProduct().SalePrice(ctx, &models.BranchProductPrimaryKey{BranchID: branch.Id, ProductID: product.Id}); err != nil || got != 1400 {
+		t.Fatalf("branch sale price %v err=%v", got, err)
+	}
+	if got := asOf(branch.Id, time.Now()); got != 1400 {
+		t.Fatalf("branch price now = %v, want 1400", got)
+	}
+	if got := asOf(branch.Id, created); got != 1000 {
+		t.Fatalf("branch price before its override = %v, want the catalog 1000", got)
+	}
+	if got := charged(); got != 1400 {
+		t.Fatalf("checkout after the branch override charged %v, want 1400", got)
+	}
+
+	// stock left in a branch that no longer carries the product sells at the catalog price
+	if err = strg.BranchProduct().Delete(ctx, &models.BranchProductPrimaryKey{BranchID: branch.Id, ProductID: product.Id}); err != nil {
+		t.Fatalf("delete branch product: %v", err)
+	}
+	if got := charged(); got != 1500 {
+		t.Fatalf("checkout after the override is dropped charged %v, want 1500", got)
+	}
+	if _, err = strg.BranchProduct().Set(ctx, &models.SetBranchProduct{BranchID: branch.Id, ProductID: product.Id, Price: &override}); err != nil {
+		t.Fatalf("set branch product: %v", err)
+	}
+
+	// a repricing schedules its lines
+	var (
+		repriced  = 990.0
+		nextMonth = time.Now().AddDate(0, 1, 0)
+	)
+	repricing, err := strg.Repricing().Create(ctx, &models.CreateRepricing{
+		IncrementID:   "RP-" + uuid.New().String()[:8],
+		BranchID:      branch.Id,
+		Percent:       func() *float64 { p := -10.0; return &p }(),
+		EffectiveFrom: &nextMonth,
+		Lines:         []*models.CreatePriceChange{{ProductID: product.Id, BranchID: branch.Id, Price: &repriced}},
+	})
+	if err != nil || len(repricing.Lines) != 1 || repricing.Lines[0].DocumentID != repricing.Id || repricing.Lines[0].AppliedAt != "" || *repricing.Percent != -10 {
+		t.Fatalf("create repricing %+v err=%v", repricing, err)
+	}
+	if got := asOf(branch.Id, nextMonth.Add(time.Minute)); got != 990 {
+		t.Fatalf("branch price next month = %v, want 990", got)
+	}
+	list, err := strg.Repricing().GetList(ctx, &models.GetListRepricingRequest{
+		Filter: models.Filter{Fields: map[string][]string{"increment_id": {repricing.IncrementID}}},
+	})
+	if err != nil || list.Count != 1 || list.Repricings[0].Id != repricing.Id {
+		t.Fatalf("repricings %+v err=%v", list, err)
+	}
+	if n, err := strg.PriceChange().Cancel(ctx, &models.PriceChangePrimaryKey{Id: repricing.Lines[0].Id}); err != nil || n != 1 {
+		t.Fatalf("cancel a pending change %d err=%v", n, err)
+	}
+	if got := asOf(branch.Id, nextMonth.Add(time.Minute)); got != 1400 {
+		t.Fatalf("branch price next month after cancel = %v, want 1400", got)
+	}
+}
+
+func nodeIndex(nodes []*models.CategoryNode, id string) int {
+	for i, node := range nodes {
+		if node.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkDigit is the EAN check digit of code, to make up valid codes.
+func checkDigit(code string) byte {
+	var sum int
+	for i := range code {
+		weight := 1
+		if (len(code)-i)%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(code[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func testRemainder(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+
+	remainder, err := strg.Remainder().Create(ctx, &models.Remainder{
+		ProductID:   product.Id,
+		Name:        product.Name,
+		Quantity:    7,
+		ComingPrice: 4000,
+		SalePrice:   5000,
+		BranchID:    branch.Id,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if remainder.ProductID != product.Id || remainder.BranchID != branch.Id || remainder.Quantity != 7 ||
+		remainder.ComingPrice != 4000 || remainder.SalePrice != 5000 {
+		t.Fatalf("unexpected remainder %+v", remainder)
+	}
+}
+
+func testCheckout(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	remainder, err := strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 5, SalePrice: 6000, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-9999991",
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if checkout.Sale.TotalPrice != 12000 || checkout.Sale.Debd != 12000 || len(checkout.SaleProducts) != 1 {
+		t.Fatalf("unexpected checkout %+v", checkout.Sale)
+	}
+	if checkout.SaleProducts[0].Price != 6000 || checkout.SaleProducts[0].TotalPrice != 12000 {
+		t.Fatalf("unexpected line %+v", checkout.SaleProducts[0])
+	}
+
+	assertQuantity(t, strg, remainder.Id, 3)
+
+	_, err = strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-9999992",
+		Products: []*models.CheckoutProduct{
+			{ProductID: product.Id, Quantity: 1},
+			{ProductID: product.Id, Quantity: 10},
+		},
+	})
+	if !errors.Is(err, storage.ErrNotEnoughQuantity) {
+		t.Fatalf("expected ErrNotEnoughQuantity, got %v", err)
+	}
+
+	// the first line must be rolled back together with the failing one
+	assertQuantity(t, strg, remainder.Id, 3)
+}
+
+func testPayment(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, _ := createProduct(t, strg, 1000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	sale, err := strg.Sale().Create(ctx, &models.CreateSale{ClientID: client.Id, BranchID: branch.Id, IncrementID: "S-9999981", TotalPrice: 10000})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+
+	for _, amount := range []float64{4000, 5000, -2000} {
+		if _, err := strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: amount, Method: "cash"}); err != nil {
+			t.Fatalf("pay %v: %v", amount, err)
+		}
+	}
+
+	_, err = strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: 3001, Method: "card"})
+	if !errors.Is(err, storage.ErrOverpayment) {
+		t.Fatalf("expected ErrOverpayment, got %v", err)
+	}
+
+	_, err = strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: -7001, Method: "cash"})
+	if !errors.Is(err, storage.ErrOverRefund) {
+		t.Fatalf("expected ErrOverRefund, got %v", err)
+	}
+
+	got, err := strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: sale.Id})
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if got.Paid != 7000 || got.Debd != 3000 {
+		t.Fatalf("want paid 7000 and debt 3000, got %v and %v", got.Paid, got.Debd)
+	}
+
+	// an edit of the sale keeps what was paid and moves the debt with the total
+	if _, err = strg.Sale().Update(ctx, &models.UpdateSale{Id: sale.Id, ClientID: client.Id, BranchID: branch.Id, IncrementID: sale.IncrementID, TotalPrice: 12000}); err != nil {
+		t.Fatalf("update sale: %v", err)
+	}
+	if got, _ = strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: sale.Id}); got.Paid != 7000 || got.Debd != 5000 {
+		t.Fatalf("want paid 7000 and debt 5000 after the edit, got %v and %v", got.Paid, got.Debd)
+	}
+
+	list, err := strg.Payment().GetList(ctx, &models.GetListPaymentRequest{
+		Limit:  10,
+		Filter: models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}},
+	})
+	if err != nil {
+		t.Fatalf("list payments: %v", err)
+	}
+	if list.Count != 3 || list.Payments[0].Amount != -2000 {
+		t.Fatalf("unexpected history %+v", list)
+	}
+
+	// payments are of the branch of their sale
+	other, _ := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Yakkasaroy"})
+	for branchID, want := range map[string]int{branch.Id: 3, other.Id: 0} {
+		list, err = strg.Payment().GetList(ctx, &models.GetListPaymentRequest{
+			Filter: models.Filter{Fields: map[string][]string{"branch_id": {branchID}}},
+		})
+		if err != nil || list.Count != want {
+			t.Fatalf("payments of branch %s %+v err=%v, want %d", branchID, list, err, want)
+		}
+	}
+}
+
+func testClientDebt(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, _ := createProduct(t, strg, 1000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Olim", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	sale, err := strg.Sale().Create(ctx, &models.CreateSale{ClientID: client.Id, BranchID: branch.Id, IncrementID: "S-9999971", TotalPrice: 10000})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+
+	if _, err := strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: 3000, Method: "card"}); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+
+	statement, err := strg.Client().Statement(ctx, &models.ClientStatementRequest{ClientID: client.Id})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if len(statement.Entries) != 2 || statement.Entries[0].Balance != 10000 || statement.Entries[1].Balance != 7000 || statement.ClosingBalance != 7000 {
+		t.Fatalf("unexpected statement %+v", statement)
+	}
+
+	later := time.Now().Add(time.Hour)
+	statement, err = strg.Client().Statement(ctx, &models.ClientStatementRequest{ClientID: client.Id, From: &later})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if len(statement.Entries) != 0 || statement.OpeningBalance != 7000 || statement.ClosingBalance != 7000 {
+		t.Fatalf("unexpected statement from %v: %+v", later, statement)
+	}
+
+	at := time.Now().AddDate(0, 0, 45)
+	aging, err := strg.Sale().DebtAging(ctx, &models.DebtAgingRequest{BranchID: branch.Id, At: &at})
+	if err != nil {
+		t.Fatalf("debt aging: %v", err)
+	}
+	if len(aging.Clients) != 1 || aging.Clients[0].Days31To60 != 7000 || aging.Total.Total != 7000 {
+		t.Fatalf("unexpected aging %+v", aging.Clients)
+	}
+}
+
+func testSaleReturn(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Vali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 5, SalePrice: 6000, BranchID: branch.Id}); err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	var (
+		sale = checkout.Sale
+		line = checkout.SaleProducts[0]
+	)
+
+	if _, err = strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: 20000, Method: "cash"}); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+
+	// 6000 back: 4000 of debt is written off, 2000 refunded
+	saleReturn, err := strg.SaleReturn().Create(ctx, &models.CreateSaleReturn{
+		IncrementID: "R-" + uuid.New().String()[:8],
+		SaleID:      sale.Id,
+		Method:      "cash",
+		Lines:       []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if saleReturn.TotalPrice != 6000 || saleReturn.Quantity != 1 || saleReturn.DebtReduced != 4000 || saleReturn.Refund != 2000 ||
+		len(saleReturn.RefundPaymentID) == 0 || len(saleReturn.Lines) != 1 || saleReturn.Lines[0].ProductID != product.Id {
+		t.Fatalf("unexpected return %+v", saleReturn)
+	}
+
+	sale, err = strg.Sale().GetByID(ctx, &models.SalePrimaryKey{Id: sale.Id})
+	if err != nil || sale.Returned != 6000 || sale.Paid != 18000 || sale.Debd != 0 {
+		t.Fatalf("sale after return: %+v %v", sale, err)
+	}
+
+	if _, err = strg.Payment().Create(ctx, &models.CreatePayment{SaleID: sale.Id, Amount: 1, Method: "cash"}); !errors.Is(err, storage.ErrOverpayment) {
+		t.Fatalf("expected ErrOverpayment after return, got %v", err)
+	}
+
+	_, err = strg.SaleReturn().Create(ctx, &models.CreateSaleReturn{
+		IncrementID: "R-" + uuid.New().String()[:8],
+		SaleID:      sale.Id,
+		Method:      "cash",
+		Lines:       []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 4}},
+	})
+	if !errors.Is(err, storage.ErrOverReturn) {
+		t.Fatalf("expected ErrOverReturn, got %v", err)
+	}
+
+	returned, err := strg.SaleReturn().Returned(ctx, &models.SalePrimaryKey{Id: sale.Id})
+	if err != nil || returned[line.Id] != 1 {
+		t.Fatalf("returned: %v %v", returned, err)
+	}
+
+	list, err := strg.SaleReturn().GetList(ctx, &models.GetListSaleReturnRequest{
+		Filter: models.Filter{Fields: map[string][]string{"sale_id": {sale.Id}}},
+	})
+	if err != nil || list.Count != 1 || list.SaleReturns[0].Quantity != 1 {
+		t.Fatalf("list: %+v %v", list, err)
+	}
+
+	statement, err := strg.Client().Statement(ctx, &models.ClientStatementRequest{ClientID: client.Id})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if len(statement.Entries) != 4 || statement.Entries[2].Type != "return" || statement.Entries[2].Credit != 6000 || statement.ClosingBalance != 0 {
+		t.Fatalf("unexpected statement %+v", statement)
+	}
+}
+
+func testPromotion(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Aziz", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id, Group: "vip"})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	clients, err := strg.Client().GetList(ctx, &models.GetListClientRequest{
+		Filter: models.Filter{Fields: map[string][]string{"group": {"vip"}, "branch_id": {branch.Id}}},
+	})
+	if err != nil || clients.Count != 1 || clients.Clients[0].Group != "vip" {
+		t.Fatalf("clients of the group: %+v %v", clients, err)
+	}
+
+	if _, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Quantity: 10, SalePrice: 6000, BranchID: branch.Id}); err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	var (
+		lastWeek  = time.Now().Add(-7 * 24 * time.Hour)
+		yesterday = time.Now().Add(-24 * time.Hour)
+		hourAgo   = time.Now().Add(-time.Hour)
+		nextWeek  = time.Now().Add(7 * 24 * time.Hour)
+	)
+
+	create := func(req *models.CreatePromotion) *models.Promotion {
+		req.BranchID = branch.Id
+		promotion, err := strg.Promotion().Create(ctx, req)
+		if err != nil {
+			t.Fatalf("create promotion %s: %v", req.Name, err)
+		}
+		return promotion
+	}
+
+	var (
+		percent = create(&models.CreatePromotion{Name: "10% off", Type: models.PromotionPercent, Value: 10, ProductID: product.Id, StartsAt: &hourAgo})
+		buyGet  = create(&models.CreatePromotion{Name: "2+1", Type: models.PromotionBuyGet, BuyQuantity: 2, FreeQuantity: 1, ClientGroup: "vip", StartsAt: &hourAgo})
+		expired = create(&models.CreatePromotion{Name: "Last week", Type: models.PromotionFixed, Value: 500, StartsAt: &lastWeek, EndsAt: &yesterday})
+	)
+
+	active := func(at time.Time) map[string]bool {
+		promotions, err := strg.Promotion().Active(ctx, branch.Id, at)
+		if err != nil {
+			t.Fatalf("active promotions: %v", err)
+		}
+		ids := map[string]bool{}
+		for _, promotion := range promotions {
+			ids[promotion.Id] = true
+		}
+		return ids
+	}
+	if ids := active(time.Now()); !ids[percent.Id] || !ids[buyGet.Id] || ids[expired.Id] {
+		t.Fatalf("promotions running now %v", ids)
+	}
+	if ids := active(yesterday.Add(-time.Hour)); ids[percent.Id] || !ids[expired.Id] {
+		t.Fatalf("promotions running yesterday %v", ids)
+	}
+
+	// the line gets the promotion taking the most off it: one unit of three free
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products: []*models.CheckoutProduct{
+			{ProductID: product.Id, Quantity: 3, Promotions: []*models.Promotion{percent, buyGet}},
+			{ProductID: product.Id, Quantity: 1, Promotions: []*models.Promotion{percent}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	var line = checkout.SaleProducts[0]
+	if line.Discount != 6000 || line.PromotionID != buyGet.Id || line.TotalPrice != 12000 {
+		t.Fatalf("unexpected line %+v", line)
+	}
+	if other := checkout.SaleProducts[1]; other.Discount != 600 || other.PromotionID != percent.Id || other.TotalPrice != 5400 {
+		t.Fatalf("unexpected line %+v", other)
+	}
+	if checkout.Sale.TotalPrice != 17400 {
+		t.Fatalf("unexpected sale %+v", checkout.Sale)
+	}
+
+	report, err := strg.Promotion().Report(ctx, &models.PromotionReportRequest{BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.Discount != 6600 || len(report.Promotions) != 2 || *report.Promotions[0] != (models.PromotionCost{PromotionID: buyGet.Id, Name: "2+1", Type: models.PromotionBuyGet, Sales: 1, Lines: 1, Quantity: 3, Discount: 6000}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// a return refunds what a unit sold for after the discount
+	saleReturn, err := strg.SaleReturn().Create(ctx, &models.CreateSaleReturn{
+		IncrementID: "R-" + uuid.New().String()[:8],
+		SaleID:      checkout.Sale.Id,
+		Method:      "cash",
+		Lines:       []*models.CreateSaleReturnLine{{SaleProductID: line.Id, Quantity: 1}},
+	})
+	if err != nil || saleReturn.TotalPrice != 4000 {
+		t.Fatalf("return: %+v %v", saleReturn, err)
+	}
+
+	// the returned unit gives its share of the discount back
+	if report, err = strg.Promotion().Report(ctx, &models.PromotionReportRequest{BranchID: branch.Id}); err != nil || report.Discount != 4600 || report.Promotions[0].Quantity != 2 || report.Promotions[0].Discount != 4000 {
+		t.Fatalf("report after return: %+v %v", report, err)
+	}
+
+	// a deleted promotion stops but its sales keep it
+	if err = strg.Promotion().Delete(ctx, &models.PromotionPrimaryKey{Id: percent.Id}); err != nil {
+		t.Fatalf("delete promotion: %v", err)
+	}
+	if ids := active(time.Now()); ids[percent.Id] || !ids[buyGet.Id] {
+		t.Fatalf("promotions running after delete %v", ids)
+	}
+	if report, err = strg.Promotion().Report(ctx, &models.PromotionReportRequest{BranchID: branch.Id}); err != nil || report.Discount != 4600 || len(report.Promotions) != 2 || report.Promotions[1].PromotionID != percent.Id {
+		t.Fatalf("report after delete: %+v %v", report, err)
+	}
+	other, err := strg.SaleProduct().GetByID(ctx, &models.SaleProductPrimaryKey{Id: checkout.SaleProducts[1].Id})
+	if err != nil || other.Discount != 600 || other.PromotionID != percent.Id {
+		t.Fatalf("line after delete: %+v %v", other, err)
+	}
+
+	// one that has not started never will
+	later := create(&models.CreatePromotion{Name: "Next week", Type: models.PromotionPercent, Value: 5, StartsAt: &nextWeek})
+	if err = strg.Promotion().Delete(ctx, &models.PromotionPrimaryKey{Id: later.Id}); err != nil {
+		t.Fatalf("delete promotion: %v", err)
+	}
+	if ids := active(nextWeek.Add(time.Hour)); ids[later.Id] {
+		t.Fatalf("promotions running next week %v", ids)
+	}
+}
+
+func testComingLifecycle(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, _ := createProduct(t, strg, 5000)
+
+	coming, err := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-" + uuid.New().String()[:8], BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create coming: %v", err)
+	}
+	if coming.Status != models.ComingDraft {
+		t.Fatalf("new coming status = %q, want %q", coming.Status, models.ComingDraft)
+	}
+
+	finish := models.ComingStatus{Id: coming.Id, From: models.ComingDraft, To: models.ComingFinished}
+
+	rows, err := strg.Coming().SetStatus(ctx, &finish)
+	if err != nil || rows != 1 {
+		t.Fatalf("finish: rows=%d err=%v", rows, err)
+	}
+
+	rows, err = strg.Coming().SetStatus(ctx, &finish)
+	if err != nil || rows != 0 {
+		t.Fatalf("finish twice: rows=%d err=%v", rows, err)
+	}
+
+	_, err = strg.Coming().CreateEvent(ctx, &models.CreateComingEvent{ComingID: coming.Id, Action: models.ComingEventFinish})
+	if err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	_, err = strg.Coming().CreateEvent(ctx, &models.CreateComingEvent{ComingID: coming.Id, Action: models.ComingEventReverse, Reason: "wrong supplier"})
+	if err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	events, err := strg.Coming().GetEvents(ctx, &models.ComingPrimaryKey{Id: coming.Id})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("events: %d %v", len(events), err)
+	}
+	if events[0].Action != models.ComingEventFinish || events[1].Reason != "wrong supplier" {
+		t.Fatalf("unexpected events %+v %+v", events[0], events[1])
+	}
+}
+
+func testStockMovement(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+
+	receipt := models.CreateStockMovement{
+		BranchID:       branch.Id,
+		ProductID:      product.Id,
+		Quantity:       5,
+		Type:           models.MovementReceipt,
+		DocumentID:     uuid.New().String(),
+		DocumentNumber: "C-00001",
+		Name:           product.Name,
+		ComingPrice:    4000,
+		SalePrice:      5000,
+	}
+
+	movement, err := strg.StockMovement().Create(ctx, &receipt)
+	if err != nil {
+		t.Fatalf("receipt to missing remainder: %v", err)
+	}
+	if movement.Quantity != 5 || movement.Type != models.MovementReceipt || movement.DocumentNumber != "C-00001" {
+		t.Fatalf("unexpected movement %+v", movement)
+	}
+
+	receipt.Quantity = 3
+	if _, err = strg.StockMovement().Create(ctx, &receipt); err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+
+	list, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}, "product_id": {product.Id}}},
+	})
+	if err != nil || len(list.Remainders) != 1 {
+		t.Fatalf("remainders: %+v %v", list, err)
+	}
+	remainder := list.Remainders[0]
+	assertQuantity(t, strg, remainder.Id, 8)
+
+	writeOff := models.CreateStockMovement{BranchID: branch.Id, ProductID: product.Id, Quantity: -9, Type: models.MovementWriteOff}
+
+	_, err = strg.StockMovement().Create(ctx, &writeOff)
+	if !errors.Is(err, storage.ErrNotEnoughQuantity) {
+		t.Fatalf("write off too much: expected ErrNotEnoughQuantity, got %v", err)
+	}
+	assertQuantity(t, strg, remainder.Id, 8)
+
+	writeOff.Quantity = -2
+	if _, err = strg.StockMovement().Create(ctx, &writeOff); err != nil {
+		t.Fatalf("write off: %v", err)
+	}
+	assertQuantity(t, strg, remainder.Id, 6)
+
+	history, err := strg.StockMovement().GetList(ctx, &models.GetListStockMovementRequest{
+		Filter: models.Filter{Fields: map[string][]string{"product_id": {product.Id}}},
+	})
+	if err != nil || history.Count != 3 {
+		t.Fatalf("history: %+v %v", history, err)
+	}
+	if history.StockMovements[0].Quantity != -2 || history.StockMovements[0].Type != models.MovementWriteOff {
+		t.Fatalf("newest movement %+v", history.StockMovements[0])
+	}
+
+	// remainder edited behind the journal's back
+	remainder.Quantity = 10
+	if _, err = strg.Remainder().Update(ctx, remainder); err != nil {
+		t.Fatalf("update remainder: %v", err)
+	}
+
+	rebuild := models.RebuildRemainderRequest{BranchID: branch.Id, DryRun: true}
+
+	report, err := strg.StockMovement().RebuildRemainder(ctx, &rebuild)
+	if err != nil || len(report.Discrepancies) != 1 {
+		t.Fatalf("dry run: %+v %v", report, err)
+	}
+	if d := report.Discrepancies[0]; d.ProductID != product.Id || d.Remainder != 10 || d.Journal != 6 || d.Difference != -4 {
+		t.Fatalf("unexpected discrepancy %+v", d)
+	}
+	assertQuantity(t, strg, remainder.Id, 10)
+
+	rebuild.DryRun = false
+	if _, err = strg.StockMovement().RebuildRemainder(ctx, &rebuild); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	assertQuantity(t, strg, remainder.Id, 6)
+
+	report, err = strg.StockMovement().RebuildRemainder(ctx, &rebuild)
+	if err != nil || len(report.Discrepancies) != 0 {
+		t.Fatalf("after rebuild: %+v %v", report, err)
+	}
+}
+
+func testTransfer(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	from, product := createProduct(t, strg, 5000)
+
+	to, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Olmazor"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	other, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 2000, BranchID: from.Id})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	transfer, err := strg.Transfer().Create(ctx, &models.CreateTransfer{
+		IncrementID:  "T-" + uuid.New().String()[:8],
+		FromBranchID: from.Id,
+		ToBranchID:   to.Id,
+		Lines:        []*models.CreateTransferLine{{ProductID: product.Id, Quantity: 5}, {ProductID: other.Id, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if transfer.Status != models.TransferDraft || len(transfer.Lines) != 2 || transfer.Lines[0].ProductID != product.Id {
+		t.Fatalf("unexpected transfer %+v", transfer)
+	}
+
+	rows, err := strg.Transfer().Update(ctx, &models.UpdateTransfer{
+		Id:         transfer.Id,
+		ToBranchID: to.Id,
+		Lines:      []*models.CreateTransferLine{{ProductID: product.Id, Quantity: 4}},
+	})
+	if err != nil || rows != 1 {
+		t.Fatalf("update draft: rows=%d err=%v", rows, err)
+	}
+
+	send := models.TransferStatus{Id: transfer.Id, From: models.TransferDraft, To: models.TransferSent}
+	if rows, err = strg.Transfer().SetStatus(ctx, &send); err != nil || rows != 1 {
+		t.Fatalf("send: rows=%d err=%v", rows, err)
+	}
+	if rows, err = strg.Transfer().SetStatus(ctx, &send); err != nil || rows != 0 {
+		t.Fatalf("send twice: rows=%d err=%v", rows, err)
+	}
+
+	rows, err = strg.Transfer().Update(ctx, &models.UpdateTransfer{Id: transfer.Id, ToBranchID: to.Id})
+	if err != nil || rows != 0 {
+		t.Fatalf("update sent: rows=%d err=%v", rows, err)
+	}
+
+	transfer, err = strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: transfer.Id})
+	if err != nil || len(transfer.Lines) != 1 || transfer.Lines[0].Quantity != 4 || len(transfer.SentAt) == 0 {
+		t.Fatalf("get sent: %+v %v", transfer, err)
+	}
+
+	line := transfer.Lines[0]
+	if _, err = strg.Transfer().UpdateLine(ctx, &models.UpdateTransferLine{Id: line.Id, ComingPrice: 4000}); err != nil {
+		t.Fatalf("update line: %v", err)
+	}
+
+	transit, err := strg.Transfer().InTransit(ctx, &models.InTransitRequest{BranchID: to.Id})
+	if err != nil || len(transit.Lines) != 1 || transit.Lines[0].Quantity != 4 || transit.Lines[0].ComingPrice != 4000 {
+		t.Fatalf("in transit: %+v %v", transit, err)
+	}
+
+	list, err := strg.Transfer().GetList(ctx, &models.GetListTransferRequest{BranchID: to.Id})
+	if err != nil || list.Count != 1 || list.Transfers[0].Id != transfer.Id {
+		t.Fatalf("list by branch: %+v %v", list, err)
+	}
+
+	received := 3
+	if _, err = strg.Transfer().UpdateLine(ctx, &models.UpdateTransferLine{Id: line.Id, ComingPrice: 4000, ReceivedQuantity: &received}); err != nil {
+		t.Fatalf("receive line: %v", err)
+	}
+
+	receive := models.TransferStatus{Id: transfer.Id, From: models.TransferSent, To: models.TransferReceived}
+	if rows, err = strg.Transfer().SetStatus(ctx, &receive); err != nil || rows != 1 {
+		t.Fatalf("receive: rows=%d err=%v", rows, err)
+	}
+
+	transfer, err = strg.Transfer().GetByID(ctx, &models.TransferPrimaryKey{Id: transfer.Id})
+	if err != nil || transfer.Status != models.TransferReceived || len(transfer.ReceivedAt) == 0 {
+		t.Fatalf("get received: %+v %v", transfer, err)
+	}
+	if line = transfer.Lines[0]; line.ReceivedQuantity == nil || *line.ReceivedQuantity != 3 || line.Shortage != 1 {
+		t.Fatalf("unexpected received line %+v", line)
+	}
+
+	transit, err = strg.Transfer().InTransit(ctx, &models.InTransitRequest{BranchID: to.Id})
+	if err != nil || len(transit.Lines) != 0 {
+		t.Fatalf("in transit after receipt: %+v %v", transit, err)
+	}
+}
+
+func testStockTake(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+
+	other, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Aspirin", Price: 2000, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	stockTake, err := strg.StockTake().Create(ctx, &models.CreateStockTake{
+		IncrementID: "I-" + uuid.New().String()[:8],
+		BranchID:    branch.Id,
+		Lines:       []*models.CreateStockTakeLine{{ProductID: product.Id, Expected: 10, ComingPrice: 4000}, {ProductID: other.Id, Expected: 3, ComingPrice: 1500}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if stockTake.Status != models.StockTakeOpen || len(stockTake.Lines) != 2 || stockTake.Lines[0].ProductID != product.Id || stockTake.Uncounted != 2 {
+		t.Fatalf("unexpected stock-take %+v", stockTake)
+	}
+
+	rows, err := strg.StockTake().SetCounts(ctx, &models.SetStockTakeCounts{
+		Id:     stockTake.Id,
+		Counts: []*models.StockTakeCount{{ProductID: product.Id, Counted: 8}},
+	})
+	if err != nil || rows != 1 {
+		t.Fatalf("set counts: rows=%d err=%v", rows, err)
+	}
+
+	stockTake, err = strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: stockTake.Id})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if line := stockTake.Lines[0]; line.Counted == nil || *line.Counted != 8 || line.Variance != -2 || line.CostImpact != -8000 {
+		t.Fatalf("unexpected counted line %+v", line)
+	}
+	if stockTake.Uncounted != 1 || stockTake.Shortage != 8000 || stockTake.CostImpact != -8000 {
+		t.Fatalf("unexpected totals %+v", stockTake)
+	}
+
+	list, err := strg.StockTake().GetList(ctx, &models.GetListStockTakeRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}, "status": {models.StockTakeOpen}}},
+	})
+	if err != nil || list.Count != 1 || list.StockTakes[0].Id != stockTake.Id {
+		t.Fatalf("list: %+v %v", list, err)
+	}
+
+	approve := models.StockTakeStatus{Id: stockTake.Id, From: models.StockTakeOpen, To: models.StockTakeApproved}
+	if rows, err = strg.StockTake().SetStatus(ctx, &approve); err != nil || rows != 1 {
+		t.Fatalf("approve: rows=%d err=%v", rows, err)
+	}
+	if rows, err = strg.StockTake().SetStatus(ctx, &approve); err != nil || rows != 0 {
+		t.Fatalf("approve twice: rows=%d err=%v", rows, err)
+	}
+
+	rows, err = strg.StockTake().SetCounts(ctx, &models.SetStockTakeCounts{
+		Id:     stockTake.Id,
+		Counts: []*models.StockTakeCount{{ProductID: other.Id, Counted: 3}},
+	})
+	if err != nil || rows != 0 {
+		t.Fatalf("count approved: rows=%d err=%v", rows, err)
+	}
+
+	stockTake, err = strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: stockTake.Id})
+	if err != nil || stockTake.Status != models.StockTakeApproved || len(stockTake.ApprovedAt) == 0 {
+		t.Fatalf("get approved: %+v %v", stockTake, err)
+	}
+
+	if err = strg.StockTake().Delete(ctx, &models.StockTakePrimaryKey{Id: stockTake.Id}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = strg.StockTake().GetByID(ctx, &models.StockTakePrimaryKey{Id: stockTake.Id}); err == nil {
+		t.Fatalf("get deleted: %v", err)
+	}
+}
+
+func testLot(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 100)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Lola", Birthday: "2000-01-02", Gender: "female", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	day := func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02") }
+
+	for _, lot := range []struct {
+		number, expiry string
+		quantity       int
+	}{
+		{"A", day(60), 3},
+		{"B", day(10), 2},
+		{"X", day(-1), 4},
+		{"", "", 1},
+	} {
+		_, err = strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID:    branch.Id,
+			ProductID:   product.Id,
+			Quantity:    lot.quantity,
+			Type:        models.MovementReceipt,
+			LotNumber:   lot.number,
+			ExpiryDate:  lot.expiry,
+			Name:        product.Name,
+			ComingPrice: 50,
+			SalePrice:   120,
+		})
+		if err != nil {
+			t.Fatalf("receive lot %q: %v", lot.number, err)
+		}
+	}
+
+	expiring, err := strg.Remainder().Expiring(ctx, &models.ExpiringRequest{BranchID: branch.Id, Days: 30})
+	if err != nil {
+		t.Fatalf("expiring: %v", err)
+	}
+
+	if len(expiring.Lots) != 2 || expiring.Lots[0].LotNumber != "X" || expiring.Lots[1].LotNumber != "B" {
+		t.Fatalf("unexpected expiring lots %+v", expiring.Lots)
+	}
+	if expiring.Lots[0].DaysLeft != -1 || expiring.Lots[1].DaysLeft != 10 || expiring.Expired != 200 || expiring.Cost != 300 {
+		t.Fatalf("unexpected expiring %+v %+v", expiring, expiring.Lots[0])
+	}
+
+	// B expires first, X is expired and the lot without expiry goes last
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-9999961",
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if checkout.SaleProducts[0].Price != 120 {
+		t.Fatalf("unexpected line %+v", checkout.SaleProducts[0])
+	}
+
+	movements, err := strg.StockMovement().GetList(ctx, &models.GetListStockMovementRequest{
+		Filter: models.Filter{Fields: map[string][]string{"document_id": {checkout.Sale.Id}}},
+	})
+	if err != nil {
+		t.Fatalf("list movements: %v", err)
+	}
+
+	var sold = map[string]int{}
+	for _, m := range movements.StockMovements {
+		sold[m.LotNumber] += m.Quantity
+	}
+	if len(sold) != 2 || sold["B"] != -2 || sold["A"] != -2 {
+		t.Fatalf("unexpected allocation %v", sold)
+	}
+
+	lots := map[string]*models.Remainder{}
+	list, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}}},
+	})
+	if err != nil {
+		t.Fatalf("list remainder: %v", err)
+	}
+	for _, rm := range list.Remainders {
+		lots[rm.LotNumber] = rm
+	}
+
+	// the oldest lot comes first
+	if list.Remainders[0].LotNumber != "A" || list.Remainders[len(list.Remainders)-1].LotNumber != "" {
+		t.Fatalf("lots out of order %+v", list.Remainders)
+	}
+
+	if len(lots) != 4 || lots["A"].Quantity != 1 || lots["B"].Quantity != 0 || lots["X"].Quantity != 4 || lots[""].Quantity != 1 {
+		t.Fatalf("unexpected lots %+v", list.Remainders)
+	}
+	if lots["A"].ExpiryDate != day(60) {
+		t.Fatalf("lot A expires %q, want %q", lots["A"].ExpiryDate, day(60))
+	}
+
+	_, err = strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-9999962",
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 3}},
+	})
+	if !errors.Is(err, storage.ErrNotEnoughQuantity) {
+		t.Fatalf("expected expired stock to stay unsold, got %v", err)
+	}
+
+	// expired stock is written off by lot
+	_, err = strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+		BranchID:  branch.Id,
+		ProductID: product.Id,
+		Quantity:  -4,
+		Type:      models.MovementWriteOff,
+		LotNumber: "X",
+	})
+	if err != nil {
+		t.Fatalf("write off lot X: %v", err)
+	}
+	assertQuantity(t, strg, lots["X"].Id, 0)
+
+	// an edit of a lot adjusts that lot and no other
+	for _, quantity := range []int{2, -1} {
+		_, err = strg.StockMovement().Create(ctx, &models.CreateStockMovement{
+			BranchID:   branch.Id,
+			ProductID:  product.Id,
+			Quantity:   quantity,
+			Type:       models.MovementAdjustment,
+			LotNumber:  "A",
+			ExpiryDate: day(60),
+		})
+		if err != nil {
+			t.Fatalf("adjust lot A by %d: %v", quantity, err)
+		}
+	}
+	assertQuantity(t, strg, lots["A"].Id, 2)
+	assertQuantity(t, strg, lots["B"].Id, 0)
+	assertQuantity(t, strg, lots[""].Id, 1)
+
+	rebuild, err := strg.StockMovement().RebuildRemainder(ctx, &models.RebuildRemainderRequest{BranchID: branch.Id, DryRun: true})
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if len(rebuild.Discrepancies) != 0 {
+		t.Fatalf("unexpected discrepancies %+v", rebuild.Discrepancies[0])
+	}
+}
+
+func testSupplier(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 100)
+
+	tin := uuid.New().String()[:8]
+	supplier, err := strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Pharma Trade", TIN: tin})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+
+	_, err = strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Pharma Trade 2", TIN: tin})
+	var appErr *apperror.Error
+	if !errors.As(apperror.Translate(err), &appErr) || appErr.Code != apperror.Conflict || len(appErr.Fields["tin"]) == 0 {
+		t.Fatalf("expected a conflict on tin for a second supplier with tin %q, got %v", tin, err)
+	}
+
+	// receive 10 x 50 and 4 x 25, the second coming is reversed
+	var comings []*models.Coming
+	for _, line := range []struct {
+		quantity int
+		price    float64
+	}{{10, 50}, {4, 25}} {
+		coming, err := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-" + uuid.New().String()[:8], BranchID: branch.Id, SupplierID: supplier.Id})
+		if err != nil {
+			t.Fatalf("create coming: %v", err)
+		}
+
+		_, err = strg.PickingList().Create(ctx, &models.PickingList{Product_ID: product.Id, Quantity: line.quantity, Price: line.price, ComingID: coming.Id, ComingIncrementID: coming.IncrementID})
+		if err != nil {
+			t.Fatalf("create picking list: %v", err)
+		}
+
+		if _, err = strg.Coming().SetStatus(ctx, &models.ComingStatus{Id: coming.Id, From: models.ComingDraft, To: models.ComingFinished}); err != nil {
+			t.Fatalf("finish: %v", err)
+		}
+		if _, err = strg.Coming().CreateEvent(ctx, &models.CreateComingEvent{ComingID: coming.Id, Action: models.ComingEventFinish}); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+
+		comings = append(comings, coming)
+	}
+
+	if _, err = strg.Coming().SetStatus(ctx, &models.ComingStatus{Id: comings[1].Id, From: models.ComingFinished, To: models.ComingReversed}); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	if _, err = strg.Coming().CreateEvent(ctx, &models.CreateComingEvent{ComingID: comings[1].Id, Action: models.ComingEventReverse, Reason: "damaged"}); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	coming, err := strg.Coming().GetByID(ctx, &models.ComingPrimaryKey{Id: comings[0].Id})
+	if err != nil {
+		t.Fatalf("get coming: %v", err)
+	}
+	if coming.SupplierID != supplier.Id || coming.TotalPrice != 500 {
+		t.Fatalf("unexpected coming %+v", coming)
+	}
+
+	_, err = strg.SupplierPayment().Create(ctx, &models.CreateSupplierPayment{SupplierID: supplier.Id, BranchID: branch.Id, Amount: 200, Method: "cash"})
+	if err != nil {
+		t.Fatalf("pay supplier: %v", err)
+	}
+
+	_, err = strg.SupplierPayment().Create(ctx, &models.CreateSupplierPayment{SupplierID: supplier.Id, BranchID: branch.Id, Amount: 301, Method: "cash"})
+	if !errors.Is(err, storage.ErrOverpaySupplier) {
+		t.Fatalf("expected ErrOverpaySupplier, got %v", err)
+	}
+
+	statement, err := strg.Supplier().Statement(ctx, &models.SupplierStatementRequest{SupplierID: supplier.Id, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+
+	var types []string
+	for _, e := range statement.Entries {
+		types = append(types, e.Type)
+	}
+	if fmt.Sprint(types) != "[coming coming reversal payment]" || statement.Credit != 600 || statement.Debit != 300 || statement.ClosingBalance != 300 {
+		t.Fatalf("unexpected statement %v %+v", types, statement)
+	}
+
+	later := time.Now().Add(time.Hour)
+	statement, err = strg.Supplier().Statement(ctx, &models.SupplierStatementRequest{SupplierID: supplier.Id, From: &later})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if statement.OpeningBalance != 300 || statement.ClosingBalance != 300 || len(statement.Entries) != 0 {
+		t.Fatalf("unexpected statement %+v", statement)
+	}
+
+	payables, err := strg.Supplier().Payables(ctx, &models.PayablesRequest{BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("payables: %v", err)
+	}
+	if len(payables.Suppliers) != 1 || payables.Suppliers[0].Received != 500 || payables.Suppliers[0].Paid != 200 || payables.Total != 300 {
+		t.Fatalf("unexpected payables %+v", payables)
+	}
+}
+
+func testPurchaseOrder(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 100)
+
+	supplier, err := strg.Supplier().Create(ctx, &models.CreateSupplier{Name: "Pharma Trade"})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+
+	po, err := strg.PurchaseOrder().Create(ctx, &models.CreatePurchaseOrder{
+		IncrementID: "P-" + uuid.New().String()[:8],
+		SupplierID:  supplier.Id,
+		BranchID:    branch.Id,
+		Lines:       []*models.CreatePurchaseOrderLine{{ProductID: product.Id, Quantity: 10, Price: 40}},
+	})
+	if err != nil {
+		t.Fatalf("create purchase order: %v", err)
+	}
+	if po.Status != models.PurchaseOrderOpen || len(po.Lines) != 1 || po.Lines[0].Outstanding != 10 || len(po.Lines[0].Flags) != 0 {
+		t.Fatalf("unexpected purchase order %+v %+v", po, po.Lines)
+	}
+
+	coming, err := strg.Coming().Create(ctx, &models.CreateComing{IncrementID: "C-" + uuid.New().String()[:8], BranchID: branch.Id, SupplierID: supplier.Id, PurchaseOrderID: po.Id})
+	if err != nil {
+		t.Fatalf("create coming: %v", err)
+	}
+
+	// 12 arrive at 45, a draft coming counts for nothing
+	_, err = strg.PickingList().Create(ctx, &models.PickingList{Product_ID: product.Id, Quantity: 12, Price: 45, ComingID: coming.Id, ComingIncrementID: coming.IncrementID})
+	if err != nil {
+		t.Fatalf("create picking list: %v", err)
+	}
+
+	if got, _ := strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: po.Id}); got.Lines[0].Received != 0 {
+		t.Fatalf("a draft coming was received %+v", got.Lines[0])
+	}
+
+	if _, err = strg.Coming().SetStatus(ctx, &models.ComingStatus{Id: coming.Id, From: models.ComingDraft, To: models.ComingFinished}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	got, err := strg.PurchaseOrder().GetByID(ctx, &models.PurchaseOrderPrimaryKey{Id: po.Id})
+	if err != nil {
+		t.Fatalf("get purchase order: %v", err)
+	}
+	if line := got.Lines[0]; line.Received != 12 || line.ReceivedPrice != 45 || line.Outstanding != 0 || fmt.Sprint(line.Flags) != "[over price]" || !got.FullyReceived() {
+		t.Fatalf("unexpected line %+v", line)
+	}
+
+	rows, err := strg.PurchaseOrder().Update(ctx, &models.UpdatePurchaseOrder{Id: po.Id, SupplierID: supplier.Id})
+	if err != nil || rows != 0 {
+		t.Fatalf("update with comings: rows=%d err=%v", rows, err)
+	}
+
+	rows, err = strg.PurchaseOrder().SetStatus(ctx, &models.PurchaseOrderStatus{Id: po.Id, From: models.PurchaseOrderOpen, To: models.PurchaseOrderClosed})
+	if err != nil || rows != 1 {
+		t.Fatalf("close: rows=%d err=%v", rows, err)
+	}
+
+	list, err := strg.PurchaseOrder().GetList(ctx, &models.GetListPurchaseOrderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"supplier_id": {supplier.Id}, "status": {models.PurchaseOrderClosed}}},
+	})
+	if err != nil {
+		t.Fatalf("list purchase orders: %v", err)
+	}
+	if list.Count != 1 || list.PurchaseOrders[0].ClosedAt == "" {
+		t.Fatalf("unexpected purchase orders %+v", list.PurchaseOrders)
+	}
+}
+
+func testStockLevel(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 5000)
+	client, err := strg.Client().Create(ctx, &models.CreateClient{FirstName: "Ali", Birthday: "2000-01-02", Gender: "male", BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	_, err = strg.Remainder().Create(ctx, &models.Remainder{ProductID: product.Id, Name: product.Name, Quantity: 20, ComingPrice: 4000, SalePrice: 5000, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create remainder: %v", err)
+	}
+
+	checkout, err := strg.Sale().Checkout(ctx, &models.CreateCheckout{
+		ClientID:    client.Id,
+		BranchID:    branch.Id,
+		IncrementID: "S-" + uuid.New().String()[:8],
+		Products:    []*models.CheckoutProduct{{ProductID: product.Id, Quantity: 6}},
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	level, err := strg.StockLevel().Set(ctx, &models.SetStockLevel{BranchID: branch.Id, ProductID: product.Id, MinQuantity: 15, MaxQuantity: 40})
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if level.MinQuantity != 15 || level.MaxQuantity != 40 {
+		t.Fatalf("unexpected stock level %+v", level)
+	}
+
+	low, err := strg.StockLevel().Low(ctx, &models.LowStockRequest{BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("low: %v", err)
+	}
+	if len(low.Products) != 1 || low.Products[0].Quantity != 14 || low.Products[0].Shortage != 1 || low.Products[0].Name != product.Name {
+		t.Fatalf("unexpected low stock %+v", low.Products)
+	}
+
+	// 6 sold in 30 days, 2 more go before an order placed now arrives
+	reorder, err := strg.StockLevel().Reorder(ctx, &models.ReorderRequest{BranchID: branch.Id, WindowDays: 30, LeadDays: 10})
+	if err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	if len(reorder.Lines) != 1 || reorder.Cost != 112000 {
+		t.Fatalf("unexpected reorder %+v", reorder)
+	}
+	if line := reorder.Lines[0]; line.Sold != 6 || line.DailySales != 0.2 || line.Projected != 12 || line.Suggested != 28 || line.Price != 4000 {
+		t.Fatalf("unexpected reorder line %+v", line)
+	}
+
+	// setting it again replaces the levels
+	if _, err = strg.StockLevel().Set(ctx, &models.SetStockLevel{BranchID: branch.Id, ProductID: product.Id, MinQuantity: 10, MaxQuantity: 40}); err != nil {
+		t.Fatalf("set again: %v", err)
+	}
+
+	levels, err := strg.StockLevel().GetList(ctx, &models.GetListStockLevelRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}}},
+	})
+	if err != nil || levels.Count != 1 || levels.StockLevels[0].MinQuantity != 10 {
+		t.Fatalf("unexpected stock levels %+v err=%v", levels, err)
+	}
+
+	if low, _ = strg.StockLevel().Low(ctx, &models.LowStockRequest{BranchID: branch.Id}); len(low.Products) != 0 {
+		t.Fatalf("stock above the minimum is low %+v", low.Products)
+	}
+
+	if reorder, _ = strg.StockLevel().Reorder(ctx, &models.ReorderRequest{BranchID: branch.Id, WindowDays: 30, LeadDays: 10}); len(reorder.Lines) != 0 {
+		t.Fatalf("reorder above the minimum %+v", reorder.Lines[0])
+	}
+
+	// a longer lead time sells below the minimum before the order arrives
+	if reorder, _ = strg.StockLevel().Reorder(ctx, &models.ReorderRequest{BranchID: branch.Id, WindowDays: 30, LeadDays: 30}); len(reorder.Lines) != 1 || reorder.Lines[0].Suggested != 32 {
+		t.Fatalf("reorder with a long lead time %+v", reorder.Lines)
+	}
+
+	// units returned in the window were not sold
+	_, err = strg.SaleReturn().Create(ctx, &models.CreateSaleReturn{
+		IncrementID: "R-" + uuid.New().String()[:8],
+		SaleID:      checkout.Sale.Id,
+		Method:      "cash",
+		Lines:       []*models.CreateSaleReturnLine{{SaleProductID: checkout.SaleProducts[0].Id, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if reorder, _ = strg.StockLevel().Reorder(ctx, &models.ReorderRequest{BranchID: branch.Id, WindowDays: 30, LeadDays: 30}); len(reorder.Lines) != 0 {
+		t.Fatalf("reorder after a return %+v", reorder.Lines[0])
+	}
+
+	if err = strg.StockLevel().Delete(ctx, &models.StockLevelPrimaryKey{BranchID: branch.Id, ProductID: product.Id}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err = strg.StockLevel().GetByID(ctx, &models.StockLevelPrimaryKey{BranchID: branch.Id, ProductID: product.Id}); err == nil {
+		t.Fatal("stock level is still there after delete")
+	}
+}
+
+func testUser(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	login := "user-" + uuid.New().String()[:8]
+	user, err := strg.User().Create(ctx, &models.CreateUser{Login: login, Password: "hash", ClientType: "SUPER-ADMIN"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	_, err = strg.User().Create(ctx, &models.CreateUser{Login: login, Password: "hash", ClientType: "SUPER-ADMIN"})
+	var appErr *apperror.Error
+	if !errors.As(apperror.Translate(err), &appErr) || appErr.Code != apperror.Conflict || len(appErr.Fields["login"]) == 0 {
+		t.Fatalf("expected a conflict on login for a second user with login %q, got %v", login, err)
+	}
+
+	if _, err := strg.User().Update(ctx, &models.UpdateUser{Id: user.Id, Login: login, FirstName: "Aziz", ClientType: "SUPER-ADMIN"}); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+
+	got, err := strg.User().GetByID(ctx, &models.UserPrimaryKey{Login: login})
+	if err != nil {
+		t.Fatalf("get user by login: %v", err)
+	}
+	// an empty password on update keeps the current one
+	if got.Id != user.Id || got.FirstName != "Aziz" || got.Password != "hash" {
+		t.Fatalf("unexpected user %+v", got)
+	}
+}
+
+func testWithTx(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	var (
+		committed  *models.Branch
+		rolledBack *models.Branch
+		errAbort   = errors.New("abort")
+	)
+
+	err := strg.WithTx(ctx, func(tx storage.StorageI) (err error) {
+		committed, err = tx.Branch().Create(ctx, &models.CreateBranch{Name: "committed"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	err = strg.WithTx(ctx, func(tx storage.StorageI) (err error) {
+		rolledBack, err = tx.Branch().Create(ctx, &models.CreateBranch{Name: "rolled back"})
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("rollback: expected errAbort, got %v", err)
+	}
+
+	if _, err = strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: committed.Id}); err != nil {
+		t.Fatalf("committed branch: %v", err)
+	}
+
+	if _, err = strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: rolledBack.Id}); err == nil {
+		t.Fatal("rolled back branch was persisted")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to propagate")
+			}
+		}()
+
+		_ = strg.WithTx(ctx, func(tx storage.StorageI) (err error) {
+			rolledBack, err = tx.Branch().Create(ctx, &models.CreateBranch{Name: "panicked"})
+			if err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	if _, err = strg.Branch().GetByID(ctx, &models.BranchPrimaryKey{Id: rolledBack.Id}); err == nil {
+		t.Fatal("branch created before panic was persisted")
+	}
+}
+
+func testDocumentNumber(t *testing.T, strg storage.StorageI) {
+
+	var (
+		ctx     = context.Background()
+		branch  = uuid.New().String()
+		req     = &models.NextDocumentNumber{Document: "conformance-" + branch[:8], BranchID: branch, Prefix: "T-", Width: 5, PerBranch: true}
+		numbers = make(chan string, 20)
+		errs    = make(chan error, 20)
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			number, err := strg.DocumentNumber().Next(ctx, req)
+			numbers <- number
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for number := range numbers {
+		if seen[number] {
+			t.Fatalf("number %s handed out twice", number)
+		}
+		seen[number] = true
+	}
+
+	if !seen["T-00001"] || !seen["T-00020"] {
+		t.Fatalf("unexpected numbers %v", seen)
+	}
+
+	// another branch has its own counter
+	other := *req
+	other.BranchID = uuid.New().String()
+	number, err := strg.DocumentNumber().Next(ctx, &other)
+	if err != nil || number != "T-00001" {
+		t.Fatalf("other branch: %s %v", number, err)
+	}
+
+	yearly := *req
+	yearly.YearlyReset = true
+	number, err = strg.DocumentNumber().Next(ctx, &yearly)
+	if want := fmt.Sprintf("T-%d-00001", time.Now().Year()); err != nil || number != want {
+		t.Fatalf("yearly: %s %v, want %s", number, err, want)
+	}
+}
+
+func createProduct(t *testing.T, strg storage.StorageI, price float64) (*models.Branch, *models.Product) {
+
+	ctx := context.Background()
+
+	branch, err := strg.Branch().Create(ctx, &models.CreateBranch{Name: "Mirobod"})
+	if err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	product, err := strg.Product().Create(ctx, &models.CreateProduct{Name: "Paracetamol", Price: price, BranchID: branch.Id})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	return branch, product
+}
+
+func assertQuantity(t *testing.T, strg storage.StorageI, remainderId string, want int) {
+
+	t.Helper()
+
+	remainder, err := strg.Remainder().GetByID(context.Background(), &models.RemainderPrimaryKey{Id: remainderId})
+	if err != nil {
+		t.Fatalf("get remainder: %v", err)
+	}
+
+	if remainder.Quantity != want {
+		t.Fatalf("remainder quantity = %d, want %d", remainder.Quantity, want)
+	}
+}
+
+func testFilter(t *testing.T, strg storage.StorageI) {
+
+	ctx := context.Background()
+
+	branch, product := createProduct(t, strg, 1000)
+	other, _ := createProduct(t, strg, 1000)
+
+	for _, req := range []*models.Remainder{
+		{ProductID: product.Id, Name: "filter-a", Quantity: 1, BranchID: branch.Id},
+		{ProductID: product.Id, Name: "filter-b", Quantity: 5, BranchID: branch.Id},
+		{ProductID: product.Id, Name: "filter-c", Quantity: 9, BranchID: other.Id},
+	} {
+		if _, err := strg.Remainder().Create(ctx, req); err != nil {
+			t.Fatalf("create remainder: %v", err)
+		}
+	}
+
+	min := 2.0
+	resp, err := strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{
+			Fields: map[string][]string{"branch_id": {branch.Id, other.Id}},
+			Ranges: map[string]models.NumberRange{"quantity": {Min: &min}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if resp.Count != 2 {
+		t.Fatalf("IN and range: count = %d, want 2", resp.Count)
+	}
+
+	resp, err = strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}}},
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if resp.Count != 2 {
+		t.Fatalf("equality: count = %d, want 2", resp.Count)
+	}
+
+	// search values are bound, never spliced into SQL
+	resp, err = strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Search: "' OR '1'='1",
+		Filter: models.Filter{Fields: map[string][]string{"branch_id": {branch.Id}}},
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if resp.Count != 0 {
+		t.Fatalf("injection: count = %d, want 0", resp.Count)
+	}
+
+	_, err = strg.Remainder().GetList(ctx, &models.GetListRemainderRequest{
+		Filter: models.Filter{Fields: map[string][]string{"title": {"x"}}},
+	})
+	if err == nil {
+		t.Fatal("undeclared column: expected error")
+	}
+}